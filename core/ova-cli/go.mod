@@ -6,8 +6,10 @@ require (
 	github.com/dgraph-io/badger/v3 v3.2103.5
 	github.com/gin-gonic/gin v1.10.1
 	github.com/google/uuid v1.6.0
+	github.com/klauspost/compress v1.12.3
 	github.com/pterm/pterm v0.12.81
 	github.com/spf13/cobra v1.9.1
+	github.com/zeebo/xxh3 v1.0.2
 	golang.org/x/crypto v0.41.0
 )
 
@@ -22,9 +24,7 @@ require (
 	github.com/golang/protobuf v1.5.0 // indirect
 	github.com/golang/snappy v0.0.3 // indirect
 	github.com/google/flatbuffers v1.12.1 // indirect
-	github.com/klauspost/compress v1.12.3 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
-	github.com/zeebo/xxh3 v1.0.2 // indirect
 	go.opencensus.io v0.22.5 // indirect
 )
 
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+
+	"ova-cli/source/internal/repo"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// adminClient talks to the local admin API of a running server over its Unix socket.
+type adminClient struct {
+	http *http.Client
+}
+
+func newAdminClient(socketPath string) *adminClient {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socketPath)
+		},
+	}
+	return &adminClient{http: &http.Client{Transport: transport}}
+}
+
+// call sends a request to the admin API and decodes the data of a successful response into out.
+func (c *adminClient) call(method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, "http://ova"+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach the running server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Status string          `json:"status"`
+		Data   json.RawMessage `json:"data"`
+		Error  struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("invalid response from the running server: %w", err)
+	}
+	if envelope.Status != "success" {
+		return fmt.Errorf("the running server reported: %s", envelope.Error.Message)
+	}
+	if out != nil && len(envelope.Data) > 0 {
+		return json.Unmarshal(envelope.Data, out)
+	}
+	return nil
+}
+
+// runRepoMutation runs a command that writes the repository at rootDir. While a server runs
+// on the repository the command is handed to it with remote, so the two never overwrite each
+// other's changes; otherwise the repository is opened and locked for the duration of local.
+func runRepoMutation(rootDir, command string, local func(*repo.RepoManager) error, remote func(*adminClient) error) error {
+	handOff := func(socket string, pid int) error {
+		pterm.Info.Printf("A server (pid %d) is running on this repository; it carries out the %s.\n", pid, command)
+		return remote(newAdminClient(socket))
+	}
+
+	if holder := repo.ReadRepoLock(rootDir); holder != nil && holder.Socket != "" {
+		return handOff(holder.Socket, holder.PID)
+	}
+
+	repository, err := repo.NewRepoManager(rootDir)
+	if err != nil {
+		return fmt.Errorf("failed to initialize repository: %w", err)
+	}
+	if err := repository.Lock(command, ""); err != nil {
+		// A server may have started since the lock was checked
+		var locked *repo.RepoLockedError
+		if errors.As(err, &locked) && locked.Holder.Socket != "" {
+			return handOff(locked.Holder.Socket, locked.Holder.PID)
+		}
+		return err
+	}
+	defer repository.Unlock()
+
+	return local(repository)
+}
+
+// openLockedRepository opens the repository given with -r and locks it for command, for
+// commands a running server cannot carry out. While a server or another command holds the
+// lock it fails with a *repo.RepoLockedError; release the lock with Unlock.
+func openLockedRepository(cmd *cobra.Command, command string) (*repo.RepoManager, error) {
+	rootDir, err := repositoryPath(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if holder := repo.ReadRepoLock(rootDir); holder != nil {
+		return nil, &repo.RepoLockedError{Holder: *holder}
+	}
+
+	repository, err := repo.NewRepoManager(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := repository.Lock(command, ""); err != nil {
+		return nil, err
+	}
+	return repository, nil
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	"ova-cli/source/internal/repo"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the video cache of a running server",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Cache command invoked: use a subcommand like 'refresh'.")
+	},
+}
+
+// cacheRefreshCmd rebuilds the in-memory video listing of a running server.
+var cacheRefreshCmd = &cobra.Command{
+	Use:   "refresh",
+	Short: "Reload the video listing of the running server from the repository",
+	Long: `The server keeps the list of videos it serves in memory. This command has it
+reload the list from the repository. Without a running server there is nothing
+to refresh: the list is built when the server starts.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		repoRoot, err := repositoryPath(cmd)
+		if err != nil {
+			pterm.Error.Println(err)
+			return
+		}
+
+		holder := repo.ReadRepoLock(repoRoot)
+		if holder == nil || holder.Socket == "" {
+			pterm.Info.Println("No server is running on this repository; its video cache is built when it starts.")
+			return
+		}
+
+		var result struct {
+			Videos int `json:"videos"`
+		}
+		if err := newAdminClient(holder.Socket).call(http.MethodPost, "/cache/refresh", nil, &result); err != nil {
+			pterm.Error.Println("Failed to refresh the video cache:", err)
+			os.Exit(1)
+		}
+
+		if jsonFlag, _ := cmd.Flags().GetBool("json"); jsonFlag {
+			jsonData, err := json.Marshal(result)
+			if err != nil {
+				fmt.Println("Failed to marshal result to JSON:", err)
+				return
+			}
+			fmt.Println(string(jsonData))
+			return
+		}
+		pterm.Success.Printf("Video cache of the server (pid %d) refreshed: %d videos\n", holder.PID, result.Videos)
+	},
+}
+
+func InitCommandCache(rootCmd *cobra.Command) {
+	cacheRefreshCmd.Flags().StringP("repository", "r", "", "Specify the repository directory")
+	cacheRefreshCmd.Flags().BoolP("json", "j", false, "Output the result in JSON format")
+
+	cacheCmd.AddCommand(cacheRefreshCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
@@ -3,12 +3,9 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 
-	"ova-cli/source/internal/repo"
-
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 )
@@ -40,18 +37,13 @@ var configServerCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		// Create RepoManager instance
-		repoPath, err := filepath.Abs(".")
-		if err != nil {
-			pterm.Error.Println("Failed to resolve path:", err)
-			os.Exit(1)
-		}
-		
-		repoManager, err := repo.NewRepoManager(repoPath)
+		// A running server keeps its config in memory and would not see the change
+		repoManager, err := openLockedRepository(cmd, "config server")
 		if err != nil {
 			fmt.Println("Failed to initialize repository:", err)
 			return
 		}
+		defer repoManager.Unlock()
 
 
 		// Load config from disk (or create default if not exists)
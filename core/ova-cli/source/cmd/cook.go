@@ -2,7 +2,9 @@ package cmd
 
 import (
 	"fmt"
+	"net/http"
 	"os"
+	"ova-cli/source/internal/datatypes"
 	"ova-cli/source/internal/repo"
 
 	"github.com/spf13/cobra"
@@ -12,50 +14,49 @@ import (
 var cookCmd = &cobra.Command{
 	Use:   "cook",
 	Short: "Generate VTT files for all videos in the repository",
+	Long: `Generate the preview thumbnails of every indexed video that has none yet. When a
+server is running on the repository, it does the cooking so the thumbnails are
+served right away.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		repoRoot, err := os.Getwd()
+		repoRoot, err := repositoryPath(cmd)
 		if err != nil {
-			fmt.Println("Failed to get working directory:", err)
+			fmt.Println(err)
 			return
 		}
 
-		repoManager, err := repo.NewRepoManager(repoRoot)
+		var summary *datatypes.CookSummary
+		err = runRepoMutation(repoRoot, "cook",
+			func(repository *repo.RepoManager) error {
+				summary, err = repository.CookAllVideos(func(percent int) {
+					fmt.Printf("\rProgress: %d%%", percent) // Update progress in place
+				})
+				fmt.Println()
+				return err
+			},
+			func(client *adminClient) error {
+				return client.call(http.MethodPost, "/cook", nil, &summary)
+			})
 		if err != nil {
-			fmt.Println("Failed to initialize repository:", err)
-			return
+			fmt.Println("Cooking failed:", err)
+			if summary == nil {
+				os.Exit(1)
+			}
 		}
 
-		// Scan for all video paths
-		videoPaths, err := repoManager.ScanDiskForVideos()
-		if err != nil || len(videoPaths) == 0 {
+		for _, msg := range summary.Errors {
+			fmt.Printf("Cooking Error: %s\n", msg)
+		}
+		if summary.VideosFound == 0 {
 			fmt.Println("No videos found in the repository.")
 			return
 		}
-
-		progressChan := make(chan int)
-		errorChan := make(chan error)
-
-		go func() {
-			for progress := range progressChan {
-				fmt.Printf("\rProgress: %d%%", progress) // Update progress in place
-			}
-		}()
-
-		go func() {
-			for err := range errorChan {
-				fmt.Printf("\nCooking Error: %v\n", err)
-			}
-		}()
-
-		// Use the new CookMultiVideos method
-		_ = repoManager.CookMultiVideos(videoPaths, progressChan, errorChan)
-
-		// Print completion message
-		fmt.Println("\n✅ Sprite sheets and VTT generation complete.")
+		fmt.Printf("Cooked: %d, already cooked: %d, failed: %d\n", summary.Cooked, summary.Skipped, summary.Failed)
+		fmt.Println("✅ Sprite sheets and VTT generation complete.")
 	},
 }
 
 func InitCommandCook(rootCmd *cobra.Command) {
+	cookCmd.Flags().StringP("repository", "r", "", "Specify the repository directory")
 
 	rootCmd.AddCommand(cookCmd)
 }
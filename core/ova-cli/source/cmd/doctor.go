@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"ova-cli/source/internal/datatypes"
+	"ova-cli/source/internal/repo"
+	"path/filepath"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// doctorCmd checks the external tools and the health of a repository.
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the external tools, codecs, repository files and certificates, and suggest fixes",
+	Long: `Checks that ffmpeg, ffprobe, openssl and mp4info are installed and recent enough,
+that ffmpeg includes the encoders and filters ova uses, and, inside a repository, that its
+folders are writable, its storage files are valid, every indexed video still has its file,
+no artefacts are left over from removed videos and its certificates have not expired.
+Exits with status 1 when a check fails.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		repoAddress, _ := cmd.Flags().GetString("repository")
+		explicitRepo := repoAddress != ""
+		if !explicitRepo {
+			repoAddress, _ = os.Getwd()
+		}
+		absPath, err := filepath.Abs(repoAddress)
+		if err != nil {
+			fmt.Printf("Error resolving absolute path: %v\n", err)
+			return
+		}
+
+		checks := repo.CheckEnvironment()
+
+		// Outside a repository only the environment is checked, unless one was named with -r
+		_, statErr := os.Stat(filepath.Join(absPath, ".ova-repo"))
+		repoPath := ""
+		if explicitRepo || statErr == nil {
+			repoPath = absPath
+			checks = append(checks, repo.DiagnoseRepository(absPath)...)
+		}
+
+		report := repo.NewDoctorReport(repoPath, checks)
+
+		jsonFlag, _ := cmd.Flags().GetBool("json")
+		if jsonFlag {
+			jsonData, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				fmt.Println("Failed to marshal report to JSON:", err)
+				return
+			}
+			fmt.Println(string(jsonData))
+		} else {
+			printDoctorReport(report)
+		}
+
+		if !report.Healthy() {
+			os.Exit(1)
+		}
+	},
+}
+
+// printDoctorReport prints the checks grouped by category, followed by the fixes.
+func printDoctorReport(report datatypes.DoctorReport) {
+	category := ""
+	for _, check := range report.Checks {
+		if check.Category != category {
+			category = check.Category
+			pterm.DefaultSection.Println(category)
+		}
+
+		line := fmt.Sprintf("%-16s %s", check.Name, check.Message)
+		switch check.Status {
+		case datatypes.DoctorStatusOK:
+			pterm.Success.Println(line)
+			continue
+		case datatypes.DoctorStatusWarn:
+			pterm.Warning.Println(line)
+		default:
+			pterm.Error.Println(line)
+		}
+
+		for i, detail := range check.Details {
+			if i == 10 {
+				pterm.Printf("    ... and %d more (see --json)\n", len(check.Details)-i)
+				break
+			}
+			pterm.Println("    " + detail)
+		}
+		if check.Fix != "" {
+			pterm.Println("    Fix: " + check.Fix)
+		}
+	}
+
+	pterm.Println()
+	if report.RepoPath == "" {
+		pterm.Info.Println("Not inside a repository; only the environment was checked (use -r to check one)")
+	}
+	summary := fmt.Sprintf("%d ok, %d warnings, %d failures", report.OK, report.Warnings, report.Failures)
+	if report.Healthy() {
+		pterm.Success.Println(summary)
+	} else {
+		pterm.Error.Println(summary)
+	}
+}
+
+func InitCommandDoctor(rootCmd *cobra.Command) {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().StringP("repository", "r", "", "Specify the repository directory")
+	doctorCmd.Flags().BoolP("json", "j", false, "Output the report in JSON format")
+}
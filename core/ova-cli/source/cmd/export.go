@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// exportCmd writes a space to a bundle that 'ova import' reads.
+var exportCmd = &cobra.Command{
+	Use:   "export <space>",
+	Short: "Export a space to a portable bundle",
+	Long: `Export the videos of a space to a self-describing tar.zst bundle: the video
+files, their metadata, tags, markers, subtitles, thumbnails and previews, and
+the parts of users' playlists that reference them. Import the bundle into
+another repository with 'ova import'.
+
+With --by-reference the video files are left out of the bundle; the importing
+repository copies them from their current location, which must be reachable
+from there.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		repository, err := openRepository(cmd)
+		if err != nil {
+			fmt.Println("Failed to initialize repository:", err)
+			return
+		}
+
+		byReference, _ := cmd.Flags().GetBool("by-reference")
+		output, _ := cmd.Flags().GetString("output")
+		if output == "" {
+			output = fmt.Sprintf("ova-export-%s-%s.tar.zst", args[0], time.Now().Format("20060102-150405"))
+		}
+
+		manifest, err := repository.ExportSpace(args[0], output, byReference)
+		if err != nil {
+			pterm.Error.Println("Export failed:", err)
+			os.Exit(1)
+		}
+
+		if jsonFlag, _ := cmd.Flags().GetBool("json"); jsonFlag {
+			jsonData, err := json.Marshal(map[string]any{
+				"bundleId":    manifest.BundleID,
+				"output":      output,
+				"space":       manifest.Space.SpaceName,
+				"byReference": manifest.ByReference,
+				"videos":      len(manifest.Videos),
+				"playlists":   len(manifest.Playlists),
+			})
+			if err != nil {
+				fmt.Println("Failed to marshal result to JSON:", err)
+				return
+			}
+			fmt.Println(string(jsonData))
+			return
+		}
+
+		size := int64(0)
+		if info, err := os.Stat(output); err == nil {
+			size = info.Size()
+		}
+		pterm.Success.Printf("Exported %d videos and %d playlists of %s to %s (%s)\n",
+			len(manifest.Videos), len(manifest.Playlists), manifest.Space.SpaceName, output, formatSize(size))
+	},
+}
+
+func InitCommandExport(rootCmd *cobra.Command) {
+	exportCmd.Flags().StringP("repository", "r", "", "Specify the repository directory")
+	exportCmd.Flags().StringP("output", "o", "", "Bundle to write (default ova-export-<space>-<time>.tar.zst)")
+	exportCmd.Flags().Bool("by-reference", false, "Leave the video files out and record their paths instead")
+	exportCmd.Flags().BoolP("json", "j", false, "Output the result in JSON format")
+
+	rootCmd.AddCommand(exportCmd)
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"ova-cli/source/internal/datatypes"
+	"ova-cli/source/internal/repo"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// importCmd merges a bundle written by 'ova export' into a repository.
+var importCmd = &cobra.Command{
+	Use:   "import <bundle>",
+	Short: "Import a bundle written by 'ova export'",
+	Long: `Merge the videos and playlists of an exported bundle into the repository,
+in the space they were exported from or the one given with --space, which is
+created if needed.
+
+Videos are matched by content: a video that is already in the library is not
+copied again but gets the bundle's tags and playlist entries. A bundled video
+whose ID is used by other content here is imported under a new ID, and a file
+that would overwrite another one is renamed. Every such case is reported as a
+conflict. Use --dry-run to see the report without changing anything.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, err := repositoryPath(cmd)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		// A server resolves paths against its own working directory
+		bundle, err := filepath.Abs(args[0])
+		if err != nil {
+			pterm.Error.Println("Failed to resolve bundle path:", err)
+			return
+		}
+
+		space, _ := cmd.Flags().GetString("space")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		var report *datatypes.ImportReport
+		err = runRepoMutation(rootDir, "import",
+			func(repository *repo.RepoManager) error {
+				report, err = repository.ImportBundle(bundle, space, dryRun)
+				return err
+			},
+			func(client *adminClient) error {
+				body := map[string]any{"bundle": bundle, "space": space, "dryRun": dryRun}
+				return client.call(http.MethodPost, "/import", body, &report)
+			})
+		if err != nil {
+			pterm.Error.Println("Import failed:", err)
+			os.Exit(1)
+		}
+
+		if jsonFlag, _ := cmd.Flags().GetBool("json"); jsonFlag {
+			jsonData, err := json.Marshal(report)
+			if err != nil {
+				fmt.Println("Failed to marshal report to JSON:", err)
+				return
+			}
+			fmt.Println(string(jsonData))
+			return
+		}
+
+		if dryRun {
+			pterm.Info.Println("Dry run: nothing was changed.")
+		}
+		if len(report.Videos) > 0 {
+			tableData := pterm.TableData{{"Bundle ID", "Video ID", "Path", "Status"}}
+			for _, video := range report.Videos {
+				tableData = append(tableData, []string{video.BundleVideoID, video.VideoID, video.Path, video.Status})
+			}
+			pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+		}
+
+		counts := map[string]int{}
+		for _, video := range report.Videos {
+			counts[video.Status]++
+		}
+		created := ""
+		if report.SpaceCreated {
+			created = " (new space)"
+		}
+		pterm.Success.Printf("Imported into %s%s: %d new, %d renamed, %d already present, %d skipped; %d playlists created, %d merged\n",
+			report.Space, created, counts[datatypes.ImportStatusNew], counts[datatypes.ImportStatusRenamed], counts[datatypes.ImportStatusExisting], counts[datatypes.ImportStatusSkipped],
+			report.PlaylistsCreated, report.PlaylistsMerged)
+
+		for _, conflict := range report.Conflicts {
+			pterm.Warning.Println(conflict)
+		}
+	},
+}
+
+func InitCommandImport(rootCmd *cobra.Command) {
+	importCmd.Flags().StringP("repository", "r", "", "Specify the repository directory")
+	importCmd.Flags().String("space", "", "Space to import into (default the exported space)")
+	importCmd.Flags().Bool("dry-run", false, "Report what would be imported without changing anything")
+	importCmd.Flags().BoolP("json", "j", false, "Output the report in JSON format")
+
+	rootCmd.AddCommand(importCmd)
+}
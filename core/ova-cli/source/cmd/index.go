@@ -2,9 +2,10 @@ package cmd
 
 import (
 	"fmt"
+	"net/http"
 	"os"
+	"ova-cli/source/internal/datatypes"
 	"ova-cli/source/internal/repo"
-	"sync"
 
 	"github.com/spf13/cobra"
 )
@@ -13,104 +14,58 @@ import (
 var indexCmd = &cobra.Command{
 	Use:   "index",
 	Short: "index all videos from disk",
+	Long: `Register the spaces found on disk and index every video in them that is not
+indexed yet. When a server is running on the repository, it does the indexing
+so its listings pick up the new videos.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		repoRoot, err := os.Getwd()
+		repoRoot, err := repositoryPath(cmd)
 		if err != nil {
-			fmt.Println("Failed to get working directory:", err)
+			fmt.Println(err)
 			return
 		}
 
-		repository, err := repo.NewRepoManager(repoRoot)
-		if err != nil {
-			fmt.Println("Failed to initialize repository:", err)
-			return
-		}
-
-		repository.ScanAndAddAllSpaces()
-
-		// Step 1: Scan the disk for all spaces
-		spaces, err := repository.ScanDiskForSpaces()
-		if err != nil {
-			fmt.Printf("Error scanning disk at path '%s': %v\n", repoRoot, err)
-			return
-		}
-
-		if len(spaces) == 0 {
-			fmt.Println("No spaces found on disk to process.")
-			return
-		}
-
-		// Step 2: Loop through each space and index its videos
-		for _, space := range spaces {
-			spaceVideos := repository.GetVideosFromSpaceScan(space)
-
-			if len(spaceVideos) == 0 {
-				fmt.Printf("\nNo videos found in space '%s'. Skipping.\n", space.Space)
-				continue
-			}
-
-			fmt.Printf("\nIndexing Videos on space: '%s'\n", space.Space)
-			fmt.Printf("Found %d videos.\n", len(spaceVideos))
-
-			// Channels for THIS SPACE ONLY
-			indexingProgressChan := make(chan int)
-			indexingErrorChan := make(chan error)
-
-			var wg sync.WaitGroup
-			var errors []error
-
-			// Handle progress
-			wg.Add(1)
-			go func() {
-				defer wg.Done()
-				for progress := range indexingProgressChan {
-					fmt.Printf("\rProgress: %3d%%", progress)
+		var summary *datatypes.IndexSummary
+		err = runRepoMutation(repoRoot, "index",
+			func(repository *repo.RepoManager) error {
+				current := ""
+				summary, err = repository.IndexAllSpaces(func(space string, percent int) {
+					if space != current {
+						current = space
+						fmt.Printf("\nIndexing videos of space '%s'\n", space)
+					}
+					fmt.Printf("\rProgress: %3d%%", percent)
+				})
+				if current != "" {
+					fmt.Println()
 				}
-			}()
-
-			// Handle errors (collect for later printing)
-			wg.Add(1)
-			go func() {
-				defer wg.Done()
-				for err := range indexingErrorChan {
-					errors = append(errors, err)
-				}
-			}()
-
-			// ---- Call IndexMultiVideos ----
-			_, err := repository.IndexMultiVideos(
-				spaceVideos,
-				indexingProgressChan,
-				indexingErrorChan,
-			)
-
-			// Wait for goroutines
-			wg.Wait()
-
-			// Print result
-			fmt.Printf("\rProgress: 100%%\n")
-			if err != nil {
-				fmt.Printf("Failed to index space '%s': %v\n", space.Space, err)
-			}
-
-			// Print collected errors if any
-			if len(errors) > 0 {
-				fmt.Printf("Errors: %d\n", len(errors))
+				return err
+			},
+			func(client *adminClient) error {
+				return client.call(http.MethodPost, "/index", nil, &summary)
+			})
+		if err != nil {
+			fmt.Println("Indexing failed:", err)
+			if summary == nil {
+				os.Exit(1)
 			}
 		}
 
+		for _, msg := range summary.Errors {
+			fmt.Println(msg)
+		}
 		fmt.Println()
 		fmt.Println("Indexing Summary")
 		fmt.Println("================")
-		fmt.Printf("Spaces processed: %d\n", 4)
-		fmt.Printf("Videos found: %d\n", 6)
-		fmt.Printf("Indexed: %d\n", 0)
-		fmt.Printf("Skipped (already indexed): %d\n", 6)
-		fmt.Printf("Errors: %d\n", 0)
+		fmt.Printf("Spaces processed: %d\n", summary.Spaces)
+		fmt.Printf("Videos found: %d\n", summary.VideosFound)
+		fmt.Printf("Indexed: %d\n", summary.Indexed)
+		fmt.Printf("Skipped (already indexed): %d\n", summary.Skipped)
+		fmt.Printf("Errors: %d\n", summary.Failed)
 	},
 }
 
 func InitCommandIndex(rootCmd *cobra.Command) {
+	indexCmd.Flags().StringP("repository", "r", "", "Specify the repository directory")
 
 	rootCmd.AddCommand(indexCmd)
 }
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"ova-cli/source/internal/repo"
+	"strconv"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// playlistCmd is the root command for playlist operations.
+var playlistCmd = &cobra.Command{
+	Use:   "playlist",
+	Short: "Import and export user playlists",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Playlist command invoked: use a subcommand like 'export' or 'import'.")
+	},
+}
+
+// playlistExportCmd writes a user's playlist as M3U8, XSPF or JSON.
+var playlistExportCmd = &cobra.Command{
+	Use:   "export <slug>",
+	Short: "Export a user playlist as m3u8, xspf or json",
+	Long: `Export a user playlist. M3U8 and XSPF entries point at the stream URLs of
+the server given by --base-url (default: the configured host and port).
+The json format is ova's portable format, which keeps relative paths and
+content hashes so the playlist can be imported into another repository.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		repository, err := openRepository(cmd)
+		if err != nil {
+			fmt.Println("Failed to initialize repository:", err)
+			return
+		}
+
+		username, _ := cmd.Flags().GetString("user")
+		if username == "" {
+			username = repository.GetRootUsername()
+		}
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		baseURL, _ := cmd.Flags().GetString("base-url")
+		if baseURL == "" {
+			cfg := repository.GetConfigs()
+			host := cfg.ServerHost
+			if host == "" || host == "0.0.0.0" {
+				host = "localhost"
+			}
+			baseURL = "http://" + host + ":" + strconv.Itoa(cfg.ServerPort)
+		}
+
+		data, _, err := repository.ExportPlaylist(username, args[0], format, baseURL)
+		if err != nil {
+			pterm.Error.Println("Failed to export playlist:", err)
+			return
+		}
+
+		if output == "" {
+			fmt.Print(string(data))
+			return
+		}
+		if err := os.WriteFile(output, data, 0644); err != nil {
+			pterm.Error.Println("Failed to write playlist:", err)
+			return
+		}
+		pterm.Success.Printf("Playlist %s exported to %s\n", args[0], output)
+	},
+}
+
+// playlistImportCmd reads a playlist file into a user's playlists.
+var playlistImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import a playlist from an m3u8, xspf or json file",
+	Long: `Import a playlist file. Entries are matched to indexed videos by video ID,
+stream URL, path relative to the repository or content hash. Videos are added
+to the playlist named by --title (default: the title stored in the file),
+which is created if it does not exist. Unmatched entries are reported.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, err := repositoryPath(cmd)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		username, _ := cmd.Flags().GetString("user")
+		format, _ := cmd.Flags().GetString("format")
+		title, _ := cmd.Flags().GetString("title")
+
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			pterm.Error.Println("Failed to read playlist file:", err)
+			return
+		}
+
+		var report *repo.PlaylistImportReport
+		err = runRepoMutation(rootDir, "playlist import",
+			func(repository *repo.RepoManager) error {
+				if username == "" {
+					username = repository.GetRootUsername()
+				}
+				report, err = repository.ImportPlaylist(username, data, format, title, true)
+				return err
+			},
+			func(client *adminClient) error {
+				body := map[string]any{"username": username, "data": data, "format": format, "title": title}
+				return client.call(http.MethodPost, "/playlists/import", body, &report)
+			})
+		if err != nil {
+			pterm.Error.Println("Failed to import playlist:", err)
+			return
+		}
+
+		jsonFlag, _ := cmd.Flags().GetBool("json")
+		if jsonFlag {
+			jsonData, err := json.Marshal(report)
+			if err != nil {
+				fmt.Println("Failed to marshal report to JSON:", err)
+				return
+			}
+			fmt.Println(string(jsonData))
+			return
+		}
+
+		printPlaylistImportReport(report)
+	},
+}
+
+func printPlaylistImportReport(report *repo.PlaylistImportReport) {
+	action := "updated"
+	if report.Created {
+		action = "created"
+	}
+	pterm.Success.Printf("Playlist %s %s: %d added, %d already present, %d unresolved\n",
+		report.Slug, action, len(report.Added), report.Duplicates, len(report.Unresolved))
+
+	if len(report.Unresolved) == 0 {
+		return
+	}
+
+	tableData := pterm.TableData{{"Entry", "Reason"}}
+	for _, entry := range report.Unresolved {
+		tableData = append(tableData, []string{entry.Entry, entry.Reason})
+	}
+	pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+}
+
+func InitCommandPlaylist(rootCmd *cobra.Command) {
+	playlistCmd.AddCommand(playlistExportCmd)
+	playlistExportCmd.Flags().StringP("repository", "r", "", "Specify the repository directory")
+	playlistExportCmd.Flags().StringP("user", "u", "", "Owner of the playlist (default: root user)")
+	playlistExportCmd.Flags().StringP("format", "f", repo.PlaylistFormatM3U8, "Output format: m3u8, xspf or json")
+	playlistExportCmd.Flags().StringP("output", "o", "", "Write to this file instead of stdout")
+	playlistExportCmd.Flags().String("base-url", "", "Server URL used for stream links (default: configured host and port)")
+
+	playlistCmd.AddCommand(playlistImportCmd)
+	playlistImportCmd.Flags().StringP("repository", "r", "", "Specify the repository directory")
+	playlistImportCmd.Flags().StringP("user", "u", "", "User to import the playlist for (default: root user)")
+	playlistImportCmd.Flags().StringP("format", "f", "", "Input format: m3u8, xspf or json (default: detect)")
+	playlistImportCmd.Flags().StringP("title", "t", "", "Title of the target playlist (default: title in the file)")
+	playlistImportCmd.Flags().BoolP("json", "j", false, "Output the import report in JSON format")
+
+	rootCmd.AddCommand(playlistCmd)
+}
@@ -2,10 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
-
-	"ova-cli/source/internal/repo"
 
 	"github.com/spf13/cobra"
 )
@@ -15,27 +11,13 @@ var repoPurgeCmd = &cobra.Command{
 	Short: "Purge repository",
 	Run: func(cmd *cobra.Command, args []string) {
 
-		// Get the repository address from the --repository flag
-		repoAddress, _ := cmd.Flags().GetString("repository")
-
-		// If repository address is not provided, use the current working directory (os.Getwd())
-		if repoAddress == "" {
-			repoAddress, _ = os.Getwd() // Default to the current working directory
-		}
-
-		// Resolve the absolute path of the repository
-		absPath, err := filepath.Abs(repoAddress)
-		if err != nil {
-			fmt.Printf("Error resolving absolute path: %v\n", err)
-			return
-		}
-
-		// Create a new RepoManager instance
-		repository, err := repo.NewRepoManager(absPath)
+		// Purging under a running server would leave it serving deleted data
+		repository, err := openLockedRepository(cmd, "purge")
 		if err != nil {
 			fmt.Println("Failed to initialize repository:", err)
 			return
 		}
+		defer repository.Unlock()
 
 		// Ask for confirmation before purging
 		fmt.Print("Are you sure you want to purge the repository? This action cannot be undone. (y/N): ")
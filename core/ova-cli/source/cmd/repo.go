@@ -238,6 +238,12 @@ var repoDuplicateCmd = &cobra.Command{
 			return
 		}
 
+		// Perceptual comparison of fingerprints instead of file hashes
+		if near, _ := cmd.Flags().GetBool("near"); near {
+			printNearDuplicates(cmd, repository)
+			return
+		}
+
 		// Scan for duplicate videos
 		duplicateVideos, err := repository.ScanDiskForDuplicateVideos()
 		if err != nil {
@@ -287,6 +293,35 @@ var repoDuplicateCmd = &cobra.Command{
 	},
 }
 
+// openRepository opens the repository given by the --repository flag,
+// or the current working directory when the flag is empty.
+func openRepository(cmd *cobra.Command) (*repo.RepoManager, error) {
+	absPath, err := repositoryPath(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return repo.NewRepoManager(absPath)
+}
+
+// repositoryPath returns the absolute path of the repository given with -r, or of the
+// current directory, for commands that work on a repository without opening it.
+func repositoryPath(cmd *cobra.Command) (string, error) {
+	repoAddress, _ := cmd.Flags().GetString("repository")
+	if repoAddress == "" {
+		var err error
+		repoAddress, err = os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("failed to get current working directory: %w", err)
+		}
+	}
+
+	absPath, err := filepath.Abs(repoAddress)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve absolute path: %w", err)
+	}
+	return absPath, nil
+}
+
 func InitCommandRepo(rootCmd *cobra.Command) {
 
 	// Add flags for the repo info and videos commands
@@ -302,11 +337,18 @@ func InitCommandRepo(rootCmd *cobra.Command) {
 	repoCmd.AddCommand(repoDuplicateCmd)
 	repoDuplicateCmd.Flags().StringP("repository", "r", "", "Path to the video repository (default: current directory)")
 	repoDuplicateCmd.Flags().BoolP("json", "j", false, "Output results in JSON format")
+	initRepoNearDuplicateCommands()
 
 	repoCmd.AddCommand(repoVideosCmd)
 	repoVideosCmd.Flags().BoolP("json", "j", false, "Output the video paths in JSON format")
 	repoVideosCmd.Flags().StringP("repository", "r", "", "Specify the repository directory")
 
+	initRepoAttachCommands()
+	initRepoGCCommands()
+	initRepoVerifyCommands()
+	initRepoBackupCommands()
+	initRepoMigrateCommands()
+
 	// Add the repoCmd to the root command (which could be `rootCmd`)
 	rootCmd.AddCommand(repoCmd)
 }
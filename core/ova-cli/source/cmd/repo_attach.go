@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"ova-cli/source/internal/repo"
+	"path/filepath"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// repoAttachCmd attaches another ova repository to this one.
+var repoAttachCmd = &cobra.Command{
+	Use:   "attach <name> <path>",
+	Short: "Attach another repository so its videos appear in this library",
+	Long: `Attach another ova repository, e.g. one on an external drive. Its videos are
+listed, searched and streamed through this repository with IDs prefixed by
+"<name>:". When the drive is not connected the repository is reported as
+unavailable and its videos are hidden until it comes back.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, err := repositoryPath(cmd)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		// A server resolves paths against its own working directory
+		path, err := filepath.Abs(args[1])
+		if err != nil {
+			pterm.Error.Println("Failed to resolve path:", err)
+			return
+		}
+
+		err = runRepoMutation(rootDir, "repo attach",
+			func(repository *repo.RepoManager) error {
+				return repository.AttachSubRepository(args[0], path)
+			},
+			func(client *adminClient) error {
+				return client.call(http.MethodPost, "/repositories", map[string]string{"name": args[0], "path": path}, nil)
+			})
+		if err != nil {
+			pterm.Error.Println("Failed to attach repository:", err)
+			return
+		}
+		pterm.Success.Printf("Repository %s attached as %s\n", args[1], args[0])
+	},
+}
+
+// repoDetachCmd detaches a previously attached repository.
+var repoDetachCmd = &cobra.Command{
+	Use:   "detach <name>",
+	Short: "Detach an attached repository (its data is left untouched)",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, err := repositoryPath(cmd)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		err = runRepoMutation(rootDir, "repo detach",
+			func(repository *repo.RepoManager) error {
+				return repository.DetachSubRepository(args[0])
+			},
+			func(client *adminClient) error {
+				return client.call(http.MethodDelete, "/repositories/"+url.PathEscape(args[0]), nil, nil)
+			})
+		if err != nil {
+			pterm.Error.Println("Failed to detach repository:", err)
+			return
+		}
+		pterm.Success.Printf("Repository %s detached\n", args[0])
+	},
+}
+
+// repoChildrenCmd lists attached repositories and their availability.
+var repoChildrenCmd = &cobra.Command{
+	Use:   "children",
+	Short: "List attached repositories and whether they are available",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		repository, err := openRepository(cmd)
+		if err != nil {
+			fmt.Println("Failed to initialize repository:", err)
+			return
+		}
+
+		statuses := repository.GetSubRepositoryStatuses()
+
+		jsonFlag, _ := cmd.Flags().GetBool("json")
+		if jsonFlag {
+			jsonData, err := json.Marshal(statuses)
+			if err != nil {
+				fmt.Println("Failed to marshal repositories to JSON:", err)
+				return
+			}
+			fmt.Println(string(jsonData))
+			return
+		}
+
+		if len(statuses) == 0 {
+			fmt.Println("No attached repositories.")
+			return
+		}
+
+		tableData := pterm.TableData{{"Name", "Path", "Status", "Videos"}}
+		for _, status := range statuses {
+			state := "online"
+			if !status.Online {
+				state = "unavailable"
+			}
+			tableData = append(tableData, []string{
+				status.Name,
+				status.Path,
+				state,
+				fmt.Sprintf("%d", status.TotalVideos),
+			})
+		}
+		pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+	},
+}
+
+// initRepoAttachCommands adds the sub repository commands to the repo command.
+func initRepoAttachCommands() {
+	repoCmd.AddCommand(repoAttachCmd)
+	repoAttachCmd.Flags().StringP("repository", "r", "", "Specify the repository directory")
+
+	repoCmd.AddCommand(repoDetachCmd)
+	repoDetachCmd.Flags().StringP("repository", "r", "", "Specify the repository directory")
+
+	repoCmd.AddCommand(repoChildrenCmd)
+	repoChildrenCmd.Flags().StringP("repository", "r", "", "Specify the repository directory")
+	repoChildrenCmd.Flags().BoolP("json", "j", false, "Output the repositories in JSON format")
+}
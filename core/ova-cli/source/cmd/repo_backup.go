@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"ova-cli/source/internal/repo"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// repoBackupCmd writes a compressed archive of the repository data folder.
+var repoBackupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Back up the repository metadata to a tar.zst archive",
+	Long: `Back up the repository's .ova-repo folder: the config, all storage JSON
+including users, playlists and sessions, markers and subtitles. Thumbnails,
+previews and other regenerable media are added with --media, certificates
+with --ssl. Video files, the trash and pre-migration backups are never
+included.
+
+With --incremental the archive only holds what changed since the given backup;
+restore it together with that backup.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		repository, err := openRepository(cmd)
+		if err != nil {
+			fmt.Println("Failed to initialize repository:", err)
+			return
+		}
+
+		var opts repo.BackupOptions
+		opts.IncludeMedia, _ = cmd.Flags().GetBool("media")
+		opts.IncludeSSL, _ = cmd.Flags().GetBool("ssl")
+		opts.BaseArchive, _ = cmd.Flags().GetString("incremental")
+
+		output, _ := cmd.Flags().GetString("output")
+		if output == "" {
+			kind := "full"
+			if opts.BaseArchive != "" {
+				kind = "incremental"
+			}
+			output = fmt.Sprintf("ova-backup-%s-%s.tar.zst", time.Now().Format("20060102-150405"), kind)
+		}
+
+		manifest, err := repository.CreateBackup(output, opts)
+		if err != nil {
+			pterm.Error.Println("Backup failed:", err)
+			os.Exit(1)
+		}
+
+		if jsonFlag, _ := cmd.Flags().GetBool("json"); jsonFlag {
+			manifest.Files = nil
+			jsonData, err := json.Marshal(manifest)
+			if err != nil {
+				fmt.Println("Failed to marshal manifest to JSON:", err)
+				return
+			}
+			fmt.Println(string(jsonData))
+			return
+		}
+
+		size := int64(0)
+		if info, err := os.Stat(output); err == nil {
+			size = info.Size()
+		}
+		pterm.Success.Printf("Wrote %s backup %s: %d of %d files (%s, %s compressed)\n",
+			manifest.Kind, output, manifest.Archived, len(manifest.Files), formatSize(manifest.ArchivedBytes), formatSize(size))
+	},
+}
+
+// repoRestoreCmd rebuilds the repository data folder from backups.
+var repoRestoreCmd = &cobra.Command{
+	Use:   "restore <backup> [incremental-backup...]",
+	Short: "Restore the repository metadata from a full backup and its incremental backups",
+	Long: `Restore a repository's .ova-repo folder from a full backup, followed by the
+incremental backups made on top of it in the order they were made. The target
+is the repository given with -r, or the current directory; it may be a fresh
+folder. An existing repository is only replaced with --force and is kept next
+to the restored one.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target, err := repositoryPath(cmd)
+		if err != nil {
+			pterm.Error.Println(err)
+			return
+		}
+
+		force, _ := cmd.Flags().GetBool("force")
+		manifest, previousDir, err := repo.RestoreBackup(target, args, force)
+		if err != nil {
+			pterm.Error.Println("Restore failed:", err)
+			os.Exit(1)
+		}
+
+		if jsonFlag, _ := cmd.Flags().GetBool("json"); jsonFlag {
+			jsonData, err := json.Marshal(map[string]any{
+				"backupId":    manifest.BackupID,
+				"createdAt":   manifest.CreatedAt,
+				"files":       len(manifest.Files),
+				"previousDir": previousDir,
+			})
+			if err != nil {
+				fmt.Println("Failed to marshal result to JSON:", err)
+				return
+			}
+			fmt.Println(string(jsonData))
+			return
+		}
+
+		pterm.Success.Printf("Restored %d files from the backup of %s into %s\n",
+			len(manifest.Files), manifest.CreatedAt.Local().Format(time.DateTime), target)
+		if previousDir != "" {
+			pterm.Info.Printf("The previous repository data was kept in %s\n", previousDir)
+		}
+		if !manifest.IncludesMedia {
+			pterm.Info.Println("The backup has no media; run 'ova cook' to regenerate missing thumbnails and previews.")
+		}
+	},
+}
+
+// initRepoBackupCommands adds the backup and restore commands to the repo command.
+func initRepoBackupCommands() {
+	repoCmd.AddCommand(repoBackupCmd)
+	repoBackupCmd.Flags().StringP("repository", "r", "", "Specify the repository directory")
+	repoBackupCmd.Flags().StringP("output", "o", "", "Archive to write (default ova-backup-<time>-<kind>.tar.zst)")
+	repoBackupCmd.Flags().String("incremental", "", "Only store changes since this earlier backup")
+	repoBackupCmd.Flags().Bool("media", false, "Include thumbnails, previews, preview thumbnails and audio variants")
+	repoBackupCmd.Flags().Bool("ssl", false, "Include certificates and private keys")
+	repoBackupCmd.Flags().BoolP("json", "j", false, "Output the manifest in JSON format")
+
+	repoCmd.AddCommand(repoRestoreCmd)
+	repoRestoreCmd.Flags().StringP("repository", "r", "", "Repository directory to restore into")
+	repoRestoreCmd.Flags().Bool("force", false, "Replace an existing repository")
+	repoRestoreCmd.Flags().BoolP("json", "j", false, "Output the result in JSON format")
+}
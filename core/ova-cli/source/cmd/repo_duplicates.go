@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"ova-cli/source/internal/datatypes"
+	"ova-cli/source/internal/repo"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// printNearDuplicates runs `ova repo duplicates --near`: it groups videos by perceptual
+// fingerprint, optionally fingerprinting videos cooked before fingerprints were recorded.
+func printNearDuplicates(cmd *cobra.Command, repository *repo.RepoManager) {
+	jsonFlag, _ := cmd.Flags().GetBool("json")
+	threshold, _ := cmd.Flags().GetFloat64("threshold")
+
+	if fingerprint, _ := cmd.Flags().GetBool("fingerprint"); fingerprint {
+		// Fingerprints are stored with the videos, which a running server also writes
+		if err := repository.Lock("repo duplicates", ""); err != nil {
+			pterm.Error.Println("Failed to fingerprint videos:", err)
+			return
+		}
+		defer repository.Unlock()
+
+		videos, err := repository.GetAllIndexedVideos()
+		if err != nil {
+			pterm.Error.Println("Failed to list videos:", err)
+			return
+		}
+		for _, video := range videos {
+			if video.Fingerprint != nil {
+				continue
+			}
+			if _, err := repository.FingerprintVideo(video.VideoID); err != nil && !jsonFlag {
+				pterm.Warning.Printf("%s: %v\n", video.VideoID, err)
+			}
+		}
+	}
+
+	report, err := repository.FindNearDuplicates(threshold)
+	if err != nil {
+		pterm.Error.Println("Failed to find near-duplicates:", err)
+		return
+	}
+
+	if jsonFlag {
+		jsonData, err := json.Marshal(report)
+		if err != nil {
+			fmt.Println("Failed to marshal near-duplicates to JSON:", err)
+			return
+		}
+		fmt.Println(string(jsonData))
+		return
+	}
+
+	if len(report.Groups) == 0 {
+		fmt.Println("No near-duplicate videos found.")
+	}
+	for i, group := range report.Groups {
+		pterm.DefaultSection.Printf("Group %d\n", i+1)
+		tableData := pterm.TableData{{"", "Video ID", "Path", "Resolution", "Duration", "Size", "Similarity"}}
+		for _, video := range group.Videos {
+			mark := ""
+			if video.VideoID == group.KeepVideoID {
+				mark = "keep"
+			}
+			tableData = append(tableData, []string{
+				mark,
+				video.VideoID,
+				video.Path,
+				fmt.Sprintf("%dx%d", video.Resolution.Width, video.Resolution.Height),
+				fmt.Sprintf("%ds", video.DurationSec),
+				formatSize(video.FileSize),
+				fmt.Sprintf("%.0f%%", video.Similarity*100),
+			})
+		}
+		pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+	}
+
+	if report.Skipped > 0 {
+		pterm.Info.Printf("%d videos have no fingerprint yet; cook them or rerun with --fingerprint.\n", report.Skipped)
+	}
+	if len(report.Groups) > 0 {
+		pterm.Info.Println("Keep one copy of a group with `ova repo duplicates merge <keep-id> <duplicate-id>...`")
+	}
+}
+
+// repoDuplicateMergeCmd keeps one copy of a near-duplicate group and removes the others.
+var repoDuplicateMergeCmd = &cobra.Command{
+	Use:   "merge <keep-id> <duplicate-id>...",
+	Short: "Keep one copy of a duplicated video, moving the other copies' tags and playlist entries to it",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, err := repositoryPath(cmd)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		deleteFiles, _ := cmd.Flags().GetBool("delete-files")
+		if deleteFiles {
+			confirm, _ := pterm.DefaultInteractiveConfirm.Show(fmt.Sprintf("⚠️  Delete the files of %d videos from disk?", len(args)-1))
+			if !confirm {
+				pterm.Info.Println("Operation cancelled.")
+				return
+			}
+		}
+
+		var report datatypes.DuplicateMergeReport
+		err = runRepoMutation(rootDir, "repo duplicates merge",
+			func(repository *repo.RepoManager) error {
+				report, err = repository.MergeDuplicateVideos(args[0], args[1:], deleteFiles)
+				return err
+			},
+			func(client *adminClient) error {
+				body := map[string]any{"keepId": args[0], "duplicateIds": args[1:], "deleteFiles": deleteFiles}
+				return client.call(http.MethodPost, "/duplicates/merge", body, &report)
+			})
+		if err != nil {
+			pterm.Error.Println("Failed to merge duplicates:", err)
+			return
+		}
+
+		jsonFlag, _ := cmd.Flags().GetBool("json")
+		if jsonFlag {
+			jsonData, err := json.Marshal(report)
+			if err != nil {
+				fmt.Println("Failed to marshal report to JSON:", err)
+				return
+			}
+			fmt.Println(string(jsonData))
+			return
+		}
+
+		for _, msg := range report.Errors {
+			pterm.Warning.Println(msg)
+		}
+		pterm.Success.Printf("Kept %s and removed %d duplicates: %d tags added, %d playlists, %d saved lists and %d watch histories updated, %d files deleted\n",
+			report.KeptVideoID, len(report.RemovedVideoIDs), report.TagsAdded, report.PlaylistsUpdated, report.SavedUpdated, report.WatchHistoryUpdated, len(report.FilesDeleted))
+	},
+}
+
+// initRepoNearDuplicateCommands adds the near-duplicate flags and the merge command to `repo duplicates`.
+func initRepoNearDuplicateCommands() {
+	repoDuplicateCmd.Flags().Bool("near", false, "Find re-encodes and other resolutions by comparing video fingerprints")
+	repoDuplicateCmd.Flags().Float64("threshold", repo.DefaultNearDuplicateThreshold, "Minimum similarity (0-1) for --near")
+	repoDuplicateCmd.Flags().Bool("fingerprint", false, "With --near, fingerprint videos that have no fingerprint yet")
+
+	repoDuplicateCmd.AddCommand(repoDuplicateMergeCmd)
+	repoDuplicateMergeCmd.Flags().StringP("repository", "r", "", "Specify the repository directory")
+	repoDuplicateMergeCmd.Flags().BoolP("json", "j", false, "Output the report in JSON format")
+	repoDuplicateMergeCmd.Flags().Bool("delete-files", false, "Also delete the removed copies' files from disk")
+}
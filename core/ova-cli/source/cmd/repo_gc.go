@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"ova-cli/source/internal/datatypes"
+	"ova-cli/source/internal/repo"
+	"path/filepath"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// repoGCCmd deletes generated artefacts that no indexed video needs anymore.
+var repoGCCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Delete thumbnails, previews and other artefacts of removed videos and interrupted cooks",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, err := repositoryPath(cmd)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		var report datatypes.GarbageReport
+		err = runRepoMutation(rootDir, "repo gc",
+			func(repository *repo.RepoManager) error {
+				report, err = repository.CollectGarbage(dryRun)
+				return err
+			},
+			func(client *adminClient) error {
+				return client.call(http.MethodPost, "/gc", map[string]bool{"dryRun": dryRun}, &report)
+			})
+		if err != nil {
+			pterm.Error.Println("Garbage collection failed:", err)
+			return
+		}
+
+		jsonFlag, _ := cmd.Flags().GetBool("json")
+		if jsonFlag {
+			jsonData, err := json.Marshal(report)
+			if err != nil {
+				fmt.Println("Failed to marshal report to JSON:", err)
+				return
+			}
+			fmt.Println(string(jsonData))
+			return
+		}
+
+		if len(report.Items) == 0 {
+			pterm.Success.Println("Nothing to collect.")
+			return
+		}
+
+		tableData := pterm.TableData{{"Artefact", "Reason", "Size"}}
+		for _, item := range report.Items {
+			rel, err := filepath.Rel(rootDir, item.Path)
+			if err != nil {
+				rel = item.Path
+			}
+			tableData = append(tableData, []string{rel, item.Reason, formatSize(item.Size)})
+		}
+		pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+
+		for _, msg := range report.Errors {
+			pterm.Warning.Println(msg)
+		}
+		if dryRun {
+			pterm.Info.Printf("%d artefacts, %s reclaimable. Run without --dry-run to delete them.\n",
+				len(report.Items), formatSize(report.ReclaimableBytes))
+			return
+		}
+		pterm.Success.Printf("Deleted %d of %d artefacts, freed %s\n",
+			len(report.Items)-len(report.Errors), len(report.Items), formatSize(report.DeletedBytes))
+	},
+}
+
+// initRepoGCCommands adds the garbage collection command to the repo command.
+func initRepoGCCommands() {
+	repoCmd.AddCommand(repoGCCmd)
+	repoGCCmd.Flags().StringP("repository", "r", "", "Specify the repository directory")
+	repoGCCmd.Flags().BoolP("json", "j", false, "Output the report in JSON format")
+	repoGCCmd.Flags().Bool("dry-run", false, "Only report what would be deleted")
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"ova-cli/source/internal/repo"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// repoMigrateCmd shows and applies the schema migrations of a repository.
+var repoMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Show or apply pending schema migrations of the repository",
+	Long: `Bring the repository's config and storage files to the schema version of this
+build of ova. Opening a repository does the same automatically; this command
+lets you check and run the migrations on their own. A backup of the repository
+metadata is written to .ova-repo/backups before anything is migrated.
+
+--status lists the schema version of every file and the pending migrations.
+--dry-run runs the migrations without writing, and reports how many records
+each one would change.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, err := repositoryPath(cmd)
+		if err != nil {
+			pterm.Error.Println(err)
+			return
+		}
+		jsonFlag, _ := cmd.Flags().GetBool("json")
+
+		if statusFlag, _ := cmd.Flags().GetBool("status"); statusFlag {
+			status, err := repo.GetSchemaStatus(rootDir)
+			if err != nil {
+				pterm.Error.Println("Failed to read the schema status:", err)
+				os.Exit(1)
+			}
+			if jsonFlag {
+				printMigrateJSON(status)
+				return
+			}
+
+			tableData := pterm.TableData{{"File", "Version", "Latest"}}
+			for _, file := range status.Files {
+				tableData = append(tableData, []string{file.File, strconv.Itoa(file.Version), strconv.Itoa(file.Latest)})
+			}
+			pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+
+			for _, file := range status.Files {
+				if file.Version > file.Latest {
+					pterm.Error.Printf("%s was written by a newer version of ova; upgrade ova to use this repository.\n", file.File)
+					os.Exit(1)
+				}
+			}
+			if len(status.Pending) == 0 {
+				pterm.Success.Println("The repository schema is up to date.")
+				return
+			}
+			pterm.Info.Printf("%d pending migrations:\n", len(status.Pending))
+			for _, m := range status.Pending {
+				fmt.Printf("  %-24s %s\n", m.ID, m.Description)
+			}
+			return
+		}
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		report, err := repo.MigrateRepository(rootDir, dryRun)
+		if err != nil {
+			pterm.Error.Println("Migration failed:", err)
+			os.Exit(1)
+		}
+		if jsonFlag {
+			printMigrateJSON(report)
+			return
+		}
+
+		if len(report.Migrations) == 0 {
+			pterm.Success.Println("The repository schema is up to date.")
+			return
+		}
+		tableData := pterm.TableData{{"Migration", "Description", "Records"}}
+		for _, m := range report.Migrations {
+			tableData = append(tableData, []string{m.ID, m.Description, strconv.Itoa(m.Records)})
+		}
+		pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+
+		if dryRun {
+			pterm.Info.Printf("Dry run: %d migrations would be applied; nothing was changed.\n", len(report.Migrations))
+			return
+		}
+		pterm.Success.Printf("Applied %d migrations\n", len(report.Migrations))
+		if report.Backup != "" {
+			pterm.Info.Printf("The previous metadata is backed up in %s\n", report.Backup)
+		}
+	},
+}
+
+func printMigrateJSON(v any) {
+	jsonData, err := json.Marshal(v)
+	if err != nil {
+		fmt.Println("Failed to marshal result to JSON:", err)
+		return
+	}
+	fmt.Println(string(jsonData))
+}
+
+// initRepoMigrateCommands adds the migrate command to the repo command.
+func initRepoMigrateCommands() {
+	repoCmd.AddCommand(repoMigrateCmd)
+	repoMigrateCmd.Flags().StringP("repository", "r", "", "Specify the repository directory")
+	repoMigrateCmd.Flags().Bool("status", false, "Show schema versions and pending migrations")
+	repoMigrateCmd.Flags().Bool("dry-run", false, "Report what the pending migrations would change without writing")
+	repoMigrateCmd.Flags().BoolP("json", "j", false, "Output the result in JSON format")
+}
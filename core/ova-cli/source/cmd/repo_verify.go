@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"ova-cli/source/internal/datatypes"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// repoVerifyCmd re-hashes every video file and reports the ones whose content changed.
+var repoVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Hash every video file in full and report missing, edited or corrupted files",
+	Long: `Reads every indexed video file and compares its size and content hash with the ones
+recorded when it was indexed. Videos indexed before hashes were recorded get one now.
+Exits with status 1 when a file is missing, modified or corrupted.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Verifying records missing hashes, and --accept replaces changed ones
+		repository, err := openLockedRepository(cmd, "repo verify")
+		if err != nil {
+			fmt.Println("Failed to initialize repository:", err)
+			return
+		}
+		defer repository.Unlock()
+
+		jsonFlag, _ := cmd.Flags().GetBool("json")
+		accept, _ := cmd.Flags().GetBool("accept")
+
+		var spinner *pterm.SpinnerPrinter
+		if !jsonFlag {
+			spinner, _ = pterm.DefaultSpinner.Start("Hashing video files...")
+		}
+		results, err := repository.VerifyVideos(accept)
+		if spinner != nil {
+			spinner.Stop()
+		}
+		if err != nil {
+			pterm.Error.Println("Verification failed:", err)
+			return
+		}
+
+		counts := map[string]int{}
+		for _, result := range results {
+			counts[result.Status]++
+		}
+		problems := counts[datatypes.VerifyMissing] + counts[datatypes.VerifyFailed]
+		if !accept {
+			problems += counts[datatypes.VerifyModified] + counts[datatypes.VerifyCorrupted]
+		}
+
+		if jsonFlag {
+			jsonData, err := json.Marshal(results)
+			if err != nil {
+				fmt.Println("Failed to marshal results to JSON:", err)
+				return
+			}
+			fmt.Println(string(jsonData))
+		} else {
+			tableData := pterm.TableData{{"Video ID", "Status", "Path", "Details"}}
+			for _, result := range results {
+				if result.Status == datatypes.VerifyOK || result.Status == datatypes.VerifyRecorded {
+					continue
+				}
+				details := result.Error
+				if result.Status == datatypes.VerifyModified {
+					details = fmt.Sprintf("size %s, was %s", formatSize(result.ActualSize), formatSize(result.ExpectedSize))
+				}
+				tableData = append(tableData, []string{result.VideoID, result.Status, result.Path, details})
+			}
+			if len(tableData) > 1 {
+				pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+			}
+
+			summary := fmt.Sprintf("%d ok, %d recorded, %d modified, %d corrupted, %d missing, %d unreadable",
+				counts[datatypes.VerifyOK], counts[datatypes.VerifyRecorded], counts[datatypes.VerifyModified],
+				counts[datatypes.VerifyCorrupted], counts[datatypes.VerifyMissing], counts[datatypes.VerifyFailed])
+			if problems == 0 {
+				pterm.Success.Println(summary)
+			} else {
+				pterm.Warning.Println(summary)
+			}
+			changed := counts[datatypes.VerifyModified] + counts[datatypes.VerifyCorrupted]
+			if accept && changed > 0 {
+				pterm.Info.Println("The current hashes of modified and corrupted files were stored.")
+			} else if changed > 0 {
+				pterm.Info.Println("Restore damaged files from a backup, or run with --accept if the changes were intended.")
+			}
+		}
+
+		if problems > 0 {
+			repository.Unlock()
+			os.Exit(1)
+		}
+	},
+}
+
+// initRepoVerifyCommands adds the verify command to the repo command.
+func initRepoVerifyCommands() {
+	repoCmd.AddCommand(repoVerifyCmd)
+	repoVerifyCmd.Flags().StringP("repository", "r", "", "Specify the repository directory")
+	repoVerifyCmd.Flags().BoolP("json", "j", false, "Output the results in JSON format")
+	repoVerifyCmd.Flags().Bool("accept", false, "Store the current hash of modified and corrupted files")
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	"ova-cli/source/internal/repo"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var sessionCmd = &cobra.Command{
+	Use:   "session",
+	Short: "Manage the login sessions of the repository",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Session command invoked: use a subcommand like 'clear'.")
+	},
+}
+
+// sessionClearCmd signs users out.
+var sessionClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Sign out every user, or a single one with --user",
+	Long: `Remove login sessions so their users have to sign in again. When a server is
+running on the repository, it clears the sessions it holds in memory and the
+users are signed out right away.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		repoRoot, err := repositoryPath(cmd)
+		if err != nil {
+			pterm.Error.Println(err)
+			return
+		}
+		username, _ := cmd.Flags().GetString("user")
+
+		var result struct {
+			Removed *int `json:"removed,omitempty"`
+		}
+		err = runRepoMutation(repoRoot, "session clear",
+			func(repository *repo.RepoManager) error {
+				if err := repository.LoadUserSessionsFromDisk(); err != nil {
+					return fmt.Errorf("failed to load sessions: %w", err)
+				}
+				if username == "" {
+					if err := repository.ClearAllSessions(); err != nil {
+						return err
+					}
+				} else {
+					removed, err := repository.DeleteUserSessions(username)
+					if err != nil {
+						return err
+					}
+					result.Removed = &removed
+				}
+				return repository.SaveUserSessionOnDisk()
+			},
+			func(client *adminClient) error {
+				return client.call(http.MethodPost, "/sessions/clear", map[string]string{"username": username}, &result)
+			})
+		if err != nil {
+			pterm.Error.Println("Failed to clear sessions:", err)
+			os.Exit(1)
+		}
+
+		if jsonFlag, _ := cmd.Flags().GetBool("json"); jsonFlag {
+			jsonData, err := json.Marshal(result)
+			if err != nil {
+				fmt.Println("Failed to marshal result to JSON:", err)
+				return
+			}
+			fmt.Println(string(jsonData))
+			return
+		}
+		if username == "" {
+			pterm.Success.Println("All sessions cleared.")
+			return
+		}
+		removed := 0
+		if result.Removed != nil {
+			removed = *result.Removed
+		}
+		pterm.Success.Printf("Removed %d sessions of user '%s'.\n", removed, username)
+	},
+}
+
+func InitCommandSession(rootCmd *cobra.Command) {
+	sessionClearCmd.Flags().String("user", "", "Only sign out this user")
+	sessionClearCmd.Flags().StringP("repository", "r", "", "Specify the repository directory")
+	sessionClearCmd.Flags().BoolP("json", "j", false, "Output the result in JSON format")
+
+	sessionCmd.AddCommand(sessionClearCmd)
+	rootCmd.AddCommand(sessionCmd)
+}
@@ -1,12 +1,17 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
-	"os"
+	"net/http"
+	"net/url"
 	"ova-cli/source/internal/datatypes"
 	"ova-cli/source/internal/repo"
-	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 )
 
@@ -31,26 +36,28 @@ var createSpaceCmd = &cobra.Command{
 		// Get the space name from the arguments
 		spaceName := args[0]
 
-		repoAddress, _ := os.Getwd() // Default to current working directory if no flag is provided
-
-		// Resolve the absolute path of the repository
-		absPath, err := filepath.Abs(repoAddress)
+		rootDir, err := repositoryPath(cmd)
 		if err != nil {
-			fmt.Printf("Error resolving absolute path: %v\n", err)
+			fmt.Println(err)
 			return
 		}
 
-		// Create a new RepoManager instance
-		repository, err := repo.NewRepoManager(absPath)
+		owner, _ := cmd.Flags().GetString("owner")
+		err = runRepoMutation(rootDir, "space create",
+			func(repository *repo.RepoManager) error {
+				if owner == "" {
+					owner = repository.GetRootUsername()
+				}
+				return repository.CreateSpace(datatypes.CreateDefaultSpaceData(spaceName, owner))
+			},
+			func(client *adminClient) error {
+				return client.call(http.MethodPost, "/spaces", map[string]string{"name": spaceName, "owner": owner}, nil)
+			})
 		if err != nil {
-			fmt.Println("Failed to initialize repository:", err)
+			fmt.Println("Failed to create space:", err)
 			return
 		}
 
-		spaceData := datatypes.CreateDefaultSpaceData(spaceName, "testdebuguser")
-
-		repository.CreateSpace(spaceData)
-
 		// Output the space creation confirmation
 		fmt.Printf("Space Created: %s\n", spaceName)
 	},
@@ -60,24 +67,292 @@ var addAllSpacesCmd = &cobra.Command{
 	Use:   "addall",
 	Short: "scan and index all spaces",
 	Run: func(cmd *cobra.Command, args []string) {
+		absPath, err := repositoryPath(cmd)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		err = runRepoMutation(absPath, "space addall",
+			func(repository *repo.RepoManager) error {
+				return repository.ScanAndAddAllSpaces()
+			},
+			func(client *adminClient) error {
+				var result struct {
+					Spaces []string `json:"spaces"`
+				}
+				if err := client.call(http.MethodPost, "/spaces/addall", nil, &result); err != nil {
+					return err
+				}
+				fmt.Printf("Spaces: %s\n", strings.Join(result.Spaces, ", "))
+				return nil
+			})
+		if err != nil {
+			fmt.Println("Failed to add spaces:", err)
+		}
+	},
+}
 
-		repoAddress, _ := os.Getwd() // Default to current working directory if no flag is provided
+// resolveSpaceArg opens the repository and finds the space named or identified by ref.
+func resolveSpaceArg(cmd *cobra.Command, ref string) (*repo.RepoManager, *datatypes.SpaceData, bool) {
+	repository, err := openRepository(cmd)
+	if err != nil {
+		fmt.Println("Failed to initialize repository:", err)
+		return nil, nil, false
+	}
 
-		// Resolve the absolute path of the repository
-		absPath, err := filepath.Abs(repoAddress)
+	space, err := repository.FindSpace(ref)
+	if err != nil {
+		pterm.Error.Println(err)
+		return nil, nil, false
+	}
+
+	return repository, space, true
+}
+
+// changeSpace finds the space named or identified by ref and applies change to it. While a
+// server runs on the repository the change is handed to it as a method request to the
+// space's admin route followed by action. It returns the space as it was found.
+func changeSpace(cmd *cobra.Command, ref, command, method, action string, body any,
+	change func(*repo.RepoManager, *datatypes.SpaceData) error) (*datatypes.SpaceData, error) {
+	rootDir, err := repositoryPath(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	var space *datatypes.SpaceData
+	err = runRepoMutation(rootDir, command,
+		func(repository *repo.RepoManager) error {
+			if space, err = repository.FindSpace(ref); err != nil {
+				return err
+			}
+			return change(repository, space)
+		},
+		func(client *adminClient) error {
+			return client.call(method, "/spaces/"+url.PathEscape(ref)+action, body, &space)
+		})
+	return space, err
+}
+
+var listSpacesCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all spaces",
+	Run: func(cmd *cobra.Command, args []string) {
+		repository, err := openRepository(cmd)
 		if err != nil {
-			fmt.Printf("Error resolving absolute path: %v\n", err)
+			fmt.Println("Failed to initialize repository:", err)
 			return
 		}
 
-		// Create a new RepoManager instance
-		repository, err := repo.NewRepoManager(absPath)
+		spaces, err := repository.GetAllSpaces()
 		if err != nil {
-			fmt.Println("Failed to initialize repository:", err)
+			pterm.Error.Println("Failed to load spaces:", err)
+			return
+		}
+
+		jsonFlag, _ := cmd.Flags().GetBool("json")
+		if jsonFlag {
+			jsonData, err := json.MarshalIndent(spaces, "", "  ")
+			if err != nil {
+				fmt.Println("Failed to marshal spaces to JSON:", err)
+				return
+			}
+			fmt.Println(string(jsonData))
+			return
+		}
+
+		if len(spaces) == 0 {
+			fmt.Println("No spaces found.")
+			return
+		}
+
+		tableData := pterm.TableData{{"Name", "ID", "Owner", "Archived", "Created At"}}
+		for _, space := range spaces {
+			tableData = append(tableData, []string{
+				space.SpaceName,
+				space.SpaceId,
+				space.SpaceOwner,
+				fmt.Sprintf("%t", space.IsArchived),
+				space.CreatedAt.Format("2006-01-02 15:04:05"),
+			})
+		}
+		pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+	},
+}
+
+var infoSpaceCmd = &cobra.Command{
+	Use:   "info <space>",
+	Short: "Show details of a space by name or ID",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		_, space, ok := resolveSpaceArg(cmd, args[0])
+		if !ok {
+			return
+		}
+
+		jsonFlag, _ := cmd.Flags().GetBool("json")
+		if jsonFlag {
+			jsonData, err := json.MarshalIndent(space, "", "  ")
+			if err != nil {
+				fmt.Println("Failed to marshal space to JSON:", err)
+				return
+			}
+			fmt.Println(string(jsonData))
+			return
+		}
+
+		pterm.DefaultSection.Println("Space:", space.SpaceName)
+		pterm.Info.Println("ID:", space.SpaceId)
+		pterm.Info.Println("Owner:", space.SpaceOwner)
+		pterm.Info.Println("Members:", space.MemberIds)
+		pterm.Info.Println("Private:", space.SpaceSettings.IsPrivate)
+		pterm.Info.Println("Disk Limit:", space.SpaceSettings.MaxDiskLimit)
+		pterm.Info.Println("Archived:", space.IsArchived)
+		if space.IsArchived {
+			pterm.Info.Println("Archived At:", space.ArchivedAt.Format(time.RFC3339))
+		}
+		pterm.Info.Println("Created At:", space.CreatedAt.Format(time.RFC3339))
+	},
+}
+
+var renameSpaceCmd = &cobra.Command{
+	Use:   "rename <space> <new-name>",
+	Short: "Rename a space and its folder",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		space, err := changeSpace(cmd, args[0], "space rename", http.MethodPost, "/rename", map[string]string{"name": args[1]},
+			func(repository *repo.RepoManager, space *datatypes.SpaceData) error {
+				return repository.RenameSpace(space.SpaceId, args[1])
+			})
+		if err != nil {
+			pterm.Error.Println("Failed to rename space:", err)
+			return
+		}
+		pterm.Success.Printf("Space %s renamed to %s\n", space.SpaceName, args[1])
+	},
+}
+
+var archiveSpaceCmd = &cobra.Command{
+	Use:   "archive <space>",
+	Short: "Archive a space",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		space, err := changeSpace(cmd, args[0], "space archive", http.MethodPost, "/archive", nil,
+			func(repository *repo.RepoManager, space *datatypes.SpaceData) error {
+				return repository.ArchiveSpace(space.SpaceId)
+			})
+		if err != nil {
+			pterm.Error.Println("Failed to archive space:", err)
+			return
+		}
+		pterm.Success.Printf("Space %s archived\n", space.SpaceName)
+	},
+}
+
+var unarchiveSpaceCmd = &cobra.Command{
+	Use:   "unarchive <space>",
+	Short: "Restore an archived space",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		space, err := changeSpace(cmd, args[0], "space unarchive", http.MethodPost, "/unarchive", nil,
+			func(repository *repo.RepoManager, space *datatypes.SpaceData) error {
+				return repository.UnarchiveSpace(space.SpaceId)
+			})
+		if err != nil {
+			pterm.Error.Println("Failed to unarchive space:", err)
+			return
+		}
+		pterm.Success.Printf("Space %s unarchived\n", space.SpaceName)
+	},
+}
+
+var settingsSpaceCmd = &cobra.Command{
+	Use:   "settings <space>",
+	Short: "Update the settings of a space",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Only the settings given as flags change
+		var changes struct {
+			IsPrivate    *bool   `json:"isPrivate,omitempty"`
+			MaxDiskLimit *string `json:"maxDiskLimit,omitempty"`
+		}
+		if cmd.Flags().Changed("private") {
+			private, _ := cmd.Flags().GetBool("private")
+			changes.IsPrivate = &private
+		}
+		if cmd.Flags().Changed("disk-limit") {
+			limit, _ := cmd.Flags().GetString("disk-limit")
+			changes.MaxDiskLimit = &limit
+		}
+
+		space, err := changeSpace(cmd, args[0], "space settings", http.MethodPut, "/settings", changes,
+			func(repository *repo.RepoManager, space *datatypes.SpaceData) error {
+				settings := space.SpaceSettings
+				if changes.IsPrivate != nil {
+					settings.IsPrivate = *changes.IsPrivate
+				}
+				if changes.MaxDiskLimit != nil {
+					settings.MaxDiskLimit = *changes.MaxDiskLimit
+				}
+				return repository.UpdateSpaceSettings(space.SpaceId, settings)
+			})
+		if err != nil {
+			pterm.Error.Println("Failed to update space settings:", err)
 			return
 		}
+		pterm.Success.Printf("Settings of space %s updated\n", space.SpaceName)
+	},
+}
 
-		repository.ScanAndAddAllSpaces()
+var transferSpaceCmd = &cobra.Command{
+	Use:   "transfer <space> <new-owner>",
+	Short: "Transfer ownership of a space to another user",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		space, err := changeSpace(cmd, args[0], "space transfer", http.MethodPost, "/transfer", map[string]string{"owner": args[1]},
+			func(repository *repo.RepoManager, space *datatypes.SpaceData) error {
+				return repository.TransferSpaceOwnership(space.SpaceId, args[1])
+			})
+		if err != nil {
+			pterm.Error.Println("Failed to transfer ownership:", err)
+			return
+		}
+		pterm.Success.Printf("Space %s is now owned by %s\n", space.SpaceName, args[1])
+	},
+}
+
+var deleteSpaceCmd = &cobra.Command{
+	Use:   "delete <space>",
+	Short: "Delete a space with its video metadata and generated artefacts",
+	Long: `Delete a space. Videos of the space are removed from the index, from all
+users' favorites, watched lists and playlists, and their thumbnails, previews
+and markers are deleted. Video files stay on disk unless --files is given.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		deleteFiles, _ := cmd.Flags().GetBool("files")
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		if !yes {
+			prompt := fmt.Sprintf("Delete space %s and all of its video metadata?", args[0])
+			if deleteFiles {
+				prompt = fmt.Sprintf("Delete space %s including its folder and video files?", args[0])
+			}
+			confirm, _ := pterm.DefaultInteractiveConfirm.Show(prompt)
+			if !confirm {
+				pterm.Info.Println("Aborted.")
+				return
+			}
+		}
+
+		space, err := changeSpace(cmd, args[0], "space delete", http.MethodDelete, "?files="+strconv.FormatBool(deleteFiles), nil,
+			func(repository *repo.RepoManager, space *datatypes.SpaceData) error {
+				return repository.DeleteSpace(space.SpaceId, deleteFiles)
+			})
+		if err != nil {
+			pterm.Error.Println("Failed to delete space:", err)
+			return
+		}
+		pterm.Success.Printf("Space %s deleted\n", space.SpaceName)
 	},
 }
 
@@ -86,7 +361,31 @@ func InitCommandSpace(rootCmd *cobra.Command) {
 	// Add the root `space` command to the root command
 	rootCmd.AddCommand(spaceCmd)
 	spaceCmd.AddCommand(addAllSpacesCmd)
+	addAllSpacesCmd.Flags().StringP("repository", "r", "", "Specify the repository directory")
 
 	// Add `create` as a subcommand of `space`
 	spaceCmd.AddCommand(createSpaceCmd)
+	createSpaceCmd.Flags().StringP("owner", "o", "", "Owner of the new space (default: root user)")
+
+	spaceCmd.AddCommand(listSpacesCmd)
+	spaceCmd.AddCommand(infoSpaceCmd)
+	spaceCmd.AddCommand(renameSpaceCmd)
+	spaceCmd.AddCommand(archiveSpaceCmd)
+	spaceCmd.AddCommand(unarchiveSpaceCmd)
+	spaceCmd.AddCommand(settingsSpaceCmd)
+	spaceCmd.AddCommand(transferSpaceCmd)
+	spaceCmd.AddCommand(deleteSpaceCmd)
+
+	for _, c := range []*cobra.Command{createSpaceCmd, listSpacesCmd, infoSpaceCmd, renameSpaceCmd, archiveSpaceCmd,
+		unarchiveSpaceCmd, settingsSpaceCmd, transferSpaceCmd, deleteSpaceCmd} {
+		c.Flags().StringP("repository", "r", "", "Specify the repository directory")
+	}
+	listSpacesCmd.Flags().BoolP("json", "j", false, "Output the data in JSON format")
+	infoSpaceCmd.Flags().BoolP("json", "j", false, "Output the data in JSON format")
+
+	settingsSpaceCmd.Flags().Bool("private", true, "Make the space private")
+	settingsSpaceCmd.Flags().String("disk-limit", "", "Maximum disk usage of the space (e.g. 100GB)")
+
+	deleteSpaceCmd.Flags().Bool("files", false, "Also delete the space folder and its video files from disk")
+	deleteSpaceCmd.Flags().BoolP("yes", "y", false, "Skip the confirmation prompt")
 }
@@ -60,10 +60,10 @@ var toolsPreviewCmd = &cobra.Command{
 	},
 }
 
-// GetMP4Info runs mp4info on the provided video path and returns the output as string.
+// toolsInfoCmd prints the box metadata of an MP4 file.
 var toolsInfoCmd = &cobra.Command{
 	Use:   "videoinfo <video-path>",
-	Short: "Print technical metadata of an MP4 file using mp4info",
+	Short: "Print technical metadata of an MP4 file",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		videoPath := args[0]
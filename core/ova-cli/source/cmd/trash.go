@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"ova-cli/source/internal/datatypes"
+	"ova-cli/source/internal/repo"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "List, restore and purge deleted videos",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Trash command invoked: use a subcommand like 'list', 'restore' or 'purge'.")
+	},
+}
+
+// trashListCmd shows the videos waiting in the trash.
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List deleted videos that can still be restored",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		repository, err := openRepository(cmd)
+		if err != nil {
+			fmt.Println("Failed to initialize repository:", err)
+			return
+		}
+
+		entries, err := repository.ListTrash()
+		if err != nil {
+			pterm.Error.Println("Failed to list trash:", err)
+			return
+		}
+
+		if jsonFlag, _ := cmd.Flags().GetBool("json"); jsonFlag {
+			jsonData, err := json.Marshal(entries)
+			if err != nil {
+				fmt.Println("Failed to marshal trash to JSON:", err)
+				return
+			}
+			fmt.Println(string(jsonData))
+			return
+		}
+
+		if len(entries) == 0 {
+			pterm.Info.Println("The trash is empty.")
+			return
+		}
+
+		tableData := pterm.TableData{{"Video ID", "Original Path", "Deleted", "Expires", "Size"}}
+		for _, entry := range entries {
+			expires := "never"
+			if !entry.ExpiresAt.IsZero() {
+				expires = entry.ExpiresAt.Local().Format(time.DateTime)
+			}
+			path := entry.OriginalPath
+			if !entry.HasFile {
+				path += " (file was missing)"
+			}
+			tableData = append(tableData, []string{
+				entry.Video.VideoID,
+				path,
+				entry.DeletedAt.Local().Format(time.DateTime),
+				expires,
+				formatSize(entry.Size),
+			})
+		}
+		pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+	},
+}
+
+// trashRestoreCmd moves videos out of the trash.
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore <video-id>...",
+	Short: "Restore deleted videos with their metadata, artefacts and user references",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, err := repositoryPath(cmd)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		err = runRepoMutation(rootDir, "trash restore",
+			func(repository *repo.RepoManager) error {
+				for _, videoID := range args {
+					video, err := repository.RestoreVideo(videoID)
+					printRestoredVideo(videoID, video, err)
+				}
+				return nil
+			},
+			func(client *adminClient) error {
+				for _, videoID := range args {
+					var video *datatypes.VideoData
+					err := client.call(http.MethodPost, "/trash/"+url.PathEscape(videoID)+"/restore", nil, &video)
+					printRestoredVideo(videoID, video, err)
+				}
+				return nil
+			})
+		if err != nil {
+			pterm.Error.Println("Failed to restore videos:", err)
+		}
+	},
+}
+
+// trashPurgeCmd permanently deletes videos from the trash.
+var trashPurgeCmd = &cobra.Command{
+	Use:   "purge [video-id...]",
+	Short: "Permanently delete videos from the trash; without IDs only expired ones",
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, err := repositoryPath(cmd)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		all, _ := cmd.Flags().GetBool("all")
+		if all || len(args) > 0 {
+			confirm, _ := pterm.DefaultInteractiveConfirm.Show("⚠️  Permanently delete these videos? They cannot be restored.")
+			if !confirm {
+				pterm.Info.Println("Operation cancelled.")
+				return
+			}
+		}
+
+		var report datatypes.TrashPurgeReport
+		err = runRepoMutation(rootDir, "trash purge",
+			func(repository *repo.RepoManager) error {
+				report, err = repository.PurgeTrash(args, all)
+				return err
+			},
+			func(client *adminClient) error {
+				return client.call(http.MethodPost, "/trash/purge", map[string]any{"videoIds": args, "all": all}, &report)
+			})
+		if err != nil {
+			pterm.Error.Println("Failed to purge trash:", err)
+			return
+		}
+
+		if jsonFlag, _ := cmd.Flags().GetBool("json"); jsonFlag {
+			jsonData, err := json.Marshal(report)
+			if err != nil {
+				fmt.Println("Failed to marshal report to JSON:", err)
+				return
+			}
+			fmt.Println(string(jsonData))
+			return
+		}
+
+		for _, msg := range report.Errors {
+			pterm.Warning.Println(msg)
+		}
+		pterm.Success.Printf("Purged %d videos, freed %s\n", len(report.PurgedVideoIDs), formatSize(report.FreedBytes))
+	},
+}
+
+// printRestoredVideo reports the result of restoring videoID.
+func printRestoredVideo(videoID string, video *datatypes.VideoData, err error) {
+	if err != nil {
+		pterm.Error.Printf("Failed to restore %s: %v\n", videoID, err)
+		return
+	}
+	pterm.Success.Printf("Restored %s to %s\n", video.VideoID, repo.GetVideoRelativePath(video))
+}
+
+func InitCommandTrash(rootCmd *cobra.Command) {
+	trashCmd.AddCommand(trashListCmd)
+	trashCmd.AddCommand(trashRestoreCmd)
+	trashCmd.AddCommand(trashPurgeCmd)
+
+	for _, c := range []*cobra.Command{trashListCmd, trashRestoreCmd, trashPurgeCmd} {
+		c.Flags().StringP("repository", "r", "", "Specify the repository directory")
+	}
+	trashListCmd.Flags().BoolP("json", "j", false, "Output the data in JSON format")
+	trashPurgeCmd.Flags().BoolP("json", "j", false, "Output the report in JSON format")
+	trashPurgeCmd.Flags().Bool("all", false, "Empty the whole trash, expired or not")
+
+	rootCmd.AddCommand(trashCmd)
+}
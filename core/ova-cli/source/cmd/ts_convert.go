@@ -6,8 +6,9 @@ import (
 	"path/filepath"
 	"strings"
 
+	"ova-cli/source/internal/interfaces"
 	"ova-cli/source/internal/logs"
-	"ova-cli/source/internal/thirdparty"
+	"ova-cli/source/internal/toolchain"
 
 	"github.com/spf13/cobra"
 )
@@ -17,20 +18,29 @@ var tsConvertLogger = logs.Loggers("TsConvert")
 // recursive flag
 var recursive bool
 
+// fragment flag
+var fragment bool
+
 // tsconvert command
 var tsConvertCmd = &cobra.Command{
 	Use:   "tsconvert [file.ts]",
 	Short: "Convert .ts video(s) to MP4 format",
 	Args:  cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		tools, err := toolchain.NewMediaToolchain("ffmpeg")
+		if err != nil {
+			tsConvertLogger.Error("Failed to initialize media toolchain: %v", err)
+			return
+		}
+
 		if recursive {
 			// Scan current folder & subdirectories
-			err := filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
+			err = filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
 				if err != nil {
 					return err
 				}
 				if !info.IsDir() && strings.HasSuffix(strings.ToLower(info.Name()), ".ts") {
-					convertFile(path)
+					convertFile(tools, path)
 				}
 				return nil
 			})
@@ -45,12 +55,12 @@ var tsConvertCmd = &cobra.Command{
 			return
 		}
 
-		convertFile(args[0])
+		convertFile(tools, args[0])
 	},
 }
 
 // convertFile handles a single .ts → .mp4 conversion
-func convertFile(inputPath string) {
+func convertFile(tools interfaces.MediaToolchain, inputPath string) {
 	ext := filepath.Ext(inputPath)
 	if strings.ToLower(ext) != ".ts" {
 		tsConvertLogger.Error("File is not a .ts: %s", inputPath)
@@ -59,11 +69,16 @@ func convertFile(inputPath string) {
 
 	outputPath := strings.TrimSuffix(inputPath, ext) + ".mp4"
 
-	err := thirdparty.ConvertToMP4(inputPath, outputPath)
-	if err != nil {
+	if err := tools.Transcode(inputPath, outputPath); err != nil {
 		tsConvertLogger.Error("Failed to convert %s: %v", inputPath, err)
 		return
 	}
+	if fragment {
+		if err := tools.Fragment(outputPath); err != nil {
+			tsConvertLogger.Error("Failed to fragment %s: %v", outputPath, err)
+			return
+		}
+	}
 
 	tsConvertLogger.Info("Converted: %s → %s", inputPath, outputPath)
 	fmt.Println(outputPath) // for scripting
@@ -72,5 +87,6 @@ func convertFile(inputPath string) {
 // InitCommandTsConvert initializes the tsconvert command
 func InitCommandTsConvert(rootCmd *cobra.Command) {
 	tsConvertCmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "Scan current folder and subdirectories for .ts files")
+	tsConvertCmd.Flags().BoolVar(&fragment, "fragment", false, "Write fragmented MP4, which players can start before the whole file is loaded")
 	rootCmd.AddCommand(tsConvertCmd)
 }
@@ -3,9 +3,12 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
 	"strings"
 
+	"ova-cli/source/internal/datatypes"
 	"ova-cli/source/internal/logs"
 	"ova-cli/source/internal/repo"
 
@@ -106,7 +109,6 @@ var userAddCmd = &cobra.Command{
 		username, _ := cmd.Flags().GetString("user")
 		password, _ := cmd.Flags().GetString("pass")
 		role, _ := cmd.Flags().GetString("role")
-		repoAddress, _ := cmd.Flags().GetString("repository")
 		jsonFlag, _ := cmd.Flags().GetBool("json")
 
 		// If username or password is not provided, prompt the user interactively
@@ -129,15 +131,23 @@ var userAddCmd = &cobra.Command{
 			role = "user"
 		}
 
-		repository, err := repo.NewRepoManager(repoAddress)
+		repoRoot, err := repositoryPath(cmd)
 		if err != nil {
-			fmt.Println("Failed to initialize repository:", err)
+			fmt.Println(err)
 			return
 		}
 
-
 		// Create the user using the CreateUser method, which handles hashing and role assignment
-		newUser, err := repository.CreateUser(username, password, role)
+		var newUser *datatypes.UserData
+		err = runRepoMutation(repoRoot, "users add",
+			func(repository *repo.RepoManager) error {
+				newUser, err = repository.CreateUser(username, password, role)
+				return err
+			},
+			func(client *adminClient) error {
+				body := map[string]string{"username": username, "password": password, "role": role}
+				return client.call(http.MethodPost, "/users", body, &newUser)
+			})
 		if err != nil {
 			pterm.Error.Printf("Error adding user '%s': %v\n", username, err)
 			os.Exit(1)
@@ -167,21 +177,32 @@ var userRmCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		username := args[0]
 
-		// Get the repository address from the --repository flag, or use the current working directory
-		repoAddress, _ := cmd.Flags().GetString("repository")
-		if repoAddress == "" {
-			repoAddress, _ = os.Getwd() // Default to current working directory if no flag is provided
-		}
-
-		repository, err := repo.NewRepoManager(repoAddress)
+		repoRoot, err := repositoryPath(cmd)
 		if err != nil {
-			fmt.Println("Failed to initialize repository:", err)
+			fmt.Println(err)
 			return
 		}
 
-
 		// Attempt to delete the user and get the deleted user data
-		deletedUser, err := repository.DeleteUser(username)
+		var deletedUser *datatypes.UserData
+		err = runRepoMutation(repoRoot, "users rm",
+			func(repository *repo.RepoManager) error {
+				deletedUser, err = repository.DeleteUser(username)
+				if err != nil {
+					return err
+				}
+				// Sign the user out of the sessions a server would load
+				if err := repository.LoadUserSessionsFromDisk(); err != nil {
+					return fmt.Errorf("failed to load sessions: %w", err)
+				}
+				if removed, _ := repository.DeleteUserSessions(username); removed > 0 {
+					return repository.SaveUserSessionOnDisk()
+				}
+				return nil
+			},
+			func(client *adminClient) error {
+				return client.call(http.MethodDelete, "/users/"+url.PathEscape(username), nil, &deletedUser)
+			})
 		if err != nil {
 			pterm.Error.Printf("Error removing user '%s': %v\n", username, err)
 			os.Exit(1)
@@ -3,6 +3,7 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"time"
@@ -37,12 +38,6 @@ var videoAddOneCmd = &cobra.Command{
 			return
 		}
 
-		repository, err := repo.NewRepoManager(repoRoot)
-		if err != nil {
-			fmt.Println("Failed to initialize repository:", err)
-			return
-		}
-
 		// Process single video (arg is a specific path)
 		absPath, err := filepath.Abs(args[0])
 		if err != nil {
@@ -59,7 +54,13 @@ var videoAddOneCmd = &cobra.Command{
 		cook, _ := cmd.Flags().GetBool("cook")
 
 		// Use AddOneVideo to add the single video
-		err = repository.AddOneVideo(absPath, cook)
+		err = runRepoMutation(repoRoot, "video addone",
+			func(repository *repo.RepoManager) error {
+				return repository.AddOneVideo(absPath, cook)
+			},
+			func(client *adminClient) error {
+				return client.call(http.MethodPost, "/videos/add", map[string]any{"path": absPath, "cook": cook}, nil)
+			})
 		if err != nil {
 			fmt.Println("Failed to add video:", err)
 			return
@@ -69,7 +70,7 @@ var videoAddOneCmd = &cobra.Command{
 
 var videoRemoveCmd = &cobra.Command{
 	Use:   "remove [path|all]",
-	Short: "Remove video(s)",
+	Short: "Move video(s) to the trash; restore them with 'ova trash restore'",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		repoRoot, err := os.Getwd()
@@ -78,27 +79,16 @@ var videoRemoveCmd = &cobra.Command{
 			return
 		}
 
-		repository, err := repo.NewRepoManager(repoRoot)
-		if err != nil {
-			fmt.Println("Failed to initialize repository:", err)
-			return
-		}
-
 		arg := args[0]
+		all := arg == "all"
 		var videoPaths []string
 
-		if arg == "all" {
+		if all {
 			confirm, _ := pterm.DefaultInteractiveConfirm.Show("⚠️  Are you sure you want to remove ALL videos?")
 			if !confirm {
 				pterm.Info.Println("Operation cancelled.")
 				return
 			}
-
-			videoPaths, err = repository.ScanDiskForVideos()
-			if err != nil {
-				pterm.Error.Println("Failed to retrieve video paths:", err)
-				return
-			}
 		} else {
 			if _, err := os.Stat(arg); os.IsNotExist(err) {
 				pterm.Error.Println("Specified file does not exist.")
@@ -112,39 +102,76 @@ var videoRemoveCmd = &cobra.Command{
 		successCount := 0
 		var warnings []string
 
-		multi := pterm.DefaultMultiPrinter
-		processSpinner, _ := pterm.DefaultSpinner.WithWriter(multi.NewWriter()).Start("Initializing...")
-		progressbar, _ := pterm.DefaultProgressbar.WithTotal(total).WithWriter(multi.NewWriter()).Start("Removing videos")
-		warningStatus, _ := pterm.DefaultSpinner.WithWriter(multi.NewWriter()).Start("Warnings: 0")
-		multi.Start()
-
-		for i, absPath := range videoPaths {
-			fileName := filepath.Base(absPath)
-			processSpinner.UpdateText(fmt.Sprintf("Removing (%d/%d): %s", i+1, total, fileName))
-
-			err := repository.UnIndexVideo(absPath)
-			if err != nil {
-				warnings = append(warnings, fmt.Sprintf("⚠️  %s: failed to remove: %v", fileName, err))
-				warningStatus.UpdateText(fmt.Sprintf("Warnings: %d", len(warnings)))
-			} else {
-				successCount++
-			}
+		err = runRepoMutation(repoRoot, "video remove",
+			func(repository *repo.RepoManager) error {
+				if all {
+					if videoPaths, err = repository.ScanDiskForVideos(); err != nil {
+						return fmt.Errorf("failed to retrieve video paths: %w", err)
+					}
+					total = len(videoPaths)
+				}
 
-			progressbar.Increment()
-			time.Sleep(30 * time.Millisecond)
-		}
+				multi := pterm.DefaultMultiPrinter
+				processSpinner, _ := pterm.DefaultSpinner.WithWriter(multi.NewWriter()).Start("Initializing...")
+				progressbar, _ := pterm.DefaultProgressbar.WithTotal(total).WithWriter(multi.NewWriter()).Start("Removing videos")
+				warningStatus, _ := pterm.DefaultSpinner.WithWriter(multi.NewWriter()).Start("Warnings: 0")
+				multi.Start()
+
+				for i, absPath := range videoPaths {
+					fileName := filepath.Base(absPath)
+					processSpinner.UpdateText(fmt.Sprintf("Removing (%d/%d): %s", i+1, total, fileName))
+
+					videoID, err := repository.ResolveVideoID(absPath)
+					if err == nil {
+						_, err = repository.TrashVideo(videoID, repository.GetRootUsername())
+					}
+					if err != nil {
+						warnings = append(warnings, fmt.Sprintf("⚠️  %s: failed to remove: %v", fileName, err))
+						warningStatus.UpdateText(fmt.Sprintf("Warnings: %d", len(warnings)))
+					} else {
+						successCount++
+					}
+
+					progressbar.Increment()
+					time.Sleep(30 * time.Millisecond)
+				}
 
-		processSpinner.Success("All removals processed.")
-		progressbar.Stop()
-		if len(warnings) > 0 {
-			warningStatus.Warning(fmt.Sprintf("Warnings: %d (see below)", len(warnings)))
-		} else {
-			warningStatus.Success("No warnings.")
+				processSpinner.Success("All removals processed.")
+				progressbar.Stop()
+				if len(warnings) > 0 {
+					warningStatus.Warning(fmt.Sprintf("Warnings: %d (see below)", len(warnings)))
+				} else {
+					warningStatus.Success("No warnings.")
+				}
+				multi.Stop()
+				return nil
+			},
+			func(client *adminClient) error {
+				var result struct {
+					Removed int      `json:"removed"`
+					Total   int      `json:"total"`
+					Errors  []string `json:"errors"`
+				}
+				body := map[string]any{"paths": videoPaths, "all": all}
+				if err := client.call(http.MethodPost, "/videos/remove", body, &result); err != nil {
+					return err
+				}
+				total, successCount = result.Total, result.Removed
+				for _, msg := range result.Errors {
+					warnings = append(warnings, "⚠️  "+msg)
+				}
+				return nil
+			})
+		if err != nil {
+			pterm.Error.Println("Failed to remove videos:", err)
+			return
 		}
-		multi.Stop()
 
 		pterm.Println()
 		pterm.Success.Printf("✅ Successfully removed %d of %d videos.\n", successCount, total)
+		if successCount > 0 {
+			pterm.Info.Println("Removed videos are in the trash; see 'ova trash list'.")
+		}
 
 		if len(warnings) > 0 {
 			pterm.Warning.Println("⚠️  The following removals had issues:")
@@ -241,6 +268,16 @@ var videoInfoCmd = &cobra.Command{
 			return
 		}
 
+		if jsonFlag, _ := cmd.Flags().GetBool("json"); jsonFlag {
+			data, err := json.MarshalIndent(video, "", "  ")
+			if err != nil {
+				pterm.Error.Println("Failed to encode video:", err)
+				return
+			}
+			fmt.Println(string(data))
+			return
+		}
+
 		pterm.Info.Println("Video Info:")
 		pterm.DefaultSection.Println("ID:", video.VideoID)
 		pterm.DefaultSection.Println("File Name:", video.FileName)
@@ -248,6 +285,15 @@ var videoInfoCmd = &cobra.Command{
 		pterm.DefaultSection.Println("Duration (seconds):", fmt.Sprintf("%d", video.Codecs.DurationSec))
 		pterm.DefaultSection.Println("Tags:", fmt.Sprintf("%v", video.Tags))
 		pterm.DefaultSection.Println("Uploaded At:", video.UploadedAt.Format(time.RFC3339))
+
+		if video.Codecs.Bitrate > 0 {
+			pterm.DefaultSection.Println("Bitrate:", formatBitrate(video.Codecs.Bitrate))
+		}
+		if len(video.Codecs.Streams) == 0 {
+			pterm.Info.Println("No stream details recorded; run 'ova video probe " + video.VideoID + "' to add them.")
+			return
+		}
+		printVideoStreams(video.Codecs.Streams)
 	},
 }
 
@@ -261,6 +307,13 @@ func InitCommandVideo(rootCmd *cobra.Command) {
 	videoCmd.AddCommand(videoInfoCmd)
 	videoCmd.AddCommand(videoRemoveCmd)
 
+	initVideoChaptersCommands()
+	initVideoSubtitlesCommands()
+	initVideoStreamsCommands()
+	initVideoMoveCommands()
+
+	videoInfoCmd.Flags().BoolP("json", "j", false, "Output the data in JSON format")
+
 	videoListCmd.Flags().BoolP("json", "j", false, "Output the data in JSON format")
 	videoListCmd.Flags().StringP("repository", "r", "", "Specify the repository directory")
 
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"ova-cli/source/internal/datatypes"
+	"ova-cli/source/internal/repo"
+	"path/filepath"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// videoChaptersCmd groups the chapter import, export and embed commands.
+var videoChaptersCmd = &cobra.Command{
+	Use:   "chapters",
+	Short: "Import, export and embed video chapters",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Chapters command invoked: use a subcommand like 'import', 'export' or 'embed'.")
+	},
+}
+
+// videoChaptersImportCmd reads chapters into a video's markers.
+var videoChaptersImportCmd = &cobra.Command{
+	Use:   "import <video-id> [file]",
+	Short: "Import chapters from a file, stdin or the video container",
+	Long: `Import chapters into a video's markers. Supported formats are webvtt,
+youtube ("00:00 Title" lines), ffmetadata and container, which reads the
+chapters stored in the video file itself and needs no input file. Without
+--format the format of the file is detected. Without a file, chapters are
+read from stdin.`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, err := repositoryPath(cmd)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		replace, _ := cmd.Flags().GetBool("replace")
+
+		var data []byte
+		switch {
+		case format == repo.ChapterFormatContainer:
+		case len(args) == 2:
+			data, err = os.ReadFile(args[1])
+		default:
+			data, err = io.ReadAll(os.Stdin)
+		}
+		if err != nil {
+			pterm.Error.Println("Failed to read chapters:", err)
+			return
+		}
+
+		var markers []datatypes.VideoMarker
+		err = runRepoMutation(rootDir, "video chapters import",
+			func(repository *repo.RepoManager) error {
+				markers, err = repository.ImportChapters(args[0], format, data, replace)
+				return err
+			},
+			func(client *adminClient) error {
+				body := map[string]any{"format": format, "data": data, "replace": replace}
+				return client.call(http.MethodPost, "/videos/"+url.PathEscape(args[0])+"/chapters", body, &markers)
+			})
+		if err != nil {
+			pterm.Error.Println("Failed to import chapters:", err)
+			return
+		}
+		pterm.Success.Printf("Video %s now has %d markers\n", args[0], len(markers))
+	},
+}
+
+// videoChaptersExportCmd writes a video's markers in a chapter format.
+var videoChaptersExportCmd = &cobra.Command{
+	Use:   "export <video-id>",
+	Short: "Export markers as webvtt, youtube or ffmetadata chapters",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		repository, err := openRepository(cmd)
+		if err != nil {
+			fmt.Println("Failed to initialize repository:", err)
+			return
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+
+		data, err := repository.ExportChapters(args[0], format)
+		if err != nil {
+			pterm.Error.Println("Failed to export chapters:", err)
+			return
+		}
+
+		if output == "" {
+			fmt.Print(string(data))
+			return
+		}
+		if err := os.WriteFile(output, data, 0644); err != nil {
+			pterm.Error.Println("Failed to write chapters:", err)
+			return
+		}
+		pterm.Success.Printf("Chapters of %s exported to %s\n", args[0], output)
+	},
+}
+
+// videoChaptersEmbedCmd muxes a video's markers into a copy of the video file.
+var videoChaptersEmbedCmd = &cobra.Command{
+	Use:   "embed <video-id>",
+	Short: "Write a copy of a video with its markers embedded as chapters",
+	Long: `Write a copy of a video with its markers stored as container chapters, so
+players show them when the file is used outside ova. Streams are copied
+without re-encoding. The indexed file is not modified, because video IDs
+are derived from file content.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		repository, err := openRepository(cmd)
+		if err != nil {
+			fmt.Println("Failed to initialize repository:", err)
+			return
+		}
+
+		output, _ := cmd.Flags().GetString("output")
+		if output == "" {
+			video, err := repository.GetVideoByID(args[0])
+			if err != nil {
+				pterm.Error.Println("Failed to find video:", err)
+				return
+			}
+			output = filepath.Base(video.FileName) + ".chapters" + video.Codecs.Format
+		}
+
+		if err := repository.EmbedChapters(args[0], output); err != nil {
+			pterm.Error.Println("Failed to embed chapters:", err)
+			return
+		}
+		pterm.Success.Printf("Video with chapters written to %s\n", output)
+	},
+}
+
+// initVideoChaptersCommands registers the chapters subcommands under the video command.
+func initVideoChaptersCommands() {
+	videoChaptersCmd.AddCommand(videoChaptersImportCmd)
+	videoChaptersImportCmd.Flags().StringP("repository", "r", "", "Specify the repository directory")
+	videoChaptersImportCmd.Flags().StringP("format", "f", "", "Input format: webvtt, youtube, ffmetadata or container (default: detect)")
+	videoChaptersImportCmd.Flags().Bool("replace", false, "Replace existing markers instead of adding to them")
+
+	videoChaptersCmd.AddCommand(videoChaptersExportCmd)
+	videoChaptersExportCmd.Flags().StringP("repository", "r", "", "Specify the repository directory")
+	videoChaptersExportCmd.Flags().StringP("format", "f", repo.ChapterFormatWebVTT, "Output format: webvtt, youtube or ffmetadata")
+	videoChaptersExportCmd.Flags().StringP("output", "o", "", "Write to this file instead of stdout")
+
+	videoChaptersCmd.AddCommand(videoChaptersEmbedCmd)
+	videoChaptersEmbedCmd.Flags().StringP("repository", "r", "", "Specify the repository directory")
+	videoChaptersEmbedCmd.Flags().StringP("output", "o", "", "Output file (default: <title>.chapters<ext> in the current directory)")
+
+	videoCmd.AddCommand(videoChaptersCmd)
+}
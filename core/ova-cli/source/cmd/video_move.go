@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"ova-cli/source/internal/datatypes"
+	"ova-cli/source/internal/repo"
+	"path/filepath"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// videoMoveCmd moves or renames a video file and updates its space and group.
+var videoMoveCmd = &cobra.Command{
+	Use:   "mv <video-id|path> <destination>",
+	Short: "Move or rename a video within the repository, keeping its metadata",
+	Long: `Move or rename a video file within the repository. The destination is a
+file or folder path relative to the repository root; a folder keeps the file
+name. The video's space and group follow the new location, while its tags,
+markers, playlists and saved and watched entries stay attached to it.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, err := repositoryPath(cmd)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		// The video can be given by the path of its file as well as by ID
+		videoID, videoPath := args[0], ""
+		if info, err := os.Stat(args[0]); err == nil && !info.IsDir() {
+			if videoPath, err = filepath.Abs(args[0]); err != nil {
+				pterm.Error.Println("Failed to resolve path:", err)
+				return
+			}
+		}
+
+		// Destinations are relative to the repository root, wherever the command runs
+		destination := filepath.FromSlash(args[1])
+		if !filepath.IsAbs(destination) {
+			destination = filepath.Join(rootDir, destination)
+		}
+		if strings.HasSuffix(filepath.ToSlash(args[1]), "/") {
+			destination += "/"
+		}
+
+		var video *datatypes.VideoData
+		err = runRepoMutation(rootDir, "video mv",
+			func(repository *repo.RepoManager) error {
+				if videoPath != "" {
+					if videoID, err = repository.ResolveVideoID(videoPath); err != nil {
+						return fmt.Errorf("video is not indexed: %w", err)
+					}
+				}
+				video, err = repository.MoveVideo(videoID, destination)
+				return err
+			},
+			func(client *adminClient) error {
+				body := map[string]string{"destination": destination}
+				if videoPath != "" {
+					body["path"] = videoPath
+				} else {
+					body["videoId"] = videoID
+				}
+				return client.call(http.MethodPost, "/videos/move", body, &video)
+			})
+		if err != nil {
+			pterm.Error.Println("Failed to move video:", err)
+			return
+		}
+
+		if jsonFlag, _ := cmd.Flags().GetBool("json"); jsonFlag {
+			data, err := json.Marshal(video)
+			if err != nil {
+				pterm.Error.Println("Failed to encode video:", err)
+				return
+			}
+			fmt.Println(string(data))
+			return
+		}
+		pterm.Success.Printf("Moved %s to %s\n", video.VideoID, repo.GetVideoRelativePath(video))
+	},
+}
+
+func initVideoMoveCommands() {
+	videoCmd.AddCommand(videoMoveCmd)
+	videoMoveCmd.Flags().StringP("repository", "r", "", "Specify the repository directory")
+	videoMoveCmd.Flags().BoolP("json", "j", false, "Output the moved video in JSON format")
+}
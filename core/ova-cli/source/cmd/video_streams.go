@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"ova-cli/source/internal/datatypes"
+	"ova-cli/source/internal/repo"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// videoProbeCmd records the stream list of videos indexed before it was stored.
+var videoProbeCmd = &cobra.Command{
+	Use:   "probe [video-id]",
+	Short: "Read the stream list (audio tracks, subtitles, HDR, rotation) of a video or of all videos",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		all, _ := cmd.Flags().GetBool("all")
+		if all == (len(args) == 1) {
+			pterm.Error.Println("Specify either a video ID or --all")
+			return
+		}
+
+		rootDir, err := repositoryPath(cmd)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		var result struct {
+			Videos []*datatypes.VideoData `json:"videos"`
+			Total  int                    `json:"total"`
+			Errors []string               `json:"errors"`
+		}
+		err = runRepoMutation(rootDir, "video probe",
+			func(repository *repo.RepoManager) error {
+				videoIDs, err := videoIDsOrAll(repository, args, all)
+				if err != nil {
+					return err
+				}
+				result.Total = len(videoIDs)
+				for _, id := range videoIDs {
+					video, err := repository.RefreshVideoStreams(id)
+					if err != nil {
+						result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", id, err))
+						continue
+					}
+					result.Videos = append(result.Videos, video)
+				}
+				return nil
+			},
+			func(client *adminClient) error {
+				return client.call(http.MethodPost, "/videos/probe", map[string]any{"videoIds": args, "all": all}, &result)
+			})
+		if err != nil {
+			pterm.Error.Println("Failed to probe videos:", err)
+			return
+		}
+
+		for _, msg := range result.Errors {
+			pterm.Warning.Println(msg)
+		}
+		if result.Total == 1 && len(result.Videos) == 1 {
+			printVideoStreams(result.Videos[0].Codecs.Streams)
+		}
+		pterm.Success.Printf("Recorded streams of %d of %d videos\n", len(result.Videos), result.Total)
+	},
+}
+
+// videoIDsOrAll returns the given video IDs, or the IDs of every indexed video with all.
+func videoIDsOrAll(repository *repo.RepoManager, videoIDs []string, all bool) ([]string, error) {
+	if !all {
+		return videoIDs, nil
+	}
+	videos, err := repository.GetAllIndexedVideos()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list videos: %w", err)
+	}
+	ids := make([]string, 0, len(videos))
+	for _, v := range videos {
+		ids = append(ids, v.VideoID)
+	}
+	return ids, nil
+}
+
+// printVideoStreams renders a video's streams as a table.
+func printVideoStreams(streams []datatypes.MediaStream) {
+	table := pterm.TableData{{"#", "Type", "Codec", "Language", "Title", "Details", "Bitrate"}}
+	for _, s := range streams {
+		var details []string
+		switch s.Type {
+		case datatypes.StreamTypeVideo:
+			details = append(details, fmt.Sprintf("%dx%d", s.Width, s.Height))
+			if s.FrameRate > 0 {
+				details = append(details, strconv.FormatFloat(s.FrameRate, 'f', -1, 64)+" fps")
+			}
+			if s.PixelFormat != "" {
+				details = append(details, s.PixelFormat)
+			}
+			if s.HDR != "" {
+				details = append(details, s.HDR)
+			}
+			if s.Rotation != 0 {
+				details = append(details, fmt.Sprintf("rotated %d°", s.Rotation))
+			}
+		case datatypes.StreamTypeAudio:
+			if s.ChannelLayout != "" {
+				details = append(details, s.ChannelLayout)
+			} else if s.Channels > 0 {
+				details = append(details, fmt.Sprintf("%d channels", s.Channels))
+			}
+			if s.SampleRate > 0 {
+				details = append(details, fmt.Sprintf("%d Hz", s.SampleRate))
+			}
+		}
+		if s.Default {
+			details = append(details, "default")
+		}
+		if s.Forced {
+			details = append(details, "forced")
+		}
+
+		bitrate := ""
+		if s.Bitrate > 0 {
+			bitrate = formatBitrate(s.Bitrate)
+		}
+		table = append(table, []string{
+			strconv.Itoa(s.Index), s.Type, s.Codec, s.Language, s.Title, strings.Join(details, ", "), bitrate,
+		})
+	}
+	pterm.DefaultTable.WithHasHeader().WithData(table).Render()
+}
+
+// formatBitrate formats bits per second as kb/s or Mb/s.
+func formatBitrate(bps int64) string {
+	if bps >= 1_000_000 {
+		return fmt.Sprintf("%.1f Mb/s", float64(bps)/1_000_000)
+	}
+	return fmt.Sprintf("%d kb/s", bps/1000)
+}
+
+// initVideoStreamsCommands registers the stream commands under the video command.
+func initVideoStreamsCommands() {
+	videoCmd.AddCommand(videoProbeCmd)
+	videoProbeCmd.Flags().StringP("repository", "r", "", "Specify the repository directory")
+	videoProbeCmd.Flags().Bool("all", false, "Probe every indexed video")
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"ova-cli/source/internal/datatypes"
+	"ova-cli/source/internal/repo"
+	"path/filepath"
+	"strconv"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// videoSubtitlesCmd groups the subtitle track commands.
+var videoSubtitlesCmd = &cobra.Command{
+	Use:   "subtitles",
+	Short: "List, discover, add and remove subtitle tracks",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("Subtitles command invoked: use a subcommand like 'list', 'scan', 'add' or 'remove'.")
+	},
+}
+
+// videoSubtitlesListCmd lists the subtitle tracks of a video.
+var videoSubtitlesListCmd = &cobra.Command{
+	Use:   "list <video-id>",
+	Short: "List the subtitle tracks of a video",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		repository, err := openRepository(cmd)
+		if err != nil {
+			fmt.Println("Failed to initialize repository:", err)
+			return
+		}
+
+		tracks, err := repository.GetSubtitleTracks(args[0])
+		if err != nil {
+			pterm.Error.Println("Failed to get subtitle tracks:", err)
+			return
+		}
+
+		if jsonFlag, _ := cmd.Flags().GetBool("json"); jsonFlag {
+			data, err := json.MarshalIndent(tracks, "", "  ")
+			if err != nil {
+				pterm.Error.Println("Failed to encode subtitle tracks:", err)
+				return
+			}
+			fmt.Println(string(data))
+			return
+		}
+
+		if len(tracks) == 0 {
+			fmt.Println("No subtitle tracks found.")
+			return
+		}
+
+		table := pterm.TableData{{"ID", "Language", "Label", "Source", "Format", "Default", "Forced"}}
+		for _, t := range tracks {
+			table = append(table, []string{
+				t.ID, t.Language, t.Label, t.Source, t.OriginalFormat,
+				strconv.FormatBool(t.Default), strconv.FormatBool(t.Forced),
+			})
+		}
+		pterm.DefaultTable.WithHasHeader().WithData(table).Render()
+	},
+}
+
+// videoSubtitlesScanCmd re-discovers sidecar and embedded subtitles.
+var videoSubtitlesScanCmd = &cobra.Command{
+	Use:   "scan [video-id]",
+	Short: "Discover sidecar and embedded subtitles of a video or of all videos",
+	Long: `Look for subtitle files next to the video (movie.srt, movie.en.srt,
+movie.de.forced.ass, ...) and for text subtitle streams inside the video
+file, and convert them to WebVTT tracks. Previously discovered tracks are
+refreshed; uploaded tracks are kept.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		all, _ := cmd.Flags().GetBool("all")
+		if all == (len(args) == 1) {
+			pterm.Error.Println("Specify either a video ID or --all")
+			return
+		}
+
+		rootDir, err := repositoryPath(cmd)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		var result struct {
+			Tracks int      `json:"tracks"`
+			Total  int      `json:"total"`
+			Errors []string `json:"errors"`
+		}
+		err = runRepoMutation(rootDir, "video subtitles scan",
+			func(repository *repo.RepoManager) error {
+				videoIDs, err := videoIDsOrAll(repository, args, all)
+				if err != nil {
+					return err
+				}
+				result.Total = len(videoIDs)
+				for _, id := range videoIDs {
+					n, err := repository.RefreshSubtitles(id)
+					if err != nil {
+						result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", id, err))
+					}
+					result.Tracks += n
+				}
+				return nil
+			},
+			func(client *adminClient) error {
+				return client.call(http.MethodPost, "/subtitles/scan", map[string]any{"videoIds": args, "all": all}, &result)
+			})
+		if err != nil {
+			pterm.Error.Println("Failed to scan subtitles:", err)
+			return
+		}
+		for _, msg := range result.Errors {
+			pterm.Warning.Println(msg)
+		}
+		pterm.Success.Printf("Found %d subtitle tracks in %d videos\n", result.Tracks, result.Total)
+	},
+}
+
+// videoSubtitlesAddCmd adds a subtitle file as a new track.
+var videoSubtitlesAddCmd = &cobra.Command{
+	Use:   "add <video-id> <file>",
+	Short: "Add a subtitle file (srt, vtt, ass or ssa) as a track",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, err := repositoryPath(cmd)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		language, _ := cmd.Flags().GetString("lang")
+		label, _ := cmd.Flags().GetString("label")
+
+		data, err := os.ReadFile(args[1])
+		if err != nil {
+			pterm.Error.Println("Failed to read subtitle file:", err)
+			return
+		}
+
+		fileName := filepath.Base(args[1])
+		var track *datatypes.SubtitleTrack
+		err = runRepoMutation(rootDir, "video subtitles add",
+			func(repository *repo.RepoManager) error {
+				track, err = repository.AddSubtitleTrack(args[0], data, fileName, language, label, repository.GetRootUsername())
+				return err
+			},
+			func(client *adminClient) error {
+				body := map[string]any{"data": data, "fileName": fileName, "language": language, "label": label}
+				return client.call(http.MethodPost, "/videos/"+url.PathEscape(args[0])+"/subtitles", body, &track)
+			})
+		if err != nil {
+			pterm.Error.Println("Failed to add subtitle track:", err)
+			return
+		}
+		pterm.Success.Printf("Subtitle track %s (%s) added to %s\n", track.ID, track.Language, args[0])
+	},
+}
+
+// videoSubtitlesRemoveCmd deletes a subtitle track.
+var videoSubtitlesRemoveCmd = &cobra.Command{
+	Use:   "remove <video-id> <track-id>",
+	Short: "Remove a subtitle track",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		rootDir, err := repositoryPath(cmd)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		err = runRepoMutation(rootDir, "video subtitles remove",
+			func(repository *repo.RepoManager) error {
+				return repository.RemoveSubtitleTrack(args[0], args[1])
+			},
+			func(client *adminClient) error {
+				path := "/videos/" + url.PathEscape(args[0]) + "/subtitles/" + url.PathEscape(args[1])
+				return client.call(http.MethodDelete, path, nil, nil)
+			})
+		if err != nil {
+			pterm.Error.Println("Failed to remove subtitle track:", err)
+			return
+		}
+		pterm.Success.Printf("Subtitle track %s removed from %s\n", args[1], args[0])
+	},
+}
+
+// initVideoSubtitlesCommands registers the subtitles subcommands under the video command.
+func initVideoSubtitlesCommands() {
+	videoSubtitlesCmd.AddCommand(videoSubtitlesListCmd)
+	videoSubtitlesListCmd.Flags().StringP("repository", "r", "", "Specify the repository directory")
+	videoSubtitlesListCmd.Flags().BoolP("json", "j", false, "Output the data in JSON format")
+
+	videoSubtitlesCmd.AddCommand(videoSubtitlesScanCmd)
+	videoSubtitlesScanCmd.Flags().StringP("repository", "r", "", "Specify the repository directory")
+	videoSubtitlesScanCmd.Flags().Bool("all", false, "Scan every indexed video")
+
+	videoSubtitlesCmd.AddCommand(videoSubtitlesAddCmd)
+	videoSubtitlesAddCmd.Flags().StringP("repository", "r", "", "Specify the repository directory")
+	videoSubtitlesAddCmd.Flags().StringP("lang", "l", "", "Language code of the track, e.g. en or pt-BR")
+	videoSubtitlesAddCmd.Flags().String("label", "", "Display label (default: the language code)")
+	videoSubtitlesAddCmd.MarkFlagRequired("lang")
+
+	videoSubtitlesCmd.AddCommand(videoSubtitlesRemoveCmd)
+	videoSubtitlesRemoveCmd.Flags().StringP("repository", "r", "", "Specify the repository directory")
+
+	videoCmd.AddCommand(videoSubtitlesCmd)
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"net/http"
+	"ova-cli/source/internal/repo"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminMiddleware only lets users with the "admin" role through.
+// It must run after AuthMiddleware, which sets the username in the context.
+func AdminMiddleware(repoMgr *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if repoMgr.AuthEnabled {
+			// Skip all authentication checks
+			c.Next()
+			return
+		}
+
+		username := c.GetString("username")
+		if username == "" {
+			respondError(c, http.StatusUnauthorized, "Authentication required")
+			c.Abort()
+			return
+		}
+
+		user, err := repoMgr.GetUserByUsername(username)
+		if err != nil || !slices.Contains(user.Roles, "admin") {
+			respondError(c, http.StatusForbidden, "Admin role required")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
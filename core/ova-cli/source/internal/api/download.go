@@ -1,16 +1,13 @@
 package api
 
 import (
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
-	"os/exec"
-	"path/filepath"
 	"strconv"
 
 	"ova-cli/source/internal/repo"
-	"ova-cli/source/internal/thirdparty"
 
 	"github.com/gin-gonic/gin"
 )
@@ -26,12 +23,35 @@ func downloadVideo(rm *repo.RepoManager) gin.HandlerFunc {
 		videoId := c.Param("videoId")
 
 		video, err := rm.GetVideoByID(videoId)
+		if errors.Is(err, repo.ErrSubRepositoryOffline) {
+			respondError(c, http.StatusServiceUnavailable, "Video is stored in an offline repository")
+			return
+		} else if err != nil {
+			respondError(c, http.StatusNotFound, "Video not found")
+			return
+		}
+		if rm.IsVideoArchived(videoId) {
+			respondError(c, http.StatusForbidden, "Video belongs to an archived space")
+			return
+		}
+
+		videoPath, err := rm.GetVideoFilePathByID(videoId)
 		if err != nil {
 			respondError(c, http.StatusNotFound, "Video not found")
 			return
 		}
+		if _, err := os.Stat(videoPath); os.IsNotExist(err) {
+			respondError(c, http.StatusNotFound, "Video file not found on disk")
+			return
+		}
+
+		// Downloads carry the video's markers as chapters, so players outside ova show them
+		if chapteredPath, err := rm.GetVideoFilePathWithChapters(videoId); err != nil {
+			fmt.Printf("Warning: serving %s without chapters: %v\n", videoId, err)
+		} else {
+			videoPath = chapteredPath
+		}
 
-		videoPath := filepath.Join(video.OwnedSpace, video.FileName)
 		info, err := os.Stat(videoPath)
 		if os.IsNotExist(err) {
 			respondError(c, http.StatusNotFound, "Video file not found on disk")
@@ -41,6 +61,8 @@ func downloadVideo(rm *repo.RepoManager) gin.HandlerFunc {
 			return
 		}
 
+		countDownload(rm, videoId)
+
 		// Set headers for download
 		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.mp4\"", video.FileName))
 		c.Header("Content-Type", "application/octet-stream")
@@ -76,108 +98,71 @@ func downloadTrimmedVideo(rm *repo.RepoManager) gin.HandlerFunc {
 		}
 
 		video, err := rm.GetVideoByID(videoId)
-		if err != nil {
-			respondError(c, http.StatusNotFound, "Video not found")
-			return
-		}
-
-		videoPath := filepath.Join(video.OwnedSpace, video.FileName)
-		if _, err := os.Stat(videoPath); os.IsNotExist(err) {
-			respondError(c, http.StatusNotFound, "Video file not found on disk")
+		if errors.Is(err, repo.ErrSubRepositoryOffline) {
+			respondError(c, http.StatusServiceUnavailable, "Video is stored in an offline repository")
 			return
 		} else if err != nil {
-			respondError(c, http.StatusInternalServerError, "Error accessing video file")
+			respondError(c, http.StatusNotFound, "Video not found")
 			return
 		}
-
-		ffmpegPath, err := thirdparty.GetFFmpegPath()
-		if err != nil {
-			respondError(c, http.StatusInternalServerError, "FFmpeg not found")
+		if rm.IsVideoArchived(videoId) {
+			respondError(c, http.StatusForbidden, "Video belongs to an archived space")
 			return
 		}
 
-		args := []string{
-			"-ss", fmt.Sprintf("%.2f", start),
-			"-i", videoPath,
-			"-t", fmt.Sprintf("%.2f", duration),
-			"-c:v", "libx264",
-			"-c:a", "aac",
-			"-preset", "fast",
-			"-movflags", "frag_keyframe+empty_moov",
-			"-f", "mp4",
-			"pipe:1",
-		}
-
-		fmt.Println("Running FFmpeg:", ffmpegPath, args)
-
-		cmd := exec.Command(ffmpegPath, args...)
-
-		stdout, err := cmd.StdoutPipe()
+		videoPath, err := rm.GetVideoFilePathByID(videoId)
 		if err != nil {
-			respondError(c, http.StatusInternalServerError, "Failed to create output pipe")
+			respondError(c, http.StatusNotFound, "Video not found")
 			return
 		}
-
-		stderr, err := cmd.StderrPipe()
-		if err != nil {
-			respondError(c, http.StatusInternalServerError, "Failed to create stderr pipe")
+		if _, err := os.Stat(videoPath); os.IsNotExist(err) {
+			respondError(c, http.StatusNotFound, "Video file not found on disk")
+			return
+		} else if err != nil {
+			respondError(c, http.StatusInternalServerError, "Error accessing video file")
 			return
 		}
 
 		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s_trimmed.mp4\"", video.FileName))
 		c.Header("Content-Type", "video/mp4")
 
-		if err := cmd.Start(); err != nil {
-			respondError(c, http.StatusInternalServerError, "Failed to start ffmpeg")
-			return
+		// The trimmed file is streamed as it is encoded; cancelling the request stops the encoder
+		out := &flushWriter{w: c.Writer}
+		err = rm.GetMediaToolchain().Trim(c.Request.Context(), videoPath, start, duration, out)
+		if out.written {
+			countDownload(rm, videoId)
 		}
-
-		go func() {
-			errOutput, _ := io.ReadAll(stderr)
-			if len(errOutput) > 0 {
-				fmt.Println("FFmpeg stderr:\n", string(errOutput))
-			}
-		}()
-
-		clientGone := c.Request.Context().Done()
-		go func() {
-			<-clientGone
-			fmt.Println("Client disconnected, killing ffmpeg")
-			if cmd.Process != nil {
-				_ = cmd.Process.Kill()
-			}
-		}()
-
-		flusher, ok := c.Writer.(http.Flusher)
-		if !ok {
-			fmt.Println("ResponseWriter does not implement http.Flusher")
+		if err != nil && !out.written {
+			fmt.Printf("Warning: failed to trim %s: %v\n", videoId, err)
+			c.Writer.Header().Del("Content-Disposition")
+			c.Writer.Header().Del("Content-Type")
+			respondError(c, http.StatusInternalServerError, "Failed to trim video")
+		} else if err != nil && c.Request.Context().Err() == nil {
+			fmt.Printf("Warning: trimmed download of %s ended early: %v\n", videoId, err)
 		}
+	}
+}
 
-		buf := make([]byte, 32*1024)
-		for {
-			n, err := stdout.Read(buf)
-			if n > 0 {
-				if _, wErr := c.Writer.Write(buf[:n]); wErr != nil {
-					fmt.Println("Error writing to client:", wErr)
-					break
-				}
-				if ok {
-					flusher.Flush()
-				}
-			}
-			if err != nil {
-				if err != io.EOF {
-					fmt.Println("Error reading ffmpeg output:", err)
-				}
-				break
-			}
-		}
+// flushWriter sends every write to the client right away and remembers whether
+// anything was sent, after which errors can no longer change the response status.
+type flushWriter struct {
+	w       gin.ResponseWriter
+	written bool
+}
 
-		waitErr := cmd.Wait()
-		if waitErr != nil {
-			fmt.Println("FFmpeg exited with error:", waitErr)
-		} else {
-			fmt.Println("FFmpeg finished successfully.")
-		}
+func (f *flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	if n > 0 {
+		f.written = true
+		f.w.Flush()
+	}
+	return n, err
+}
+
+// countDownload records a download of a video. A failure is logged rather than
+// denying the download.
+func countDownload(rm *repo.RepoManager, videoId string) {
+	if _, err := rm.RecordVideoDownload(videoId); err != nil {
+		fmt.Printf("Warning: failed to count download of %s: %v\n", videoId, err)
 	}
 }
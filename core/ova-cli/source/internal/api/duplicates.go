@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"ova-cli/source/internal/repo"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterDuplicateRoutes registers the near-duplicate detection routes. The group is
+// expected to be protected by AdminMiddleware.
+func RegisterDuplicateRoutes(rg *gin.RouterGroup, rm *repo.RepoManager) {
+	duplicates := rg.Group("/duplicates")
+	{
+		duplicates.GET("", getNearDuplicates(rm))
+		duplicates.POST("/merge", mergeDuplicates(rm))
+	}
+}
+
+func getNearDuplicates(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		threshold := repo.DefaultNearDuplicateThreshold
+		if value := c.Query("threshold"); value != "" {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil || parsed <= 0 || parsed > 1 {
+				respondError(c, http.StatusBadRequest, "Threshold must be a number between 0 and 1")
+				return
+			}
+			threshold = parsed
+		}
+
+		report, err := rm.FindNearDuplicates(threshold)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "Failed to find near-duplicates")
+			return
+		}
+		respondSuccess(c, http.StatusOK, report, "Near-duplicates retrieved successfully")
+	}
+}
+
+func mergeDuplicates(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			KeepVideoID       string   `json:"keepVideoId"`
+			DuplicateVideoIDs []string `json:"duplicateVideoIds"`
+			DeleteFiles       bool     `json:"deleteFiles"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil || body.KeepVideoID == "" || len(body.DuplicateVideoIDs) == 0 {
+			respondError(c, http.StatusBadRequest, "Invalid body: keepVideoId and duplicateVideoIds are required")
+			return
+		}
+
+		report, err := rm.MergeDuplicateVideos(body.KeepVideoID, body.DuplicateVideoIDs, body.DeleteFiles)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondSuccess(c, http.StatusOK, report, "Duplicates merged")
+	}
+}
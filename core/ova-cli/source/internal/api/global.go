@@ -2,6 +2,7 @@ package api
 
 import (
 	"net/http"
+	"ova-cli/source/internal/datatypes"
 	"ova-cli/source/internal/repo"
 	"strconv"
 
@@ -12,12 +13,14 @@ import (
 func RegisterLatestVideoRoute(rg *gin.RouterGroup, repoMgr *repo.RepoManager) {
 	videos := rg.Group("/videos")
 	{
-		// GET /api/v1/videos/latest?bucket=1
+		// GET /api/v1/videos/global?bucket=1&sort=title&ascending=true
 		videos.GET("/global", getLatestVideos(repoMgr))
 	}
 }
 
-// getLatestVideos retrieves the latest video IDs based on the bucket provided in query params.
+// getLatestVideos retrieves the video IDs of the bucket provided in query params. Videos are
+// sorted by the sort param, one of uploadedAt (default), title, duration or downloads,
+// newest/largest first unless ascending is true.
 func getLatestVideos(repoMgr *repo.RepoManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Parse bucket from query parameters (default to 1 if not provided)
@@ -30,6 +33,13 @@ func getLatestVideos(repoMgr *repo.RepoManager) gin.HandlerFunc {
 			return
 		}
 
+		sortBy := c.DefaultQuery("sort", datatypes.SmartSortUploadedAt)
+		if !datatypes.IsVideoSortKey(sortBy) {
+			respondError(c, http.StatusBadRequest, "Invalid sort parameter")
+			return
+		}
+		ascending := c.Query("ascending") == "true"
+
 		// Hardcode the bucket size to 20
 		bucketContentSize := repoMgr.GetConfigs().MaxBucketSize
 
@@ -40,6 +50,20 @@ func getLatestVideos(repoMgr *repo.RepoManager) gin.HandlerFunc {
 			return
 		}
 
+		// An empty library, e.g. with every space archived, has no buckets
+		if totalVideos == 0 {
+			respondSuccess(c, http.StatusOK, gin.H{
+				"videoIds":          []string{},
+				"totalVideos":       0,
+				"currentBucket":     bucket,
+				"sort":              sortBy,
+				"ascending":         ascending,
+				"bucketContentSize": bucketContentSize,
+				"totalBuckets":      0,
+			}, "No videos found")
+			return
+		}
+
 		// Calculate the start and end indices based on bucket and hardcoded bucket_size (20)
 		start := (bucket - 1) * bucketContentSize
 		end := start + bucketContentSize
@@ -50,7 +74,7 @@ func getLatestVideos(repoMgr *repo.RepoManager) gin.HandlerFunc {
 		}
 
 		// Fetch video IDs in the calculated range from memory storage
-		videoIDsInRange, err := repoMgr.GetSortedVideosByRange(start, end)
+		videoIDsInRange, err := repoMgr.GetSortedVideosByRange(sortBy, ascending, start, end)
 		if err != nil {
 			respondError(c, http.StatusInternalServerError, "Failed to retrieve videos")
 			return
@@ -61,6 +85,8 @@ func getLatestVideos(repoMgr *repo.RepoManager) gin.HandlerFunc {
 			"videoIds":          videoIDsInRange,
 			"totalVideos":       totalVideos, // Add total video count to the response
 			"currentBucket":     bucket,
+			"sort":              sortBy,
+			"ascending":         ascending,
 			"bucketContentSize": bucketContentSize,
 			"totalBuckets":      (totalVideos + bucketContentSize - 1) / bucketContentSize, // Calculate total number of buckets
 		}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"net/http"
+
+	"ova-cli/source/internal/repo"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterLocalAdminRoutes registers the operations the CLI hands to a running server over
+// its admin socket instead of writing the repository itself. The socket is only open to
+// users allowed to access the repository folder, so these routes take no session.
+func RegisterLocalAdminRoutes(rg *gin.RouterGroup, rm *repo.RepoManager) {
+	rg.GET("/status", getLocalAdminStatus(rm))
+	rg.POST("/index", indexAllSpaces(rm))
+	rg.POST("/spaces/addall", addAllSpaces(rm))
+	rg.POST("/cook", cookAllVideos(rm))
+	rg.POST("/users", addLocalUser(rm))
+	rg.DELETE("/users/:username", removeLocalUser(rm))
+	rg.POST("/cache/refresh", refreshVideoCache(rm))
+	rg.POST("/sessions/clear", clearSessions(rm))
+	registerLocalAdminLibraryRoutes(rg, rm)
+}
+
+func getLocalAdminStatus(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respondSuccess(c, http.StatusOK, gin.H{"root": rm.GetRootPath()}, "Server is running")
+	}
+}
+
+func indexAllSpaces(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		summary, err := rm.IndexAllSpaces(nil)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		respondSuccess(c, http.StatusOK, summary, "Indexing finished")
+	}
+}
+
+func addAllSpaces(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := rm.ScanAndAddAllSpaces(); err != nil {
+			respondError(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		spaces, err := rm.GetAllSpaces()
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "Failed to load spaces")
+			return
+		}
+		names := []string{}
+		for _, space := range spaces {
+			names = append(names, space.SpaceName)
+		}
+		respondSuccess(c, http.StatusOK, gin.H{"spaces": names}, "Spaces added")
+	}
+}
+
+func cookAllVideos(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		summary, err := rm.CookAllVideos(nil)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		respondSuccess(c, http.StatusOK, summary, "Cooking finished")
+	}
+}
+
+func addLocalUser(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+			Role     string `json:"role"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil || body.Username == "" || body.Password == "" {
+			respondError(c, http.StatusBadRequest, "Invalid body: username and password are required")
+			return
+		}
+
+		user, err := rm.CreateUser(body.Username, body.Password, body.Role)
+		if err != nil {
+			respondError(c, http.StatusConflict, err.Error())
+			return
+		}
+		respondSuccess(c, http.StatusCreated, user, "User created")
+	}
+}
+
+func removeLocalUser(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := rm.DeleteUser(c.Param("username"))
+		if err != nil {
+			respondError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		// Sessions outlive their user otherwise
+		if removed, _ := rm.DeleteUserSessions(user.Username); removed > 0 {
+			if err := rm.SaveUserSessionOnDisk(); err != nil {
+				respondError(c, http.StatusInternalServerError, "Failed to save sessions: "+err.Error())
+				return
+			}
+		}
+		respondSuccess(c, http.StatusOK, user, "User deleted")
+	}
+}
+
+func refreshVideoCache(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := rm.CacheLatestVideos(); err != nil {
+			respondError(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		total, err := rm.GetTotalVideosCached()
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		respondSuccess(c, http.StatusOK, gin.H{"videos": total}, "Video cache refreshed")
+	}
+}
+
+// clearSessions signs out one user, or everybody when no username is given. The server
+// keeps sessions in memory, so they are written to disk right away.
+func clearSessions(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Username string `json:"username"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid JSON")
+			return
+		}
+
+		result := gin.H{}
+		if body.Username == "" {
+			if err := rm.ClearAllSessions(); err != nil {
+				respondError(c, http.StatusInternalServerError, err.Error())
+				return
+			}
+		} else {
+			removed, err := rm.DeleteUserSessions(body.Username)
+			if err != nil {
+				respondError(c, http.StatusInternalServerError, err.Error())
+				return
+			}
+			result["removed"] = removed
+		}
+		if err := rm.SaveUserSessionOnDisk(); err != nil {
+			respondError(c, http.StatusInternalServerError, "Failed to save sessions: "+err.Error())
+			return
+		}
+		respondSuccess(c, http.StatusOK, result, "Sessions cleared")
+	}
+}
@@ -0,0 +1,500 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+
+	"ova-cli/source/internal/datatypes"
+	"ova-cli/source/internal/repo"
+
+	"github.com/gin-gonic/gin"
+)
+
+// registerLocalAdminLibraryRoutes registers the admin socket routes of the CLI commands that
+// change videos, spaces and playlists, so their changes reach the server's video cache.
+func registerLocalAdminLibraryRoutes(rg *gin.RouterGroup, rm *repo.RepoManager) {
+	rg.POST("/videos/add", addLocalVideo(rm))
+	rg.POST("/videos/remove", trashLocalVideos(rm))
+	rg.POST("/videos/move", moveLocalVideo(rm))
+	rg.POST("/videos/probe", probeLocalVideos(rm))
+	rg.POST("/videos/:videoId/chapters", importLocalChapters(rm))
+	rg.POST("/videos/:videoId/subtitles", addLocalSubtitleTrack(rm))
+	rg.DELETE("/videos/:videoId/subtitles/:trackId", removeLocalSubtitleTrack(rm))
+	rg.POST("/subtitles/scan", scanLocalSubtitles(rm))
+
+	rg.POST("/trash/:videoId/restore", restoreTrashedVideo(rm))
+	rg.POST("/trash/purge", purgeLocalTrash(rm))
+
+	rg.POST("/spaces", createLocalSpace(rm))
+	rg.POST("/spaces/:space/rename", renameLocalSpace(rm))
+	rg.POST("/spaces/:space/archive", archiveLocalSpace(rm, true))
+	rg.POST("/spaces/:space/unarchive", archiveLocalSpace(rm, false))
+	rg.PUT("/spaces/:space/settings", updateLocalSpaceSettings(rm))
+	rg.POST("/spaces/:space/transfer", transferLocalSpace(rm))
+	rg.DELETE("/spaces/:space", deleteLocalSpace(rm))
+
+	rg.POST("/repositories", attachLocalRepository(rm))
+	rg.DELETE("/repositories/:name", detachLocalRepository(rm))
+
+	rg.POST("/gc", collectLocalGarbage(rm))
+	rg.POST("/duplicates/merge", mergeLocalDuplicates(rm))
+	rg.POST("/playlists/import", importLocalPlaylist(rm))
+	rg.POST("/import", importLocalBundle(rm))
+}
+
+// localVideoBatch names the videos a batch command works on: the given IDs, or every
+// indexed video when All is set.
+type localVideoBatch struct {
+	VideoIDs []string `json:"videoIds"`
+	All      bool     `json:"all"`
+}
+
+func (b localVideoBatch) resolve(rm *repo.RepoManager) ([]string, error) {
+	if !b.All {
+		return b.VideoIDs, nil
+	}
+	videos, err := rm.GetAllIndexedVideos()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(videos))
+	for _, v := range videos {
+		ids = append(ids, v.VideoID)
+	}
+	return ids, nil
+}
+
+func addLocalVideo(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Path string `json:"path"`
+			Cook bool   `json:"cook"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil || body.Path == "" {
+			respondError(c, http.StatusBadRequest, "Invalid body: path is required")
+			return
+		}
+		if err := rm.AddOneVideo(body.Path, body.Cook); err != nil {
+			respondError(c, http.StatusConflict, err.Error())
+			return
+		}
+		respondSuccess(c, http.StatusOK, nil, "Video added")
+	}
+}
+
+// trashLocalVideos moves the videos stored in the given files, or in every video file of the
+// repository with all, to the trash.
+func trashLocalVideos(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Paths []string `json:"paths"`
+			All   bool     `json:"all"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid JSON")
+			return
+		}
+
+		paths := body.Paths
+		if body.All {
+			var err error
+			if paths, err = rm.ScanDiskForVideos(); err != nil {
+				respondError(c, http.StatusInternalServerError, err.Error())
+				return
+			}
+		}
+
+		removed := 0
+		failures := []string{}
+		for _, path := range paths {
+			videoID, err := rm.ResolveVideoID(path)
+			if err == nil {
+				_, err = rm.TrashVideo(videoID, rm.GetRootUsername())
+			}
+			if err != nil {
+				failures = append(failures, fmt.Sprintf("%s: failed to remove: %v", filepath.Base(path), err))
+				continue
+			}
+			removed++
+		}
+		respondSuccess(c, http.StatusOK, gin.H{"removed": removed, "total": len(paths), "errors": failures}, "Videos moved to the trash")
+	}
+}
+
+// moveLocalVideo moves a video given by ID or by the absolute path of its file.
+func moveLocalVideo(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			VideoID     string `json:"videoId"`
+			Path        string `json:"path"`
+			Destination string `json:"destination"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil || (body.VideoID == "" && body.Path == "") || body.Destination == "" {
+			respondError(c, http.StatusBadRequest, "Invalid body: videoId or path, and destination are required")
+			return
+		}
+
+		videoID := body.VideoID
+		if body.Path != "" {
+			var err error
+			if videoID, err = rm.ResolveVideoID(body.Path); err != nil {
+				respondError(c, http.StatusNotFound, "Video is not indexed: "+err.Error())
+				return
+			}
+		}
+
+		video, err := rm.MoveVideo(videoID, body.Destination)
+		if err != nil {
+			respondError(c, http.StatusConflict, err.Error())
+			return
+		}
+		respondSuccess(c, http.StatusOK, video, "Video moved")
+	}
+}
+
+func probeLocalVideos(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body localVideoBatch
+		if err := c.ShouldBindJSON(&body); err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid JSON")
+			return
+		}
+		videoIDs, err := body.resolve(rm)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		videos := []*datatypes.VideoData{}
+		failures := []string{}
+		for _, id := range videoIDs {
+			video, err := rm.RefreshVideoStreams(id)
+			if err != nil {
+				failures = append(failures, fmt.Sprintf("%s: %v", id, err))
+				continue
+			}
+			videos = append(videos, video)
+		}
+		respondSuccess(c, http.StatusOK, gin.H{"videos": videos, "total": len(videoIDs), "errors": failures}, "Streams recorded")
+	}
+}
+
+func importLocalChapters(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Format  string `json:"format"`
+			Data    []byte `json:"data"`
+			Replace bool   `json:"replace"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid JSON")
+			return
+		}
+		markers, err := rm.ImportChapters(c.Param("videoId"), body.Format, body.Data, body.Replace)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondSuccess(c, http.StatusOK, markers, "Chapters imported")
+	}
+}
+
+func addLocalSubtitleTrack(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Data     []byte `json:"data"`
+			FileName string `json:"fileName"`
+			Language string `json:"language"`
+			Label    string `json:"label"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil || body.FileName == "" {
+			respondError(c, http.StatusBadRequest, "Invalid body: fileName is required")
+			return
+		}
+		track, err := rm.AddSubtitleTrack(c.Param("videoId"), body.Data, body.FileName, body.Language, body.Label, rm.GetRootUsername())
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondSuccess(c, http.StatusCreated, track, "Subtitle track added")
+	}
+}
+
+func removeLocalSubtitleTrack(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := rm.RemoveSubtitleTrack(c.Param("videoId"), c.Param("trackId")); err != nil {
+			respondError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		respondSuccess(c, http.StatusOK, nil, "Subtitle track removed")
+	}
+}
+
+func scanLocalSubtitles(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body localVideoBatch
+		if err := c.ShouldBindJSON(&body); err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid JSON")
+			return
+		}
+		videoIDs, err := body.resolve(rm)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		tracks := 0
+		failures := []string{}
+		for _, id := range videoIDs {
+			n, err := rm.RefreshSubtitles(id)
+			if err != nil {
+				failures = append(failures, fmt.Sprintf("%s: %v", id, err))
+			}
+			tracks += n
+		}
+		respondSuccess(c, http.StatusOK, gin.H{"tracks": tracks, "total": len(videoIDs), "errors": failures}, "Subtitles scanned")
+	}
+}
+
+func purgeLocalTrash(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body localVideoBatch
+		if err := c.ShouldBindJSON(&body); err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid JSON")
+			return
+		}
+		report, err := rm.PurgeTrash(body.VideoIDs, body.All)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		respondSuccess(c, http.StatusOK, report, "Trash purged")
+	}
+}
+
+// The space routes take a space name or ID and respond with the space as it was found, so
+// the CLI can report it by its name from before the change.
+
+func createLocalSpace(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Name  string `json:"name"`
+			Owner string `json:"owner"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil || body.Name == "" {
+			respondError(c, http.StatusBadRequest, "Invalid body: name is required")
+			return
+		}
+		if body.Owner == "" {
+			body.Owner = rm.GetRootUsername()
+		}
+
+		space := datatypes.CreateDefaultSpaceData(body.Name, body.Owner)
+		if err := rm.CreateSpace(space); err != nil {
+			respondError(c, http.StatusConflict, err.Error())
+			return
+		}
+		respondSuccess(c, http.StatusCreated, space, "Space created")
+	}
+}
+
+// changeLocalSpace finds the space of the :space parameter and applies change to it.
+func changeLocalSpace(c *gin.Context, rm *repo.RepoManager, change func(*datatypes.SpaceData) error) {
+	space, err := rm.FindSpace(c.Param("space"))
+	if err != nil {
+		respondError(c, http.StatusNotFound, err.Error())
+		return
+	}
+	if err := change(space); err != nil {
+		respondError(c, http.StatusConflict, err.Error())
+		return
+	}
+	respondSuccess(c, http.StatusOK, space, "Space updated")
+}
+
+func renameLocalSpace(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Name string `json:"name"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil || body.Name == "" {
+			respondError(c, http.StatusBadRequest, "Invalid body: name is required")
+			return
+		}
+		changeLocalSpace(c, rm, func(space *datatypes.SpaceData) error {
+			return rm.RenameSpace(space.SpaceId, body.Name)
+		})
+	}
+}
+
+func archiveLocalSpace(rm *repo.RepoManager, archive bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		changeLocalSpace(c, rm, func(space *datatypes.SpaceData) error {
+			if archive {
+				return rm.ArchiveSpace(space.SpaceId)
+			}
+			return rm.UnarchiveSpace(space.SpaceId)
+		})
+	}
+}
+
+// updateLocalSpaceSettings changes the settings present in the body and keeps the others.
+func updateLocalSpaceSettings(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			IsPrivate    *bool   `json:"isPrivate"`
+			MaxDiskLimit *string `json:"maxDiskLimit"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid JSON")
+			return
+		}
+		changeLocalSpace(c, rm, func(space *datatypes.SpaceData) error {
+			settings := space.SpaceSettings
+			if body.IsPrivate != nil {
+				settings.IsPrivate = *body.IsPrivate
+			}
+			if body.MaxDiskLimit != nil {
+				settings.MaxDiskLimit = *body.MaxDiskLimit
+			}
+			return rm.UpdateSpaceSettings(space.SpaceId, settings)
+		})
+	}
+}
+
+func transferLocalSpace(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Owner string `json:"owner"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil || body.Owner == "" {
+			respondError(c, http.StatusBadRequest, "Invalid body: owner is required")
+			return
+		}
+		changeLocalSpace(c, rm, func(space *datatypes.SpaceData) error {
+			return rm.TransferSpaceOwnership(space.SpaceId, body.Owner)
+		})
+	}
+}
+
+// deleteLocalSpace deletes a space, and its folder and video files with ?files=true.
+func deleteLocalSpace(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		changeLocalSpace(c, rm, func(space *datatypes.SpaceData) error {
+			return rm.DeleteSpace(space.SpaceId, c.Query("files") == "true")
+		})
+	}
+}
+
+func attachLocalRepository(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Name string `json:"name"`
+			Path string `json:"path"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil || body.Name == "" || body.Path == "" {
+			respondError(c, http.StatusBadRequest, "Invalid body: name and path are required")
+			return
+		}
+		if err := rm.AttachSubRepository(body.Name, body.Path); err != nil {
+			respondError(c, http.StatusConflict, err.Error())
+			return
+		}
+		respondSuccess(c, http.StatusCreated, nil, "Repository attached")
+	}
+}
+
+func detachLocalRepository(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := rm.DetachSubRepository(c.Param("name")); err != nil {
+			respondError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		respondSuccess(c, http.StatusOK, nil, "Repository detached")
+	}
+}
+
+func collectLocalGarbage(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			DryRun bool `json:"dryRun"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid JSON")
+			return
+		}
+		report, err := rm.CollectGarbage(body.DryRun)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		respondSuccess(c, http.StatusOK, report, "Garbage collected")
+	}
+}
+
+func mergeLocalDuplicates(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			KeepID       string   `json:"keepId"`
+			DuplicateIDs []string `json:"duplicateIds"`
+			DeleteFiles  bool     `json:"deleteFiles"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil || body.KeepID == "" || len(body.DuplicateIDs) == 0 {
+			respondError(c, http.StatusBadRequest, "Invalid body: keepId and duplicateIds are required")
+			return
+		}
+		report, err := rm.MergeDuplicateVideos(body.KeepID, body.DuplicateIDs, body.DeleteFiles)
+		if err != nil {
+			respondError(c, http.StatusConflict, err.Error())
+			return
+		}
+		respondSuccess(c, http.StatusOK, report, "Duplicates merged")
+	}
+}
+
+// importLocalPlaylist imports a playlist file for a user, the root user when none is given.
+func importLocalPlaylist(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Username string `json:"username"`
+			Data     []byte `json:"data"`
+			Format   string `json:"format"`
+			Title    string `json:"title"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid JSON")
+			return
+		}
+		if body.Username == "" {
+			body.Username = rm.GetRootUsername()
+		}
+		report, err := rm.ImportPlaylist(body.Username, body.Data, body.Format, body.Title, true)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondSuccess(c, http.StatusOK, report, "Playlist imported")
+	}
+}
+
+// importLocalBundle imports a bundle from an absolute path on the server's machine.
+func importLocalBundle(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Bundle string `json:"bundle"`
+			Space  string `json:"space"`
+			DryRun bool   `json:"dryRun"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil || body.Bundle == "" {
+			respondError(c, http.StatusBadRequest, "Invalid body: bundle is required")
+			return
+		}
+		report, err := rm.ImportBundle(body.Bundle, body.Space, body.DryRun)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		respondSuccess(c, http.StatusOK, report, "Bundle imported")
+	}
+}
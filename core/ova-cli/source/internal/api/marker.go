@@ -1,10 +1,10 @@
 package api
 
 import (
+	"io"
 	"net/http"
 	"os"
 	"strconv"
-	"strings"
 
 	"ova-cli/source/internal/datatypes"
 	"ova-cli/source/internal/repo"
@@ -19,6 +19,24 @@ func RegisterMarkerRoutes(rg *gin.RouterGroup, rm *repo.RepoManager) {
 	rg.GET("/video/markers/:videoId/file", getMarkerFile(rm))
 	rg.DELETE("/video/markers/:videoId", deleteAllMarkers(rm))
 	rg.DELETE("/video/markers/:videoId/:hour/:minute/:second", deleteMarker(rm))
+
+	rg.POST("/video/markers/:videoId/marker", addMarker(rm))
+	rg.GET("/video/markers/:videoId/marker/:markerId", getMarkerByID(rm))
+	rg.PUT("/video/markers/:videoId/marker/:markerId", updateMarkerByID(rm))
+	rg.DELETE("/video/markers/:videoId/marker/:markerId", deleteMarkerByID(rm))
+
+	rg.GET("/video/markers/:videoId/export", exportChapters(rm))
+	rg.POST("/video/markers/:videoId/import", importChapters(rm))
+}
+
+// markerAuthor returns the user making the request, if known.
+func markerAuthor(c *gin.Context) string {
+	if username, ok := c.Get("username"); ok {
+		if name, ok := username.(string); ok {
+			return name
+		}
+	}
+	return ""
 }
 
 func updateMarkers(rm *repo.RepoManager) gin.HandlerFunc {
@@ -35,37 +53,16 @@ func updateMarkers(rm *repo.RepoManager) gin.HandlerFunc {
 			return
 		}
 
-		var markersToSave []datatypes.VideoMarker
-		for _, vm := range req.Markers {
-			if vm.Hour < 0 || vm.Minute < 0 || vm.Second < 0 {
-				respondError(c, http.StatusBadRequest, "Marker time values cannot be negative")
-				return
-			}
-			if vm.Minute >= 60 || vm.Second >= 60 {
-				respondError(c, http.StatusBadRequest, "Minute and second must be less than 60")
-				return
-			}
-			if strings.TrimSpace(vm.Title) == "" {
-				respondError(c, http.StatusBadRequest, "Marker title cannot be empty")
-				return
-			}
-			markersToSave = append(markersToSave, vm)
-		}
-
-		if err := rm.DeleteAllMarkersFromVideo(videoId); err != nil {
-			respondError(c, http.StatusInternalServerError, "Failed to clear existing markers: "+err.Error())
-			return
-		}
-		for _, marker := range markersToSave {
-			if err := rm.AddMarkerToVideo(videoId, marker); err != nil {
-				respondError(c, http.StatusInternalServerError, "Failed to add marker: "+err.Error())
-				return
+		author := markerAuthor(c)
+		for i := range req.Markers {
+			if req.Markers[i].Author == "" {
+				req.Markers[i].Author = author
 			}
 		}
 
-		markersForResponse, err := rm.GetMarkersForVideo(videoId)
+		markersForResponse, err := rm.ReplaceMarkers(videoId, req.Markers)
 		if err != nil {
-			respondError(c, http.StatusInternalServerError, "Failed to retrieve updated markers: "+err.Error())
+			respondError(c, http.StatusBadRequest, "Failed to update markers: "+err.Error())
 			return
 		}
 
@@ -193,3 +190,128 @@ func deleteMarker(rm *repo.RepoManager) gin.HandlerFunc {
 		}, "Marker deleted successfully")
 	}
 }
+
+func addMarker(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		videoId := c.Param("videoId")
+
+		var marker datatypes.VideoMarker
+		if err := c.ShouldBindJSON(&marker); err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid JSON payload: "+err.Error())
+			return
+		}
+		if author := markerAuthor(c); author != "" {
+			marker.Author = author
+		}
+
+		created, err := rm.AddMarkerToVideo(videoId, marker)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Failed to add marker: "+err.Error())
+			return
+		}
+
+		respondSuccess(c, http.StatusCreated, created, "Marker added successfully")
+	}
+}
+
+func getMarkerByID(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		marker, err := rm.GetMarkerByID(c.Param("videoId"), c.Param("markerId"))
+		if err != nil {
+			respondError(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		respondSuccess(c, http.StatusOK, marker, "Marker fetched successfully")
+	}
+}
+
+func updateMarkerByID(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		videoId := c.Param("videoId")
+		markerId := c.Param("markerId")
+
+		var marker datatypes.VideoMarker
+		if err := c.ShouldBindJSON(&marker); err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid JSON payload: "+err.Error())
+			return
+		}
+
+		updated, err := rm.UpdateMarker(videoId, markerId, marker)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Failed to update marker: "+err.Error())
+			return
+		}
+
+		respondSuccess(c, http.StatusOK, updated, "Marker updated successfully")
+	}
+}
+
+func deleteMarkerByID(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		videoId := c.Param("videoId")
+		markerId := c.Param("markerId")
+
+		if err := rm.DeleteMarkerByID(videoId, markerId); err != nil {
+			respondError(c, http.StatusNotFound, "Failed to delete marker: "+err.Error())
+			return
+		}
+
+		respondSuccess(c, http.StatusOK, gin.H{
+			"videoId":  videoId,
+			"markerId": markerId,
+		}, "Marker deleted successfully")
+	}
+}
+
+// exportChapters returns a video's markers as webvtt, youtube or ffmetadata (?format=, default webvtt).
+func exportChapters(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		videoId := c.Param("videoId")
+		format := c.DefaultQuery("format", repo.ChapterFormatWebVTT)
+
+		data, err := rm.ExportChapters(videoId, format)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Failed to export chapters: "+err.Error())
+			return
+		}
+
+		contentType := "text/plain; charset=utf-8"
+		if format == repo.ChapterFormatWebVTT {
+			contentType = "text/vtt"
+		}
+		c.Data(http.StatusOK, contentType, data)
+	}
+}
+
+// importChapters adds chapters from the raw request body to a video's markers.
+// ?format= overrides detection (container reads the video file and ignores the body),
+// and ?replace=true drops the existing markers first.
+func importChapters(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		videoId := c.Param("videoId")
+		format := c.Query("format")
+		replace := c.Query("replace") == "true"
+
+		data, err := io.ReadAll(io.LimitReader(c.Request.Body, 1<<20))
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Failed to read request body: "+err.Error())
+			return
+		}
+		if len(data) == 0 && format != repo.ChapterFormatContainer {
+			respondError(c, http.StatusBadRequest, "Request body is empty")
+			return
+		}
+
+		markers, err := rm.ImportChapters(videoId, format, data, replace)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Failed to import chapters: "+err.Error())
+			return
+		}
+
+		respondSuccess(c, http.StatusOK, gin.H{
+			"videoId": videoId,
+			"markers": markers,
+		}, "Chapters imported successfully")
+	}
+}
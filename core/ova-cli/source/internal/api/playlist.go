@@ -18,6 +18,7 @@ func RegisterUserPlaylistRoutes(rg *gin.RouterGroup, rm *repo.RepoManager) {
 		users.DELETE("/:username/playlists/:slug", deleteUserPlaylistBySlug(rm))
 		users.PUT("/:username/playlists/order", setUserPlaylistsOrder(rm))
 		users.PUT("/:username/playlists/:slug", updateUserPlaylistInfo(rm))
+		users.PUT("/:username/playlists/:slug/rules", updateUserPlaylistRules(rm))
 	}
 }
 
@@ -30,24 +31,29 @@ func getUserPlaylists(rm *repo.RepoManager) gin.HandlerFunc {
 			return
 		}
 
-		var headVideoId string
-
-		if len(user.Playlists) > 0 && len(user.Playlists[0].VideoIDs) > 0 {
-			headVideoId = user.Playlists[0].VideoIDs[0]
-		} else {
-			headVideoId = "" // or nil in JSON
-		}
-
 		// Process each playlist to add headVideoId and totalVideos
 		playlists := []map[string]interface{}{}
 		for _, playlist := range user.Playlists {
+			// Smart playlists are resolved from their rules
+			videoIDs, err := rm.GetUserPlaylistVideoIDs(username, playlist.Slug)
+			if err != nil {
+				videoIDs = playlist.VideoIDs
+			}
+			totalVideos := len(videoIDs)
+			headVideoId := "" // empty for playlists without videos
+			if totalVideos > 0 {
+				headVideoId = videoIDs[0]
+			}
+
 			playlists = append(playlists, map[string]interface{}{
 				"title":       playlist.Title,
 				"description": playlist.Description,
-				"headVideoId": headVideoId,            // first video ID in the playlist
-				"totalVideos": len(playlist.VideoIDs), // count of videos
+				"headVideoId": headVideoId, // first video ID in the playlist
+				"totalVideos": totalVideos, // count of videos
 				"slug":        playlist.Slug,
 				"order":       playlist.Order,
+				"isSmart":     playlist.IsSmart(),
+				"rules":       playlist.Rules,
 			})
 		}
 
@@ -156,3 +162,29 @@ func updateUserPlaylistInfo(rm *repo.RepoManager) gin.HandlerFunc {
 		respondSuccess(c, http.StatusOK, pl, "Playlist info updated")
 	}
 }
+
+func updateUserPlaylistRules(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username := c.Param("username")
+		slug := c.Param("slug")
+
+		var rules datatypes.SmartPlaylistRules
+		if err := c.ShouldBindJSON(&rules); err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid JSON: "+err.Error())
+			return
+		}
+
+		if err := rm.UpdatePlaylistRules(username, slug, rules); err != nil {
+			respondError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		pl, err := rm.GetUserPlaylist(username, slug)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "Failed to retrieve updated playlist")
+			return
+		}
+
+		respondSuccess(c, http.StatusOK, pl, "Playlist rules updated")
+	}
+}
@@ -1,7 +1,9 @@
 package api
 
 import (
+	"errors"
 	"net/http"
+	"ova-cli/source/internal/datatypes"
 	"ova-cli/source/internal/repo"
 	"strconv"
 
@@ -15,6 +17,10 @@ func RegisterUserPlaylistContentRoutes(rg *gin.RouterGroup, rm *repo.RepoManager
 		users.GET("/:username/playlists/:slug", getUserPlaylistContents(rm))
 		users.POST("/:username/playlists/:slug/videos", addVideoToPlaylist(rm))
 		users.DELETE("/:username/playlists/:slug/videos/:videoId", deleteVideoFromPlaylist(rm))
+		users.PUT("/:username/playlists/:slug/videos/:videoId/position", moveVideoInPlaylist(rm))
+		users.POST("/:username/playlists/:slug/videos/remove", removeVideosFromPlaylist(rm))
+		users.POST("/:username/playlists/:slug/videos/copy", copyVideosToPlaylist(rm))
+		users.POST("/:username/playlists/:slug/dedupe", deduplicatePlaylist(rm))
 	}
 }
 
@@ -85,21 +91,33 @@ func getUserPlaylistContents(rm *repo.RepoManager) gin.HandlerFunc {
 	}
 }
 
+// addVideoToPlaylist adds one video ("videoId") or many ("videoIds") to a playlist.
+// An optional "index" inserts them at that position instead of appending.
 func addVideoToPlaylist(rm *repo.RepoManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		username := c.Param("username")
 		slug := c.Param("slug")
 
 		var body struct {
-			VideoID string `json:"videoId"`
+			VideoID  string   `json:"videoId"`
+			VideoIDs []string `json:"videoIds"`
+			Index    *int     `json:"index"`
 		}
-		if err := c.ShouldBindJSON(&body); err != nil || body.VideoID == "" {
-			respondError(c, http.StatusBadRequest, "Invalid or missing videoId")
+		if err := c.ShouldBindJSON(&body); err != nil || (body.VideoID == "" && len(body.VideoIDs) == 0) {
+			respondError(c, http.StatusBadRequest, "Invalid or missing videoId or videoIds")
 			return
 		}
 
-		err := rm.AddVideoToPlaylist(username, slug, body.VideoID)
-		if err != nil {
+		videoIDs := body.VideoIDs
+		if body.VideoID != "" {
+			videoIDs = append([]string{body.VideoID}, videoIDs...)
+		}
+		index := -1
+		if body.Index != nil {
+			index = *body.Index
+		}
+
+		if _, err := rm.InsertVideosIntoPlaylist(username, slug, videoIDs, index); err != nil {
 			respondError(c, http.StatusInternalServerError, err.Error())
 			return
 		}
@@ -125,3 +143,110 @@ func deleteVideoFromPlaylist(rm *repo.RepoManager) gin.HandlerFunc {
 		respondSuccess(c, http.StatusOK, pl, "Video removed from playlist")
 	}
 }
+
+func moveVideoInPlaylist(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username := c.Param("username")
+		slug := c.Param("slug")
+		videoId := c.Param("videoId")
+
+		var body struct {
+			Index    *int `json:"index"`
+			Revision *int `json:"revision"` // Optional; the move fails with 409 if the playlist changed since
+		}
+		if err := c.ShouldBindJSON(&body); err != nil || body.Index == nil {
+			respondError(c, http.StatusBadRequest, "Invalid or missing index")
+			return
+		}
+
+		err := rm.MoveVideoInPlaylist(username, slug, videoId, *body.Index, body.Revision)
+		if errors.Is(err, datatypes.ErrPlaylistRevisionConflict) {
+			respondError(c, http.StatusConflict, err.Error())
+			return
+		} else if err != nil {
+			respondError(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		pl, _ := rm.GetUserPlaylist(username, slug)
+		respondSuccess(c, http.StatusOK, pl, "Video moved in playlist")
+	}
+}
+
+// removeVideosFromPlaylist removes every video listed in "videoIds" in one step.
+func removeVideosFromPlaylist(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username := c.Param("username")
+		slug := c.Param("slug")
+
+		var body struct {
+			VideoIDs []string `json:"videoIds"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil || len(body.VideoIDs) == 0 {
+			respondError(c, http.StatusBadRequest, "Invalid or missing videoIds")
+			return
+		}
+
+		removed, err := rm.RemoveVideosFromPlaylist(username, slug, body.VideoIDs)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		pl, _ := rm.GetUserPlaylist(username, slug)
+		respondSuccess(c, http.StatusOK, gin.H{"playlist": pl, "removed": removed}, "Videos removed from playlist")
+	}
+}
+
+// copyVideosToPlaylist copies videos from another playlist, which may be owned by another
+// user who shared it, into this one. Without "videoIds" the whole source is copied.
+func copyVideosToPlaylist(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username := c.Param("username")
+		slug := c.Param("slug")
+
+		var body struct {
+			SourceOwner string   `json:"sourceOwner"`
+			SourceSlug  string   `json:"sourceSlug"`
+			VideoIDs    []string `json:"videoIds"`
+			Index       *int     `json:"index"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil || body.SourceSlug == "" {
+			respondError(c, http.StatusBadRequest, "Invalid or missing sourceSlug")
+			return
+		}
+		if body.SourceOwner == "" {
+			body.SourceOwner = username
+		}
+		index := -1
+		if body.Index != nil {
+			index = *body.Index
+		}
+
+		added, err := rm.CopyPlaylistVideos(username, body.SourceOwner, body.SourceSlug, slug, body.VideoIDs, index)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		pl, _ := rm.GetUserPlaylist(username, slug)
+		respondSuccess(c, http.StatusOK, gin.H{"playlist": pl, "added": added}, "Videos copied to playlist")
+	}
+}
+
+// deduplicatePlaylist removes repeated videos, keeping the first occurrence of each.
+func deduplicatePlaylist(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username := c.Param("username")
+		slug := c.Param("slug")
+
+		removed, err := rm.DeduplicatePlaylist(username, slug)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		pl, _ := rm.GetUserPlaylist(username, slug)
+		respondSuccess(c, http.StatusOK, gin.H{"playlist": pl, "removed": removed}, "Playlist deduplicated")
+	}
+}
@@ -0,0 +1,297 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"ova-cli/source/internal/datatypes"
+	"ova-cli/source/internal/repo"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterPlaylistSharingRoutes registers the routes to share playlists and to work on playlists shared with a user.
+func RegisterPlaylistSharingRoutes(rg *gin.RouterGroup, rm *repo.RepoManager) {
+	users := rg.Group("/users")
+	{
+		// Owner side: manage who the playlist is shared with
+		users.PUT("/:username/playlists/:slug/collaborators/:collaborator", sharePlaylistWithUser(rm))
+		users.DELETE("/:username/playlists/:slug/collaborators/:collaborator", unsharePlaylistWithUser(rm))
+		users.PUT("/:username/playlists/:slug/spaces/:spaceId", sharePlaylistWithSpace(rm))
+		users.DELETE("/:username/playlists/:slug/spaces/:spaceId", unsharePlaylistWithSpace(rm))
+
+		// Collaborator side: playlists of other users shared with :username
+		users.GET("/:username/shared-playlists", getPlaylistsSharedWithUser(rm))
+		users.GET("/:username/shared-playlists/:owner/:slug", getSharedPlaylistContents(rm))
+		users.POST("/:username/shared-playlists/:owner/:slug/videos", addVideoToSharedPlaylist(rm))
+		users.DELETE("/:username/shared-playlists/:owner/:slug/videos/:videoId", deleteVideoFromSharedPlaylist(rm))
+		users.PUT("/:username/shared-playlists/:owner/:slug/videos/:videoId/position", moveVideoInSharedPlaylist(rm))
+	}
+}
+
+func sharePlaylistWithUser(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username, ok := sharingUser(c)
+		if !ok {
+			return
+		}
+		slug := c.Param("slug")
+		collaborator := c.Param("collaborator")
+
+		var body struct {
+			Permission string `json:"permission"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil || body.Permission == "" {
+			respondError(c, http.StatusBadRequest, "Invalid or missing permission")
+			return
+		}
+
+		if err := rm.SharePlaylistWithUser(username, slug, collaborator, body.Permission); err != nil {
+			respondError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		pl, _ := rm.GetUserPlaylist(username, slug)
+		respondSuccess(c, http.StatusOK, pl, "Playlist shared with user")
+	}
+}
+
+func unsharePlaylistWithUser(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username, ok := sharingUser(c)
+		if !ok {
+			return
+		}
+		slug := c.Param("slug")
+
+		if err := rm.UnsharePlaylistWithUser(username, slug, c.Param("collaborator")); err != nil {
+			respondError(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		pl, _ := rm.GetUserPlaylist(username, slug)
+		respondSuccess(c, http.StatusOK, pl, "Playlist no longer shared with user")
+	}
+}
+
+func sharePlaylistWithSpace(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username, ok := sharingUser(c)
+		if !ok {
+			return
+		}
+		slug := c.Param("slug")
+
+		var body struct {
+			Permission string `json:"permission"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil || body.Permission == "" {
+			respondError(c, http.StatusBadRequest, "Invalid or missing permission")
+			return
+		}
+
+		if err := rm.SharePlaylistWithSpace(username, slug, c.Param("spaceId"), body.Permission); err != nil {
+			respondError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		pl, _ := rm.GetUserPlaylist(username, slug)
+		respondSuccess(c, http.StatusOK, pl, "Playlist shared with space")
+	}
+}
+
+func unsharePlaylistWithSpace(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username, ok := sharingUser(c)
+		if !ok {
+			return
+		}
+		slug := c.Param("slug")
+
+		if err := rm.UnsharePlaylistWithSpace(username, slug, c.Param("spaceId")); err != nil {
+			respondError(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		pl, _ := rm.GetUserPlaylist(username, slug)
+		respondSuccess(c, http.StatusOK, pl, "Playlist no longer shared with space")
+	}
+}
+
+func getPlaylistsSharedWithUser(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username, ok := sharingUser(c)
+		if !ok {
+			return
+		}
+
+		if _, err := rm.GetUserByUsername(username); err != nil {
+			respondError(c, http.StatusNotFound, "User not found")
+			return
+		}
+
+		shared, err := rm.GetPlaylistsSharedWithUser(username)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		respondSuccess(c, http.StatusOK, gin.H{
+			"username":       username,
+			"playlists":      shared,
+			"totalPlaylists": len(shared),
+		}, "Shared playlists retrieved")
+	}
+}
+
+func getSharedPlaylistContents(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username, ok := sharingUser(c)
+		if !ok {
+			return
+		}
+		owner := c.Param("owner")
+		slug := c.Param("slug")
+
+		bucket, err := strconv.Atoi(c.DefaultQuery("bucket", "1"))
+		if err != nil || bucket <= 0 {
+			respondError(c, http.StatusBadRequest, "Invalid bucket parameter")
+			return
+		}
+
+		bucketContentSize := rm.GetConfigs().MaxBucketSize
+
+		totalVideos, err := rm.GetSharedPlaylistContentVideosCount(username, owner, slug)
+		if err != nil {
+			respondError(c, http.StatusForbidden, err.Error())
+			return
+		}
+
+		videos := []string{}
+		start := (bucket - 1) * bucketContentSize
+		end := min(start+bucketContentSize, totalVideos)
+		if start < end {
+			videos, err = rm.GetSharedPlaylistContentVideosInRange(username, owner, slug, start, end)
+			if err != nil {
+				respondError(c, http.StatusInternalServerError, "Failed to retrieve playlist videos")
+				return
+			}
+		}
+
+		pl, _ := rm.GetUserPlaylist(owner, slug)
+		revision := 0
+		if pl != nil {
+			revision = pl.Revision
+		}
+
+		respondSuccess(c, http.StatusOK, gin.H{
+			"username":          username,
+			"owner":             owner,
+			"slug":              slug,
+			"revision":          revision,
+			"videoIds":          videos,
+			"totalVideos":       totalVideos,
+			"currentBucket":     bucket,
+			"bucketContentSize": bucketContentSize,
+			"totalBuckets":      (totalVideos + bucketContentSize - 1) / bucketContentSize,
+		}, "Playlist contents retrieved successfully")
+	}
+}
+
+func addVideoToSharedPlaylist(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username, ok := sharingUser(c)
+		if !ok {
+			return
+		}
+		owner := c.Param("owner")
+		slug := c.Param("slug")
+
+		var body struct {
+			VideoID string `json:"videoId"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil || body.VideoID == "" {
+			respondError(c, http.StatusBadRequest, "Invalid or missing videoId")
+			return
+		}
+
+		err := rm.AddVideoToSharedPlaylist(username, owner, slug, body.VideoID)
+		if err != nil {
+			respondSharedPlaylistError(c, err)
+			return
+		}
+
+		pl, _ := rm.GetUserPlaylist(owner, slug)
+		respondSuccess(c, http.StatusOK, pl, "Video added to playlist")
+	}
+}
+
+func deleteVideoFromSharedPlaylist(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username, ok := sharingUser(c)
+		if !ok {
+			return
+		}
+		owner := c.Param("owner")
+		slug := c.Param("slug")
+
+		err := rm.RemoveVideoFromSharedPlaylist(username, owner, slug, c.Param("videoId"))
+		if err != nil {
+			respondSharedPlaylistError(c, err)
+			return
+		}
+
+		pl, _ := rm.GetUserPlaylist(owner, slug)
+		respondSuccess(c, http.StatusOK, pl, "Video removed from playlist")
+	}
+}
+
+func moveVideoInSharedPlaylist(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username, ok := sharingUser(c)
+		if !ok {
+			return
+		}
+		owner := c.Param("owner")
+		slug := c.Param("slug")
+
+		var body struct {
+			Index    *int `json:"index"`
+			Revision *int `json:"revision"` // Optional; the move fails with 409 if the playlist changed since
+		}
+		if err := c.ShouldBindJSON(&body); err != nil || body.Index == nil {
+			respondError(c, http.StatusBadRequest, "Invalid or missing index")
+			return
+		}
+
+		err := rm.MoveVideoInSharedPlaylist(username, owner, slug, c.Param("videoId"), *body.Index, body.Revision)
+		if err != nil {
+			respondSharedPlaylistError(c, err)
+			return
+		}
+
+		pl, _ := rm.GetUserPlaylist(owner, slug)
+		respondSuccess(c, http.StatusOK, pl, "Video moved in playlist")
+	}
+}
+
+// sharingUser returns the user a sharing request acts as. Permissions are checked against the
+// session user, so :username must name them; without authentication the path is trusted.
+func sharingUser(c *gin.Context) (string, bool) {
+	username := c.Param("username")
+	if session, ok := c.Get("username"); ok && session != username {
+		respondError(c, http.StatusForbidden, "Cannot act on behalf of another user")
+		return "", false
+	}
+	return username, true
+}
+
+// respondSharedPlaylistError reports a failed write to a shared playlist, with 409 when the
+// playlist changed since the revision the client based its edit on.
+func respondSharedPlaylistError(c *gin.Context, err error) {
+	if errors.Is(err, datatypes.ErrPlaylistRevisionConflict) {
+		respondError(c, http.StatusConflict, err.Error())
+		return
+	}
+	respondError(c, http.StatusForbidden, err.Error())
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"ova-cli/source/internal/repo"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxPlaylistImportSize caps the size of an uploaded playlist file.
+const maxPlaylistImportSize = 10 << 20
+
+// RegisterPlaylistTransferRoutes registers playlist import and export routes under the user scope.
+func RegisterPlaylistTransferRoutes(rg *gin.RouterGroup, rm *repo.RepoManager) {
+	users := rg.Group("/users")
+	{
+		users.GET("/:username/playlists/:slug/export", exportUserPlaylist(rm))
+		users.POST("/:username/playlists/import", importUserPlaylist(rm))
+	}
+}
+
+// exportUserPlaylist returns a playlist as m3u8, xspf or json (?format=, default m3u8).
+// Stream URLs point back at the host the request was made to.
+func exportUserPlaylist(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username := c.Param("username")
+		slug := c.Param("slug")
+		format := c.DefaultQuery("format", repo.PlaylistFormatM3U8)
+
+		scheme := "http"
+		if c.Request.TLS != nil {
+			scheme = "https"
+		}
+		if forwarded := c.GetHeader("X-Forwarded-Proto"); forwarded != "" {
+			scheme = forwarded
+		}
+
+		data, contentType, err := rm.ExportPlaylist(username, slug, format, scheme+"://"+c.Request.Host)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		c.Header("Content-Disposition", `attachment; filename="`+slug+"."+format+`"`)
+		c.Data(http.StatusOK, contentType, data)
+	}
+}
+
+// importUserPlaylist reads a playlist file from the raw request body.
+// ?format= overrides detection and ?title= names the target playlist.
+func importUserPlaylist(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username := c.Param("username")
+
+		if _, err := rm.GetUserByUsername(username); err != nil {
+			respondError(c, http.StatusNotFound, "User not found")
+			return
+		}
+
+		data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxPlaylistImportSize+1))
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Failed to read request body: "+err.Error())
+			return
+		}
+		if len(data) > maxPlaylistImportSize {
+			respondError(c, http.StatusRequestEntityTooLarge, "Playlist file is too large")
+			return
+		}
+		if len(data) == 0 {
+			respondError(c, http.StatusBadRequest, "Request body is empty")
+			return
+		}
+
+		report, err := rm.ImportPlaylist(username, data, c.Query("format"), c.Query("title"), false)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		status := http.StatusOK
+		if report.Created {
+			status = http.StatusCreated
+		}
+		respondSuccess(c, status, report, "Playlist imported")
+	}
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"net/http"
+	"ova-cli/source/internal/datatypes"
+	"ova-cli/source/internal/repo"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterUserProgressRoutes adds playback progress, continue watching and history endpoints for users.
+func RegisterUserProgressRoutes(rg *gin.RouterGroup, repoMgr *repo.RepoManager) {
+	users := rg.Group("/users/:username")
+	{
+		users.PUT("/progress/:videoId", updateWatchProgress(repoMgr))    // PUT    /api/v1/users/:username/progress/:videoId
+		users.GET("/progress/:videoId", getWatchProgress(repoMgr))       // GET    /api/v1/users/:username/progress/:videoId
+		users.DELETE("/progress/:videoId", deleteWatchProgress(repoMgr)) // DELETE /api/v1/users/:username/progress/:videoId
+		users.GET("/continue-watching", getContinueWatching(repoMgr))    // GET    /api/v1/users/:username/continue-watching
+		users.GET("/history", getWatchHistory(repoMgr))                  // GET    /api/v1/users/:username/history
+	}
+}
+
+// updateWatchProgress is called periodically by the player with the current playback position.
+func updateWatchProgress(r *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username := c.Param("username")
+		videoID := c.Param("videoId")
+
+		var req struct {
+			PositionSec float64 `json:"positionSec"`
+			DurationSec float64 `json:"durationSec"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid JSON: "+err.Error())
+			return
+		}
+
+		progress, err := r.UpdateWatchProgress(username, videoID, req.PositionSec, req.DurationSec)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		respondSuccess(c, http.StatusOK, progress, "Watch progress updated")
+	}
+}
+
+func getWatchProgress(r *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		progress, err := r.GetWatchProgress(c.Param("username"), c.Param("videoId"))
+		if err != nil {
+			respondError(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		respondSuccess(c, http.StatusOK, progress, "Watch progress retrieved")
+	}
+}
+
+func deleteWatchProgress(r *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := r.DeleteWatchProgress(c.Param("username"), c.Param("videoId")); err != nil {
+			respondError(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		respondSuccess(c, http.StatusOK, nil, "Watch progress deleted")
+	}
+}
+
+// getContinueWatching returns started but unfinished videos, most recent first.
+func getContinueWatching(r *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		entries, err := r.GetUserContinueWatching(c.Param("username"))
+		if err != nil {
+			respondError(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		respondProgressBucket(c, r, entries, "Fetched continue watching videos")
+	}
+}
+
+// getWatchHistory returns every played video with its progress, most recent first.
+func getWatchHistory(r *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		entries, err := r.GetUserWatchHistory(c.Param("username"))
+		if err != nil {
+			respondError(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		respondProgressBucket(c, r, entries, "Fetched watch history")
+	}
+}
+
+// respondProgressBucket responds with one bucket of progress entries, using the same
+// bucket fields as the other video listings plus the progress of each video.
+func respondProgressBucket(c *gin.Context, r *repo.RepoManager, entries []datatypes.WatchProgress, message string) {
+	bucket, err := strconv.Atoi(c.DefaultQuery("bucket", "1"))
+	if err != nil || bucket <= 0 {
+		respondError(c, http.StatusBadRequest, "Invalid bucket parameter")
+		return
+	}
+
+	bucketContentSize := r.GetConfigs().MaxBucketSize
+	if bucketContentSize <= 0 {
+		bucketContentSize = 20
+	}
+	totalVideos := len(entries)
+
+	start := (bucket - 1) * bucketContentSize
+	end := start + bucketContentSize
+	if start > totalVideos {
+		start = totalVideos
+	}
+	if end > totalVideos {
+		end = totalVideos
+	}
+
+	inRange := entries[start:end]
+	videoIds := make([]string, 0, len(inRange))
+	for _, entry := range inRange {
+		videoIds = append(videoIds, entry.VideoID)
+	}
+
+	respondSuccess(c, http.StatusOK, gin.H{
+		"videoIds":          videoIds,
+		"progress":          inRange,
+		"totalVideos":       totalVideos,
+		"currentBucket":     bucket,
+		"bucketContentSize": bucketContentSize,
+		"totalBuckets":      (totalVideos + bucketContentSize - 1) / bucketContentSize,
+	}, message)
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"net/http"
+	"slices"
 
 	"ova-cli/source/internal/repo"
 
@@ -22,6 +23,8 @@ func getSpaceList(rm *repo.RepoManager) gin.HandlerFunc {
 			return
 		}
 
+		// Archived spaces are not listed
+		spaces = slices.DeleteFunc(spaces, rm.IsSpaceArchived)
 		spaces = append(spaces, ".")
 
 		respondSuccess(c, http.StatusOK, spaces, "Folders retrieved successfully")
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"ova-cli/source/internal/datatypes"
+	"ova-cli/source/internal/repo"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterSpaceAdminRoutes registers the space lifecycle routes. The group is
+// expected to be protected by AdminMiddleware.
+func RegisterSpaceAdminRoutes(rg *gin.RouterGroup, rm *repo.RepoManager) {
+	spaces := rg.Group("/spaces")
+	{
+		spaces.GET("", getAdminSpaces(rm))
+		spaces.POST("", createAdminSpace(rm))
+		spaces.GET("/:spaceId", getAdminSpace(rm))
+		spaces.DELETE("/:spaceId", deleteAdminSpace(rm))
+		spaces.PUT("/:spaceId/name", renameAdminSpace(rm))
+		spaces.PUT("/:spaceId/settings", updateAdminSpaceSettings(rm))
+		spaces.PUT("/:spaceId/owner", transferAdminSpaceOwnership(rm))
+		spaces.POST("/:spaceId/archive", archiveAdminSpace(rm, true))
+		spaces.POST("/:spaceId/unarchive", archiveAdminSpace(rm, false))
+	}
+}
+
+func getAdminSpaces(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		spaces, err := rm.GetAllSpaces()
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "Failed to load spaces")
+			return
+		}
+		respondSuccess(c, http.StatusOK, gin.H{
+			"spaces":      spaces,
+			"totalSpaces": len(spaces),
+		}, "Spaces retrieved successfully")
+	}
+}
+
+func createAdminSpace(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Name  string `json:"name"`
+			Owner string `json:"owner"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil || body.Name == "" {
+			respondError(c, http.StatusBadRequest, "Invalid or missing space name")
+			return
+		}
+		if body.Owner == "" {
+			body.Owner = rm.GetRootUsername()
+		}
+
+		space := datatypes.CreateDefaultSpaceData(body.Name, body.Owner)
+		if err := rm.CreateSpace(space); err != nil {
+			respondError(c, http.StatusConflict, err.Error())
+			return
+		}
+		respondSuccess(c, http.StatusCreated, space, "Space created")
+	}
+}
+
+func getAdminSpace(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		space, err := rm.GetSpaceByID(c.Param("spaceId"))
+		if err != nil {
+			respondError(c, http.StatusNotFound, "Space not found")
+			return
+		}
+		respondSuccess(c, http.StatusOK, space, "Space retrieved successfully")
+	}
+}
+
+func deleteAdminSpace(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		spaceID := c.Param("spaceId")
+		deleteFiles, _ := strconv.ParseBool(c.DefaultQuery("files", "false"))
+
+		if !rm.SpaceExists(spaceID) {
+			respondError(c, http.StatusNotFound, "Space not found")
+			return
+		}
+
+		if err := rm.DeleteSpace(spaceID, deleteFiles); err != nil {
+			respondError(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		respondSuccess(c, http.StatusOK, gin.H{}, "Space deleted")
+	}
+}
+
+func renameAdminSpace(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		spaceID := c.Param("spaceId")
+
+		var body struct {
+			Name string `json:"name"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil || body.Name == "" {
+			respondError(c, http.StatusBadRequest, "Invalid or missing space name")
+			return
+		}
+
+		if !rm.SpaceExists(spaceID) {
+			respondError(c, http.StatusNotFound, "Space not found")
+			return
+		}
+
+		if err := rm.RenameSpace(spaceID, body.Name); err != nil {
+			respondError(c, http.StatusConflict, err.Error())
+			return
+		}
+
+		space, _ := rm.GetSpaceByID(spaceID)
+		respondSuccess(c, http.StatusOK, space, "Space renamed")
+	}
+}
+
+func updateAdminSpaceSettings(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		spaceID := c.Param("spaceId")
+
+		var settings datatypes.SpaceSettings
+		if err := c.ShouldBindJSON(&settings); err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid JSON: "+err.Error())
+			return
+		}
+
+		if err := rm.UpdateSpaceSettings(spaceID, settings); err != nil {
+			respondError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		respondSuccess(c, http.StatusOK, settings, "Space settings updated")
+	}
+}
+
+func transferAdminSpaceOwnership(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		spaceID := c.Param("spaceId")
+
+		var body struct {
+			Owner string `json:"owner"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil || body.Owner == "" {
+			respondError(c, http.StatusBadRequest, "Invalid or missing owner")
+			return
+		}
+
+		if !rm.SpaceExists(spaceID) {
+			respondError(c, http.StatusNotFound, "Space not found")
+			return
+		}
+
+		if err := rm.TransferSpaceOwnership(spaceID, body.Owner); err != nil {
+			respondError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		space, _ := rm.GetSpaceByID(spaceID)
+		respondSuccess(c, http.StatusOK, space, "Space ownership transferred")
+	}
+}
+
+func archiveAdminSpace(rm *repo.RepoManager, archived bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		spaceID := c.Param("spaceId")
+
+		var err error
+		if archived {
+			err = rm.ArchiveSpace(spaceID)
+		} else {
+			err = rm.UnarchiveSpace(spaceID)
+		}
+		if err != nil {
+			respondError(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		message := "Space unarchived"
+		if archived {
+			message = "Space archived"
+		}
+		respondSuccess(c, http.StatusOK, gin.H{"spaceId": spaceID, "isArchived": archived}, message)
+	}
+}
@@ -30,6 +30,11 @@ func getVideosOnSpace(repoMgr *repo.RepoManager) gin.HandlerFunc {
 			return
 		}
 
+		if repoMgr.IsSpaceArchived(requestedPath) {
+			respondError(c, http.StatusForbidden, "Space is archived")
+			return
+		}
+
 		// Fixed bucket size
 		bucketContentSize := 20
 
@@ -1,10 +1,10 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
-	"path/filepath"
 
 	"ova-cli/source/internal/repo"
 
@@ -15,20 +15,40 @@ import (
 func RegisterStreamRoutes(rg *gin.RouterGroup, repoManager *repo.RepoManager) {
 	rg.GET("/stream/:videoId", streamVideo(repoManager))
 	rg.HEAD("/stream/:videoId", streamVideo(repoManager)) // vidstack needs this for loading the video
+	rg.GET("/stream/:videoId/audio", listAudioTracks(repoManager))
 }
 
 // streamVideo returns a handler function that streams a video file by its ID.
+// The optional "audio" query picks an audio track by language or stream index.
 func streamVideo(repoManager *repo.RepoManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		videoId := c.Param("videoId")
 
-		video, err := repoManager.GetVideoByID(videoId)
-		if err != nil {
+		if repoManager.IsVideoArchived(videoId) {
+			respondError(c, http.StatusForbidden, "Video belongs to an archived space")
+			return
+		}
+
+		videoPath, err := repoManager.GetVideoFilePathByID(videoId)
+		if errors.Is(err, repo.ErrSubRepositoryOffline) {
+			respondError(c, http.StatusServiceUnavailable, "Video is stored in an offline repository")
+			return
+		} else if err != nil {
 			respondError(c, http.StatusNotFound, "Video not found")
 			return
 		}
 
-		videoPath := filepath.Join(video.OwnedSpace, video.FileName+video.Codecs.Format)
+		if audio := c.Query("audio"); audio != "" {
+			videoPath, err = repoManager.GetVideoFilePathWithAudio(videoId, audio)
+			if errors.Is(err, repo.ErrAudioTrackNotFound) {
+				respondError(c, http.StatusNotFound, err.Error())
+				return
+			} else if err != nil {
+				respondError(c, http.StatusInternalServerError, "Failed to prepare audio track: "+err.Error())
+				return
+			}
+		}
+
 		file, err := os.Open(videoPath)
 		if err != nil {
 			if os.IsNotExist(err) {
@@ -55,3 +75,24 @@ func streamVideo(repoManager *repo.RepoManager) gin.HandlerFunc {
 		http.ServeContent(c.Writer, c.Request, videoPath, fi.ModTime(), file)
 	}
 }
+
+// listAudioTracks returns the audio streams that can be chosen with the "audio" query of the stream endpoint.
+func listAudioTracks(repoManager *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		videoId := c.Param("videoId")
+
+		tracks, err := repoManager.GetVideoAudioStreams(videoId)
+		if errors.Is(err, repo.ErrSubRepositoryOffline) {
+			respondError(c, http.StatusServiceUnavailable, "Video is stored in an offline repository")
+			return
+		} else if err != nil {
+			respondError(c, http.StatusNotFound, "Failed to get audio tracks: "+err.Error())
+			return
+		}
+
+		respondSuccess(c, http.StatusOK, gin.H{
+			"videoId": videoId,
+			"tracks":  tracks,
+		}, "Audio tracks retrieved successfully")
+	}
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"net/http"
+
+	"ova-cli/source/internal/repo"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterSubRepositoryRoutes exposes the repositories attached to this library.
+func RegisterSubRepositoryRoutes(rg *gin.RouterGroup, rm *repo.RepoManager) {
+	rg.GET("/repositories", getSubRepositories(rm))
+}
+
+// getSubRepositories lists attached repositories and whether they are currently available.
+func getSubRepositories(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Pick up drives that were plugged in or removed since the last check
+		if err := rm.RefreshSubRepositories(); err != nil {
+			respondError(c, http.StatusInternalServerError, "Failed to refresh repositories")
+			return
+		}
+
+		statuses := rm.GetSubRepositoryStatuses()
+		respondSuccess(c, http.StatusOK, gin.H{
+			"repositories":      statuses,
+			"totalRepositories": len(statuses),
+		}, "Repositories retrieved successfully")
+	}
+}
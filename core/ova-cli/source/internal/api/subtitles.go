@@ -0,0 +1,129 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"ova-cli/source/internal/repo"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxSubtitleUploadSize limits uploaded subtitle files; text subtitles are rarely over a megabyte.
+const maxSubtitleUploadSize = 10 << 20
+
+// RegisterSubtitleRoutes sets up the API endpoints for listing, serving and uploading subtitle tracks.
+func RegisterSubtitleRoutes(rg *gin.RouterGroup, rm *repo.RepoManager) {
+	rg.GET("/subtitles/:videoId", listSubtitleTracks(rm))
+	rg.GET("/subtitles/:videoId/:trackId", getSubtitleTrackFile(rm))
+	rg.POST("/subtitles/:videoId", uploadSubtitleTrack(rm))
+	rg.DELETE("/subtitles/:videoId/:trackId", deleteSubtitleTrack(rm))
+}
+
+func listSubtitleTracks(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		videoId := c.Param("videoId")
+		if _, err := rm.GetVideoByID(videoId); err != nil {
+			respondError(c, http.StatusNotFound, "Video not found")
+			return
+		}
+
+		tracks, err := rm.GetSubtitleTracks(videoId)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "Failed to get subtitle tracks: "+err.Error())
+			return
+		}
+
+		respondSuccess(c, http.StatusOK, gin.H{
+			"videoId": videoId,
+			"tracks":  tracks,
+		}, "Subtitle tracks retrieved successfully")
+	}
+}
+
+func getSubtitleTrackFile(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		videoId := c.Param("videoId")
+		trackId := strings.TrimSuffix(c.Param("trackId"), ".vtt")
+		if _, err := rm.GetVideoByID(videoId); err != nil {
+			respondError(c, http.StatusNotFound, "Video not found")
+			return
+		}
+
+		_, filePath, err := rm.GetSubtitleTrack(videoId, trackId)
+		if err != nil {
+			respondError(c, http.StatusNotFound, err.Error())
+			return
+		}
+		if _, err := os.Stat(filePath); err != nil {
+			respondError(c, http.StatusNotFound, "Subtitle file not found for track: "+trackId)
+			return
+		}
+
+		c.Header("Content-Type", "text/vtt; charset=utf-8")
+		c.File(filePath)
+	}
+}
+
+func uploadSubtitleTrack(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		videoId := c.Param("videoId")
+
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Subtitle file is required")
+			return
+		}
+		if fileHeader.Size > maxSubtitleUploadSize {
+			respondError(c, http.StatusRequestEntityTooLarge, "Subtitle file is too large")
+			return
+		}
+
+		language := strings.TrimSpace(c.PostForm("language"))
+		if language == "" {
+			respondError(c, http.StatusBadRequest, "language is required")
+			return
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "Failed to open subtitle file")
+			return
+		}
+		defer file.Close()
+
+		data, err := io.ReadAll(file)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "Failed to read subtitle file")
+			return
+		}
+
+		track, err := rm.AddSubtitleTrack(videoId, data, fileHeader.Filename, language, strings.TrimSpace(c.PostForm("label")), markerAuthor(c))
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Failed to add subtitle track: "+err.Error())
+			return
+		}
+
+		respondSuccess(c, http.StatusCreated, track, "Subtitle track added successfully")
+	}
+}
+
+func deleteSubtitleTrack(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		videoId := c.Param("videoId")
+		trackId := c.Param("trackId")
+		if _, err := rm.GetVideoByID(videoId); err != nil {
+			respondError(c, http.StatusNotFound, "Video not found")
+			return
+		}
+
+		if err := rm.RemoveSubtitleTrack(videoId, trackId); err != nil {
+			respondError(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		respondSuccess(c, http.StatusOK, gin.H{"videoId": videoId, "trackId": trackId}, "Subtitle track deleted successfully")
+	}
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"net/http"
+
+	"ova-cli/source/internal/repo"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterTrashRoutes registers the routes that list, restore and purge deleted videos.
+// The group is expected to be protected by AdminMiddleware.
+func RegisterTrashRoutes(rg *gin.RouterGroup, rm *repo.RepoManager) {
+	trash := rg.Group("/trash")
+	{
+		trash.GET("", getTrash(rm))
+		trash.DELETE("", purgeTrash(rm))
+		trash.POST("/:videoId/restore", restoreTrashedVideo(rm))
+		trash.DELETE("/:videoId", purgeTrashedVideo(rm))
+	}
+}
+
+func getTrash(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		entries, err := rm.ListTrash()
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "Failed to load trash")
+			return
+		}
+		respondSuccess(c, http.StatusOK, gin.H{
+			"videos":      entries,
+			"totalVideos": len(entries),
+		}, "Trash retrieved successfully")
+	}
+}
+
+// purgeTrash deletes the expired videos, or the whole trash with ?all=true.
+func purgeTrash(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report, err := rm.PurgeTrash(nil, c.Query("all") == "true")
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "Failed to purge trash")
+			return
+		}
+		respondSuccess(c, http.StatusOK, report, "Trash purged")
+	}
+}
+
+func restoreTrashedVideo(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		video, err := rm.RestoreVideo(c.Param("videoId"))
+		if err != nil {
+			respondError(c, http.StatusConflict, err.Error())
+			return
+		}
+		respondSuccess(c, http.StatusOK, video, "Video restored")
+	}
+}
+
+func purgeTrashedVideo(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report, err := rm.PurgeTrash([]string{c.Param("videoId")}, false)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "Failed to purge video")
+			return
+		}
+		if len(report.PurgedVideoIDs) == 0 {
+			respondError(c, http.StatusNotFound, "Video not found in the trash")
+			return
+		}
+		respondSuccess(c, http.StatusOK, report, "Video purged")
+	}
+}
@@ -43,6 +43,11 @@ func getVideosByFolder(repoMgr *repo.RepoManager) gin.HandlerFunc {
 		folderQuery := c.Query("folder")
 		requestedPath := filepath.ToSlash(strings.Trim(folderQuery, "/"))
 
+		if repoMgr.IsSpaceArchived(requestedPath) {
+			respondError(c, http.StatusForbidden, "Space is archived")
+			return
+		}
+
 		videosInFolder, err := repoMgr.GetIndxedVideosOnSpace(requestedPath)
 		if err != nil {
 			respondError(c, http.StatusInternalServerError, "Failed to load videos")
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+
+	"ova-cli/source/internal/repo"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterVideoAdminRoutes registers the routes that move and delete videos. The
+// group is expected to be protected by AdminMiddleware.
+func RegisterVideoAdminRoutes(rg *gin.RouterGroup, rm *repo.RepoManager) {
+	videos := rg.Group("/videos")
+	{
+		videos.POST("/:videoId/move", moveAdminVideo(rm))
+		videos.DELETE("/:videoId", deleteAdminVideo(rm))
+	}
+}
+
+func moveAdminVideo(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Destination string `json:"destination"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil || body.Destination == "" {
+			respondError(c, http.StatusBadRequest, "Invalid or missing destination")
+			return
+		}
+
+		videoID := c.Param("videoId")
+		if _, err := rm.GetVideoByID(videoID); err != nil {
+			respondError(c, http.StatusNotFound, "Video not found")
+			return
+		}
+
+		video, err := rm.MoveVideo(videoID, body.Destination)
+		if err != nil {
+			respondError(c, http.StatusConflict, err.Error())
+			return
+		}
+		respondSuccess(c, http.StatusOK, video, "Video moved")
+	}
+}
+
+// deleteAdminVideo moves a video to the trash, from where it can be restored.
+func deleteAdminVideo(rm *repo.RepoManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		videoID := c.Param("videoId")
+		if _, err := rm.GetVideoByID(videoID); err != nil {
+			respondError(c, http.StatusNotFound, "Video not found")
+			return
+		}
+
+		entry, err := rm.TrashVideo(videoID, c.GetString("username"))
+		if err != nil {
+			respondError(c, http.StatusConflict, err.Error())
+			return
+		}
+		respondSuccess(c, http.StatusOK, entry, "Video moved to the trash")
+	}
+}
@@ -1,15 +1,23 @@
 package jsondb
 
 import (
+	"errors"
 	"fmt"
 	"ova-cli/source/internal/datatypes"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
+// errSpaceNotFound is returned when a video path points into a folder that is not a space.
+var errSpaceNotFound = errors.New("space not found")
+
 // CreateSpace adds a new space if a space with the same name does not already exist.
 // Returns an error if a space with the provided name already exists.
 func (s *JsonDB) CreateSpace(space *datatypes.SpaceData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	// Load existing spaces
 	spaces, err := s.loadSpaces()
 	if err != nil {
@@ -29,6 +37,9 @@ func (s *JsonDB) CreateSpace(space *datatypes.SpaceData) error {
 }
 
 func (s *JsonDB) DeleteSpace(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	// Load existing spaces
 	spaces, err := s.loadSpaces()
 	if err != nil {
@@ -48,6 +59,9 @@ func (s *JsonDB) DeleteSpace(name string) error {
 }
 
 func (s *JsonDB) UpdateSpace(name string, updatedSpace *datatypes.SpaceData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	// Load existing spaces
 	spaces, err := s.loadSpaces()
 	if err != nil {
@@ -67,6 +81,9 @@ func (s *JsonDB) UpdateSpace(name string, updatedSpace *datatypes.SpaceData) err
 }
 
 func (s *JsonDB) GetAllSpaces() (map[string]datatypes.SpaceData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	// Load existing spaces
 	spaces, err := s.loadSpaces()
 	if err != nil {
@@ -75,7 +92,82 @@ func (s *JsonDB) GetAllSpaces() (map[string]datatypes.SpaceData, error) {
 	return spaces, nil
 }
 
+// GetSpaceByID finds a space by its stable SpaceId.
+// Returns a pointer to a copy of SpaceData if found, or an error if no space has that ID.
+func (s *JsonDB) GetSpaceByID(spaceID string) (*datatypes.SpaceData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	spaces, err := s.loadSpaces()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load spaces: %w", err)
+	}
+
+	for _, space := range spaces {
+		if space.SpaceId == spaceID {
+			return &space, nil
+		}
+	}
+	return nil, fmt.Errorf("space with ID %q not found", spaceID)
+}
+
+// RenameSpace moves a space from oldName to newName and rewrites the OwnedSpace
+// field of every video that belonged to it. If the videos cannot be saved the
+// space file is restored so both files stay consistent.
+func (s *JsonDB) RenameSpace(oldName, newName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	spaces, err := s.loadSpaces()
+	if err != nil {
+		return fmt.Errorf("failed to load spaces: %w", err)
+	}
+
+	space, exists := spaces[oldName]
+	if !exists {
+		return fmt.Errorf("space with name %q does not exist", oldName)
+	}
+	if _, taken := spaces[newName]; taken {
+		return fmt.Errorf("space with name %q already exists", newName)
+	}
+
+	videos, err := s.loadVideos()
+	if err != nil {
+		return fmt.Errorf("failed to load videos: %w", err)
+	}
+
+	// Keep an untouched copy so the rename can be rolled back
+	original := make(map[string]datatypes.SpaceData, len(spaces))
+	for name, sp := range spaces {
+		original[name] = sp
+	}
+
+	space.SpaceName = newName
+	delete(spaces, oldName)
+	spaces[newName] = space
+	if err := s.saveSpaces(spaces); err != nil {
+		return fmt.Errorf("failed to save spaces: %w", err)
+	}
+
+	for id, video := range videos {
+		if strings.Trim(filepath.ToSlash(video.OwnedSpace), "/") == oldName {
+			video.OwnedSpace = newName
+			videos[id] = video
+		}
+	}
+	if err := s.saveVideos(videos); err != nil {
+		if rbErr := s.saveSpaces(original); rbErr != nil {
+			return fmt.Errorf("failed to save videos: %v (rollback failed: %v)", err, rbErr)
+		}
+		return fmt.Errorf("failed to save videos: %w", err)
+	}
+
+	return nil
+}
+
 func (s *JsonDB) AddVideoIDToSpace(videoId, filePath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
 	// 1. Load all spaces
 	spaces, err := s.loadSpaces()
@@ -83,6 +175,15 @@ func (s *JsonDB) AddVideoIDToSpace(videoId, filePath string) error {
 		return err
 	}
 
+	if err := addVideoIDToSpaces(spaces, videoId, filePath); err != nil {
+		return err
+	}
+	return s.saveSpaces(spaces)
+}
+
+// addVideoIDToSpaces adds videoId to the group of spaces that matches filePath,
+// creating missing groups on the way. Nothing is saved.
+func addVideoIDToSpaces(spaces map[string]datatypes.SpaceData, videoId, filePath string) error {
 	// 2. Normalize path separators
 	filePath = filepath.ToSlash(filePath)
 
@@ -107,7 +208,7 @@ func (s *JsonDB) AddVideoIDToSpace(videoId, filePath string) error {
 	// 3. Find the target space
 	space, ok := spaces[spaceName]
 	if !ok {
-		return fmt.Errorf("space not found: %s", spaceName)
+		return fmt.Errorf("%w: %s", errSpaceNotFound, spaceName)
 	}
 
 	// 4. Find the root group. Every space should have one.
@@ -145,11 +246,83 @@ func (s *JsonDB) AddVideoIDToSpace(videoId, filePath string) error {
 	}
 	targetGroup.VideoIds = append(targetGroup.VideoIds, videoId)
 
-	// 7. Update the map and save the data
+	// 7. Update the map
 	spaces[spaceName] = space
+	return nil
+}
+
+// removeVideoIDFromGroups removes videoId from groups and all their subgroups.
+func removeVideoIDFromGroups(groups []datatypes.SpaceGroup, videoId string) {
+	for i := range groups {
+		groups[i].VideoIds = slices.DeleteFunc(groups[i].VideoIds, func(id string) bool { return id == videoId })
+		removeVideoIDFromGroups(groups[i].Groups, videoId)
+	}
+}
+
+// RemoveVideoIDFromSpaces removes a video ID from every space group it belongs to.
+func (s *JsonDB) RemoveVideoIDFromSpaces(videoId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	spaces, err := s.loadSpaces()
+	if err != nil {
+		return fmt.Errorf("failed to load spaces: %w", err)
+	}
+	for name, space := range spaces {
+		removeVideoIDFromGroups(space.Groups, videoId)
+		spaces[name] = space
+	}
 	return s.saveSpaces(spaces)
 }
 
+// UpdateVideoLocation saves a video whose file was moved and moves its ID to the
+// space group matching filePath, the new path relative to the repository root.
+// Destinations outside a registered space leave the video without a group, as
+// indexing does. If the video cannot be saved the space file is restored.
+func (s *JsonDB) UpdateVideoLocation(video datatypes.VideoData, filePath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	videos, err := s.loadVideos()
+	if err != nil {
+		return fmt.Errorf("failed to load videos: %w", err)
+	}
+	if _, exists := videos[video.VideoID]; !exists {
+		return fmt.Errorf("video %q not found", video.VideoID)
+	}
+
+	// Loaded twice so the first copy stays untouched for the rollback
+	original, err := s.loadSpaces()
+	if err != nil {
+		return fmt.Errorf("failed to load spaces: %w", err)
+	}
+	spaces, err := s.loadSpaces()
+	if err != nil {
+		return fmt.Errorf("failed to load spaces: %w", err)
+	}
+
+	for name, space := range spaces {
+		removeVideoIDFromGroups(space.Groups, video.VideoID)
+		spaces[name] = space
+	}
+	if err := addVideoIDToSpaces(spaces, video.VideoID, filePath); err != nil && !errors.Is(err, errSpaceNotFound) {
+		return err
+	}
+	if err := s.saveSpaces(spaces); err != nil {
+		return fmt.Errorf("failed to save spaces: %w", err)
+	}
+
+	videos[video.VideoID] = video
+	if err := s.saveVideos(videos); err != nil {
+		if rbErr := s.saveSpaces(original); rbErr != nil {
+			return fmt.Errorf("failed to save videos: %v (rollback failed: %v)", err, rbErr)
+		}
+		return fmt.Errorf("failed to save videos: %w", err)
+	}
+
+	return nil
+}
+
 func findOrCreateGroup(groups *[]datatypes.SpaceGroup, pathParts []string) *datatypes.SpaceGroup {
 	if len(pathParts) == 0 {
 		return nil
@@ -88,3 +88,65 @@ func (s *JsonDB) GetAllUsers() ([]datatypes.UserData, error) {
 	}
 	return users, nil
 }
+
+// RemoveVideoFromAllUsers drops every reference to videoID from all users:
+// favorites, watched history, playback progress and playlists. It is used when
+// a video is removed from the repository so no dangling IDs are left behind.
+func (s *JsonDB) RemoveVideoFromAllUsers(videoID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users, err := s.loadUsers()
+	if err != nil {
+		return fmt.Errorf("failed to load users: %w", err)
+	}
+
+	changed := false
+	for username, user := range users {
+		userChanged := false
+
+		if filtered, removed := removeID(user.Favorites, videoID); removed {
+			user.Favorites = filtered
+			userChanged = true
+		}
+		if filtered, removed := removeID(user.Watched, videoID); removed {
+			user.Watched = filtered
+			userChanged = true
+		}
+		if _, exists := user.WatchProgress[videoID]; exists {
+			delete(user.WatchProgress, videoID)
+			userChanged = true
+		}
+		for i := range user.Playlists {
+			if filtered, removed := removeID(user.Playlists[i].VideoIDs, videoID); removed {
+				user.Playlists[i].VideoIDs = filtered
+				userChanged = true
+			}
+		}
+
+		if userChanged {
+			users[username] = user
+			changed = true
+		}
+	}
+
+	// Only save if something was actually removed to prevent unnecessary disk writes.
+	if !changed {
+		return nil
+	}
+	return s.saveUsers(users)
+}
+
+// removeID returns ids without any occurrence of target and whether anything was removed.
+func removeID(ids []string, target string) ([]string, bool) {
+	filtered := make([]string, 0, len(ids))
+	removed := false
+	for _, id := range ids {
+		if id == target {
+			removed = true
+			continue
+		}
+		filtered = append(filtered, id)
+	}
+	return filtered, removed
+}
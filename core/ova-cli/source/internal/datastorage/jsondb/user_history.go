@@ -2,6 +2,7 @@ package jsondb
 
 import (
 	"fmt"
+	"ova-cli/source/internal/datatypes"
 )
 
 func (s *JsonDB) AddVideoToWatched(username, videoID string) error {
@@ -78,8 +79,110 @@ func (s *JsonDB) ClearUserWatchedHistory(username string) error {
 
 	// Clear the watched list by re-initializing it as an empty slice
 	user.Watched = []string{} // Or make([]string, 0)
+	user.WatchProgress = map[string]datatypes.WatchProgress{}
 
 	users[username] = user // Update the user map with the modified user data
 
 	return s.saveUsers(users) // Save the updated users data back to storage
 }
+
+// SaveWatchProgress stores the playback progress of a video for a user.
+// A completed video is also added to the user's watched list.
+func (s *JsonDB) SaveWatchProgress(username string, progress datatypes.WatchProgress) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users, err := s.loadUsers()
+	if err != nil {
+		return fmt.Errorf("failed to load users: %w", err)
+	}
+
+	user, exists := users[username]
+	if !exists {
+		return fmt.Errorf("user %q not found", username)
+	}
+
+	if user.WatchProgress == nil {
+		user.WatchProgress = map[string]datatypes.WatchProgress{}
+	}
+	user.WatchProgress[progress.VideoID] = progress
+
+	if progress.Completed {
+		if _, found := removeID(user.Watched, progress.VideoID); !found {
+			user.Watched = append(user.Watched, progress.VideoID)
+		}
+	}
+
+	users[username] = user
+
+	return s.saveUsers(users)
+}
+
+// GetWatchProgress returns the playback progress of a video for a user.
+func (s *JsonDB) GetWatchProgress(username, videoID string) (*datatypes.WatchProgress, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users, err := s.loadUsers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load users: %w", err)
+	}
+
+	user, exists := users[username]
+	if !exists {
+		return nil, fmt.Errorf("user %q not found", username)
+	}
+
+	progress, exists := user.WatchProgress[videoID]
+	if !exists {
+		return nil, fmt.Errorf("no progress for video %q", videoID)
+	}
+	return &progress, nil
+}
+
+// GetUserWatchProgress returns the playback progress of every video a user has played.
+func (s *JsonDB) GetUserWatchProgress(username string) ([]datatypes.WatchProgress, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users, err := s.loadUsers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load users: %w", err)
+	}
+
+	user, exists := users[username]
+	if !exists {
+		return nil, fmt.Errorf("user %q not found", username)
+	}
+
+	progress := make([]datatypes.WatchProgress, 0, len(user.WatchProgress))
+	for _, p := range user.WatchProgress {
+		progress = append(progress, p)
+	}
+	return progress, nil
+}
+
+// DeleteWatchProgress forgets the playback progress of a video for a user.
+func (s *JsonDB) DeleteWatchProgress(username, videoID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users, err := s.loadUsers()
+	if err != nil {
+		return fmt.Errorf("failed to load users: %w", err)
+	}
+
+	user, exists := users[username]
+	if !exists {
+		return fmt.Errorf("user %q not found", username)
+	}
+
+	if _, exists := user.WatchProgress[videoID]; !exists {
+		return fmt.Errorf("no progress for video %q", videoID)
+	}
+	delete(user.WatchProgress, videoID)
+
+	users[username] = user
+
+	return s.saveUsers(users)
+}
@@ -105,7 +105,8 @@ func (s *JsonDB) DeleteUserPlaylist(username, slug string) error {
 }
 
 // AddVideoToPlaylist adds a video ID to a specific playlist of a user.
-// Returns an error if the user, playlist, or video (in global storage) is not found.
+// Returns an error if the user or playlist is not found. The caller checks that the
+// video exists, since it may belong to an attached repository.
 // Returns nil if the video is already in the playlist.
 func (s *JsonDB) AddVideoToPlaylist(username, slug, videoID string) error {
 	s.mu.Lock()
@@ -121,15 +122,6 @@ func (s *JsonDB) AddVideoToPlaylist(username, slug, videoID string) error {
 		return fmt.Errorf("user %q not found", username)
 	}
 
-	// Check if video exists in main video storage before adding to playlist
-	videos, err := s.loadVideos()
-	if err != nil {
-		return fmt.Errorf("failed to load videos to check existence: %w", err)
-	}
-	if _, videoExists := videos[videoID]; !videoExists {
-		return fmt.Errorf("video %q not found in video storage", videoID)
-	}
-
 	foundPlaylistIndex := -1
 	for i := range user.Playlists {
 		if user.Playlists[i].Slug == slug {
@@ -142,6 +134,10 @@ func (s *JsonDB) AddVideoToPlaylist(username, slug, videoID string) error {
 		return fmt.Errorf("playlist with slug %q not found for user %q", slug, username)
 	}
 
+	if user.Playlists[foundPlaylistIndex].IsSmart() {
+		return fmt.Errorf("playlist %q is a smart playlist; its videos come from its rules", slug)
+	}
+
 	// Check if video already exists in the playlist
 	for _, vid := range user.Playlists[foundPlaylistIndex].VideoIDs {
 		if vid == videoID {
@@ -150,6 +146,7 @@ func (s *JsonDB) AddVideoToPlaylist(username, slug, videoID string) error {
 	}
 
 	user.Playlists[foundPlaylistIndex].VideoIDs = append(user.Playlists[foundPlaylistIndex].VideoIDs, videoID)
+	user.Playlists[foundPlaylistIndex].Revision++
 	users[username] = user // Update the map with the modified user struct
 	return s.saveUsers(users)
 }
@@ -197,6 +194,7 @@ func (s *JsonDB) RemoveVideoFromPlaylist(username, slug, videoID string) error {
 	}
 
 	user.Playlists[foundPlaylistIndex].VideoIDs = newVideos
+	user.Playlists[foundPlaylistIndex].Revision++
 	users[username] = user // Update the map with the modified user struct
 	return s.saveUsers(users)
 }
@@ -281,6 +279,38 @@ func (s *JsonDB) UpdatePlaylistInfo(username, playlistSlug, newTitle, newDescrip
 	return s.saveUsers(users)
 }
 
+// UpdatePlaylistRules replaces the rules of a user's smart playlist.
+// Returns an error if the user or playlist is not found, or the playlist is not a smart playlist.
+func (s *JsonDB) UpdatePlaylistRules(username, slug string, rules *datatypes.SmartPlaylistRules) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users, err := s.loadUsers()
+	if err != nil {
+		return fmt.Errorf("failed to load users: %w", err)
+	}
+
+	user, exists := users[username]
+	if !exists {
+		return fmt.Errorf("user %q not found", username)
+	}
+
+	for i := range user.Playlists {
+		if user.Playlists[i].Slug != slug {
+			continue
+		}
+		if !user.Playlists[i].IsSmart() {
+			return fmt.Errorf("playlist %q is not a smart playlist", slug)
+		}
+
+		user.Playlists[i].Rules = rules
+		users[username] = user
+		return s.saveUsers(users)
+	}
+
+	return fmt.Errorf("playlist with slug %q not found for user %q", slug, username)
+}
+
 func (s *JsonDB) GetUserPlaylistContentVideosCount(username, playlistSlug string) (int, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
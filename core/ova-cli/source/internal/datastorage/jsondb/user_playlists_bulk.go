@@ -0,0 +1,192 @@
+package jsondb
+
+import (
+	"fmt"
+	"ova-cli/source/internal/datatypes"
+	"slices"
+)
+
+// --- Playlist Bulk Editing ---
+//
+// Every operation below loads, edits and saves the users file under one lock,
+// so it either applies completely or not at all.
+
+// InsertVideosIntoPlaylist inserts videoIDs at index in a playlist, keeping their order.
+// IDs already in the playlist or repeated in videoIDs are skipped. A negative index or
+// one past the end appends. The caller checks that the videos exist.
+// Returns the number of videos inserted.
+func (s *JsonDB) InsertVideosIntoPlaylist(username, slug string, videoIDs []string, index int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users, err := s.loadUsers()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load users: %w", err)
+	}
+
+	pl, err := findEditablePlaylist(users, username, slug)
+	if err != nil {
+		return 0, err
+	}
+
+	inserted := insertPlaylistVideos(pl, videoIDs, index)
+	if inserted == 0 {
+		return 0, nil
+	}
+	return inserted, s.saveUsers(users)
+}
+
+// RemoveVideosFromPlaylist removes every given video from a playlist.
+// IDs that are not in the playlist are ignored. Returns the number of videos removed.
+func (s *JsonDB) RemoveVideosFromPlaylist(username, slug string, videoIDs []string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users, err := s.loadUsers()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load users: %w", err)
+	}
+
+	pl, err := findEditablePlaylist(users, username, slug)
+	if err != nil {
+		return 0, err
+	}
+
+	before := len(pl.VideoIDs)
+	pl.VideoIDs = slices.DeleteFunc(pl.VideoIDs, func(id string) bool {
+		return slices.Contains(videoIDs, id)
+	})
+	removed := before - len(pl.VideoIDs)
+	if removed == 0 {
+		return 0, nil
+	}
+
+	pl.Revision++
+	return removed, s.saveUsers(users)
+}
+
+// DeduplicatePlaylist removes repeated videos from a playlist, keeping the first occurrence.
+// Returns the number of entries removed.
+func (s *JsonDB) DeduplicatePlaylist(username, slug string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users, err := s.loadUsers()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load users: %w", err)
+	}
+
+	pl, err := findEditablePlaylist(users, username, slug)
+	if err != nil {
+		return 0, err
+	}
+
+	seen := make(map[string]bool, len(pl.VideoIDs))
+	before := len(pl.VideoIDs)
+	pl.VideoIDs = slices.DeleteFunc(pl.VideoIDs, func(id string) bool {
+		if seen[id] {
+			return true
+		}
+		seen[id] = true
+		return false
+	})
+	removed := before - len(pl.VideoIDs)
+	if removed == 0 {
+		return 0, nil
+	}
+
+	pl.Revision++
+	return removed, s.saveUsers(users)
+}
+
+// CopyPlaylistVideos copies videos from one playlist into another at index; the playlists
+// may belong to different users. A nil videoIDs copies the whole source playlist. Every
+// requested ID must be in the source. Returns the number of videos inserted.
+func (s *JsonDB) CopyPlaylistVideos(srcOwner, srcSlug, dstOwner, dstSlug string, videoIDs []string, index int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users, err := s.loadUsers()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load users: %w", err)
+	}
+
+	src, err := findPlaylist(users, srcOwner, srcSlug)
+	if err != nil {
+		return 0, err
+	}
+	if src.IsSmart() {
+		return 0, fmt.Errorf("playlist %q is a smart playlist; copy its videos by ID instead", srcSlug)
+	}
+
+	if videoIDs == nil {
+		videoIDs = slices.Clone(src.VideoIDs)
+	}
+	for _, videoID := range videoIDs {
+		if !slices.Contains(src.VideoIDs, videoID) {
+			return 0, fmt.Errorf("video %q not found in playlist %q for user %q", videoID, srcSlug, srcOwner)
+		}
+	}
+
+	dst, err := findEditablePlaylist(users, dstOwner, dstSlug)
+	if err != nil {
+		return 0, err
+	}
+
+	inserted := insertPlaylistVideos(dst, videoIDs, index)
+	if inserted == 0 {
+		return 0, nil
+	}
+	return inserted, s.saveUsers(users)
+}
+
+// findPlaylist returns a pointer into users to a user's playlist.
+// The playlists slice shares its backing array with the map entry, so edits through the pointer are kept.
+func findPlaylist(users map[string]datatypes.UserData, username, slug string) (*datatypes.PlaylistData, error) {
+	user, exists := users[username]
+	if !exists {
+		return nil, fmt.Errorf("user %q not found", username)
+	}
+
+	idx := slices.IndexFunc(user.Playlists, func(pl datatypes.PlaylistData) bool {
+		return pl.Slug == slug
+	})
+	if idx < 0 {
+		return nil, fmt.Errorf("playlist with slug %q not found for user %q", slug, username)
+	}
+
+	return &user.Playlists[idx], nil
+}
+
+// findEditablePlaylist is findPlaylist for playlists whose videos are edited directly,
+// which smart playlists are not.
+func findEditablePlaylist(users map[string]datatypes.UserData, username, slug string) (*datatypes.PlaylistData, error) {
+	pl, err := findPlaylist(users, username, slug)
+	if err != nil {
+		return nil, err
+	}
+	if pl.IsSmart() {
+		return nil, fmt.Errorf("playlist %q is a smart playlist; its videos come from its rules", slug)
+	}
+	return pl, nil
+}
+
+// insertPlaylistVideos inserts the IDs not yet in pl at index and returns how many were inserted.
+func insertPlaylistVideos(pl *datatypes.PlaylistData, videoIDs []string, index int) int {
+	toInsert := make([]string, 0, len(videoIDs))
+	for _, videoID := range videoIDs {
+		if !slices.Contains(pl.VideoIDs, videoID) && !slices.Contains(toInsert, videoID) {
+			toInsert = append(toInsert, videoID)
+		}
+	}
+	if len(toInsert) == 0 {
+		return 0
+	}
+
+	if index < 0 || index > len(pl.VideoIDs) {
+		index = len(pl.VideoIDs)
+	}
+	pl.VideoIDs = slices.Insert(pl.VideoIDs, index, toInsert...)
+	pl.Revision++
+	return len(toInsert)
+}
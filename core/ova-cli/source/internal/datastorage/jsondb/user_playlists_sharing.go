@@ -0,0 +1,147 @@
+package jsondb
+
+import (
+	"fmt"
+	"ova-cli/source/internal/datatypes"
+	"slices"
+)
+
+// --- Playlist Sharing ---
+
+// SetPlaylistCollaborator grants a user a permission on a playlist, replacing any previous grant.
+func (s *JsonDB) SetPlaylistCollaborator(owner, slug, collaborator, permission string) error {
+	return s.updatePlaylist(owner, slug, func(pl *datatypes.PlaylistData) error {
+		for i := range pl.Collaborators {
+			if pl.Collaborators[i].Username == collaborator {
+				pl.Collaborators[i].Permission = permission
+				return nil
+			}
+		}
+		pl.Collaborators = append(pl.Collaborators, datatypes.PlaylistCollaborator{
+			Username:   collaborator,
+			Permission: permission,
+		})
+		return nil
+	})
+}
+
+// RemovePlaylistCollaborator revokes a user's access to a playlist.
+func (s *JsonDB) RemovePlaylistCollaborator(owner, slug, collaborator string) error {
+	return s.updatePlaylist(owner, slug, func(pl *datatypes.PlaylistData) error {
+		idx := slices.IndexFunc(pl.Collaborators, func(c datatypes.PlaylistCollaborator) bool {
+			return c.Username == collaborator
+		})
+		if idx < 0 {
+			return fmt.Errorf("user %q is not a collaborator of playlist %q", collaborator, slug)
+		}
+		pl.Collaborators = slices.Delete(pl.Collaborators, idx, idx+1)
+		return nil
+	})
+}
+
+// SetPlaylistSpaceShare grants the members of a space a permission on a playlist, replacing any previous grant.
+func (s *JsonDB) SetPlaylistSpaceShare(owner, slug, spaceID, permission string) error {
+	return s.updatePlaylist(owner, slug, func(pl *datatypes.PlaylistData) error {
+		for i := range pl.SharedSpaces {
+			if pl.SharedSpaces[i].SpaceID == spaceID {
+				pl.SharedSpaces[i].Permission = permission
+				return nil
+			}
+		}
+		pl.SharedSpaces = append(pl.SharedSpaces, datatypes.PlaylistSpaceShare{
+			SpaceID:    spaceID,
+			Permission: permission,
+		})
+		return nil
+	})
+}
+
+// RemovePlaylistSpaceShare revokes the access of a space's members to a playlist.
+func (s *JsonDB) RemovePlaylistSpaceShare(owner, slug, spaceID string) error {
+	return s.updatePlaylist(owner, slug, func(pl *datatypes.PlaylistData) error {
+		idx := slices.IndexFunc(pl.SharedSpaces, func(share datatypes.PlaylistSpaceShare) bool {
+			return share.SpaceID == spaceID
+		})
+		if idx < 0 {
+			return fmt.Errorf("playlist %q is not shared with space %q", slug, spaceID)
+		}
+		pl.SharedSpaces = slices.Delete(pl.SharedSpaces, idx, idx+1)
+		return nil
+	})
+}
+
+// MovePlaylistVideo moves a video to index within a playlist. The move is expressed by
+// video ID rather than by position, so concurrent moves, adds and removes by different
+// collaborators never overwrite each other. An index past the end moves the video last.
+// With a revision the move fails with datatypes.ErrPlaylistRevisionConflict if the playlist
+// changed since, for clients that reorder from the order they last saw.
+func (s *JsonDB) MovePlaylistVideo(owner, slug, videoID string, index int, revision *int) error {
+	return s.updatePlaylist(owner, slug, func(pl *datatypes.PlaylistData) error {
+		if pl.IsSmart() {
+			return fmt.Errorf("playlist %q is a smart playlist; its videos come from its rules", slug)
+		}
+		if revision != nil && pl.Revision != *revision {
+			return datatypes.ErrPlaylistRevisionConflict
+		}
+
+		from := slices.Index(pl.VideoIDs, videoID)
+		if from < 0 {
+			return fmt.Errorf("video %q not found in playlist %q for user %q", videoID, slug, owner)
+		}
+
+		ids := slices.Delete(pl.VideoIDs, from, from+1)
+		index = max(0, min(index, len(ids)))
+		pl.VideoIDs = slices.Insert(ids, index, videoID)
+		if index != from {
+			pl.Revision++
+		}
+		return nil
+	})
+}
+
+// GetAllPlaylists returns the playlists of every user keyed by username.
+func (s *JsonDB) GetAllPlaylists() (map[string][]datatypes.PlaylistData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users, err := s.loadUsers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load users: %w", err)
+	}
+
+	playlists := make(map[string][]datatypes.PlaylistData, len(users))
+	for username, user := range users {
+		playlists[username] = user.Playlists
+	}
+	return playlists, nil
+}
+
+// updatePlaylist applies fn to a user's playlist and saves the result, all under the storage lock.
+func (s *JsonDB) updatePlaylist(username, slug string, fn func(pl *datatypes.PlaylistData) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users, err := s.loadUsers()
+	if err != nil {
+		return fmt.Errorf("failed to load users: %w", err)
+	}
+
+	user, exists := users[username]
+	if !exists {
+		return fmt.Errorf("user %q not found", username)
+	}
+
+	idx := slices.IndexFunc(user.Playlists, func(pl datatypes.PlaylistData) bool {
+		return pl.Slug == slug
+	})
+	if idx < 0 {
+		return fmt.Errorf("playlist with slug %q not found for user %q", slug, username)
+	}
+
+	if err := fn(&user.Playlists[idx]); err != nil {
+		return err
+	}
+
+	users[username] = user
+	return s.saveUsers(users)
+}
@@ -2,6 +2,7 @@ package memorydb
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"sync"
 
@@ -10,15 +11,17 @@ import (
 )
 
 // MemoryDB implements the MemoryDataStorage interface using Go's built-in types.
-// It stores video data in memory, optimized for retrieval by upload date.
+// It stores video data in memory, kept sorted by every key of datatypes.VideoSortKeys
+// so range queries in any order need no sorting.
 type MemoryDB struct {
 	// videosMap provides quick lookup of VideoData by VideoID.
-	// This is useful if you need to retrieve a full VideoData struct by its ID.
+	// The sorted lists compare videos through it.
 	videosMap map[string]datatypes.VideoData
-	// sortedVideos stores all VideoData entries, kept sorted by UploadedAt
-	// in descending order (most recent first). This enables efficient range queries.
-	sortedVideos []datatypes.VideoData
-	// mu protects concurrent access to videosMap and sortedVideos.
+	// sortedIDs holds the cached video IDs for every sort key, in ascending order of that
+	// key. Videos that compare equal are ordered by ID, which gives every video a single
+	// position that binary search finds again when it is updated or removed.
+	sortedIDs map[string][]string
+	// mu protects concurrent access to videosMap and sortedIDs.
 	// A RWMutex allows multiple readers or a single writer.
 	mu sync.RWMutex
 }
@@ -26,39 +29,95 @@ type MemoryDB struct {
 // NewMemoryDB initializes the in-memory database.
 // It returns a pointer to a new MemoryDB instance or an error if initialization fails.
 func NewMemoryDB() (*MemoryDB, error) {
-	return &MemoryDB{
-		videosMap:    make(map[string]datatypes.VideoData),
-		sortedVideos: make([]datatypes.VideoData, 0),
-	}, nil
+	m := &MemoryDB{}
+	m.reset(0)
+	return m, nil
 }
 
 // Ensure MemoryDB implements the MemoryDataStorage interface at compile time.
 // This line will cause a compile-time error if MemoryDB does not satisfy the interface.
 var _ interfaces.MemoryDataStorage = (*MemoryDB)(nil)
 
-// GetAllCachedVideoIds returns a slice of all video IDs currently cached in memory.
+// reset empties the cache, reserving room for size videos. The caller holds the write lock.
+func (m *MemoryDB) reset(size int) {
+	m.videosMap = make(map[string]datatypes.VideoData, size)
+	m.sortedIDs = make(map[string][]string, len(datatypes.VideoSortKeys))
+	for _, key := range datatypes.VideoSortKeys {
+		m.sortedIDs[key] = make([]string, 0, size)
+	}
+}
+
+// less orders two videos by key, falling back to their IDs so no two videos compare equal.
+func less(key string, a, b datatypes.VideoData) bool {
+	if datatypes.VideoSortLess(key, a, b) {
+		return true
+	}
+	if datatypes.VideoSortLess(key, b, a) {
+		return false
+	}
+	return a.VideoID < b.VideoID
+}
+
+// position returns where video belongs in the list of key. The caller holds a lock.
+func (m *MemoryDB) position(key string, video datatypes.VideoData) int {
+	ids := m.sortedIDs[key]
+	return sort.Search(len(ids), func(i int) bool {
+		return !less(key, m.videosMap[ids[i]], video)
+	})
+}
+
+// insert adds a video that is not cached yet. The caller holds the write lock.
+func (m *MemoryDB) insert(video datatypes.VideoData) {
+	m.videosMap[video.VideoID] = video
+	for _, key := range datatypes.VideoSortKeys {
+		m.sortedIDs[key] = slices.Insert(m.sortedIDs[key], m.position(key, video), video.VideoID)
+	}
+}
+
+// remove drops a cached video. The caller holds the write lock.
+func (m *MemoryDB) remove(videoID string) {
+	video, ok := m.videosMap[videoID]
+	if !ok {
+		return
+	}
+	for _, key := range datatypes.VideoSortKeys {
+		ids := m.sortedIDs[key]
+		i := m.position(key, video)
+		if i >= len(ids) || ids[i] != videoID {
+			// Only a list that lost its order gets here; fall back to a linear search
+			i = slices.Index(ids, videoID)
+		}
+		if i >= 0 {
+			m.sortedIDs[key] = slices.Delete(ids, i, i+1)
+		}
+	}
+	delete(m.videosMap, videoID)
+}
+
+// GetAllCachedVideoIds returns a slice of all video IDs currently cached in memory,
+// from the most recent upload to the oldest.
 // It acquires a read lock to ensure thread safety.
 func (m *MemoryDB) GetAllCachedVideoIds() ([]string, error) {
-	m.mu.RLock() // Acquire a read lock
+	m.mu.RLock()         // Acquire a read lock
 	defer m.mu.RUnlock() // Ensure the read lock is released when the function exits
 
-	// Iterate over the sortedVideos slice to get IDs.
-	// This slice contains all cached videos and is already available.
-	ids := make([]string, 0, len(m.sortedVideos)) // Pre-allocate capacity for efficiency
-	for _, video := range m.sortedVideos {
-		ids = append(ids, video.VideoID)
-	}
+	ids := slices.Clone(m.sortedIDs[datatypes.SmartSortUploadedAt])
+	slices.Reverse(ids)
 	return ids, nil
 }
 
-// GetSortedVideosInRange returns video IDs within a specified range (start, end),
-// based on their upload date. The videos are sorted from most recent to oldest.
+// GetSortedVideosInRange returns video IDs within a specified range (start, end) of the
+// videos sorted by sortBy, from the newest/largest to the oldest/smallest unless ascending.
 // It acquires a read lock to ensure thread safety.
-func (m *MemoryDB) GetSortedVideosInRange(start, end int) ([]string, error) {
-	m.mu.RLock() // Acquire a read lock
+func (m *MemoryDB) GetSortedVideosInRange(sortBy string, ascending bool, start, end int) ([]string, error) {
+	m.mu.RLock()         // Acquire a read lock
 	defer m.mu.RUnlock() // Ensure the read lock is released
 
-	total := len(m.sortedVideos)
+	ids, ok := m.sortedIDs[sortBy]
+	if !ok {
+		return nil, fmt.Errorf("videos are not cached by %q", sortBy)
+	}
+	total := len(ids)
 
 	// Validate input range.
 	if start < 0 || start >= total {
@@ -73,11 +132,13 @@ func (m *MemoryDB) GetSortedVideosInRange(start, end int) ([]string, error) {
 	}
 
 	// Extract video IDs from the relevant portion of the sorted slice.
-	var resultIDs []string
-	// The capacity of the result slice is (end - start) for efficiency.
-	resultIDs = make([]string, 0, end-start)
+	resultIDs := make([]string, 0, end-start)
 	for i := start; i < end; i++ {
-		resultIDs = append(resultIDs, m.sortedVideos[i].VideoID)
+		if ascending {
+			resultIDs = append(resultIDs, ids[i])
+		} else {
+			resultIDs = append(resultIDs, ids[total-1-i])
+		}
 	}
 	return resultIDs, nil
 }
@@ -85,46 +146,76 @@ func (m *MemoryDB) GetSortedVideosInRange(start, end int) ([]string, error) {
 // GetTotalVideosCached returns the total count of videos currently cached in memory.
 // It acquires a read lock to ensure thread safety.
 func (m *MemoryDB) GetTotalVideosCached() (int, error) {
-	m.mu.RLock() // Acquire a read lock
+	m.mu.RLock()         // Acquire a read lock
 	defer m.mu.RUnlock() // Ensure the read lock is released
-	return len(m.sortedVideos), nil
+	return len(m.videosMap), nil
 }
 
 // ClearAll clears all video data from the memory storage.
 // It acquires a write lock to ensure exclusive access during the clear operation.
 func (m *MemoryDB) ClearAll() error {
-	m.mu.Lock() // Acquire a write lock
+	m.mu.Lock()         // Acquire a write lock
 	defer m.mu.Unlock() // Ensure the write lock is released
 
-	// Reinitialize the map and slice to clear all data.
-	m.videosMap = make(map[string]datatypes.VideoData)
-	m.sortedVideos = make([]datatypes.VideoData, 0)
+	m.reset(0)
 	return nil
 }
 
-// CacheVideosByUploadDate imports a slice of VideoData, clears any existing data,
-// and then populates and sorts the in-memory store by upload date (most recent first).
+// CacheVideos imports a slice of VideoData, clears any existing data, and then
+// populates the in-memory store and sorts it by every sort key.
 // It acquires a write lock to ensure exclusive access during the caching and sorting.
-func (m *MemoryDB) CacheVideosByUploadDate(videos []datatypes.VideoData) error {
-	m.mu.Lock() // Acquire a write lock
+func (m *MemoryDB) CacheVideos(videos []datatypes.VideoData) error {
+	m.mu.Lock()         // Acquire a write lock
 	defer m.mu.Unlock() // Ensure the write lock is released
 
 	// Clear existing data before caching new data to prevent duplicates or stale entries.
-	m.videosMap = make(map[string]datatypes.VideoData)
-	// Pre-allocate capacity for the sortedVideos slice for efficiency.
-	m.sortedVideos = make([]datatypes.VideoData, 0, len(videos))
-
-	// Populate the map and the slice with the new video data.
+	m.reset(len(videos))
 	for _, video := range videos {
-		m.videosMap[video.VideoID] = video       // Store in map for quick lookup by ID
-		m.sortedVideos = append(m.sortedVideos, video) // Add to slice for sorting
+		m.videosMap[video.VideoID] = video
 	}
 
-	// Sort the slice by UploadedAt in descending order (most recent first).
-	sort.Slice(m.sortedVideos, func(i, j int) bool {
-		return m.sortedVideos[i].UploadedAt.After(m.sortedVideos[j].UploadedAt)
-	})
+	for _, key := range datatypes.VideoSortKeys {
+		ids := m.sortedIDs[key]
+		for id := range m.videosMap {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool {
+			return less(key, m.videosMap[ids[i]], m.videosMap[ids[j]])
+		})
+		m.sortedIDs[key] = ids
+	}
+	return nil
+}
+
+// AddCachedVideo adds a video to the cache. A cached copy of the same video is replaced.
+func (m *MemoryDB) AddCachedVideo(video datatypes.VideoData) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
+	m.remove(video.VideoID)
+	m.insert(video)
 	return nil
 }
 
+// UpdateCachedVideo replaces the cached copy of a video and moves it to the position
+// its new data sorts to. Fails if the video is not cached.
+func (m *MemoryDB) UpdateCachedVideo(video datatypes.VideoData) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.videosMap[video.VideoID]; !ok {
+		return fmt.Errorf("video %s is not cached", video.VideoID)
+	}
+	m.remove(video.VideoID)
+	m.insert(video)
+	return nil
+}
+
+// RemoveCachedVideo removes a video from the cache. Videos that are not cached are ignored.
+func (m *MemoryDB) RemoveCachedVideo(videoID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(videoID)
+	return nil
+}
@@ -41,6 +41,19 @@ func (db *SessionDB) DeleteSession(sessionID string) error {
 	return nil
 }
 
+// DeleteUserSessions removes every session of a user and returns how many were removed.
+func (db *SessionDB) DeleteUserSessions(username string) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	removed := 0
+	for sessionID, user := range db.SessionIDs {
+		if user == username {
+			delete(db.SessionIDs, sessionID)
+			removed++
+		}
+	}
+	return removed, nil
+}
 
 // ClearAllSessions removes all sessions from the database.
 func (db *SessionDB) ClearAllSessions() error {
@@ -0,0 +1,32 @@
+package datatypes
+
+import "time"
+
+// Kinds of repository backups.
+const (
+	BackupFull        = "full"        // Holds every backed up file
+	BackupIncremental = "incremental" // Holds only the files changed since its base backup
+)
+
+// BackupFile is one file of the repository data folder at the time of a backup.
+type BackupFile struct {
+	Path string `json:"path"` // Slash-separated, relative to .ova-repo
+	Size int64  `json:"size"`
+	Hash string `json:"hash"` // XXH3-128 of the content
+}
+
+// BackupManifest describes a backup archive. It is the first entry of the archive.
+type BackupManifest struct {
+	FormatVersion  int            `json:"formatVersion"`
+	BackupID       string         `json:"backupId"`
+	Kind           string         `json:"kind"`                     // BackupFull or BackupIncremental
+	BaseBackupID   string         `json:"baseBackupId,omitempty"`   // The backup an incremental one builds on
+	RepoVersion    string         `json:"repoVersion"`              // Version recorded in the repository config
+	SchemaVersions map[string]int `json:"schemaVersions,omitempty"` // Schema version of every versioned file
+	CreatedAt      time.Time      `json:"createdAt"`
+	IncludesMedia  bool           `json:"includesMedia"` // Thumbnails, previews, preview thumbnails and audio variants
+	IncludesSSL    bool           `json:"includesSsl"`
+	Files          []BackupFile   `json:"files"`         // Every file of the repository at backup time
+	Archived       int            `json:"archived"`      // How many of Files are stored in this archive
+	ArchivedBytes  int64          `json:"archivedBytes"` // Their total size before compression
+}
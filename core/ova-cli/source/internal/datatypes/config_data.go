@@ -4,6 +4,9 @@ import (
 	"time"
 )
 
+// DefaultMaxBucketSize is how many videos a page of the video listings holds.
+const DefaultMaxBucketSize = 20
+
 type ConfigData struct {
 	Version              string    `json:"version"`
 	ServerHost           string    `json:"serverHost"`
@@ -14,4 +17,18 @@ type ConfigData struct {
 	EnableDocs           bool      `json:"enableDocs"`
 	DataStorageType      string    `json:"dataStorageType"`
 	CreatedAt            time.Time `json:"createdAt"`
+
+	// WatchCompletionThreshold is the fraction (0-1] of a video that must be played
+	// for it to be marked as watched. Zero means DefaultWatchCompletionThreshold.
+	WatchCompletionThreshold float64 `json:"watchCompletionThreshold,omitempty"`
+
+	// GCIntervalHours is how often the server deletes orphaned artefacts.
+	// Zero means the default of 24 hours; a negative value disables it.
+	GCIntervalHours int `json:"gcIntervalHours,omitempty"`
+
+	// TrashRetentionDays is how long deleted videos can be restored before the
+	// trash is purged. Zero means the default of 30 days; a negative value keeps them.
+	TrashRetentionDays int `json:"trashRetentionDays,omitempty"`
+
+	SubRepositories []SubRepository `json:"subRepositories,omitempty"`
 }
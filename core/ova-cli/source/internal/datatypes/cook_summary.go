@@ -0,0 +1,10 @@
+package datatypes
+
+// CookSummary counts the outcome of cooking every indexed video of a repository.
+type CookSummary struct {
+	VideosFound int      `json:"videosFound"`
+	Cooked      int      `json:"cooked"`
+	Skipped     int      `json:"skipped"` // Already cooked
+	Failed      int      `json:"failed"`
+	Errors      []string `json:"errors,omitempty"`
+}
@@ -0,0 +1,35 @@
+package datatypes
+
+import "time"
+
+// Doctor check results, from best to worst.
+const (
+	DoctorStatusOK   = "ok"
+	DoctorStatusWarn = "warn"
+	DoctorStatusFail = "fail"
+)
+
+// DoctorCheck is the outcome of one environment or repository health check.
+type DoctorCheck struct {
+	Category string   `json:"category"`          // "tools", "codecs", "repository", "videos" or "certificates"
+	Name     string   `json:"name"`              // What was checked, e.g. "ffmpeg" or "videos.json"
+	Status   string   `json:"status"`            // One of the DoctorStatus constants
+	Message  string   `json:"message"`           // What was found
+	Fix      string   `json:"fix,omitempty"`     // How to resolve a warning or failure
+	Details  []string `json:"details,omitempty"` // Affected items, e.g. paths of orphaned artefacts
+}
+
+// DoctorReport collects the checks of one `ova doctor` run.
+type DoctorReport struct {
+	GeneratedAt time.Time     `json:"generatedAt"`
+	RepoPath    string        `json:"repoPath,omitempty"` // Empty when no repository was checked
+	Checks      []DoctorCheck `json:"checks"`
+	OK          int           `json:"ok"`
+	Warnings    int           `json:"warnings"`
+	Failures    int           `json:"failures"`
+}
+
+// Healthy reports whether no check failed.
+func (r DoctorReport) Healthy() bool {
+	return r.Failures == 0
+}
@@ -0,0 +1,23 @@
+package datatypes
+
+// Reasons a generated artefact is garbage.
+const (
+	GarbageOrphaned   = "orphaned"   // Belongs to a video that is no longer indexed
+	GarbageUnfinished = "unfinished" // Left behind by an interrupted cook
+)
+
+// GarbageItem is a generated file or folder that can be deleted.
+type GarbageItem struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"` // GarbageOrphaned or GarbageUnfinished
+	Size   int64  `json:"size"`   // Bytes, including everything inside a folder
+}
+
+// GarbageReport is the result of one garbage collection run.
+type GarbageReport struct {
+	DryRun           bool          `json:"dryRun"`
+	Items            []GarbageItem `json:"items"`
+	ReclaimableBytes int64         `json:"reclaimableBytes"` // Total size of Items
+	DeletedBytes     int64         `json:"deletedBytes"`     // Zero on a dry run
+	Errors           []string      `json:"errors,omitempty"` // Items that could not be deleted
+}
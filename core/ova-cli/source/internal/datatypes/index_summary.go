@@ -0,0 +1,11 @@
+package datatypes
+
+// IndexSummary counts the outcome of indexing every space of a repository.
+type IndexSummary struct {
+	Spaces      int      `json:"spaces"`
+	VideosFound int      `json:"videosFound"`
+	Indexed     int      `json:"indexed"`
+	Skipped     int      `json:"skipped"` // Already indexed
+	Failed      int      `json:"failed"`
+	Errors      []string `json:"errors,omitempty"`
+}
@@ -0,0 +1,58 @@
+package datatypes
+
+import "time"
+
+// BundleVideo is one video of a library bundle.
+type BundleVideo struct {
+	Video      VideoData `json:"video"`
+	Path       string    `json:"path"`                // Slash-separated, relative to the space folder
+	SourcePath string    `json:"sourcePath"`          // Absolute path in the exporting repository
+	HasFile    bool      `json:"hasFile"`             // False for bundles made by reference
+	Artefacts  []string  `json:"artefacts,omitempty"` // Kinds of generated artefacts in the bundle
+}
+
+// BundlePlaylist is a playlist of the exporting repository, reduced to the bundled videos.
+type BundlePlaylist struct {
+	Owner    string       `json:"owner"`
+	Playlist PlaylistData `json:"playlist"`
+}
+
+// LibraryBundleManifest describes a library bundle. It is the first entry of the bundle.
+type LibraryBundleManifest struct {
+	FormatVersion    int              `json:"formatVersion"`
+	BundleID         string           `json:"bundleId"`
+	CreatedAt        time.Time        `json:"createdAt"`
+	SourceRepository string           `json:"sourceRepository"`
+	Space            SpaceData        `json:"space"`
+	ByReference      bool             `json:"byReference"` // Video files stay in the exporting repository
+	Videos           []BundleVideo    `json:"videos"`
+	Playlists        []BundlePlaylist `json:"playlists"`
+}
+
+// What importing a bundled video did.
+const (
+	ImportStatusNew      = "new"      // Added with its own ID
+	ImportStatusRenamed  = "renamed"  // Added under a new ID because its ID was taken by other content
+	ImportStatusExisting = "existing" // The same content was already in the library; metadata was merged into it
+	ImportStatusSkipped  = "skipped"  // Not imported, see the message
+)
+
+// ImportedVideo is the outcome of importing one bundled video.
+type ImportedVideo struct {
+	BundleVideoID string `json:"bundleVideoId"`
+	VideoID       string `json:"videoId,omitempty"` // ID in the importing repository
+	Path          string `json:"path,omitempty"`    // Relative to the repository root
+	Status        string `json:"status"`
+	Message       string `json:"message,omitempty"`
+}
+
+// ImportReport describes what importing a library bundle changed, or would change on a dry run.
+type ImportReport struct {
+	DryRun           bool            `json:"dryRun"`
+	Space            string          `json:"space"`
+	SpaceCreated     bool            `json:"spaceCreated"`
+	Videos           []ImportedVideo `json:"videos"`
+	PlaylistsCreated int             `json:"playlistsCreated"`
+	PlaylistsMerged  int             `json:"playlistsMerged"`
+	Conflicts        []string        `json:"conflicts,omitempty"` // Everything that was renamed, reassigned or skipped
+}
@@ -0,0 +1,50 @@
+package datatypes
+
+// Media stream types.
+const (
+	StreamTypeVideo    = "video"
+	StreamTypeAudio    = "audio"
+	StreamTypeSubtitle = "subtitle"
+	StreamTypeData     = "data"
+)
+
+// MediaStream describes one stream of a video container. Fields that do not apply
+// to the stream type are left empty.
+type MediaStream struct {
+	Index    int    `json:"index"`              // Stream index within the container
+	Type     string `json:"type"`               // video, audio, subtitle or data
+	Codec    string `json:"codec"`              // e.g. "h264", "aac", "mov_text"
+	Profile  string `json:"profile,omitempty"`  // e.g. "High", "LC"
+	Language string `json:"language,omitempty"` // ISO 639 code from the container, if tagged
+	Title    string `json:"title,omitempty"`
+	Default  bool   `json:"default"`
+	Forced   bool   `json:"forced,omitempty"`
+	Bitrate  int64  `json:"bitrate,omitempty"` // Bits per second
+
+	// Video
+	Width          int     `json:"width,omitempty"`
+	Height         int     `json:"height,omitempty"`
+	FrameRate      float64 `json:"frameRate,omitempty"`
+	PixelFormat    string  `json:"pixelFormat,omitempty"`    // e.g. "yuv420p10le"
+	ColorSpace     string  `json:"colorSpace,omitempty"`     // e.g. "bt2020nc"
+	ColorTransfer  string  `json:"colorTransfer,omitempty"`  // e.g. "smpte2084"
+	ColorPrimaries string  `json:"colorPrimaries,omitempty"` // e.g. "bt2020"
+	HDR            string  `json:"hdr,omitempty"`            // "HDR10", "HLG" or "Dolby Vision"; empty for SDR
+	Rotation       int     `json:"rotation,omitempty"`       // Display rotation in degrees, 0-359
+
+	// Audio
+	Channels      int    `json:"channels,omitempty"`
+	ChannelLayout string `json:"channelLayout,omitempty"` // e.g. "stereo", "5.1(side)"
+	SampleRate    int    `json:"sampleRate,omitempty"`    // Hz
+}
+
+// StreamsOfType returns the streams of the given type in container order.
+func (vc VideoCodecs) StreamsOfType(streamType string) []MediaStream {
+	var streams []MediaStream
+	for _, s := range vc.Streams {
+		if s.Type == streamType {
+			streams = append(streams, s)
+		}
+	}
+	return streams
+}
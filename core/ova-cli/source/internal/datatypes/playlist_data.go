@@ -1,6 +1,9 @@
 package datatypes
 
-import "ova-cli/source/internal/utils"
+import (
+	"errors"
+	"ova-cli/source/internal/utils"
+)
 
 // PlaylistData represents a single playlist.
 type PlaylistData struct {
@@ -9,6 +12,55 @@ type PlaylistData struct {
 	VideoIDs    []string `json:"videoIds"`
 	Slug        string   `json:"slug"`
 	Order       int      `json:"order"` // New order field added
+
+	Rules *SmartPlaylistRules `json:"rules,omitempty"` // Set for smart playlists, whose VideoIDs stay empty
+
+	Collaborators []PlaylistCollaborator `json:"collaborators,omitempty"` // Users the playlist is shared with
+	SharedSpaces  []PlaylistSpaceShare   `json:"sharedSpaces,omitempty"`  // Spaces whose members the playlist is shared with
+	Revision      int                    `json:"revision"`                // Incremented on every change to VideoIDs
+}
+
+// Playlist permissions, from lowest to highest.
+const (
+	PlaylistPermissionView  = "view"  // Read the playlist contents
+	PlaylistPermissionEdit  = "edit"  // Add, remove and reorder videos
+	PlaylistPermissionOwner = "owner" // Everything, including sharing
+)
+
+// PlaylistCollaborator grants a user access to another user's playlist.
+type PlaylistCollaborator struct {
+	Username   string `json:"username"`
+	Permission string `json:"permission"`
+}
+
+// PlaylistSpaceShare grants every member of a space access to a playlist.
+type PlaylistSpaceShare struct {
+	SpaceID    string `json:"spaceId"`
+	Permission string `json:"permission"`
+}
+
+// IsValidPlaylistPermission reports whether permission can be granted to others.
+func IsValidPlaylistPermission(permission string) bool {
+	return permission == PlaylistPermissionView || permission == PlaylistPermissionEdit
+}
+
+// PlaylistPermissionRank orders permissions so the highest grant wins.
+func PlaylistPermissionRank(permission string) int {
+	switch permission {
+	case PlaylistPermissionView:
+		return 1
+	case PlaylistPermissionEdit:
+		return 2
+	case PlaylistPermissionOwner:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// IsSmart reports whether the playlist contents are computed from rules.
+func (p *PlaylistData) IsSmart() bool {
+	return p.Rules != nil
 }
 
 // NewPlaylistData returns an example playlist map.
@@ -21,3 +73,6 @@ func NewPlaylistData(title string) PlaylistData {
 		Order:       0, // default order value
 	}
 }
+
+// ErrPlaylistRevisionConflict is returned when a playlist changed since the revision a write was based on.
+var ErrPlaylistRevisionConflict = errors.New("playlist was modified by someone else")
@@ -0,0 +1,30 @@
+package datatypes
+
+import "time"
+
+// PortablePlaylistFormat identifies ova's JSON playlist export format.
+const PortablePlaylistFormat = "ova-playlist"
+
+// PortablePlaylistVersion is the current version of the portable playlist format.
+const PortablePlaylistVersion = 1
+
+// PortablePlaylist is a playlist exported in a form another repository can import.
+// Items carry several identities so they can be matched even after files were moved.
+type PortablePlaylist struct {
+	Format      string                 `json:"format"`
+	Version     int                    `json:"version"`
+	Title       string                 `json:"title"`
+	Description string                 `json:"description"`
+	Rules       *SmartPlaylistRules    `json:"rules,omitempty"`
+	Items       []PortablePlaylistItem `json:"items"`
+	ExportedAt  time.Time              `json:"exportedAt"`
+}
+
+// PortablePlaylistItem is one video of a portable playlist.
+type PortablePlaylistItem struct {
+	VideoID     string `json:"videoId"`
+	Path        string `json:"path"` // Relative to the repository root, slash-separated
+	Hash        string `json:"hash"` // XXH3-128 of the whole file, empty if it was never recorded
+	Title       string `json:"title"`
+	DurationSec int    `json:"durationSec"`
+}
@@ -0,0 +1,14 @@
+package datatypes
+
+import "time"
+
+// RepoLockInfo describes the process holding a repository lock. It is the content of
+// .ova-repo/ova.lock while the lock is held.
+type RepoLockInfo struct {
+	PID         int       `json:"pid"`
+	Hostname    string    `json:"hostname"`
+	Command     string    `json:"command"`          // The ova command holding the lock, such as "serve"
+	Socket      string    `json:"socket,omitempty"` // Admin socket of a running server, which takes over mutations
+	AcquiredAt  time.Time `json:"acquiredAt"`
+	HeartbeatAt time.Time `json:"heartbeatAt"` // Refreshed while the holder is alive
+}
@@ -0,0 +1,43 @@
+package datatypes
+
+import "time"
+
+// SchemaMigration is one step of the repository schema, moving a file to the next version.
+type SchemaMigration struct {
+	ID          string    `json:"id"`      // <file>@<version>
+	File        string    `json:"file"`    // Slash-separated, relative to .ova-repo
+	Version     int       `json:"version"` // Version of the file after the migration
+	Description string    `json:"description"`
+	Records     int       `json:"records"`             // Records the migration changed, or would change
+	AppliedAt   time.Time `json:"appliedAt,omitempty"` // Set once applied
+	Backup      string    `json:"backup,omitempty"`    // Backup taken before the migration run
+}
+
+// SchemaState is the schema version of every versioned repository file and the migrations
+// applied to reach it. It is stored in .ova-repo/schema.json.
+type SchemaState struct {
+	Versions  map[string]int    `json:"versions"`
+	History   []SchemaMigration `json:"history"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+}
+
+// SchemaFileStatus compares the schema version of a file with the latest one this build writes.
+type SchemaFileStatus struct {
+	File    string `json:"file"`
+	Version int    `json:"version"`
+	Latest  int    `json:"latest"`
+}
+
+// SchemaStatus is reported by `ova repo migrate --status`.
+type SchemaStatus struct {
+	Files   []SchemaFileStatus `json:"files"`
+	Pending []SchemaMigration  `json:"pending"`
+	History []SchemaMigration  `json:"history"`
+}
+
+// MigrationReport lists the migrations of one run, with the records each changed.
+type MigrationReport struct {
+	DryRun     bool              `json:"dryRun"`
+	Backup     string            `json:"backup,omitempty"` // Taken before the first migration
+	Migrations []SchemaMigration `json:"migrations"`
+}
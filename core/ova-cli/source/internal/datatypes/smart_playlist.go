@@ -0,0 +1,39 @@
+package datatypes
+
+import "fmt"
+
+// Sort keys for smart playlists.
+const (
+	SmartSortUploadedAt = "uploadedAt"
+	SmartSortTitle      = "title"
+	SmartSortDuration   = "duration"
+	SmartSortDownloads  = "downloads"
+)
+
+// SmartPlaylistRules describes which videos a smart playlist contains.
+// The contents are computed from the library every time the playlist is read.
+type SmartPlaylistRules struct {
+	Tags            []string `json:"tags,omitempty"`            // Videos must have all of these tags
+	Space           string   `json:"space,omitempty"`           // Only videos owned by this space
+	MinDurationSec  int      `json:"minDurationSec,omitempty"`  // Minimum duration, 0 for no bound
+	MaxDurationSec  int      `json:"maxDurationSec,omitempty"`  // Maximum duration, 0 for no bound
+	UnwatchedOnly   bool     `json:"unwatchedOnly,omitempty"`   // Skip videos in the owner's watched list
+	AddedWithinDays int      `json:"addedWithinDays,omitempty"` // Only videos uploaded in the last N days, 0 for all
+	SortBy          string   `json:"sortBy,omitempty"`          // One of the SmartSort keys, default uploadedAt
+	Ascending       bool     `json:"ascending,omitempty"`       // Sort ascending instead of descending
+	Limit           int      `json:"limit,omitempty"`           // Maximum number of videos, 0 for no limit
+}
+
+// Validate checks that the rules are consistent.
+func (r *SmartPlaylistRules) Validate() error {
+	if r.MinDurationSec < 0 || r.MaxDurationSec < 0 || r.AddedWithinDays < 0 || r.Limit < 0 {
+		return fmt.Errorf("smart playlist rules must not contain negative values")
+	}
+	if r.MaxDurationSec > 0 && r.MinDurationSec > r.MaxDurationSec {
+		return fmt.Errorf("minDurationSec must not exceed maxDurationSec")
+	}
+	if r.SortBy != "" && !IsVideoSortKey(r.SortBy) {
+		return fmt.Errorf("unknown sortBy %q", r.SortBy)
+	}
+	return nil
+}
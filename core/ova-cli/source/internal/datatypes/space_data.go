@@ -1,6 +1,10 @@
 package datatypes
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type SpaceSettings struct {
 	MaxDiskLimit string `json:"maxDiskLimit"`
@@ -28,6 +32,8 @@ type SpaceData struct {
 	SpaceSettings SpaceSettings `json:"spaceSettings"`
 	InviteLink    string        `json:"inviteLink"`
 	MemberIds     []string      `json:"membersIds"`
+	IsArchived    bool          `json:"isArchived"`
+	ArchivedAt    time.Time     `json:"archivedAt,omitempty"`
 	CreatedAt     time.Time     `json:"createdAt"`
 }
 
@@ -36,7 +42,7 @@ func CreateDefaultSpaceData(spaceName string, owner string) SpaceData {
 	return SpaceData{
 		SpaceName:  spaceName,
 		SpaceOwner: owner,
-		SpaceId:    "", // Placeholder for space ID generation logic
+		SpaceId:    NewSpaceID(),
 		Groups: []SpaceGroup{{
 			GroupName: "root",
 			VideoIds:  []string{},
@@ -54,3 +60,8 @@ func CreateDefaultSpaceData(spaceName string, owner string) SpaceData {
 		CreatedAt:  time.Now().UTC(), // Placeholder for current time logic
 	}
 }
+
+// NewSpaceID returns a new stable identifier for a space.
+func NewSpaceID() string {
+	return uuid.NewString()
+}
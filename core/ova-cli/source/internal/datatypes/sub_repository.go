@@ -0,0 +1,20 @@
+package datatypes
+
+import "time"
+
+// SubRepository is another ova repository attached to this one, e.g. a repo on an external drive.
+// Its videos are merged into the parent library with IDs namespaced by Name.
+type SubRepository struct {
+	Name       string    `json:"name"`
+	Path       string    `json:"path"`
+	AttachedAt time.Time `json:"attachedAt"`
+}
+
+// SubRepositoryStatus describes whether an attached repository is currently reachable.
+type SubRepositoryStatus struct {
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	Online      bool   `json:"online"`
+	TotalVideos int    `json:"totalVideos"`
+	Error       string `json:"error,omitempty"`
+}
@@ -0,0 +1,26 @@
+package datatypes
+
+import "time"
+
+// Subtitle track sources.
+const (
+	SubtitleSourceSidecar  = "sidecar"  // A subtitle file next to the video
+	SubtitleSourceEmbedded = "embedded" // A subtitle stream inside the video container
+	SubtitleSourceUpload   = "upload"   // Uploaded by a user
+)
+
+// SubtitleTrack describes one subtitle track of a video. Every track is stored
+// as WebVTT in the repository storage regardless of its original format.
+type SubtitleTrack struct {
+	ID             string    `json:"id"`
+	Language       string    `json:"language"` // ISO 639 / BCP 47 code, "und" when unknown
+	Label          string    `json:"label"`
+	Source         string    `json:"source"`
+	OriginalFormat string    `json:"originalFormat"`        // e.g. "srt", "ass", "mov_text"
+	SourceFile     string    `json:"sourceFile,omitempty"`  // Sidecar file name
+	StreamIndex    int       `json:"streamIndex,omitempty"` // Container stream index of embedded tracks
+	Default        bool      `json:"default"`
+	Forced         bool      `json:"forced"`
+	AddedBy        string    `json:"addedBy,omitempty"`
+	AddedAt        time.Time `json:"addedAt"`
+}
@@ -0,0 +1,38 @@
+package datatypes
+
+import "time"
+
+// TrashedPlaylistEntry is the position a trashed video had in a playlist.
+type TrashedPlaylistEntry struct {
+	Owner string `json:"owner"`
+	Slug  string `json:"slug"`
+	Index int    `json:"index"`
+}
+
+// TrashedVideoReferences are the user references removed when a video was trashed,
+// put back when it is restored.
+type TrashedVideoReferences struct {
+	SavedBy   []string                 `json:"savedBy,omitempty"`
+	WatchedBy []string                 `json:"watchedBy,omitempty"`
+	Progress  map[string]WatchProgress `json:"progress,omitempty"` // Keyed by username
+	Playlists []TrashedPlaylistEntry   `json:"playlists,omitempty"`
+}
+
+// TrashEntry is a soft-deleted video waiting in the trash to be restored or purged.
+type TrashEntry struct {
+	Video        VideoData              `json:"video"`
+	OriginalPath string                 `json:"originalPath"` // Relative to the repository root
+	HasFile      bool                   `json:"hasFile"`      // False when the file was already missing
+	DeletedAt    time.Time              `json:"deletedAt"`
+	DeletedBy    string                 `json:"deletedBy,omitempty"`
+	ExpiresAt    time.Time              `json:"expiresAt,omitempty"` // Zero when the trash is kept forever
+	Size         int64                  `json:"size"`                // Bytes of the file and artefacts
+	References   TrashedVideoReferences `json:"references"`
+}
+
+// TrashPurgeReport is the result of permanently deleting videos from the trash.
+type TrashPurgeReport struct {
+	PurgedVideoIDs []string `json:"purgedVideoIds"`
+	FreedBytes     int64    `json:"freedBytes"`
+	Errors         []string `json:"errors,omitempty"`
+}
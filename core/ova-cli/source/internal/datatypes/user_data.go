@@ -14,6 +14,8 @@ type UserData struct {
 	Favorites    []string       `json:"favorites"`             // Stores VideoIDs
 	Playlists    []PlaylistData `json:"playlists"`             // Embedded user-specific playlists
 	Watched      []string       `json:"watched"`               // Stores VideoIDs the user has watched
+
+	WatchProgress map[string]WatchProgress `json:"watchProgress,omitempty"` // Playback progress keyed by VideoID
 }
 
 // NewUserData returns an initialized UserData struct for a new user.
@@ -27,5 +29,7 @@ func NewUserData(username string, passwordHashed string) UserData {
 		Favorites:    []string{},       // Initialize with empty slice
 		Playlists:    []PlaylistData{}, // Initialize with empty slice
 		Watched:      []string{},       // Initialize with empty slice
+
+		WatchProgress: map[string]WatchProgress{},
 	}
 }
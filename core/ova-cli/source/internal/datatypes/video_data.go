@@ -13,6 +13,9 @@ type VideoCodecs struct {
 	Resolution  VideoResolution `json:"resolution"`  // Resolution (width x height)
 	VideoCodec  string          `json:"videoCodec"`  // Video codec (e.g., avc1.640032)
 	AudioCodec  string          `json:"audioCodec"`  // Audio codec (e.g., mp4a.40.2)
+
+	Bitrate int64         `json:"bitrate,omitempty"` // Overall container bitrate in bits per second
+	Streams []MediaStream `json:"streams,omitempty"` // Every stream in the container, in order
 }
 
 // VideoResolution defines the width and height of a video.
@@ -33,6 +36,11 @@ type VideoData struct {
 	IsCooked       bool        `json:"isCooked"`       // Indicates if the video is processed (cooked)
 	TotalDownloads int         `json:"totalDownloads"` // Number of downloads
 	UploadedAt     time.Time   `json:"uploadedAt"`     // Timestamp of upload
+
+	FileSize    int64  `json:"fileSize,omitempty"`    // Size of the file in bytes when it was indexed
+	ContentHash string `json:"contentHash,omitempty"` // XXH3-128 of the whole file, checked by `ova repo verify`
+
+	Fingerprint *VideoFingerprint `json:"fingerprint,omitempty"` // Perceptual fingerprint, computed when the video is cooked
 }
 
 // NewVideoData returns an initialized VideoData struct.
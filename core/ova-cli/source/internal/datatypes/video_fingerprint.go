@@ -0,0 +1,45 @@
+package datatypes
+
+// VideoFingerprint is a perceptual summary of a video's content, used to find re-encodes
+// and other resolutions of the same video.
+type VideoFingerprint struct {
+	DurationSec float64  `json:"durationSec"`
+	FrameHashes []string `json:"frameHashes"` // 64-bit dHashes (hex) of frames at evenly spaced positions
+}
+
+// NearDuplicateVideo is one member of a group of near-duplicate videos.
+type NearDuplicateVideo struct {
+	VideoID     string          `json:"videoId"`
+	FileName    string          `json:"fileName"`
+	Path        string          `json:"path"` // Relative to the repository root
+	DurationSec int             `json:"durationSec"`
+	Resolution  VideoResolution `json:"resolution"`
+	FileSize    int64           `json:"fileSize,omitempty"`
+	Similarity  float64         `json:"similarity"` // 0-1, compared to the group's suggested copy
+}
+
+// NearDuplicateGroup is a set of videos that look like copies of the same content.
+type NearDuplicateGroup struct {
+	KeepVideoID string               `json:"keepVideoId"` // Suggested copy to keep: highest resolution, then largest file
+	Videos      []NearDuplicateVideo `json:"videos"`      // Suggested copy first, then by similarity
+}
+
+// NearDuplicateReport lists the near-duplicate groups of a library.
+type NearDuplicateReport struct {
+	Threshold     float64              `json:"threshold"`
+	Groups        []NearDuplicateGroup `json:"groups"`
+	Fingerprinted int                  `json:"fingerprinted"` // Videos that were compared
+	Skipped       int                  `json:"skipped"`       // Videos without a fingerprint
+}
+
+// DuplicateMergeReport describes what merging duplicates into one kept video changed.
+type DuplicateMergeReport struct {
+	KeptVideoID         string   `json:"keptVideoId"`
+	RemovedVideoIDs     []string `json:"removedVideoIds"`
+	TagsAdded           int      `json:"tagsAdded"`
+	PlaylistsUpdated    int      `json:"playlistsUpdated"`
+	SavedUpdated        int      `json:"savedUpdated"`        // Users whose saved videos now hold the kept copy
+	WatchHistoryUpdated int      `json:"watchHistoryUpdated"` // Users whose history or progress moved to the kept copy
+	FilesDeleted        []string `json:"filesDeleted"`        // Only when file deletion was requested
+	Errors              []string `json:"errors,omitempty"`
+}
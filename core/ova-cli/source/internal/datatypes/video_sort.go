@@ -0,0 +1,30 @@
+package datatypes
+
+import (
+	"slices"
+	"strings"
+)
+
+// VideoSortKeys lists the keys videos can be sorted by, in smart playlists and in the
+// cached library listing.
+var VideoSortKeys = []string{SmartSortUploadedAt, SmartSortTitle, SmartSortDuration, SmartSortDownloads}
+
+// IsVideoSortKey reports whether key is one of VideoSortKeys.
+func IsVideoSortKey(key string) bool {
+	return slices.Contains(VideoSortKeys, key)
+}
+
+// VideoSortLess reports whether a sorts before b in ascending order of the given key.
+// Unknown keys sort by upload date.
+func VideoSortLess(sortBy string, a, b VideoData) bool {
+	switch sortBy {
+	case SmartSortTitle:
+		return strings.ToLower(a.FileName) < strings.ToLower(b.FileName)
+	case SmartSortDuration:
+		return a.Codecs.DurationSec < b.Codecs.DurationSec
+	case SmartSortDownloads:
+		return a.TotalDownloads < b.TotalDownloads
+	default:
+		return a.UploadedAt.Before(b.UploadedAt)
+	}
+}
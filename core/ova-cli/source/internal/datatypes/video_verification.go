@@ -0,0 +1,23 @@
+package datatypes
+
+// Outcomes of checking a video file against its stored size and content hash.
+const (
+	VerifyOK        = "ok"        // File matches the stored hash
+	VerifyRecorded  = "recorded"  // No hash was stored yet; the current one was recorded
+	VerifyModified  = "modified"  // File size changed, the file was edited or replaced
+	VerifyCorrupted = "corrupted" // Same size but different content, e.g. bit rot
+	VerifyMissing   = "missing"   // File no longer exists
+	VerifyFailed    = "failed"    // File could not be read
+)
+
+// VideoVerification is the result of verifying one video file.
+type VideoVerification struct {
+	VideoID      string `json:"videoId"`
+	Path         string `json:"path"`
+	Status       string `json:"status"` // One of the Verify constants
+	ExpectedSize int64  `json:"expectedSize,omitempty"`
+	ActualSize   int64  `json:"actualSize,omitempty"`
+	ExpectedHash string `json:"expectedHash,omitempty"`
+	ActualHash   string `json:"actualHash,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
@@ -4,15 +4,26 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+
+	"github.com/google/uuid"
 )
 
-// VideoMarker represents a marker in a video.
-// It stores the timestamp as Hour, Minute, and Second components.
+// VideoMarker represents a marker (chapter) in a video.
+// Times are stored in milliseconds. Hour, Minute and Second mirror StartMs in whole
+// seconds for older clients; on input they are only used when StartMs is zero.
 type VideoMarker struct {
-	Hour   int    `json:"hour"`
-	Minute int    `json:"minute"`
-	Second int    `json:"second"`
-	Title  string `json:"title"`
+	ID          string `json:"id"`
+	StartMs     int64  `json:"startMs"`
+	EndMs       int64  `json:"endMs,omitempty"` // 0 means until the next marker or the end of the video
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Color       string `json:"color,omitempty"`    // CSS colour, e.g. "#ff8800"
+	Category    string `json:"category,omitempty"` // Free-form group such as "intro" or "sponsor"
+	Author      string `json:"author,omitempty"`   // Username of whoever created the marker
+
+	Hour   int `json:"hour"`
+	Minute int `json:"minute"`
+	Second int `json:"second"`
 }
 
 // UpdateMarkersRequest defines the expected JSON payload for updating markers,
@@ -21,14 +32,78 @@ type UpdateMarkersRequest struct {
 	Markers []VideoMarker `json:"markers"`
 }
 
+// NewMarkerID returns a new unique marker ID.
+func NewMarkerID() string {
+	return uuid.NewString()
+}
+
+// Normalize fills StartMs from the legacy Hour/Minute/Second fields when it is unset
+// and syncs those fields back from StartMs.
+func (vm *VideoMarker) Normalize() {
+	if vm.StartMs == 0 {
+		vm.StartMs = int64(vm.Hour*3600+vm.Minute*60+vm.Second) * 1000
+	}
+	totalSec := int(vm.StartMs / 1000)
+	vm.Hour = totalSec / 3600
+	vm.Minute = (totalSec % 3600) / 60
+	vm.Second = totalSec % 60
+}
+
+// Validate checks the marker's times and title. Call Normalize first.
+func (vm *VideoMarker) Validate() error {
+	if vm.StartMs < 0 || vm.EndMs < 0 {
+		return fmt.Errorf("marker times cannot be negative")
+	}
+	if vm.EndMs != 0 && vm.EndMs <= vm.StartMs {
+		return fmt.Errorf("marker end (%d ms) must be after its start (%d ms)", vm.EndMs, vm.StartMs)
+	}
+	if strings.TrimSpace(vm.Title) == "" {
+		return fmt.Errorf("marker title cannot be empty")
+	}
+	if strings.ContainsAny(vm.Title, "\n\r") {
+		return fmt.Errorf("marker title must be a single line")
+	}
+	if strings.Contains(vm.Title, "-->") {
+		return fmt.Errorf("marker title cannot contain \"-->\"")
+	}
+	return nil
+}
+
 // FormatHMSToVTT converts hour, minute, second to "HH:MM:SS.000" format required by VTT.
-// Milliseconds are set to 000 as they are not part of the VideoMarker struct.
 func FormatHMSToVTT(h, m, s int) string {
 	return fmt.Sprintf("%02d:%02d:%02d.000", h, m, s)
 }
 
+// FormatMsToVTT converts milliseconds to the "HH:MM:SS.mmm" format required by VTT.
+func FormatMsToVTT(ms int64) string {
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, (ms%3600000)/60000, (ms%60000)/1000, ms%1000)
+}
+
+// ParseVTTToMs parses a VTT timestamp ("HH:MM:SS.mmm", "MM:SS.mmm" or "SS.mmm") into milliseconds.
+func ParseVTTToMs(vtt string) (int64, error) {
+	timePart, fracPart, _ := strings.Cut(strings.TrimSpace(vtt), ".")
+
+	h, m, s, err := ParseVTTToHMS(timePart)
+	if err != nil {
+		return 0, err
+	}
+
+	var ms int64
+	if fracPart != "" {
+		// Pad or cut the fraction to exactly three digits
+		fracPart = (fracPart + "000")[:3]
+		frac, err := strconv.Atoi(fracPart)
+		if err != nil {
+			return 0, fmt.Errorf("invalid milliseconds in timestamp '%s': %w", vtt, err)
+		}
+		ms = int64(frac)
+	}
+
+	return int64(h*3600+m*60+s)*1000 + ms, nil
+}
+
 // ParseVTTToHMS parses a VTT timestamp string into hours, minutes, and seconds.
-// Milliseconds are ignored as they are not used in the VideoMarker structure.
+// Milliseconds are ignored; use ParseVTTToMs to keep them.
 func ParseVTTToHMS(vtt string) (h, m, s int, err error) {
 	partsDot := strings.Split(vtt, ".")
 	timePart := partsDot[0] // e.g., "00:01:05" or "01:05" or "5"
@@ -73,7 +148,7 @@ func ParseVTTToHMS(vtt string) (h, m, s int, err error) {
 	return h, m, s, nil
 }
 
-// ConvertToSeconds converts VideoMarker (H,M,S) to total seconds (float64).
+// ConvertToSeconds returns the marker start in seconds.
 func (vm *VideoMarker) ConvertToSeconds() float64 {
-	return float64(vm.Hour*3600 + vm.Minute*60 + vm.Second)
+	return float64(vm.StartMs) / 1000
 }
@@ -0,0 +1,16 @@
+package datatypes
+
+import "time"
+
+// DefaultWatchCompletionThreshold is the fraction of a video that must be played
+// before it counts as completed when the config does not set one.
+const DefaultWatchCompletionThreshold = 0.9
+
+// WatchProgress is the playback state of one video for one user.
+type WatchProgress struct {
+	VideoID       string    `json:"videoId"`
+	PositionSec   float64   `json:"positionSec"`   // Last reported playback position
+	DurationSec   float64   `json:"durationSec"`   // Duration of the video as seen by the player
+	Completed     bool      `json:"completed"`     // Position reached the completion threshold
+	LastWatchedAt time.Time `json:"lastWatchedAt"` // Time of the last progress update
+}
@@ -0,0 +1,72 @@
+package filehash
+
+import (
+	"image"
+	_ "image/jpeg" // Register the JPEG decoder for keyframes and thumbnails
+	_ "image/png"
+	"math/bits"
+	"os"
+)
+
+// DHash computes the 64-bit difference hash of an image: the image is reduced to a 9x8
+// grid of average brightness and each bit records whether a cell is brighter than its
+// right neighbour. Re-encoded or resized copies of a frame get (nearly) the same hash.
+func DHash(img image.Image) uint64 {
+	const cols, rows = 9, 8
+
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w == 0 || h == 0 {
+		return 0
+	}
+
+	var grid [rows][cols]float64
+	for y := 0; y < rows; y++ {
+		y0, y1 := bounds.Min.Y+y*h/rows, bounds.Min.Y+(y+1)*h/rows
+		y1 = max(y1, y0+1)
+		for x := 0; x < cols; x++ {
+			x0, x1 := bounds.Min.X+x*w/cols, bounds.Min.X+(x+1)*w/cols
+			x1 = max(x1, x0+1)
+
+			var sum float64
+			for py := y0; py < y1; py++ {
+				for px := x0; px < x1; px++ {
+					r, g, b, _ := img.At(px, py).RGBA()
+					sum += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+				}
+			}
+			grid[y][x] = sum / float64((y1-y0)*(x1-x0))
+		}
+	}
+
+	var hash uint64
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols-1; x++ {
+			hash <<= 1
+			if grid[y][x] > grid[y][x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// DHashImageFile decodes a JPEG or PNG file and returns its difference hash.
+func DHashImageFile(path string) (uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return 0, err
+	}
+	return DHash(img), nil
+}
+
+// HammingDistance returns the number of bits that differ between two hashes.
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
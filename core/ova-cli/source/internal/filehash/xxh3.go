@@ -0,0 +1,37 @@
+package filehash
+
+import (
+	"bufio"
+	"encoding/hex"
+	"io"
+	"os"
+
+	"github.com/zeebo/xxh3"
+)
+
+// XXH3FullFileHash hashes the whole file with 128-bit XXH3, streaming it through a large
+// buffer so memory use stays constant. It returns the hash and the number of bytes read.
+func XXH3FullFileHash(filePath string) (string, int64, error) {
+	const bufferSize = 4 * 1024 * 1024 // 4MB
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	hasher := xxh3.New()
+	size, err := io.Copy(hasher, bufio.NewReaderSize(f, bufferSize))
+	if err != nil {
+		return "", 0, err
+	}
+
+	sum := hasher.Sum128().Bytes()
+	return hex.EncodeToString(sum[:]), size, nil
+}
+
+// XXH3Hash returns the 128-bit XXH3 hash of data, hex encoded like XXH3FullFileHash.
+func XXH3Hash(data []byte) string {
+	sum := xxh3.Hash128(data).Bytes()
+	return hex.EncodeToString(sum[:])
+}
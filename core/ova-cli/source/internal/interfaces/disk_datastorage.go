@@ -25,16 +25,36 @@ type DiskDataStorage interface {
 	UpdateVideoLocalPath(videoID, newPath string) error
 	SetPlaylistsOrder(username string, newOrderSlugs []string) error
 	UpdatePlaylistInfo(username, playlistSlug, newTitle, newDescription string) error
+	UpdatePlaylistRules(username, slug string, rules *datatypes.SmartPlaylistRules) error
+
+	// Playlist sharing
+	SetPlaylistCollaborator(owner, slug, collaborator, permission string) error
+	RemovePlaylistCollaborator(owner, slug, collaborator string) error
+	SetPlaylistSpaceShare(owner, slug, spaceID, permission string) error
+	RemovePlaylistSpaceShare(owner, slug, spaceID string) error
+	MovePlaylistVideo(owner, slug, videoID string, index int, revision *int) error
+	GetAllPlaylists() (map[string][]datatypes.PlaylistData, error)
+
+	// Playlist bulk editing, each applied atomically
+	InsertVideosIntoPlaylist(username, slug string, videoIDs []string, index int) (int, error)
+	RemoveVideosFromPlaylist(username, slug string, videoIDs []string) (int, error)
+	DeduplicatePlaylist(username, slug string) (int, error)
+	CopyPlaylistVideos(srcOwner, srcSlug, dstOwner, dstSlug string, videoIDs []string, index int) (int, error)
+
 	UpdateUserPassword(username, newHashedPassword string) error
 	GetUserPlaylistContentVideosCount(username, playlistSlug string) (int, error)
 	GetUserPlaylistContentVideosInRange(username, playlistSlug string, start, end int) ([]string, error)
 
+	// Removes a video from every user's favorites, watched list and playlists
+	RemoveVideoFromAllUsers(videoID string) error
+
 	// Video tags management
 	AddTagToVideo(videoID, tag string) error
 	RemoveTagFromVideo(videoID, tag string) error
 
 	// Video management
 	AddVideo(video datatypes.VideoData) error
+	UpdateVideo(video datatypes.VideoData) error
 	DeleteVideoByID(id string) error
 	DeleteAllVideos() error
 	GetVideoByID(id string) (*datatypes.VideoData, error)
@@ -49,10 +69,17 @@ type DiskDataStorage interface {
 
 	// Spaces Management
 	CreateSpace(space *datatypes.SpaceData) error
+	DeleteSpace(name string) error
+	UpdateSpace(name string, space *datatypes.SpaceData) error
+	RenameSpace(oldName, newName string) error
+	GetAllSpaces() (map[string]datatypes.SpaceData, error)
+	GetSpaceByID(spaceID string) (*datatypes.SpaceData, error)
 	GetVideosBySpace(spacePath string) ([]datatypes.VideoData, error)
 	GetVideoCountInSpace(spacePath string) (int, error)
 	GetVideoIDsBySpaceInRange(spacePath string, start, end int) ([]string, error)
 	AddVideoIDToSpace(videoId, filePath string) error
+	UpdateVideoLocation(video datatypes.VideoData, filePath string) error
+	RemoveVideoIDFromSpaces(videoId string) error
 
 	// New method to get total video count
 	GetTotalVideoCount() (int, error)
@@ -62,5 +89,11 @@ type DiskDataStorage interface {
 	GetUserWatchedVideos(username string) ([]string, error)
 	ClearUserWatchedHistory(username string) error
 
+	// Playback progress
+	SaveWatchProgress(username string, progress datatypes.WatchProgress) error
+	GetWatchProgress(username, videoID string) (*datatypes.WatchProgress, error)
+	GetUserWatchProgress(username string) ([]datatypes.WatchProgress, error)
+	DeleteWatchProgress(username, videoID string) error
+
 	GetSearchSuggestions(query string) ([]string, error)
 }
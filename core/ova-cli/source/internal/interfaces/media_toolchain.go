@@ -0,0 +1,62 @@
+package interfaces
+
+import (
+	"context"
+	"io"
+
+	"ova-cli/source/internal/datatypes"
+)
+
+// MediaToolchain runs the media tools used to inspect videos and generate their artefacts.
+// Implementations must be safe for concurrent use; cooking runs one job per CPU.
+type MediaToolchain interface {
+	// Name identifies the implementation, e.g. "ffmpeg" or "fake".
+	Name() string
+
+	// Probe reads the container format, codecs, resolution, frame rate and stream list of a video.
+	Probe(videoPath string) (datatypes.VideoCodecs, error)
+
+	// Duration returns the duration of a video in seconds.
+	Duration(videoPath string) (float64, error)
+
+	// Thumbnail writes a JPEG frame taken at timeSec to outputPath.
+	Thumbnail(videoPath, outputPath string, timeSec float64) error
+
+	// Preview writes a short silent WebM clip starting at startSec to outputPath.
+	Preview(videoPath, outputPath string, startSec, durationSec float64) error
+
+	// Keyframes writes every keyframe, scaled to fit width x height, into outputDir as
+	// keyframe_0001.jpg, keyframe_0002.jpg, ... and returns their timestamps in seconds.
+	Keyframes(videoPath, outputDir string, width, height int) ([]float64, error)
+
+	// SpriteSheets tiles the JPEG frames of framesDir, in name order, into sprite sheets of
+	// tile ("5x5") thumbnails of width x height, written to fmt.Sprintf(outputPattern, 1), ...
+	SpriteSheets(framesDir, outputPattern, tile string, width, height int) error
+
+	// ExtractSubtitle converts the subtitle stream at streamIndex of a video to a WebVTT file.
+	ExtractSubtitle(videoPath string, streamIndex int, outputPath string) error
+
+	// ConvertSubtitle converts a subtitle file (e.g. .srt or .ass) to a WebVTT file.
+	ConvertSubtitle(inputPath, outputPath string) error
+
+	// RemuxAudio writes a copy of a video that keeps its video streams and only the audio
+	// stream at audioStreamIndex, without re-encoding.
+	RemuxAudio(videoPath string, audioStreamIndex int, outputPath string) error
+
+	// Chapters reads the chapters stored in a video file. Marker IDs are left empty.
+	Chapters(videoPath string) ([]datatypes.VideoMarker, error)
+
+	// EmbedChapters writes a copy of a video to outputPath with the chapters of an
+	// FFMETADATA1 file, without re-encoding.
+	EmbedChapters(videoPath, metadataPath, outputPath string) error
+
+	// Transcode remuxes a video (e.g. .ts) into an MP4 file at outputPath.
+	Transcode(inputPath, outputPath string) error
+
+	// Trim re-encodes durationSec seconds of a video, starting at startSec, as fragmented
+	// MP4 and writes it to w as it is produced. It stops when ctx is cancelled.
+	Trim(ctx context.Context, videoPath string, startSec, durationSec float64, w io.Writer) error
+
+	// Fragment rewrites an MP4 file in place as fragmented MP4.
+	Fragment(videoPath string) error
+}
@@ -7,15 +7,24 @@ type MemoryDataStorage interface {
 	// Get all cached video IDs (or metadata as required)
 	GetAllCachedVideoIds() ([]string, error)
 
-	// Get videos in a specific range (e.g., 0 to 10, 10 to 50)
-	GetSortedVideosInRange(start, end int) ([]string, error)
+	// Get videos in a specific range (e.g., 0 to 10, 10 to 50) of one of the datatypes.VideoSortKeys
+	GetSortedVideosInRange(sortBy string, ascending bool, start, end int) ([]string, error)
 
-		// Get the total count of videos cached in memory
+	// Get the total count of videos cached in memory
 	GetTotalVideosCached() (int, error)
 
-	// Clears all videos in the memory storage 
+	// Clears all videos in the memory storage
 	ClearAll() error
 
-	// Imports videos and sorts them by their upload date
-	CacheVideosByUploadDate(videos []datatypes.VideoData) error
+	// Replaces the cached videos and sorts them by every sort key
+	CacheVideos(videos []datatypes.VideoData) error
+
+	// Adds a video to the cache, replacing the cached copy if there is one
+	AddCachedVideo(video datatypes.VideoData) error
+
+	// Replaces the cached copy of a video and moves it to its new position
+	UpdateCachedVideo(video datatypes.VideoData) error
+
+	// Removes a video from the cache
+	RemoveCachedVideo(videoID string) error
 }
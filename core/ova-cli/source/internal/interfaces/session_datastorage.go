@@ -4,6 +4,7 @@ type SessionDataStorage interface {
 	AddSession(sessionID string, username string) error
 	GetSession(sessionID string) (string, error)
 	DeleteSession(sessionID string) error
+	DeleteUserSessions(username string) (int, error)
 	SaveOnDisk() error
 	LoadFromDisk() error
 	ClearAllSessions() error
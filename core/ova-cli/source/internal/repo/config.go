@@ -20,7 +20,7 @@ func (r *RepoManager) GetDefaultConfig() *datatypes.ConfigData {
 			ServerHost:           "0.0.0.0",
 			ServerPort:           4040,
 			EnableAuthentication: true,
-			MaxBucketSize:        20,
+			MaxBucketSize:        datatypes.DefaultMaxBucketSize,
 			DataStorageType:      "jsondb",
 			CreatedAt:            time.Now(),
 		}
@@ -39,6 +39,7 @@ func (r *RepoManager) GetDefaultConfigTemplate() (*datatypes.ConfigData, error)
 			ServerHost:           "0.0.0.0",
 			ServerPort:           4040,
 			EnableAuthentication: true,
+			MaxBucketSize:        datatypes.DefaultMaxBucketSize,
 			DataStorageType:      "jsondb",
 			CreatedAt:            time.Now(),
 		}
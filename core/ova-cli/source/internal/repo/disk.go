@@ -3,6 +3,7 @@ package repo
 import (
 	"fmt"
 	"os"
+	"ova-cli/source/internal/filehash"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -202,8 +203,8 @@ type VideoHashResult struct {
 }
 
 // ScanDiskForDuplicateVideos scans the repository for duplicate video files by checking their hashes.
-// It returns a map where keys are video hashes and values are slices of relative paths
-// that share that hash, effectively listing all duplicates.
+// It returns a map where keys are video IDs and values are slices of paths whose files have
+// the same size and full content, effectively listing all duplicates.
 func (r *RepoManager) ScanDiskForDuplicateVideos() (map[string][]string, error) {
 	videoHashes := make(map[string][]string)
 	duplicateVideos := make(map[string][]string)
@@ -259,16 +260,61 @@ func (r *RepoManager) ScanDiskForDuplicateVideos() (map[string][]string, error)
 		videoHashes[res.Hash] = append(videoHashes[res.Hash], res.RelPath)
 	}
 
-	// Identify duplicates (this part remains the same)
+	// The partial hash only covers the first and last 5 MB, so confirm every candidate
+	// set with the file size and full content hash before reporting it
 	for hash, paths := range videoHashes {
-		if len(paths) > 1 {
-			duplicateVideos[hash] = paths
+		if len(paths) < 2 {
+			continue
+		}
+		confirmed := confirmDuplicateVideos(paths)
+		for contentHash, same := range confirmed {
+			if len(confirmed) == 1 {
+				duplicateVideos[hash] = same
+			} else {
+				duplicateVideos[collisionVideoID(hash, contentHash)] = same
+			}
 		}
 	}
 
 	return duplicateVideos, nil
 }
 
+// confirmDuplicateVideos groups files that share a partial hash by their full content hash,
+// keeping only the groups with more than one file. Files of different sizes are never hashed.
+func confirmDuplicateVideos(paths []string) map[string][]string {
+	bySize := make(map[int64][]string)
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			fmt.Printf("Warning: Could not read video %s: %v\n", path, err)
+			continue
+		}
+		bySize[info.Size()] = append(bySize[info.Size()], path)
+	}
+
+	byContent := make(map[string][]string)
+	for _, sameSize := range bySize {
+		if len(sameSize) < 2 {
+			continue
+		}
+		for _, path := range sameSize {
+			contentHash, _, err := filehash.XXH3FullFileHash(path)
+			if err != nil {
+				fmt.Printf("Warning: Could not hash video %s: %v\n", path, err)
+				continue
+			}
+			byContent[contentHash] = append(byContent[contentHash], path)
+		}
+	}
+
+	for contentHash, same := range byContent {
+		if len(same) < 2 {
+			delete(byContent, contentHash)
+		}
+	}
+	return byContent
+}
+
 // IsVideoFilePathExist checks if a video file exists at the specified absolute path.
 func (r *RepoManager) IsVideoFilePathExist(absolutePath string) (bool, error) {
 	// Check if the file exists at the absolute path
@@ -0,0 +1,52 @@
+package repo
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestScanDiskForDuplicateVideos(t *testing.T) {
+	r, _ := newTestRepo(t)
+
+	// Same first and last 5 MB, so the partial hash cannot tell the files apart
+	content := make([]byte, 11<<20)
+	changedMiddle := slices.Clone(content)
+	changedMiddle[len(changedMiddle)/2] = 1
+	longer := append(slices.Clone(content[:len(content)-(5<<20)]), make([]byte, 6<<20)...)
+
+	files := map[string][]byte{
+		"Movies/original.mp4": content,
+		"Movies/copy.mp4":     content,
+		"Movies/edited.mp4":   changedMiddle,
+		"Movies/longer.mp4":   longer,
+	}
+	for relPath, data := range files {
+		path := filepath.Join(r.GetRootPath(), filepath.FromSlash(relPath))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	duplicates, err := r.ScanDiskForDuplicateVideos()
+	if err != nil {
+		t.Fatalf("ScanDiskForDuplicateVideos() error = %v", err)
+	}
+	if len(duplicates) != 1 {
+		t.Fatalf("ScanDiskForDuplicateVideos() = %v, want one set", duplicates)
+	}
+	for _, paths := range duplicates {
+		var names []string
+		for _, path := range paths {
+			names = append(names, filepath.Base(path))
+		}
+		slices.Sort(names)
+		if !slices.Equal(names, []string{"copy.mp4", "original.mp4"}) {
+			t.Errorf("duplicate set = %v, want copy.mp4 and original.mp4", names)
+		}
+	}
+}
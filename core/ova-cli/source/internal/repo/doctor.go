@@ -0,0 +1,406 @@
+package repo
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"ova-cli/source/internal/datastorage"
+	"ova-cli/source/internal/datatypes"
+	"ova-cli/source/internal/thirdparty"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Oldest tool releases ova's ffmpeg and openssl invocations are known to work with.
+const (
+	minFFmpegMajor  = 4
+	minOpenSSLMajor = 1
+	minOpenSSLMinor = 1
+)
+
+// certificateExpiryWarning is how long before expiry a certificate is reported.
+const certificateExpiryWarning = 30 * 24 * time.Hour
+
+// requiredFFmpegComponent is an encoder or filter ova passes to ffmpeg.
+type requiredFFmpegComponent struct {
+	name     string
+	usedFor  string
+	required bool // false when only an optional feature depends on it
+}
+
+var requiredFFmpegEncoders = []requiredFFmpegComponent{
+	{"mjpeg", "thumbnails and preview thumbnails", true},
+	{"libvpx", "WebM previews", true},
+	{"libx264", "trimmed downloads", false},
+	{"aac", "trimmed downloads", false},
+}
+
+var requiredFFmpegFilters = []requiredFFmpegComponent{
+	{"scale", "thumbnails and previews", true},
+}
+
+// NewDoctorReport counts the results of the given checks into a report.
+func NewDoctorReport(repoPath string, checks []datatypes.DoctorCheck) datatypes.DoctorReport {
+	report := datatypes.DoctorReport{
+		GeneratedAt: time.Now(),
+		RepoPath:    repoPath,
+		Checks:      checks,
+	}
+	for _, check := range checks {
+		switch check.Status {
+		case datatypes.DoctorStatusOK:
+			report.OK++
+		case datatypes.DoctorStatusWarn:
+			report.Warnings++
+		default:
+			report.Failures++
+		}
+	}
+	return report
+}
+
+// CheckEnvironment verifies that the external tools next to the ova executable are present,
+// recent enough and built with the encoders and filters ova uses.
+func CheckEnvironment() []datatypes.DoctorCheck {
+	ffmpegFix := "Install a static ffmpeg build (version 4 or newer) into the ffmpeg folder next to the ova executable"
+
+	var checks []datatypes.DoctorCheck
+	ffmpegCheck := checkToolVersion("ffmpeg", thirdparty.GetFFmpegVersion, minFFmpegMajor, 0, datatypes.DoctorStatusFail, ffmpegFix)
+	checks = append(checks,
+		ffmpegCheck,
+		checkToolVersion("ffprobe", thirdparty.GetFFprobeVersion, minFFmpegMajor, 0, datatypes.DoctorStatusFail, ffmpegFix),
+		checkToolVersion("openssl", thirdparty.GetOpenSSLVersion, minOpenSSLMajor, minOpenSSLMinor, datatypes.DoctorStatusWarn,
+			"Install OpenSSL 1.1 or newer into the openssl folder next to the ova executable; it is only needed by `ova ssl`"),
+	)
+
+	// mp4info is optional since MP4 metadata is read natively
+	if version, err := thirdparty.GetBentoMP4InfoVersion(); err != nil {
+		checks = append(checks, datatypes.DoctorCheck{
+			Category: "tools", Name: "mp4info", Status: datatypes.DoctorStatusOK,
+			Message: "Not installed (optional, MP4 metadata is read without it)",
+		})
+	} else {
+		checks = append(checks, datatypes.DoctorCheck{
+			Category: "tools", Name: "mp4info", Status: datatypes.DoctorStatusOK, Message: version,
+		})
+	}
+
+	// Encoders and filters can only be listed by a working ffmpeg
+	if ffmpegCheck.Status == datatypes.DoctorStatusFail {
+		return checks
+	}
+	checks = append(checks, checkFFmpegComponents("encoder", thirdparty.GetFFmpegEncoders, requiredFFmpegEncoders)...)
+	checks = append(checks, checkFFmpegComponents("filter", thirdparty.GetFFmpegFilters, requiredFFmpegFilters)...)
+	return checks
+}
+
+// checkToolVersion reports whether a tool runs and is at least version minMajor.minMinor.
+// missingStatus is the status of a missing tool.
+func checkToolVersion(name string, getVersion func() (string, error), minMajor, minMinor int, missingStatus, fix string) datatypes.DoctorCheck {
+	check := datatypes.DoctorCheck{Category: "tools", Name: name}
+
+	version, err := getVersion()
+	if err != nil {
+		check.Status = missingStatus
+		check.Message = err.Error()
+		check.Fix = fix
+		return check
+	}
+
+	check.Message = version
+	major, minor, ok := thirdparty.ParseToolVersion(version)
+	if ok && (major < minMajor || major == minMajor && minor < minMinor) {
+		check.Status = datatypes.DoctorStatusWarn
+		check.Message = fmt.Sprintf("%s is older than %d.%d", version, minMajor, minMinor)
+		check.Fix = fix
+		return check
+	}
+
+	// Development snapshots carry no version number and are assumed to be recent
+	check.Status = datatypes.DoctorStatusOK
+	return check
+}
+
+// checkFFmpegComponents reports which of the wanted encoders or filters are missing from ffmpeg.
+func checkFFmpegComponents(kind string, list func() (map[string]bool, error), wanted []requiredFFmpegComponent) []datatypes.DoctorCheck {
+	available, err := list()
+	if err != nil {
+		return []datatypes.DoctorCheck{{
+			Category: "codecs", Name: "ffmpeg " + kind + "s", Status: datatypes.DoctorStatusWarn,
+			Message: fmt.Sprintf("Failed to list ffmpeg %ss: %v", kind, err),
+		}}
+	}
+
+	var checks []datatypes.DoctorCheck
+	for _, c := range wanted {
+		check := datatypes.DoctorCheck{Category: "codecs", Name: c.name}
+		switch {
+		case available[c.name]:
+			check.Status = datatypes.DoctorStatusOK
+			check.Message = fmt.Sprintf("%s available for %s", kind, c.usedFor)
+		case c.required:
+			check.Status = datatypes.DoctorStatusFail
+			check.Message = fmt.Sprintf("ffmpeg has no %s %s, %s will fail", c.name, kind, c.usedFor)
+		default:
+			check.Status = datatypes.DoctorStatusWarn
+			check.Message = fmt.Sprintf("ffmpeg has no %s %s, %s will fail", c.name, kind, c.usedFor)
+		}
+		if check.Status != datatypes.DoctorStatusOK {
+			check.Fix = fmt.Sprintf("Replace ffmpeg with a build that includes %s (e.g. a \"full\" or \"gpl\" static build)", c.name)
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// DiagnoseRepository checks the repository at rootDir: folder permissions, storage files,
+// indexed videos whose files are gone, orphaned artefacts and SSL certificates.
+// Unlike NewRepoManager it never creates a repository; a missing one is reported instead.
+func DiagnoseRepository(rootDir string) []datatypes.DoctorCheck {
+	r := &RepoManager{rootDir: rootDir}
+
+	if !r.IsRepoExists() {
+		return []datatypes.DoctorCheck{{
+			Category: "repository", Name: ".ova-repo", Status: datatypes.DoctorStatusFail,
+			Message: fmt.Sprintf("%s is not an ova repository", rootDir),
+			Fix:     "Run `ova init` in the video folder, or pass the repository folder with -r",
+		}}
+	}
+
+	checks := []datatypes.DoctorCheck{r.checkFolderPermissions()}
+
+	storageCheck := r.checkStorageFiles()
+	checks = append(checks, storageCheck)
+
+	// Opening a repository with broken storage files fails or loses data, so stop here
+	if storageCheck.Status == datatypes.DoctorStatusFail {
+		return append(checks, r.checkCertificates()...)
+	}
+
+	// Diagnosing must not change the repository, so pending migrations are reported, not run
+	if status, err := GetSchemaStatus(rootDir); err == nil && len(status.Pending) > 0 {
+		checks = append(checks, datatypes.DoctorCheck{
+			Category: "repository", Name: "schema", Status: datatypes.DoctorStatusWarn,
+			Message: fmt.Sprintf("%d schema migrations are pending", len(status.Pending)),
+			Fix:     "Run `ova repo migrate`; the next command that opens the repository also migrates it",
+		})
+	}
+
+	opened, err := openReadOnly(rootDir)
+	if err != nil {
+		checks = append(checks, datatypes.DoctorCheck{
+			Category: "repository", Name: "open", Status: datatypes.DoctorStatusFail,
+			Message: fmt.Sprintf("Failed to open repository: %v", err),
+		})
+	} else {
+		checks = append(checks, opened.checkMissingVideoFiles(), opened.checkOrphanedArtefacts())
+	}
+
+	return append(checks, r.checkCertificates()...)
+}
+
+// openReadOnly opens an existing repository for inspection: its config and storage are
+// loaded, but nothing is migrated, cached or written, unlike with NewRepoManager.
+func openReadOnly(rootDir string) (*RepoManager, error) {
+	r, err := openForMigration(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	r.diskDataStorage, err = datastorage.NewDiskStorage(r.configs.DataStorageType, r.GetStoragePath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize data storage (%s): %w", r.configs.DataStorageType, err)
+	}
+	r.memoryDataStorage, err = datastorage.NewMemoryStorage()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize memory storage: %w", err)
+	}
+	return r, nil
+}
+
+// checkFolderPermissions verifies that ova can create files in the repository folders.
+func (r *RepoManager) checkFolderPermissions() datatypes.DoctorCheck {
+	check := datatypes.DoctorCheck{Category: "repository", Name: "permissions"}
+
+	folders := append([]string{r.GetRepoDir(), r.GetStoragePath(), r.GetSSLPath()}, r.getArtefactDirs()...)
+	checked := 0
+	for _, folder := range folders {
+		if _, err := os.Stat(folder); os.IsNotExist(err) {
+			continue // created on first use
+		}
+		checked++
+
+		f, err := os.CreateTemp(folder, ".ova-doctor-*")
+		if err != nil {
+			check.Details = append(check.Details, fmt.Sprintf("%s: %v", folder, err))
+			continue
+		}
+		f.Close()
+		os.Remove(f.Name())
+	}
+
+	if len(check.Details) > 0 {
+		check.Status = datatypes.DoctorStatusFail
+		check.Message = fmt.Sprintf("%d of %d repository folders are not writable", len(check.Details), checked)
+		check.Fix = "Give the user running ova write access to the listed folders (e.g. chown -R <user> .ova-repo)"
+		return check
+	}
+	check.Status = datatypes.DoctorStatusOK
+	check.Message = fmt.Sprintf("%d repository folders are writable", checked)
+	return check
+}
+
+// checkStorageFiles verifies that the config and every JSON storage file parse.
+func (r *RepoManager) checkStorageFiles() datatypes.DoctorCheck {
+	check := datatypes.DoctorCheck{Category: "repository", Name: "storage files"}
+
+	files := []string{r.getRepoConfigFilePath()}
+	storageFiles, _ := filepath.Glob(filepath.Join(r.GetStoragePath(), "*.json"))
+	files = append(files, storageFiles...)
+
+	checked := 0
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				check.Details = append(check.Details, fmt.Sprintf("%s: %v", file, err))
+			}
+			continue
+		}
+		checked++
+		if !json.Valid(data) {
+			check.Details = append(check.Details, fmt.Sprintf("%s: not valid JSON", file))
+		}
+	}
+
+	if len(check.Details) > 0 {
+		check.Status = datatypes.DoctorStatusFail
+		check.Message = fmt.Sprintf("%d storage files are unreadable or corrupt", len(check.Details))
+		check.Fix = "Restore the listed files from a backup or repair their JSON; ova cannot load the repository until then"
+		return check
+	}
+	check.Status = datatypes.DoctorStatusOK
+	check.Message = fmt.Sprintf("%d storage files are valid", checked)
+	return check
+}
+
+// checkMissingVideoFiles lists indexed videos of this repository whose file no longer exists.
+func (r *RepoManager) checkMissingVideoFiles() datatypes.DoctorCheck {
+	check := datatypes.DoctorCheck{Category: "videos", Name: "video files"}
+
+	videos, err := r.GetAllIndexedVideos()
+	if err != nil {
+		check.Status = datatypes.DoctorStatusFail
+		check.Message = fmt.Sprintf("Failed to list videos: %v", err)
+		return check
+	}
+
+	for _, video := range videos {
+		videoPath, err := r.GetVideoFilePathByID(video.VideoID)
+		if err != nil {
+			check.Details = append(check.Details, fmt.Sprintf("%s: %v", video.VideoID, err))
+			continue
+		}
+		if _, err := os.Stat(videoPath); err != nil {
+			check.Details = append(check.Details, fmt.Sprintf("%s: %s", video.VideoID, videoPath))
+		}
+	}
+
+	if len(check.Details) > 0 {
+		check.Status = datatypes.DoctorStatusWarn
+		check.Message = fmt.Sprintf("%d of %d indexed videos have no file", len(check.Details), len(videos))
+		check.Fix = "Move the files back to the listed paths, or delete the videos' entries from the repository"
+		return check
+	}
+	check.Status = datatypes.DoctorStatusOK
+	check.Message = fmt.Sprintf("All %d indexed videos have a file", len(videos))
+	return check
+}
+
+// checkOrphanedArtefacts lists generated artefacts whose video is no longer indexed.
+func (r *RepoManager) checkOrphanedArtefacts() datatypes.DoctorCheck {
+	check := datatypes.DoctorCheck{Category: "videos", Name: "artefacts"}
+
+	orphans, err := r.FindOrphanedArtefacts()
+	if err != nil {
+		check.Status = datatypes.DoctorStatusWarn
+		check.Message = fmt.Sprintf("Failed to scan artefacts: %v", err)
+		return check
+	}
+
+	if len(orphans) > 0 {
+		check.Status = datatypes.DoctorStatusWarn
+		check.Message = fmt.Sprintf("%d artefacts belong to videos that are no longer indexed", len(orphans))
+		check.Fix = "Run `ova repo gc --dry-run` to see the reclaimable space, then `ova repo gc` to delete them"
+		check.Details = orphans
+		return check
+	}
+	check.Status = datatypes.DoctorStatusOK
+	check.Message = "No orphaned artefacts"
+	return check
+}
+
+// checkCertificates reports the expiry of every certificate in the SSL folder.
+func (r *RepoManager) checkCertificates() []datatypes.DoctorCheck {
+	sslPath := r.GetSSLPath()
+
+	var checks []datatypes.DoctorCheck
+	filepath.WalkDir(sslPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		if ext != ".pem" && ext != ".crt" {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		block, _ := pem.Decode(data)
+		if block == nil || block.Type != "CERTIFICATE" {
+			return nil // private keys and CSRs share the extension
+		}
+
+		name, _ := filepath.Rel(sslPath, path)
+		name = filepath.ToSlash(name)
+		fix := "Run `ova ssl generate-cert` to issue a new certificate"
+		if strings.HasPrefix(name, "self-ca/") {
+			fix = "Run `ova ssl generate-ca` and then `ova ssl generate-cert`, and trust the new CA on client devices"
+		}
+
+		check := datatypes.DoctorCheck{Category: "certificates", Name: name}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		switch {
+		case err != nil:
+			check.Status = datatypes.DoctorStatusFail
+			check.Message = fmt.Sprintf("Failed to parse certificate: %v", err)
+			check.Fix = fix
+		case time.Now().After(cert.NotAfter):
+			check.Status = datatypes.DoctorStatusFail
+			check.Message = fmt.Sprintf("Expired on %s", cert.NotAfter.Format("2006-01-02"))
+			check.Fix = fix
+		case time.Until(cert.NotAfter) < certificateExpiryWarning:
+			check.Status = datatypes.DoctorStatusWarn
+			check.Message = fmt.Sprintf("Expires on %s", cert.NotAfter.Format("2006-01-02"))
+			check.Fix = fix
+		default:
+			check.Status = datatypes.DoctorStatusOK
+			check.Message = fmt.Sprintf("Valid until %s", cert.NotAfter.Format("2006-01-02"))
+		}
+		checks = append(checks, check)
+		return nil
+	})
+
+	if len(checks) == 0 {
+		checks = append(checks, datatypes.DoctorCheck{
+			Category: "certificates", Name: "ssl", Status: datatypes.DoctorStatusOK,
+			Message: "No certificates found (HTTPS is not configured)",
+		})
+	}
+	return checks
+}
@@ -9,6 +9,17 @@ func (r *RepoManager) OnInit() error {
 		return fmt.Errorf("failed to load user sessions from disk: %w", err)
 	}
 
+	// Spaces created before IDs were generated get one now
+	if err := r.EnsureSpaceIDs(); err != nil {
+		return fmt.Errorf("failed to assign space IDs: %w", err)
+	}
+
+	// Open attached repositories so their videos are part of the cache.
+	// Sub repositories don't load their own children.
+	if r.parent == nil {
+		r.LoadSubRepositories()
+	}
+
 	// Call CacheLatestVideos to load the latest videos into memory storage
 	if err := r.CacheLatestVideos(); err != nil {
 		return fmt.Errorf("failed to cache latest videos: %w", err)
@@ -43,5 +54,10 @@ func (r *RepoManager) OnShutdown() error {
 		return fmt.Errorf("failed to save session data: %w", err)
 	}
 
+	// Let other processes write the repository again
+	if err := r.Unlock(); err != nil {
+		return fmt.Errorf("failed to release the repository lock: %w", err)
+	}
+
 	return nil
 }
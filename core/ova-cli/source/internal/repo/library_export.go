@@ -0,0 +1,175 @@
+package repo
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"os"
+	"ova-cli/source/internal/datatypes"
+	"path"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BundleFormatVersion is the version of the library bundle layout written by ExportSpace.
+const BundleFormatVersion = 1
+
+const (
+	bundleManifestName    = "bundle.json"
+	bundleVideosPrefix    = "videos/"
+	bundleArtefactsPrefix = "artefacts/"
+)
+
+// bundleArtefactKinds names the artefacts of GetVideoArtefactPaths, in the same order.
+// Bundles store them as artefacts/<video ID>/<kind>.
+var bundleArtefactKinds = []string{"thumbnail", "preview", "preview_thumbnails", "markers", "subtitles", "audio_variants", "chapter_variants"}
+
+// ExportSpace writes the videos of a space to a self-describing bundle that ImportBundle can
+// merge into another repository: their files, metadata, tags, markers and other generated
+// artefacts, and the parts of users' playlists that reference them. With byReference the
+// video files are left out and imported from their current location instead. Smart playlists
+// and playlist sharing are not exported.
+func (r *RepoManager) ExportSpace(spaceRef, outputPath string, byReference bool) (*datatypes.LibraryBundleManifest, error) {
+	if !r.IsDataStorageInitialized() {
+		return nil, fmt.Errorf("data storage is not initialized")
+	}
+
+	space, err := r.FindSpace(spaceRef)
+	if err != nil {
+		return nil, err
+	}
+	videos, err := r.diskDataStorage.GetVideosBySpace(space.SpaceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list videos of %s: %w", space.SpaceName, err)
+	}
+
+	manifest := &datatypes.LibraryBundleManifest{
+		FormatVersion:    BundleFormatVersion,
+		BundleID:         uuid.NewString(),
+		CreatedAt:        time.Now().UTC(),
+		SourceRepository: r.GetRootPath(),
+		Space:            *space,
+		ByReference:      byReference,
+		Videos:           []datatypes.BundleVideo{},
+		Playlists:        []datatypes.BundlePlaylist{},
+	}
+	spaceDir := filepath.Join(r.GetRootPath(), space.SpaceName)
+
+	var exportedIDs []string
+	for _, video := range videos {
+		videoPath, err := r.GetVideoFilePathByID(video.VideoID)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(spaceDir, videoPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", videoPath, err)
+		}
+
+		_, statErr := os.Stat(videoPath)
+		if statErr != nil && !byReference {
+			fmt.Printf("Warning: %s is missing and is exported without its file\n", videoPath)
+		}
+		// Imports match videos by content, so make sure the hash is known
+		if video.ContentHash == "" && statErr == nil {
+			video.ContentHash = r.recordContentHash(video)
+		}
+
+		entry := datatypes.BundleVideo{
+			Video:      video,
+			Path:       filepath.ToSlash(rel),
+			SourcePath: videoPath,
+			HasFile:    !byReference && statErr == nil,
+		}
+		for i, artefact := range r.GetVideoArtefactPaths(video.VideoID) {
+			if _, err := os.Stat(artefact); err == nil {
+				entry.Artefacts = append(entry.Artefacts, bundleArtefactKinds[i])
+			}
+		}
+		manifest.Videos = append(manifest.Videos, entry)
+		exportedIDs = append(exportedIDs, video.VideoID)
+	}
+
+	playlists, err := r.diskDataStorage.GetAllPlaylists()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load playlists: %w", err)
+	}
+	for owner, userPlaylists := range playlists {
+		for _, playlist := range userPlaylists {
+			if playlist.IsSmart() {
+				continue
+			}
+			var ids []string
+			for _, id := range playlist.VideoIDs {
+				if slices.Contains(exportedIDs, id) {
+					ids = append(ids, id)
+				}
+			}
+			if len(ids) == 0 {
+				continue
+			}
+			manifest.Playlists = append(manifest.Playlists, datatypes.BundlePlaylist{
+				Owner: owner,
+				Playlist: datatypes.PlaylistData{
+					Title:       playlist.Title,
+					Description: playlist.Description,
+					Slug:        playlist.Slug,
+					VideoIDs:    ids,
+				},
+			})
+		}
+	}
+
+	err = createTarZst(outputPath, func(tw *tar.Writer) error {
+		manifestData, err := json.MarshalIndent(manifest, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode bundle manifest: %w", err)
+		}
+		if err := writeTarFile(tw, bundleManifestName, manifestData); err != nil {
+			return err
+		}
+
+		for _, entry := range manifest.Videos {
+			id := entry.Video.VideoID
+			if entry.HasFile {
+				if err := addTarPath(tw, bundleVideosPrefix+id+entry.Video.Codecs.Format, entry.SourcePath); err != nil {
+					return err
+				}
+			}
+			for i, artefact := range r.GetVideoArtefactPaths(id) {
+				if !slices.Contains(entry.Artefacts, bundleArtefactKinds[i]) {
+					continue
+				}
+				if err := addTarPath(tw, path.Join(bundleArtefactsPrefix, id, bundleArtefactKinds[i]), artefact); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// addTarPath adds a file, or a folder with everything inside it, to an archive under name.
+func addTarPath(tw *tar.Writer, name, absPath string) error {
+	return filepath.WalkDir(absPath, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !d.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(absPath, p)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return copyTarFile(tw, path.Join(name, filepath.ToSlash(rel)), p, info.Size())
+	})
+}
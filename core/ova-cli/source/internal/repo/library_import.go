@@ -0,0 +1,516 @@
+package repo
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"ova-cli/source/internal/datatypes"
+	"ova-cli/source/internal/filehash"
+	"ova-cli/source/internal/utils"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// importPlan is what ImportBundle decided to do with one bundled video.
+type importPlan struct {
+	entry     datatypes.BundleVideo
+	result    int                 // Index in the report's videos
+	videoID   string              // ID of the video in this library
+	video     datatypes.VideoData // Metadata to store, for videos that are added
+	destPath  string              // Absolute path of the file, for videos that are added
+	artefacts []string            // Artefact kinds to take from the bundle
+}
+
+// ImportBundle merges a bundle written by ExportSpace into this repository, placing its videos
+// in spaceName, or the space they were exported from when empty, which is created if needed.
+// Videos are matched by content hash: content that is already in the library is not copied
+// again but receives the bundle's tags and playlist entries, and a bundled ID that belongs to
+// other content here is replaced by a collision ID. Files that would overwrite another file
+// are renamed. Everything that was renamed, reassigned or skipped is reported as a conflict.
+// With dryRun nothing is changed.
+func (r *RepoManager) ImportBundle(bundlePath, spaceName string, dryRun bool) (*datatypes.ImportReport, error) {
+	if !r.IsDataStorageInitialized() {
+		return nil, fmt.Errorf("data storage is not initialized")
+	}
+
+	var manifest datatypes.LibraryBundleManifest
+	if err := readArchiveManifest(bundlePath, bundleManifestName, &manifest); err != nil {
+		return nil, err
+	}
+	if manifest.FormatVersion < 1 || manifest.FormatVersion > BundleFormatVersion {
+		return nil, fmt.Errorf("bundle format %d is not supported (this version reads up to %d)", manifest.FormatVersion, BundleFormatVersion)
+	}
+
+	if spaceName == "" {
+		spaceName = manifest.Space.SpaceName
+	}
+	report := &datatypes.ImportReport{DryRun: dryRun, Space: spaceName, Videos: []datatypes.ImportedVideo{}}
+	conflict := func(format string, args ...any) {
+		report.Conflicts = append(report.Conflicts, fmt.Sprintf(format, args...))
+	}
+
+	spaces, err := r.diskDataStorage.GetAllSpaces()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load spaces: %w", err)
+	}
+	if _, exists := spaces[spaceName]; !exists {
+		if err := validateSpaceName(spaceName); err != nil {
+			return nil, err
+		}
+		report.SpaceCreated = true
+	}
+
+	users, err := r.diskDataStorage.GetAllUsers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load users: %w", err)
+	}
+	userExists := func(username string) bool {
+		return slices.ContainsFunc(users, func(u datatypes.UserData) bool { return u.Username == username })
+	}
+
+	// Index the library by content. Videos indexed before content hashes were stored are only
+	// hashed when they could be one of the bundled videos.
+	library, err := r.diskDataStorage.GetAllVideos()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list videos: %w", err)
+	}
+	incoming := map[string]bool{}
+	for _, entry := range manifest.Videos {
+		incoming[partialVideoID(entry.Video.VideoID)] = true
+	}
+	byHash := map[string]string{}
+	taken := map[string]bool{}
+	for _, video := range library {
+		taken[video.VideoID] = true
+		hash := video.ContentHash
+		if hash == "" && incoming[partialVideoID(video.VideoID)] {
+			hash = r.videoContentHash(video, !dryRun)
+		}
+		if hash != "" {
+			byHash[hash] = video.VideoID
+		}
+	}
+
+	spaceDir := filepath.Join(r.GetRootPath(), spaceName)
+	claimed := map[string]bool{}
+	idMap := map[string]string{}
+	var plans []importPlan
+
+	for _, entry := range manifest.Videos {
+		bundleID := entry.Video.VideoID
+		report.Videos = append(report.Videos, datatypes.ImportedVideo{BundleVideoID: bundleID})
+		result := &report.Videos[len(report.Videos)-1]
+		skip := func(format string, args ...any) {
+			result.Status = datatypes.ImportStatusSkipped
+			result.Message = fmt.Sprintf(format, args...)
+			conflict("%s: %s", bundleID, result.Message)
+		}
+
+		hash := entry.Video.ContentHash
+		if !entry.HasFile {
+			if _, err := os.Stat(entry.SourcePath); err != nil {
+				skip("the file is not in the bundle and %s is not reachable", entry.SourcePath)
+				continue
+			}
+			if hash == "" {
+				hash, _, _ = filehash.XXH3FullFileHash(entry.SourcePath)
+			}
+		}
+		if hash == "" {
+			skip("the content hash is unknown, so the video cannot be matched")
+			continue
+		}
+
+		plan := importPlan{entry: entry, result: len(report.Videos) - 1}
+
+		if existingID, ok := byHash[hash]; ok {
+			result.VideoID = existingID
+			result.Status = datatypes.ImportStatusExisting
+			result.Message = "the same content is already in the library; tags and playlists were merged"
+			if existing, err := r.diskDataStorage.GetVideoByID(existingID); err == nil {
+				result.Path = GetVideoRelativePath(existing)
+			}
+			for i, artefact := range r.GetVideoArtefactPaths(existingID) {
+				if _, err := os.Stat(artefact); err != nil && slices.Contains(entry.Artefacts, bundleArtefactKinds[i]) {
+					plan.artefacts = append(plan.artefacts, bundleArtefactKinds[i])
+				}
+			}
+			plan.videoID = existingID
+			idMap[bundleID] = existingID
+			plans = append(plans, plan)
+			continue
+		}
+
+		videoID := bundleID
+		result.Status = datatypes.ImportStatusNew
+		if taken[videoID] {
+			videoID = collisionVideoID(partialVideoID(bundleID), hash)
+			if taken[videoID] {
+				skip("its ID and collision ID both belong to other videos in this library")
+				continue
+			}
+			result.Status = datatypes.ImportStatusRenamed
+			conflict("%s: the ID belongs to another video in this library; imported as %s", bundleID, videoID)
+		}
+
+		rel := path.Clean(entry.Path)
+		if rel == "." || rel == ".." || strings.HasPrefix(rel, "../") || path.IsAbs(rel) {
+			skip("the bundle has an unsafe path %q", entry.Path)
+			continue
+		}
+		destPath := filepath.Join(spaceDir, filepath.FromSlash(rel))
+		if _, err := os.Stat(destPath); err == nil || claimed[destPath] {
+			free := freeImportPath(destPath, claimed)
+			conflict("%s: %s already exists; imported as %s", bundleID, path.Join(spaceName, rel), filepath.Base(free))
+			destPath = free
+		}
+		claimed[destPath] = true
+		taken[videoID] = true
+		byHash[hash] = videoID
+
+		relRoot, err := utils.MakeRelative(r.GetRootPath(), destPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", destPath, err)
+		}
+		segments := utils.GetPathSegments(filepath.Dir(relRoot))
+
+		video := entry.Video
+		video.VideoID = videoID
+		video.ContentHash = hash
+		video.FileName = strings.TrimSuffix(filepath.Base(destPath), filepath.Ext(destPath))
+		video.OwnedSpace = segments.Root
+		video.OwnedGroup = segments.Subroot
+		if video.Tags == nil {
+			video.Tags = []string{}
+		}
+
+		result.VideoID = videoID
+		result.Path = filepath.ToSlash(relRoot)
+		plan.videoID = videoID
+		plan.video = video
+		plan.destPath = destPath
+		plan.artefacts = entry.Artefacts
+		idMap[bundleID] = videoID
+		plans = append(plans, plan)
+	}
+
+	if dryRun {
+		r.importBundlePlaylists(manifest.Playlists, idMap, userExists, report, false)
+		return report, nil
+	}
+
+	if report.SpaceCreated {
+		owner := manifest.Space.SpaceOwner
+		if !userExists(owner) {
+			owner = r.GetRootUsername()
+			if userExists(owner) {
+				conflict("space owner %q does not exist here; %s owns the space", manifest.Space.SpaceOwner, owner)
+			} else {
+				conflict("space owner %q does not exist here; the space has no owner", manifest.Space.SpaceOwner)
+			}
+		}
+		space := datatypes.CreateDefaultSpaceData(spaceName, owner)
+		space.SpaceSettings = manifest.Space.SpaceSettings
+		for _, member := range manifest.Space.MemberIds {
+			if member != owner && userExists(member) {
+				space.MemberIds = append(space.MemberIds, member)
+			}
+		}
+		if err := r.CreateSpace(space); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.extractBundle(bundlePath, plans); err != nil {
+		// Take back the files that made it before the failure
+		for _, plan := range plans {
+			if plan.destPath != "" {
+				os.Remove(plan.destPath)
+				r.DeleteVideoArtefacts(plan.video.VideoID)
+			}
+		}
+		return nil, err
+	}
+
+	for _, plan := range plans {
+		result := &report.Videos[plan.result]
+
+		if result.Status == datatypes.ImportStatusExisting {
+			r.mergeImportedTags(result.VideoID, plan.entry.Video.Tags)
+			continue
+		}
+
+		if !plan.entry.HasFile {
+			if err := copyFileContents(plan.entry.SourcePath, plan.destPath); err != nil {
+				r.discardImportedVideo(plan, report, fmt.Sprintf("failed to copy %s: %v", plan.entry.SourcePath, err))
+				continue
+			}
+		}
+		if hash, _, err := filehash.XXH3FullFileHash(plan.destPath); err != nil || hash != plan.video.ContentHash {
+			r.discardImportedVideo(plan, report, "the file does not match its recorded content hash")
+			continue
+		}
+
+		if plan.video.VideoID != plan.entry.Video.VideoID {
+			r.renamePreviewThumbnailsVTT(plan.entry.Video.VideoID, plan.video.VideoID)
+		}
+		if err := r.diskDataStorage.AddVideo(plan.video); err != nil {
+			r.discardImportedVideo(plan, report, fmt.Sprintf("failed to save metadata: %v", err))
+			continue
+		}
+		r.diskDataStorage.AddVideoIDToSpace(plan.video.VideoID, result.Path)
+		r.videoIndexed(plan.video)
+	}
+
+	r.importBundlePlaylists(manifest.Playlists, idMap, userExists, report, true)
+	return report, nil
+}
+
+// extractBundle copies the bundled files and artefacts the plans ask for into place, in one
+// pass over the bundle.
+func (r *RepoManager) extractBundle(bundlePath string, plans []importPlan) error {
+	targets := map[string]string{}   // Bundled video file -> destination
+	artefacts := map[string]string{} // artefacts/<bundle ID>/<kind> -> destination
+	for _, plan := range plans {
+		bundleID := plan.entry.Video.VideoID
+		if plan.destPath != "" && plan.entry.HasFile {
+			targets[bundleVideosPrefix+bundleID+plan.entry.Video.Codecs.Format] = plan.destPath
+		}
+		for i, artefact := range r.GetVideoArtefactPaths(plan.videoID) {
+			if slices.Contains(plan.artefacts, bundleArtefactKinds[i]) {
+				artefacts[path.Join(bundleArtefactsPrefix, bundleID, bundleArtefactKinds[i])] = artefact
+			}
+		}
+	}
+
+	tr, closeArchive, err := openBackupArchive(bundlePath)
+	if err != nil {
+		return err
+	}
+	defer closeArchive()
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", bundlePath, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		target, ok := targets[header.Name]
+		if !ok && strings.HasPrefix(header.Name, bundleArtefactsPrefix) {
+			// artefacts/<bundle ID>/<kind>[/<file inside a folder artefact>]
+			parts := strings.SplitN(header.Name, "/", 4)
+			if len(parts) < 3 {
+				continue
+			}
+			base, found := artefacts[path.Join(parts[0], parts[1], parts[2])]
+			if !found {
+				continue
+			}
+			target = base
+			if len(parts) == 4 {
+				inner := path.Clean(parts[3])
+				if inner == ".." || strings.HasPrefix(inner, "../") || path.IsAbs(inner) {
+					return fmt.Errorf("%s contains an unsafe path %q", bundlePath, header.Name)
+				}
+				target = filepath.Join(base, filepath.FromSlash(inner))
+			}
+			ok = true
+		}
+		if !ok {
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(target), err)
+		}
+		out, err := os.Create(target)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", target, err)
+		}
+		_, err = io.Copy(out, tr)
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to extract %s: %w", header.Name, err)
+		}
+	}
+}
+
+// importBundlePlaylists adds the bundled playlist entries to the playlists of their owners,
+// creating playlists that do not exist. Playlists of owners missing here go to the root user.
+func (r *RepoManager) importBundlePlaylists(bundled []datatypes.BundlePlaylist, idMap map[string]string, userExists func(string) bool, report *datatypes.ImportReport, apply bool) {
+	existing, err := r.diskDataStorage.GetAllPlaylists()
+	if err != nil {
+		report.Conflicts = append(report.Conflicts, fmt.Sprintf("failed to load playlists: %v", err))
+		return
+	}
+
+	for _, entry := range bundled {
+		var ids []string
+		for _, id := range entry.Playlist.VideoIDs {
+			if mapped, ok := idMap[id]; ok {
+				ids = append(ids, mapped)
+			}
+		}
+		if len(ids) == 0 {
+			continue
+		}
+
+		owner := entry.Owner
+		if !userExists(owner) {
+			if !userExists(r.GetRootUsername()) {
+				report.Conflicts = append(report.Conflicts, fmt.Sprintf("playlist %s: owner %q does not exist here and there is no root user; not imported", entry.Playlist.Slug, owner))
+				continue
+			}
+			report.Conflicts = append(report.Conflicts, fmt.Sprintf("playlist %s: owner %q does not exist here; added to %s", entry.Playlist.Slug, owner, r.GetRootUsername()))
+			owner = r.GetRootUsername()
+		}
+
+		index := slices.IndexFunc(existing[owner], func(p datatypes.PlaylistData) bool { return p.Slug == entry.Playlist.Slug })
+		if index >= 0 {
+			if existing[owner][index].IsSmart() {
+				report.Conflicts = append(report.Conflicts, fmt.Sprintf("playlist %s of %s is a smart playlist here; its entries were not imported", entry.Playlist.Slug, owner))
+				continue
+			}
+			if apply {
+				if _, err := r.diskDataStorage.InsertVideosIntoPlaylist(owner, entry.Playlist.Slug, ids, -1); err != nil {
+					report.Conflicts = append(report.Conflicts, fmt.Sprintf("failed to update playlist %s of %s: %v", entry.Playlist.Slug, owner, err))
+					continue
+				}
+			}
+			report.PlaylistsMerged++
+			continue
+		}
+
+		if apply {
+			playlist := datatypes.PlaylistData{
+				Title:       entry.Playlist.Title,
+				Description: entry.Playlist.Description,
+				Slug:        entry.Playlist.Slug,
+				VideoIDs:    ids,
+			}
+			if err := r.AddPlaylistToUser(owner, &playlist); err != nil {
+				report.Conflicts = append(report.Conflicts, fmt.Sprintf("failed to create playlist %s of %s: %v", entry.Playlist.Slug, owner, err))
+				continue
+			}
+		}
+		existing[owner] = append(existing[owner], entry.Playlist)
+		report.PlaylistsCreated++
+	}
+}
+
+// mergeImportedTags adds the bundled tags a library video does not have yet.
+func (r *RepoManager) mergeImportedTags(videoID string, tags []string) {
+	video, err := r.diskDataStorage.GetVideoByID(videoID)
+	if err != nil {
+		return
+	}
+	added := false
+	for _, tag := range tags {
+		if slices.ContainsFunc(video.Tags, func(t string) bool { return strings.EqualFold(t, tag) }) {
+			continue
+		}
+		if err := r.diskDataStorage.AddTagToVideo(videoID, tag); err != nil {
+			fmt.Printf("Warning: failed to add tag %q to %s: %v\n", tag, videoID, err)
+			continue
+		}
+		video.Tags = append(video.Tags, tag)
+		added = true
+	}
+	if added {
+		r.videoUpdated(*video)
+	}
+}
+
+// discardImportedVideo removes the file and artefacts of a video that could not be imported.
+func (r *RepoManager) discardImportedVideo(plan importPlan, report *datatypes.ImportReport, reason string) {
+	os.Remove(plan.destPath)
+	r.DeleteVideoArtefacts(plan.video.VideoID)
+
+	result := &report.Videos[plan.result]
+	result.Status = datatypes.ImportStatusSkipped
+	result.Message = reason
+	result.Path = ""
+	report.Conflicts = append(report.Conflicts, fmt.Sprintf("%s: %s", plan.entry.Video.VideoID, reason))
+}
+
+// renamePreviewThumbnailsVTT points the preview thumbnail cues of a video imported under a new
+// ID at the sprites of that ID.
+func (r *RepoManager) renamePreviewThumbnailsVTT(oldID, newID string) {
+	vttPath := filepath.Join(r.GetPreviewThumbnailsFolderPathByVideoID(newID), "thumbnails.vtt")
+	data, err := os.ReadFile(vttPath)
+	if err != nil {
+		return
+	}
+	if err := os.WriteFile(vttPath, []byte(strings.ReplaceAll(string(data), oldID, newID)), 0644); err != nil {
+		fmt.Printf("Warning: failed to update %s: %v\n", vttPath, err)
+	}
+}
+
+// videoContentHash returns the content hash of a library video that has none recorded,
+// saving it when record is set. It returns an empty string if the file cannot be read.
+func (r *RepoManager) videoContentHash(video datatypes.VideoData, record bool) string {
+	if record {
+		return r.recordContentHash(video)
+	}
+	videoPath, err := r.GetVideoFilePathByID(video.VideoID)
+	if err != nil {
+		return ""
+	}
+	hash, _, err := filehash.XXH3FullFileHash(videoPath)
+	if err != nil {
+		return ""
+	}
+	return hash
+}
+
+// partialVideoID strips the collision suffix from a video ID.
+func partialVideoID(videoID string) string {
+	return strings.SplitN(videoID, "-", 2)[0]
+}
+
+// freeImportPath returns the first "name (n).ext" next to p that neither exists nor is
+// claimed by another video of the import.
+func freeImportPath(p string, claimed map[string]bool) string {
+	ext := filepath.Ext(p)
+	base := strings.TrimSuffix(p, ext)
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, n, ext)
+		if _, err := os.Stat(candidate); err != nil && !claimed[candidate] {
+			return candidate
+		}
+	}
+}
+
+// copyFileContents copies src to dst, creating dst's folder.
+func copyFileContents(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -2,6 +2,7 @@ package repo
 
 import (
 	"os"
+	"ova-cli/source/internal/filehash"
 	"path/filepath"
 )
 
@@ -39,6 +40,26 @@ func (r *RepoManager) getRepoConfigFilePath() string {
 	return filepath.Join(r.rootDir, ".ova-repo", "configs.json")
 }
 
+// getSchemaFilePath returns the file recording the schema version of every versioned file.
+func (r *RepoManager) getSchemaFilePath() string {
+	return filepath.Join(r.rootDir, ".ova-repo", "schema.json")
+}
+
+// GetAdminSocketPath returns the Unix socket of the running server's local admin API.
+// Repositories whose path is too long for a socket address use one in the temporary folder.
+func (r *RepoManager) GetAdminSocketPath() string {
+	socketPath := filepath.Join(r.rootDir, ".ova-repo", "admin.sock")
+	if len(socketPath) < 100 {
+		return socketPath
+	}
+	return filepath.Join(os.TempDir(), "ova-"+filehash.XXH3Hash([]byte(r.rootDir))[:16]+".sock")
+}
+
+// GetBackupsDir returns the folder of the backups taken before schema migrations.
+func (r *RepoManager) GetBackupsDir() string {
+	return filepath.Join(r.rootDir, ".ova-repo", "backups")
+}
+
 func (r *RepoManager) getThumbsDir() string {
 	return filepath.Join(r.rootDir, ".ova-repo", "storage", "thumbnails")
 }
@@ -51,50 +72,105 @@ func (r *RepoManager) GetVideoMarkerDir() string {
 	return filepath.Join(r.rootDir, ".ova-repo", "storage", "video_markers")
 }
 
+func (r *RepoManager) GetSubtitlesDir() string {
+	return filepath.Join(r.rootDir, ".ova-repo", "storage", "subtitles")
+}
+
+func (r *RepoManager) GetAudioVariantsDir() string {
+	return filepath.Join(r.rootDir, ".ova-repo", "storage", "audio_variants")
+}
+
+func (r *RepoManager) GetChapterVariantsDir() string {
+	return filepath.Join(r.rootDir, ".ova-repo", "storage", "chapter_variants")
+}
+
 func (r *RepoManager) GetPreviewThumbnailsDir() string {
 	return filepath.Join(r.rootDir, ".ova-repo", "storage", "preview_thumbnails")
 }
 
+// GetTrashDir returns the folder holding soft-deleted videos, one folder per video ID.
+func (r *RepoManager) GetTrashDir() string {
+	return filepath.Join(r.rootDir, ".ova-repo", "trash")
+}
+
 func (r *RepoManager) GetPreviewFilePathByVideoID(videoID string) string {
+	owner, videoID := r.artefactOwner(videoID)
+
 	// Get the first two characters of the videoID
 	subfolder := videoID[:2]
 
 	// Build the full path to the preview file
-	previewPath := filepath.Join(r.getPreviewsDir(), subfolder, videoID+".webm")
+	previewPath := filepath.Join(owner.getPreviewsDir(), subfolder, videoID+".webm")
 
 	// Return the preview path directly without checking if the file exists
 	return previewPath
 }
 
 func (r *RepoManager) GetThumbnailFilePathByVideoID(videoID string) string {
+	owner, videoID := r.artefactOwner(videoID)
+
 	// Get the first two characters of the videoID
 	subfolder := videoID[:2]
 
 	// Build the full path to the thumbnail file
-	thumbnailPath := filepath.Join(r.getThumbsDir(), subfolder, videoID+".jpg")
+	thumbnailPath := filepath.Join(owner.getThumbsDir(), subfolder, videoID+".jpg")
 
 	// Return the thumbnail path directly without checking if the file exists
 	return thumbnailPath
 }
 
 func (r *RepoManager) GetPreviewThumbnailsFolderPathByVideoID(videoID string) string {
+	owner, videoID := r.artefactOwner(videoID)
+
 	// Get the first two characters of the videoID to create the subfolder
 	subfolder := videoID[:2]
 
 	// Build the full path to the storyboard folder
-	storyboardFolderPath := filepath.Join(r.GetPreviewThumbnailsDir(), subfolder, videoID)
+	storyboardFolderPath := filepath.Join(owner.GetPreviewThumbnailsDir(), subfolder, videoID)
 
 	// Return the storyboard folder path directly without checking if the folder exists
 	return storyboardFolderPath
 }
 
 func (r *RepoManager) GetVideoMarkerFilePathByVideoID(videoID string) string {
+	owner, videoID := r.artefactOwner(videoID)
+
 	// Get the first two characters of the videoID to create the subfolder
 	subfolder := videoID[:2]
 
 	// Build the full path to the video marker .vtt file
-	videoMarkerPath := filepath.Join(r.GetVideoMarkerDir(), subfolder, videoID+".vtt")
+	videoMarkerPath := filepath.Join(owner.GetVideoMarkerDir(), subfolder, videoID+".vtt")
 
 	// Return the video marker path directly without checking if the file exists
 	return videoMarkerPath
 }
+
+func (r *RepoManager) GetSubtitlesFolderPathByVideoID(videoID string) string {
+	owner, videoID := r.artefactOwner(videoID)
+
+	// Get the first two characters of the videoID to create the subfolder
+	subfolder := videoID[:2]
+
+	// Build the full path to the folder holding the video's subtitle tracks
+	return filepath.Join(owner.GetSubtitlesDir(), subfolder, videoID)
+}
+
+func (r *RepoManager) GetAudioVariantsFolderPathByVideoID(videoID string) string {
+	owner, videoID := r.artefactOwner(videoID)
+
+	// Get the first two characters of the videoID to create the subfolder
+	subfolder := videoID[:2]
+
+	// Build the full path to the folder holding the video's single-audio copies
+	return filepath.Join(owner.GetAudioVariantsDir(), subfolder, videoID)
+}
+
+func (r *RepoManager) GetChapterVariantsFolderPathByVideoID(videoID string) string {
+	owner, videoID := r.artefactOwner(videoID)
+
+	// Get the first two characters of the videoID to create the subfolder
+	subfolder := videoID[:2]
+
+	// Build the full path to the folder holding the video's copy with embedded chapters
+	return filepath.Join(owner.GetChapterVariantsDir(), subfolder, videoID)
+}
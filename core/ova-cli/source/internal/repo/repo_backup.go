@@ -0,0 +1,560 @@
+package repo
+
+import (
+	"archive/tar"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"ova-cli/source/internal/datatypes"
+	"ova-cli/source/internal/filehash"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/klauspost/compress/zstd"
+)
+
+// BackupFormatVersion is the version of the backup archive layout written by CreateBackup.
+const BackupFormatVersion = 1
+
+// supportedRepoMajorVersion is the major repository version this build can restore.
+const supportedRepoMajorVersion = 1
+
+const (
+	backupManifestName = "manifest.json"
+	backupDataPrefix   = "data/"
+)
+
+// backupMediaDirs are the storage folders of regenerable artefacts, left out of backups
+// unless media is requested.
+var backupMediaDirs = []string{
+	"storage/thumbnails",
+	"storage/previews",
+	"storage/preview_thumbnails",
+	"storage/audio_variants",
+	"storage/chapter_variants",
+}
+
+// BackupOptions selects what CreateBackup puts in an archive.
+type BackupOptions struct {
+	IncludeMedia bool   // Thumbnails, previews, preview thumbnails, audio and chapter variants
+	IncludeSSL   bool   // Certificates and private keys
+	BaseArchive  string // Makes the backup incremental on top of this archive
+}
+
+// backupSnapshotFile is a file selected for a backup. Metadata files are read into memory so
+// the archive holds one consistent state; media files, which have an absPath, are streamed from disk.
+type backupSnapshotFile struct {
+	datatypes.BackupFile
+	absPath string
+	data    []byte
+}
+
+// CreateBackup writes a zstd-compressed tar archive of the repository data folder to
+// outputPath: the config, all storage JSON including sessions, markers and subtitles, and
+// optionally media and certificates. The trash and pre-migration backups are never included.
+// An incremental backup only stores the files that changed since its base but lists every
+// file, so RestoreBackup can rebuild the repository from the base and its increments.
+func (r *RepoManager) CreateBackup(outputPath string, opts BackupOptions) (*datatypes.BackupManifest, error) {
+	manifest := &datatypes.BackupManifest{
+		FormatVersion: BackupFormatVersion,
+		BackupID:      uuid.NewString(),
+		Kind:          datatypes.BackupFull,
+		RepoVersion:   r.configs.Version,
+		CreatedAt:     time.Now().UTC(),
+		IncludesMedia: opts.IncludeMedia,
+		IncludesSSL:   opts.IncludeSSL,
+		Files:         []datatypes.BackupFile{},
+	}
+	if state, _, err := r.loadSchemaState(); err == nil {
+		manifest.SchemaVersions = state.Versions
+	}
+
+	baseHashes := map[string]string{}
+	if opts.BaseArchive != "" {
+		base, err := ReadBackupManifest(opts.BaseArchive)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read base backup: %w", err)
+		}
+		if base.IncludesMedia != opts.IncludeMedia || base.IncludesSSL != opts.IncludeSSL {
+			return nil, fmt.Errorf("an incremental backup must include the same media and SSL files as its base")
+		}
+		manifest.Kind = datatypes.BackupIncremental
+		manifest.BaseBackupID = base.BackupID
+		for _, file := range base.Files {
+			baseHashes[file.Path] = file.Hash
+		}
+	}
+
+	files, err := r.snapshotRepository(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var archived []backupSnapshotFile
+	for _, file := range files {
+		manifest.Files = append(manifest.Files, file.BackupFile)
+		if baseHashes[file.Path] == file.Hash {
+			continue
+		}
+		archived = append(archived, file)
+		manifest.Archived++
+		manifest.ArchivedBytes += file.Size
+	}
+
+	if err := writeBackupArchive(outputPath, manifest, archived); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// snapshotRepository lists and hashes the files to back up. Metadata files are read until two
+// passes agree, so a backup taken while the server writes never mixes old and new files.
+func (r *RepoManager) snapshotRepository(opts BackupOptions) ([]backupSnapshotFile, error) {
+	repoDir := r.GetRepoDir()
+
+	const attempts = 3
+	for attempt := 1; attempt <= attempts; attempt++ {
+		var files []backupSnapshotFile
+		modTimes := map[string]time.Time{}
+
+		err := filepath.WalkDir(repoDir, func(p string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(repoDir, p)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+
+			if d.IsDir() {
+				if rel != "." && !includeInBackup(rel, true, opts) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() || !includeInBackup(rel, false, opts) {
+				return nil
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			file := backupSnapshotFile{BackupFile: datatypes.BackupFile{Path: rel}}
+			if isBackupMedia(rel) {
+				file.absPath = p
+				if file.Hash, file.Size, err = filehash.XXH3FullFileHash(p); err != nil {
+					return err
+				}
+			} else {
+				modTimes[p] = info.ModTime()
+				if file.data, err = os.ReadFile(p); err != nil {
+					return err
+				}
+				if strings.HasSuffix(rel, ".json") && !json.Valid(file.data) {
+					return fmt.Errorf("%s is being written", rel)
+				}
+				file.Hash = filehash.XXH3Hash(file.data)
+				file.Size = int64(len(file.data))
+			}
+			files = append(files, file)
+			return nil
+		})
+
+		// The snapshot holds if no metadata file changed while it was taken
+		if err == nil {
+			for p, modTime := range modTimes {
+				info, statErr := os.Stat(p)
+				if statErr != nil || !info.ModTime().Equal(modTime) {
+					err = fmt.Errorf("%s changed during the backup", p)
+					break
+				}
+			}
+		}
+		if err == nil {
+			sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+			return files, nil
+		}
+		if attempt == attempts {
+			return nil, fmt.Errorf("failed to take a consistent snapshot: %w", err)
+		}
+		time.Sleep(time.Duration(attempt) * 200 * time.Millisecond)
+	}
+	return nil, nil
+}
+
+// includeInBackup reports whether a path relative to .ova-repo belongs in a backup.
+func includeInBackup(rel string, isDir bool, opts BackupOptions) bool {
+	top := strings.SplitN(rel, "/", 2)[0]
+	switch {
+	case top == "trash" || top == "backups" || rel == "ova.lock":
+		return false
+	case top == "ssl":
+		return opts.IncludeSSL
+	case isBackupMedia(rel):
+		return opts.IncludeMedia
+	case !isDir && strings.Contains(path.Base(rel), ".tmp"):
+		return false
+	}
+	return true
+}
+
+func isBackupMedia(rel string) bool {
+	for _, dir := range backupMediaDirs {
+		if rel == dir || strings.HasPrefix(rel, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// writeBackupArchive writes the manifest followed by the archived files.
+func writeBackupArchive(outputPath string, manifest *datatypes.BackupManifest, files []backupSnapshotFile) error {
+	return createTarZst(outputPath, func(tw *tar.Writer) error {
+		manifestData, err := json.MarshalIndent(manifest, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode manifest: %w", err)
+		}
+		if err := writeTarFile(tw, backupManifestName, manifestData); err != nil {
+			return err
+		}
+
+		for _, file := range files {
+			name := backupDataPrefix + file.Path
+			if file.absPath == "" {
+				if err := writeTarFile(tw, name, file.data); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := copyTarFile(tw, name, file.absPath, file.Size); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// createTarZst writes a zstd-compressed tar archive whose entries are added by write. The
+// archive is written next to outputPath first so a failure never leaves a truncated file behind.
+func createTarZst(outputPath string, write func(tw *tar.Writer) error) (err error) {
+	tmpPath := outputPath + ".tmp"
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			out.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	buffered := bufio.NewWriterSize(out, 1<<20)
+	zw, err := zstd.NewWriter(buffered)
+	if err != nil {
+		return fmt.Errorf("failed to start compression: %w", err)
+	}
+	tw := tar.NewWriter(zw)
+
+	if err := write(tw); err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to finish compression: %w", err)
+	}
+	if err := buffered.Flush(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := os.Rename(tmpPath, outputPath); err != nil {
+		return fmt.Errorf("failed to move archive into place: %w", err)
+	}
+	return nil
+}
+
+func writeTarFile(tw *tar.Writer, name string, data []byte) error {
+	header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: time.Now()}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// copyTarFile streams a file into the archive. The size was recorded when the file was
+// hashed; a file that changed size since then fails the backup.
+func copyTarFile(tw *tar.Writer, name, absPath string, size int64) error {
+	f, err := os.Open(absPath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", absPath, err)
+	}
+	defer f.Close()
+
+	header := &tar.Header{Name: name, Mode: 0644, Size: size, ModTime: time.Now()}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if _, err := io.CopyN(tw, f, size); err != nil {
+		return fmt.Errorf("failed to write %s (did it change during the backup?): %w", name, err)
+	}
+	return nil
+}
+
+// openBackupArchive opens an archive for reading. The returned close function closes both
+// the decompressor and the file.
+func openBackupArchive(archivePath string) (*tar.Reader, func(), error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	zr, err := zstd.NewReader(bufio.NewReaderSize(f, 1<<20))
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("%s is not a zstd archive: %w", archivePath, err)
+	}
+	return tar.NewReader(zr), func() { zr.Close(); f.Close() }, nil
+}
+
+// ReadBackupManifest reads the manifest at the start of a backup archive.
+func ReadBackupManifest(archivePath string) (*datatypes.BackupManifest, error) {
+	var manifest datatypes.BackupManifest
+	if err := readArchiveManifest(archivePath, backupManifestName, &manifest); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// readArchiveManifest decodes the JSON entry called name that starts an archive into v.
+func readArchiveManifest(archivePath, name string, v any) error {
+	tr, closeArchive, err := openBackupArchive(archivePath)
+	if err != nil {
+		return err
+	}
+	defer closeArchive()
+
+	header, err := tr.Next()
+	if err != nil || header.Name != name {
+		return fmt.Errorf("%s is not an ova archive of this kind: missing %s", archivePath, name)
+	}
+	if err := json.NewDecoder(tr).Decode(v); err != nil {
+		return fmt.Errorf("%s has an unreadable %s: %w", archivePath, name, err)
+	}
+	return nil
+}
+
+// checkBackupManifest rejects archives this build cannot restore.
+func checkBackupManifest(manifest *datatypes.BackupManifest) error {
+	if manifest.FormatVersion < 1 || manifest.FormatVersion > BackupFormatVersion {
+		return fmt.Errorf("backup format %d is not supported (this version reads up to %d)", manifest.FormatVersion, BackupFormatVersion)
+	}
+	major, err := strconv.Atoi(strings.SplitN(manifest.RepoVersion, ".", 2)[0])
+	if err != nil {
+		return fmt.Errorf("backup has an invalid repository version %q", manifest.RepoVersion)
+	}
+	if major != supportedRepoMajorVersion {
+		return fmt.Errorf("backup is of repository version %s; this version supports %d.x", manifest.RepoVersion, supportedRepoMajorVersion)
+	}
+	// Older schemas are migrated when the restored repository is opened, newer ones cannot be read
+	return checkSchemaVersions(manifest.SchemaVersions)
+}
+
+// RestoreBackup rebuilds the .ova-repo folder of rootDir from a full backup followed by any
+// incremental backups made on top of it, in order. The archives are unpacked and verified
+// next to the repository before anything is replaced. An existing repository is only
+// replaced with force; it is kept as .ova-repo.before-restore-<time>, and its media,
+// certificates, trash and pre-migration backups carry over when the backup does not hold them. Returns the
+// manifest of the last archive and the folder the previous repository was moved to.
+func RestoreBackup(rootDir string, archives []string, force bool) (*datatypes.BackupManifest, string, error) {
+	if len(archives) == 0 {
+		return nil, "", fmt.Errorf("no backup given")
+	}
+
+	// Validate the chain before touching anything
+	var manifests []*datatypes.BackupManifest
+	for i, archive := range archives {
+		manifest, err := ReadBackupManifest(archive)
+		if err != nil {
+			return nil, "", err
+		}
+		if err := checkBackupManifest(manifest); err != nil {
+			return nil, "", fmt.Errorf("%s: %w", archive, err)
+		}
+		switch {
+		case i == 0 && manifest.Kind != datatypes.BackupFull:
+			return nil, "", fmt.Errorf("%s is incremental; start with the full backup it builds on", archive)
+		case i > 0 && manifest.BaseBackupID != manifests[i-1].BackupID:
+			return nil, "", fmt.Errorf("%s does not build on %s", archive, archives[i-1])
+		}
+		manifests = append(manifests, manifest)
+	}
+	final := manifests[len(manifests)-1]
+
+	repoDir := filepath.Join(rootDir, ".ova-repo")
+	_, statErr := os.Stat(repoDir)
+	repoExists := statErr == nil
+	if repoExists && !force {
+		return nil, "", fmt.Errorf("%s already has a repository; use --force to replace it", rootDir)
+	}
+	if repoExists {
+		// Never replace a repository under a running server or another command
+		lock, err := AcquireRepoLock(rootDir, "repo restore", "")
+		if err != nil {
+			return nil, "", err
+		}
+		defer lock.Release()
+	}
+	if err := os.MkdirAll(rootDir, 0755); err != nil {
+		return nil, "", fmt.Errorf("failed to create %s: %w", rootDir, err)
+	}
+
+	staging := filepath.Join(rootDir, ".ova-repo.restoring")
+	if err := os.RemoveAll(staging); err != nil {
+		return nil, "", fmt.Errorf("failed to clear %s: %w", staging, err)
+	}
+	fail := func(err error) (*datatypes.BackupManifest, string, error) {
+		os.RemoveAll(staging)
+		return nil, "", err
+	}
+
+	for _, archive := range archives {
+		if err := extractBackupArchive(archive, staging); err != nil {
+			return fail(err)
+		}
+	}
+
+	// Keep exactly the files of the last backup, each with the recorded content
+	listed := map[string]string{}
+	for _, file := range final.Files {
+		listed[file.Path] = file.Hash
+	}
+	err := filepath.WalkDir(staging, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(staging, p)
+		if _, ok := listed[filepath.ToSlash(rel)]; !ok {
+			return os.Remove(p)
+		}
+		return nil
+	})
+	if err != nil {
+		return fail(fmt.Errorf("failed to prune restored files: %w", err))
+	}
+	for _, file := range final.Files {
+		hash, _, err := filehash.XXH3FullFileHash(filepath.Join(staging, filepath.FromSlash(file.Path)))
+		if err != nil {
+			return fail(fmt.Errorf("%s is missing from the backups; is an incremental backup missing?", file.Path))
+		}
+		if hash != file.Hash {
+			return fail(fmt.Errorf("%s is corrupted in the backup", file.Path))
+		}
+	}
+
+	var config datatypes.ConfigData
+	configData, err := os.ReadFile(filepath.Join(staging, "configs.json"))
+	if err == nil {
+		err = json.Unmarshal(configData, &config)
+	}
+	if err != nil {
+		return fail(fmt.Errorf("backup has no valid repository config: %w", err))
+	}
+
+	previousDir := ""
+	if repoExists {
+		carried := []string{"trash", "backups"}
+		if !final.IncludesSSL {
+			carried = append(carried, "ssl")
+		}
+		if !final.IncludesMedia {
+			carried = append(carried, backupMediaDirs...)
+		}
+		for _, rel := range carried {
+			src := filepath.Join(repoDir, filepath.FromSlash(rel))
+			dst := filepath.Join(staging, filepath.FromSlash(rel))
+			if _, err := os.Stat(src); err != nil {
+				continue
+			}
+			if err := os.MkdirAll(filepath.Dir(dst), 0755); err == nil {
+				err = os.Rename(src, dst)
+			}
+			if err != nil {
+				return fail(fmt.Errorf("failed to carry over %s: %w", rel, err))
+			}
+		}
+
+		previousDir = repoDir + ".before-restore-" + time.Now().Format("20060102-150405")
+		if err := os.Rename(repoDir, previousDir); err != nil {
+			return fail(fmt.Errorf("failed to move the current repository aside: %w", err))
+		}
+	}
+	if err := os.Rename(staging, repoDir); err != nil {
+		if previousDir != "" {
+			os.Rename(previousDir, repoDir)
+		}
+		return fail(fmt.Errorf("failed to move the restored repository into place: %w", err))
+	}
+
+	return final, previousDir, nil
+}
+
+// extractBackupArchive unpacks the data files of an archive into dir, overwriting files
+// unpacked from earlier archives of the chain.
+func extractBackupArchive(archivePath, dir string) error {
+	tr, closeArchive, err := openBackupArchive(archivePath)
+	if err != nil {
+		return err
+	}
+	defer closeArchive()
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", archivePath, err)
+		}
+		if header.Typeflag != tar.TypeReg || !strings.HasPrefix(header.Name, backupDataPrefix) {
+			continue
+		}
+
+		rel := path.Clean(strings.TrimPrefix(header.Name, backupDataPrefix))
+		if rel == "." || rel == ".." || strings.HasPrefix(rel, "../") || path.IsAbs(rel) {
+			return fmt.Errorf("%s contains an unsafe path %q", archivePath, header.Name)
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(target), err)
+		}
+		out, err := os.Create(target)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", target, err)
+		}
+		_, err = io.Copy(out, tr)
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to extract %s: %w", rel, err)
+		}
+	}
+}
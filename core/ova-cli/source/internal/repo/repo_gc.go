@@ -0,0 +1,115 @@
+package repo
+
+import (
+	"fmt"
+	"os"
+	"ova-cli/source/internal/datatypes"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultGCInterval is how often the server collects garbage when the config does not set it.
+const DefaultGCInterval = 24 * time.Hour
+
+// unfinishedArtefactAge is how old leftovers of a cook, and artefacts without an indexed
+// video, must be before they are collected, so files of a cook or an index that is still
+// running are never touched.
+const unfinishedArtefactAge = time.Hour
+
+// GetGCInterval returns how often the server collects garbage; zero disables it.
+func (r *RepoManager) GetGCInterval() time.Duration {
+	hours := r.configs.GCIntervalHours
+	switch {
+	case hours < 0:
+		return 0
+	case hours == 0:
+		return DefaultGCInterval
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// CollectGarbage finds generated artefacts that are no longer needed: artefacts of videos
+// that are not indexed anymore, keyframe folders of interrupted preview thumbnail cooks and
+// temporary files of interrupted audio remuxes. Unless dryRun is set they are deleted.
+// Only this repository's storage is collected; attached repositories collect their own.
+func (r *RepoManager) CollectGarbage(dryRun bool) (datatypes.GarbageReport, error) {
+	report := datatypes.GarbageReport{DryRun: dryRun, Items: []datatypes.GarbageItem{}}
+
+	orphans, err := r.FindOrphanedArtefacts()
+	if err != nil {
+		return report, err
+	}
+	for _, path := range orphans {
+		report.Items = append(report.Items, datatypes.GarbageItem{Path: path, Reason: datatypes.GarbageOrphaned})
+	}
+	for _, path := range r.findUnfinishedArtefacts(orphans) {
+		report.Items = append(report.Items, datatypes.GarbageItem{Path: path, Reason: datatypes.GarbageUnfinished})
+	}
+
+	for i := range report.Items {
+		report.Items[i].Size = pathSize(report.Items[i].Path)
+		report.ReclaimableBytes += report.Items[i].Size
+	}
+	if dryRun {
+		return report, nil
+	}
+
+	for _, item := range report.Items {
+		if err := os.RemoveAll(item.Path); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("failed to delete %s: %v", item.Path, err))
+			continue
+		}
+		report.DeletedBytes += item.Size
+
+		// Drop the shard folder once its last artefact is gone; this fails harmlessly otherwise
+		if item.Reason == datatypes.GarbageOrphaned {
+			os.Remove(filepath.Dir(item.Path))
+		}
+	}
+	return report, nil
+}
+
+// findUnfinishedArtefacts returns leftovers of interrupted cooks older than unfinishedArtefactAge,
+// skipping those inside the already collected orphans.
+func (r *RepoManager) findUnfinishedArtefacts(orphans []string) []string {
+	orphaned := make(map[string]bool, len(orphans))
+	for _, path := range orphans {
+		orphaned[path] = true
+	}
+	cutoff := time.Now().Add(-unfinishedArtefactAge)
+
+	// These folders are laid out as <dir>/<shard>/<videoID>/<file>
+	var leftovers []string
+	for _, dir := range []string{r.GetPreviewThumbnailsDir(), r.GetAudioVariantsDir(), r.GetChapterVariantsDir()} {
+		candidates, _ := filepath.Glob(filepath.Join(dir, "*", "*", "*"))
+		for _, path := range candidates {
+			if orphaned[filepath.Dir(path)] {
+				continue
+			}
+			name := filepath.Base(path)
+			if name != "keyframes" && !strings.Contains(name, ".tmp") {
+				continue
+			}
+			if info, err := os.Stat(path); err == nil && info.ModTime().Before(cutoff) {
+				leftovers = append(leftovers, path)
+			}
+		}
+	}
+	return leftovers
+}
+
+// pathSize returns the size of a file, or of all files inside a folder.
+func pathSize(path string) int64 {
+	var size int64
+	filepath.WalkDir(path, func(_ string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
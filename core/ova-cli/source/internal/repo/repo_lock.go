@@ -0,0 +1,235 @@
+package repo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"ova-cli/source/internal/datatypes"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// repoLockHeartbeat is how often a lock holder refreshes its heartbeat.
+	repoLockHeartbeat = 10 * time.Second
+	// repoLockStaleAfter is how old a heartbeat may get before the lock counts as abandoned
+	// where the file system offers no file locks.
+	repoLockStaleAfter = 45 * time.Second
+)
+
+// errLockBusy is returned by tryLockFile when another process holds the lock.
+var errLockBusy = errors.New("lock is held by another process")
+
+// RepoLockedError is returned when another process holds the repository lock.
+type RepoLockedError struct {
+	Holder datatypes.RepoLockInfo
+}
+
+func (e *RepoLockedError) Error() string {
+	if e.Holder.PID == 0 {
+		return "the repository is in use by another ova process"
+	}
+	return fmt.Sprintf("the repository is in use by 'ova %s' (pid %d on %s) since %s",
+		e.Holder.Command, e.Holder.PID, e.Holder.Hostname, e.Holder.AcquiredAt.Local().Format(time.DateTime))
+}
+
+// RepoLock is a held repository lock. Its heartbeat runs until Release.
+type RepoLock struct {
+	mu   sync.Mutex
+	file *os.File
+	info datatypes.RepoLockInfo
+	stop chan struct{}
+	done chan struct{}
+}
+
+func getRepoLockPath(rootDir string) string {
+	return filepath.Join(rootDir, ".ova-repo", "ova.lock")
+}
+
+// AcquireRepoLock takes the exclusive lock of the repository at rootDir for command, without
+// waiting. Only one process at a time may write a repository: a running server, or a CLI
+// command that changes it. socket is the admin socket of a server, which other processes use
+// to hand their changes to it. Fails with a *RepoLockedError when the lock is held.
+func AcquireRepoLock(rootDir, command, socket string) (*RepoLock, error) {
+	lockPath := getRepoLockPath(rootDir)
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(lockPath), err)
+	}
+	file, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", lockPath, err)
+	}
+
+	holder, _ := readRepoLockInfo(file)
+	if err := tryLockFile(file); err != nil {
+		file.Close()
+		if errors.Is(err, errLockBusy) {
+			return nil, &RepoLockedError{Holder: holder}
+		}
+		return nil, fmt.Errorf("failed to lock %s: %w", lockPath, err)
+	}
+	// Without file locks a live holder is only known by its heartbeat
+	if !fileLocksSupported && holder.PID != 0 && holder.PID != os.Getpid() && time.Since(holder.HeartbeatAt) < repoLockStaleAfter {
+		file.Close()
+		return nil, &RepoLockedError{Holder: holder}
+	}
+
+	hostname, _ := os.Hostname()
+	now := time.Now().UTC()
+	lock := &RepoLock{
+		file: file,
+		info: datatypes.RepoLockInfo{
+			PID:         os.Getpid(),
+			Hostname:    hostname,
+			Command:     command,
+			Socket:      socket,
+			AcquiredAt:  now,
+			HeartbeatAt: now,
+		},
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	if err := lock.writeInfo(); err != nil {
+		unlockFile(file)
+		file.Close()
+		return nil, fmt.Errorf("failed to write %s: %w", lockPath, err)
+	}
+
+	go lock.heartbeat()
+	return lock, nil
+}
+
+// ReadRepoLock returns the holder of the lock of the repository at rootDir, or nil when the
+// lock is free.
+func ReadRepoLock(rootDir string) *datatypes.RepoLockInfo {
+	file, err := os.OpenFile(getRepoLockPath(rootDir), os.O_RDWR, 0)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	holder, err := readRepoLockInfo(file)
+	if !fileLocksSupported {
+		if err != nil || holder.PID == 0 || time.Since(holder.HeartbeatAt) >= repoLockStaleAfter {
+			return nil
+		}
+		return &holder
+	}
+
+	if err := tryLockFile(file); err == nil {
+		unlockFile(file)
+		return nil
+	}
+	return &holder
+}
+
+// Info returns the holder information written to the lock file.
+func (l *RepoLock) Info() datatypes.RepoLockInfo {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.info
+}
+
+// Release stops the heartbeat, clears the lock file and releases the lock. The admin socket
+// advertised by the lock is removed with it.
+func (l *RepoLock) Release() error {
+	l.mu.Lock()
+	if l.file == nil {
+		l.mu.Unlock()
+		return nil
+	}
+	l.mu.Unlock()
+
+	close(l.stop)
+	<-l.done
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.info.Socket != "" {
+		os.Remove(l.info.Socket)
+	}
+	l.file.Truncate(0)
+	unlockFile(l.file)
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+func (l *RepoLock) heartbeat() {
+	defer close(l.done)
+	ticker := time.NewTicker(repoLockHeartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			l.mu.Lock()
+			l.info.HeartbeatAt = time.Now().UTC()
+			if err := l.writeInfo(); err != nil {
+				fmt.Printf("Warning: failed to refresh the repository lock: %v\n", err)
+			}
+			l.mu.Unlock()
+		}
+	}
+}
+
+// writeInfo replaces the content of the lock file. The file itself is never replaced, since
+// the lock belongs to it.
+func (l *RepoLock) writeInfo() error {
+	data, err := json.MarshalIndent(l.info, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := l.file.Truncate(0); err != nil {
+		return err
+	}
+	_, err = l.file.WriteAt(data, 0)
+	return err
+}
+
+func readRepoLockInfo(file *os.File) (datatypes.RepoLockInfo, error) {
+	var info datatypes.RepoLockInfo
+	stat, err := file.Stat()
+	if err != nil || stat.Size() == 0 {
+		return info, err
+	}
+	data := make([]byte, stat.Size())
+	if _, err := file.ReadAt(data, 0); err != nil {
+		return info, err
+	}
+	err = json.Unmarshal(data, &info)
+	return info, err
+}
+
+// Lock takes the repository lock for command for as long as this manager is open; it is
+// released by Unlock or OnShutdown. See AcquireRepoLock.
+func (r *RepoManager) Lock(command, socket string) error {
+	r.lockMu.Lock()
+	defer r.lockMu.Unlock()
+	if r.repoLock != nil {
+		return fmt.Errorf("the repository is already locked by this process")
+	}
+
+	lock, err := AcquireRepoLock(r.rootDir, command, socket)
+	if err != nil {
+		return err
+	}
+	r.repoLock = lock
+	return nil
+}
+
+// Unlock releases the repository lock taken with Lock, if any.
+func (r *RepoManager) Unlock() error {
+	r.lockMu.Lock()
+	defer r.lockMu.Unlock()
+	if r.repoLock == nil {
+		return nil
+	}
+	err := r.repoLock.Release()
+	r.repoLock = nil
+	return err
+}
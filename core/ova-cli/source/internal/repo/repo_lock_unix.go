@@ -0,0 +1,25 @@
+//go:build unix
+
+package repo
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// fileLocksSupported reports whether tryLockFile holds a real lock that the system
+// releases when the process dies.
+const fileLocksSupported = true
+
+func tryLockFile(file *os.File) error {
+	err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return errLockBusy
+	}
+	return err
+}
+
+func unlockFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
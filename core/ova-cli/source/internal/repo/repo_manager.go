@@ -5,6 +5,8 @@ import (
 	"ova-cli/source/internal/datastorage"
 	"ova-cli/source/internal/datatypes"
 	"ova-cli/source/internal/interfaces"
+	"ova-cli/source/internal/toolchain"
+	"sync"
 )
 
 // RepoManager handles video registration, thumbnails, previews, etc.
@@ -15,13 +17,56 @@ type RepoManager struct {
 	diskDataStorage    interfaces.DiskDataStorage
 	memoryDataStorage  interfaces.MemoryDataStorage
 	sessionDataStorage interfaces.SessionDataStorage
+	mediaToolchain     interfaces.MediaToolchain
+
+	parent     *RepoManager            // set when this repository is attached to another one
+	subReposMu sync.Mutex              // guards subRepos
+	subRepos   map[string]*RepoManager // opened sub repositories by name
+
+	markersMu   sync.Mutex // serializes edits of marker files
+	subtitlesMu sync.Mutex // serializes edits of subtitle manifests
+	downloadsMu sync.Mutex // serializes download counter increments
+
+	lockMu   sync.Mutex // guards repoLock
+	repoLock *RepoLock  // cross-process lock held while this process writes the repository
+
+	videoEvents videoEventBus // announces video changes, e.g. to the memory cache
+}
+
+// RepoOptions customizes how a repository is opened.
+type RepoOptions struct {
+	MediaToolchain interfaces.MediaToolchain // Tools to process media with; nil selects ffmpeg
 }
 
 // NewRepoManager creates a new instance of RepoManager and initializes data storage.
 func NewRepoManager(rootDir string) (*RepoManager, error) {
+	return newRepoManager(rootDir, nil, RepoOptions{})
+}
+
+// NewRepoManagerWithOptions creates a RepoManager like NewRepoManager, with opts applied.
+// Tests use it to index and cook videos with the fake media toolchain.
+func NewRepoManagerWithOptions(rootDir string, opts RepoOptions) (*RepoManager, error) {
+	return newRepoManager(rootDir, nil, opts)
+}
+
+// newRepoManager creates a RepoManager; parent is non-nil when opening a sub repository.
+func newRepoManager(rootDir string, parent *RepoManager, opts RepoOptions) (*RepoManager, error) {
 	r := &RepoManager{
-		rootDir:     rootDir,
-		AuthEnabled: true,
+		rootDir:        rootDir,
+		AuthEnabled:    true,
+		parent:         parent,
+		mediaToolchain: opts.MediaToolchain,
+	}
+
+	// Sub repositories process media with the same tools as their parent
+	if parent != nil {
+		r.mediaToolchain = parent.mediaToolchain
+	} else if r.mediaToolchain == nil {
+		tools, err := toolchain.NewMediaToolchain("ffmpeg")
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize media toolchain: %w", err)
+		}
+		r.mediaToolchain = tools
 	}
 
 	// Initialize the repository, which includes creating the folder, loading the config, and initializing data storage
@@ -29,6 +74,9 @@ func NewRepoManager(rootDir string) (*RepoManager, error) {
 		return nil, fmt.Errorf("failed to initialize repository: %w", err)
 	}
 
+	// Keep the memory cache in step with every change of a video
+	r.SubscribeVideoEvents(r.updateVideoCache)
+
 	// Fire initialization event
 	r.OnInit()
 
@@ -48,6 +96,11 @@ func (r *RepoManager) InitDataStorage() error {
 		return fmt.Errorf("failed to initialize repo config: %w", err)
 	}
 
+	// Bring storage written by earlier versions to the current schema before anything reads it
+	if err := r.migrateOnOpen(); err != nil {
+		return fmt.Errorf("failed to migrate repository schema: %w", err)
+	}
+
 	// Load data storage backend
 	storageType := r.configs.DataStorageType
 	storagePath := r.GetStoragePath()
@@ -70,3 +123,20 @@ func (r *RepoManager) InitDataStorage() error {
 
 	return nil
 }
+
+// GetMediaToolchain returns the tools used to probe videos and generate their artefacts.
+func (r *RepoManager) GetMediaToolchain() interfaces.MediaToolchain {
+	return r.mediaToolchain
+}
+
+// SetMediaToolchain replaces the tools used to probe videos and generate their artefacts,
+// for this repository and its opened sub repositories. Tests use it to swap in the fake toolchain.
+func (r *RepoManager) SetMediaToolchain(tools interfaces.MediaToolchain) {
+	r.mediaToolchain = tools
+
+	r.subReposMu.Lock()
+	defer r.subReposMu.Unlock()
+	for _, child := range r.subRepos {
+		child.mediaToolchain = tools
+	}
+}
@@ -0,0 +1,419 @@
+package repo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"ova-cli/source/internal/datatypes"
+	"path/filepath"
+	"time"
+)
+
+// schemaFiles are the versioned files of a repository, relative to .ova-repo. A file that no
+// migration touches yet is at version 1.
+var schemaFiles = []string{
+	"configs.json",
+	"storage/users.json",
+	"storage/videos.json",
+	"storage/spaces.json",
+	"storage/sessions.json",
+}
+
+// storageMigration moves one file from version-1 to version. apply gets the current content
+// and returns the new content and how many records it changed.
+type storageMigration struct {
+	file        string
+	version     int
+	description string
+	apply       func(data []byte) ([]byte, int, error)
+}
+
+// storageMigrations is the migration registry, in the order migrations run. The latest version
+// of a file is that of its last migration. Append new migrations at the end; released ones
+// must never change, since repositories record which of them they have applied.
+var storageMigrations = []storageMigration{
+	{"configs.json", 2, "Set the default page size where maxBucketSize is missing", migrateConfigBucketSize},
+	{"storage/users.json", 2, "Initialize missing roles, favorites, watched lists, watch progress and playlist videos", migrateUserCollections},
+	{"storage/videos.json", 2, "Initialize missing tag lists", migrateVideoTags},
+	{"storage/spaces.json", 2, "Give spaces without an ID one and initialize missing member and group video lists", migrateSpaceCollections},
+}
+
+func (m storageMigration) id() string {
+	return fmt.Sprintf("%s@%d", m.file, m.version)
+}
+
+// latestSchemaVersions returns the version of every versioned file this build writes.
+func latestSchemaVersions() map[string]int {
+	latest := map[string]int{}
+	for _, file := range schemaFiles {
+		latest[file] = 1
+	}
+	for _, m := range storageMigrations {
+		latest[m.file] = m.version
+	}
+	return latest
+}
+
+// checkSchemaVersions rejects schema versions written by a newer build, whose files this
+// build would misread or overwrite with missing fields.
+func checkSchemaVersions(versions map[string]int) error {
+	latest := latestSchemaVersions()
+	for file, version := range versions {
+		if version > latest[file] {
+			return fmt.Errorf("%s has schema version %d but this version of ova only knows up to %d; upgrade ova", file, version, latest[file])
+		}
+	}
+	return nil
+}
+
+// loadSchemaState reads the schema versions of the repository. A repository without
+// schema.json predates versioning and has every file at version 1. The second result
+// reports whether schema.json exists.
+func (r *RepoManager) loadSchemaState() (*datatypes.SchemaState, bool, error) {
+	state := &datatypes.SchemaState{Versions: map[string]int{}, History: []datatypes.SchemaMigration{}}
+
+	data, err := os.ReadFile(r.getSchemaFilePath())
+	if os.IsNotExist(err) {
+		for _, file := range schemaFiles {
+			state.Versions[file] = 1
+		}
+		return state, false, nil
+	} else if err != nil {
+		return nil, false, fmt.Errorf("failed to read schema.json: %w", err)
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, true, fmt.Errorf("failed to parse schema.json: %w", err)
+	}
+	if state.Versions == nil {
+		state.Versions = map[string]int{}
+	}
+	for _, file := range schemaFiles {
+		if state.Versions[file] == 0 {
+			state.Versions[file] = 1
+		}
+	}
+	return state, true, nil
+}
+
+func (r *RepoManager) saveSchemaState(state *datatypes.SchemaState) error {
+	state.UpdatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode schema.json: %w", err)
+	}
+	if err := writeFileAtomic(r.getSchemaFilePath(), data); err != nil {
+		return fmt.Errorf("failed to write schema.json: %w", err)
+	}
+	return nil
+}
+
+// pendingMigrations returns the registered migrations the repository has not applied yet.
+func pendingMigrations(state *datatypes.SchemaState) []storageMigration {
+	var pending []storageMigration
+	for _, m := range storageMigrations {
+		if m.version > state.Versions[m.file] {
+			pending = append(pending, m)
+		}
+	}
+	return pending
+}
+
+// GetSchemaStatus reports the schema version of every versioned file of the repository at
+// rootDir and the migrations it still needs, without opening or changing the repository.
+func GetSchemaStatus(rootDir string) (*datatypes.SchemaStatus, error) {
+	r, err := openForMigration(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	state, _, err := r.loadSchemaState()
+	if err != nil {
+		return nil, err
+	}
+
+	status := &datatypes.SchemaStatus{Pending: []datatypes.SchemaMigration{}, History: state.History}
+	latest := latestSchemaVersions()
+	for _, file := range schemaFiles {
+		status.Files = append(status.Files, datatypes.SchemaFileStatus{File: file, Version: state.Versions[file], Latest: latest[file]})
+	}
+	for _, m := range pendingMigrations(state) {
+		status.Pending = append(status.Pending, datatypes.SchemaMigration{ID: m.id(), File: m.file, Version: m.version, Description: m.description})
+	}
+	return status, nil
+}
+
+// MigrateRepository applies the pending migrations of the repository at rootDir, or with
+// dryRun only reports what they would change. Opening a repository migrates it as well;
+// this lets the migrations be inspected and run on their own.
+func MigrateRepository(rootDir string, dryRun bool) (*datatypes.MigrationReport, error) {
+	r, err := openForMigration(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	if !dryRun {
+		lock, err := AcquireRepoLock(rootDir, "repo migrate", "")
+		if err != nil {
+			return nil, err
+		}
+		defer lock.Release()
+	}
+	return r.migrateSchema(dryRun)
+}
+
+// openForMigration loads just the config of an existing repository.
+func openForMigration(rootDir string) (*RepoManager, error) {
+	r := &RepoManager{rootDir: rootDir}
+	if _, err := os.Stat(r.getRepoConfigFilePath()); err != nil {
+		return nil, fmt.Errorf("%s is not a repository", rootDir)
+	}
+	if err := r.LoadRepoConfig(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// migrateSchema brings every versioned file to the latest schema version, taking a backup
+// of the repository metadata first. Each migration is recorded as soon as it is applied, so
+// a run that fails resumes with the failed migration.
+func (r *RepoManager) migrateSchema(dryRun bool) (*datatypes.MigrationReport, error) {
+	state, exists, err := r.loadSchemaState()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSchemaVersions(state.Versions); err != nil {
+		return nil, err
+	}
+
+	report := &datatypes.MigrationReport{DryRun: dryRun, Migrations: []datatypes.SchemaMigration{}}
+	pending := pendingMigrations(state)
+	if len(pending) == 0 {
+		if !exists && !dryRun {
+			return report, r.saveSchemaState(state)
+		}
+		return report, nil
+	}
+
+	// A new repository has no storage yet and nothing worth backing up
+	if !dryRun && r.hasStorageFiles() {
+		backupDir := r.GetBackupsDir()
+		if err := os.MkdirAll(backupDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", backupDir, err)
+		}
+		backupPath := filepath.Join(backupDir, fmt.Sprintf("pre-migration-%s.tar.zst", time.Now().Format("20060102-150405")))
+		if _, err := r.CreateBackup(backupPath, BackupOptions{}); err != nil {
+			return nil, fmt.Errorf("failed to back up the repository before migrating: %w", err)
+		}
+		report.Backup = backupPath
+	}
+
+	contents := map[string][]byte{}
+	for _, m := range pending {
+		filePath := filepath.Join(r.GetRepoDir(), filepath.FromSlash(m.file))
+		data, loaded := contents[m.file]
+		if !loaded {
+			data, err = os.ReadFile(filePath)
+			if err != nil && !os.IsNotExist(err) {
+				return report, fmt.Errorf("failed to read %s: %w", m.file, err)
+			}
+		}
+
+		entry := datatypes.SchemaMigration{ID: m.id(), File: m.file, Version: m.version, Description: m.description}
+		// Files that do not exist yet are created in the latest format
+		if data != nil {
+			migrated, records, err := m.apply(data)
+			if err != nil {
+				return report, fmt.Errorf("migration %s failed: %w", m.id(), err)
+			}
+			contents[m.file] = migrated
+			entry.Records = records
+			if records > 0 && !dryRun {
+				if err := writeFileAtomic(filePath, migrated); err != nil {
+					return report, fmt.Errorf("migration %s failed to write %s: %w", m.id(), m.file, err)
+				}
+			}
+		}
+
+		if !dryRun {
+			entry.AppliedAt = time.Now().UTC()
+			entry.Backup = report.Backup
+			state.Versions[m.file] = m.version
+			state.History = append(state.History, entry)
+			if err := r.saveSchemaState(state); err != nil {
+				return report, err
+			}
+		}
+		report.Migrations = append(report.Migrations, entry)
+	}
+	return report, nil
+}
+
+// migrateOnOpen runs the pending migrations when a repository is opened, and reloads the
+// config if a migration changed it.
+func (r *RepoManager) migrateOnOpen() error {
+	state, _, err := r.loadSchemaState()
+	if err != nil {
+		return err
+	}
+	// Never migrate files a running server or another command is using
+	if len(pendingMigrations(state)) > 0 {
+		lock, err := AcquireRepoLock(r.rootDir, "repo migrate", "")
+		if err != nil {
+			return err
+		}
+		defer lock.Release()
+	}
+
+	report, err := r.migrateSchema(false)
+	if err != nil {
+		return err
+	}
+	if len(report.Migrations) == 0 {
+		return nil
+	}
+
+	if report.Backup != "" {
+		log.Printf("Migrated the repository schema (%d migrations); the previous metadata is backed up in %s", len(report.Migrations), report.Backup)
+	}
+	for _, m := range report.Migrations {
+		if m.File == "configs.json" && m.Records > 0 {
+			return r.LoadRepoConfig()
+		}
+	}
+	return nil
+}
+
+// hasStorageFiles reports whether any storage file holds data yet.
+func (r *RepoManager) hasStorageFiles() bool {
+	for _, file := range schemaFiles[1:] {
+		if _, err := os.Stat(filepath.Join(r.GetRepoDir(), filepath.FromSlash(file))); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// writeFileAtomic replaces a file through a temporary file in the same folder, so readers
+// never see it half written.
+func writeFileAtomic(filePath string, data []byte) error {
+	tmpPath := filePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, filePath)
+}
+
+// decodeJSON decodes data keeping numbers as written, so migrations do not round large
+// integers through float64.
+func decodeJSON(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// migrateRecords applies fn to every record of a file holding a JSON object of records, such
+// as users.json, and counts the records fn changed. The file is only rewritten if one did.
+func migrateRecords(data []byte, fn func(record map[string]any) bool) ([]byte, int, error) {
+	var records map[string]map[string]any
+	if err := decodeJSON(data, &records); err != nil {
+		return nil, 0, err
+	}
+
+	changed := 0
+	for _, record := range records {
+		if record != nil && fn(record) {
+			changed++
+		}
+	}
+	if changed == 0 {
+		return data, 0, nil
+	}
+
+	migrated, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return nil, 0, err
+	}
+	return migrated, changed, nil
+}
+
+// ensureJSONValue sets key to value when it is missing or null, and reports whether it did.
+func ensureJSONValue(record map[string]any, key string, value any) bool {
+	if current, ok := record[key]; ok && current != nil {
+		return false
+	}
+	record[key] = value
+	return true
+}
+
+func migrateConfigBucketSize(data []byte) ([]byte, int, error) {
+	var config map[string]any
+	if err := decodeJSON(data, &config); err != nil {
+		return nil, 0, err
+	}
+	if size, ok := config["maxBucketSize"].(json.Number); ok {
+		if n, err := size.Int64(); err == nil && n > 0 {
+			return data, 0, nil
+		}
+	}
+
+	config["maxBucketSize"] = datatypes.DefaultMaxBucketSize
+	migrated, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, 0, err
+	}
+	return migrated, 1, nil
+}
+
+func migrateUserCollections(data []byte) ([]byte, int, error) {
+	return migrateRecords(data, func(user map[string]any) bool {
+		changed := ensureJSONValue(user, "roles", []any{"user"})
+		changed = ensureJSONValue(user, "favorites", []any{}) || changed
+		changed = ensureJSONValue(user, "watched", []any{}) || changed
+		changed = ensureJSONValue(user, "playlists", []any{}) || changed
+		changed = ensureJSONValue(user, "watchProgress", map[string]any{}) || changed
+
+		playlists, _ := user["playlists"].([]any)
+		for _, p := range playlists {
+			if playlist, ok := p.(map[string]any); ok {
+				changed = ensureJSONValue(playlist, "videoIds", []any{}) || changed
+			}
+		}
+		return changed
+	})
+}
+
+func migrateVideoTags(data []byte) ([]byte, int, error) {
+	return migrateRecords(data, func(video map[string]any) bool {
+		return ensureJSONValue(video, "tags", []any{})
+	})
+}
+
+func migrateSpaceCollections(data []byte) ([]byte, int, error) {
+	var ensureGroupVideos func(groups []any) bool
+	ensureGroupVideos = func(groups []any) bool {
+		changed := false
+		for _, g := range groups {
+			group, ok := g.(map[string]any)
+			if !ok {
+				continue
+			}
+			changed = ensureJSONValue(group, "videoIds", []any{}) || changed
+			children, _ := group["groups"].([]any)
+			changed = ensureGroupVideos(children) || changed
+		}
+		return changed
+	}
+
+	return migrateRecords(data, func(space map[string]any) bool {
+		changed := false
+		if id, _ := space["spaceId"].(string); id == "" {
+			space["spaceId"] = datatypes.NewSpaceID()
+			changed = true
+		}
+		changed = ensureJSONValue(space, "membersIds", []any{}) || changed
+		groups, _ := space["groups"].([]any)
+		return ensureGroupVideos(groups) || changed
+	})
+}
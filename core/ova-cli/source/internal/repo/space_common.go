@@ -1,13 +1,59 @@
 package repo
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"ova-cli/source/internal/datatypes"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 )
 
-// CreateUser creates a new user with a hashed password and an optional role.
-func (r *RepoManager) GetAllSpaces() {
-	// return all existing spaces
+// GetAllSpaces returns all existing spaces sorted by name.
+func (r *RepoManager) GetAllSpaces() ([]datatypes.SpaceData, error) {
+	if !r.IsDataStorageInitialized() {
+		return nil, fmt.Errorf("data storage is not initialized")
+	}
+
+	spacesMap, err := r.diskDataStorage.GetAllSpaces()
+	if err != nil {
+		return nil, err
+	}
+
+	spaces := make([]datatypes.SpaceData, 0, len(spacesMap))
+	for _, space := range spacesMap {
+		spaces = append(spaces, space)
+	}
+	sort.Slice(spaces, func(i, j int) bool {
+		return spaces[i].SpaceName < spaces[j].SpaceName
+	})
+	return spaces, nil
+}
+
+// EnsureSpaceIDs assigns a stable SpaceId to every space created before IDs were generated.
+func (r *RepoManager) EnsureSpaceIDs() error {
+	if !r.IsDataStorageInitialized() {
+		return fmt.Errorf("data storage is not initialized")
+	}
+
+	spaces, err := r.diskDataStorage.GetAllSpaces()
+	if err != nil {
+		return err
+	}
+
+	for name, space := range spaces {
+		if space.SpaceId != "" {
+			continue
+		}
+		space.SpaceId = datatypes.NewSpaceID()
+		if err := r.diskDataStorage.UpdateSpace(name, &space); err != nil {
+			return fmt.Errorf("failed to assign ID to space %q: %w", name, err)
+		}
+	}
+	return nil
 }
 
 // CreateSpace creates a new space with a directory and owner.
@@ -21,21 +67,257 @@ func (r *RepoManager) CreateSpace(spacedata datatypes.SpaceData) error {
 	return nil
 }
 
-// CreateUser creates a new user with a hashed password and an optional role.
-func (r *RepoManager) DeleteSpace(space string) {
-	r.DeleteSpaceDirectory(space)
+// DeleteSpace removes a space and cascades through everything that belongs to it:
+// the metadata of its videos, their references in users' favorites, watched lists
+// and playlists, and their generated artefacts. The space folder and the video
+// files on disk are only removed when deleteFiles is set.
+func (r *RepoManager) DeleteSpace(spaceID string, deleteFiles bool) error {
+	space, err := r.GetSpaceByID(spaceID)
+	if err != nil {
+		return err
+	}
+	if space.SpaceName == "root" {
+		return fmt.Errorf("the root space cannot be deleted")
+	}
+
+	videos, err := r.diskDataStorage.GetVideosBySpace(space.SpaceName)
+	if err != nil {
+		return fmt.Errorf("failed to load videos of space %q: %w", space.SpaceName, err)
+	}
+
+	// Remove every video owned by the space before the space itself,
+	// so a failure never leaves videos pointing at a missing space.
+	for _, video := range videos {
+		if err := r.diskDataStorage.RemoveVideoFromAllUsers(video.VideoID); err != nil {
+			return fmt.Errorf("failed to remove references to video %s: %w", video.VideoID, err)
+		}
+		if err := r.DeleteVideoArtefacts(video.VideoID); err != nil {
+			return fmt.Errorf("failed to delete artefacts of video %s: %w", video.VideoID, err)
+		}
+		if err := r.diskDataStorage.DeleteVideoByID(video.VideoID); err != nil {
+			return fmt.Errorf("failed to delete video %s: %w", video.VideoID, err)
+		}
+		r.videoDeleted(video.VideoID)
+	}
+
+	if err := r.diskDataStorage.DeleteSpace(space.SpaceName); err != nil {
+		return fmt.Errorf("failed to delete space data: %w", err)
+	}
+
+	if deleteFiles {
+		if err := r.DeleteSpaceDirectory(space.SpaceName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetSpaceByID returns the space with the given stable ID.
+func (r *RepoManager) GetSpaceByID(spaceID string) (*datatypes.SpaceData, error) {
+	if !r.IsDataStorageInitialized() {
+		return nil, fmt.Errorf("data storage is not initialized")
+	}
+	return r.diskDataStorage.GetSpaceByID(spaceID)
 }
 
-func (r *RepoManager) GetSpaceByID(space string) {
+// FindSpace looks up a space by its ID, falling back to its name.
+func (r *RepoManager) FindSpace(ref string) (*datatypes.SpaceData, error) {
+	if !r.IsDataStorageInitialized() {
+		return nil, fmt.Errorf("data storage is not initialized")
+	}
 
+	if space, err := r.diskDataStorage.GetSpaceByID(ref); err == nil {
+		return space, nil
+	}
+
+	spaces, err := r.diskDataStorage.GetAllSpaces()
+	if err != nil {
+		return nil, err
+	}
+	space, ok := spaces[ref]
+	if !ok {
+		return nil, fmt.Errorf("space %q not found", ref)
+	}
+	return &space, nil
 }
 
-func (r *RepoManager) GetSpaceSettings(space string) {
+// GetSpaceSettings returns the settings of a space.
+func (r *RepoManager) GetSpaceSettings(spaceID string) (*datatypes.SpaceSettings, error) {
+	space, err := r.GetSpaceByID(spaceID)
+	if err != nil {
+		return nil, err
+	}
+	return &space.SpaceSettings, nil
+}
 
+// UpdateSpaceSettings replaces the settings of a space.
+func (r *RepoManager) UpdateSpaceSettings(spaceID string, settings datatypes.SpaceSettings) error {
+	space, err := r.GetSpaceByID(spaceID)
+	if err != nil {
+		return err
+	}
+
+	space.SpaceSettings = settings
+	return r.diskDataStorage.UpdateSpace(space.SpaceName, space)
 }
 
-func (r *RepoManager) UpdateSpaceSettings(space string) {
+// RenameSpace renames a space and its folder on disk. Videos owned by the space
+// are moved along with it. If the metadata cannot be updated the folder is moved back.
+func (r *RepoManager) RenameSpace(spaceID, newName string) error {
+	if err := validateSpaceName(newName); err != nil {
+		return err
+	}
 
+	space, err := r.GetSpaceByID(spaceID)
+	if err != nil {
+		return err
+	}
+	if space.SpaceName == "root" {
+		return fmt.Errorf("the root space cannot be renamed")
+	}
+	if space.SpaceName == newName {
+		return nil
+	}
+
+	oldDir := filepath.Join(r.GetRootPath(), space.SpaceName)
+	newDir := filepath.Join(r.GetRootPath(), newName)
+	if _, err := os.Stat(newDir); err == nil {
+		return fmt.Errorf("folder %q already exists", newName)
+	}
+
+	movedDir := false
+	if r.FolderExists(oldDir) {
+		if err := os.Rename(oldDir, newDir); err != nil {
+			return fmt.Errorf("failed to rename space folder: %w", err)
+		}
+		movedDir = true
+	}
+
+	if err := r.diskDataStorage.RenameSpace(space.SpaceName, newName); err != nil {
+		if movedDir {
+			if rbErr := os.Rename(newDir, oldDir); rbErr != nil {
+				return fmt.Errorf("failed to rename space: %v (rollback failed: %v)", err, rbErr)
+			}
+		}
+		return fmt.Errorf("failed to rename space: %w", err)
+	}
+
+	// The videos of the space now carry its new name
+	if videos, err := r.diskDataStorage.GetVideosBySpace(newName); err == nil {
+		for _, video := range videos {
+			r.videoUpdated(video)
+		}
+	}
+	return nil
+}
+
+// ErrSpaceArchived is returned for videos of an archived space, which are neither listed nor played.
+var ErrSpaceArchived = errors.New("space is archived")
+
+// ArchiveSpace marks a space as archived. Archived spaces keep their videos and settings,
+// but their videos are left out of listings and search and cannot be played or downloaded.
+func (r *RepoManager) ArchiveSpace(spaceID string) error {
+	return r.setSpaceArchived(spaceID, true)
+}
+
+// UnarchiveSpace restores an archived space.
+func (r *RepoManager) UnarchiveSpace(spaceID string) error {
+	return r.setSpaceArchived(spaceID, false)
+}
+
+func (r *RepoManager) setSpaceArchived(spaceID string, archived bool) error {
+	space, err := r.GetSpaceByID(spaceID)
+	if err != nil {
+		return err
+	}
+	if space.IsArchived == archived {
+		return nil
+	}
+
+	space.IsArchived = archived
+	if archived {
+		space.ArchivedAt = time.Now().UTC()
+	} else {
+		space.ArchivedAt = time.Time{}
+	}
+	if err := r.diskDataStorage.UpdateSpace(space.SpaceName, space); err != nil {
+		return err
+	}
+
+	// The videos of the space leave or rejoin the library listing
+	return r.CacheLatestVideos()
+}
+
+// archivedSpaceNames returns the names of the archived spaces of this repository.
+func (r *RepoManager) archivedSpaceNames() map[string]bool {
+	archived := map[string]bool{}
+	spaces, err := r.diskDataStorage.GetAllSpaces()
+	if err != nil {
+		return archived
+	}
+	for name, space := range spaces {
+		if space.IsArchived {
+			archived[name] = true
+		}
+	}
+	return archived
+}
+
+// IsSpaceArchived reports whether the space a slash-separated space path starts with is archived.
+func (r *RepoManager) IsSpaceArchived(spacePath string) bool {
+	if !r.IsDataStorageInitialized() {
+		return false
+	}
+	name, _, _ := strings.Cut(strings.Trim(spacePath, "/"), "/")
+	return r.archivedSpaceNames()[name]
+}
+
+// IsVideoArchived reports whether a video, possibly of a sub repository, belongs to an archived space.
+func (r *RepoManager) IsVideoArchived(videoID string) bool {
+	owner, localID, err := r.resolveVideoOwner(videoID)
+	if err != nil {
+		return false
+	}
+	video, err := owner.GetVideoByID(localID)
+	if err != nil {
+		return false
+	}
+	return owner.IsSpaceArchived(video.OwnedSpace)
+}
+
+// withoutArchivedVideos drops the videos of archived spaces from a list of library videos.
+func (r *RepoManager) withoutArchivedVideos(videos []datatypes.VideoData) []datatypes.VideoData {
+	archived := map[string]map[string]bool{} // owner root -> archived space names
+	return slices.DeleteFunc(videos, func(video datatypes.VideoData) bool {
+		owner, _, err := r.resolveVideoOwner(video.VideoID)
+		if err != nil {
+			return false
+		}
+		names, ok := archived[owner.rootDir]
+		if !ok {
+			names = owner.archivedSpaceNames()
+			archived[owner.rootDir] = names
+		}
+		return names[video.OwnedSpace]
+	})
+}
+
+// validateSpaceName checks that a name can be used both as a space key and as a folder name.
+func validateSpaceName(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("space name cannot be empty")
+	}
+	if name == "root" || name == "." || name == ".." {
+		return fmt.Errorf("space name %q is reserved", name)
+	}
+	if strings.HasPrefix(name, ".") {
+		return fmt.Errorf("space name cannot start with a dot")
+	}
+	if strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("space name cannot contain path separators")
+	}
+	return nil
 }
 
 // CreateUser creates a new user with a hashed password and an optional role.
@@ -73,14 +355,19 @@ func (r *RepoManager) RemoveRoleFromUserInSpace(space *datatypes.SpaceData) {
 
 }
 
-// CreateUser creates a new user with a hashed password and an optional role.
-func (r *RepoManager) SpaceExists(spaceID string) {
-
+// SpaceExists reports whether a space with the given ID exists.
+func (r *RepoManager) SpaceExists(spaceID string) bool {
+	_, err := r.GetSpaceByID(spaceID)
+	return err == nil
 }
 
-// CreateUser creates a new user with a hashed password and an optional role.
-func (r *RepoManager) IsUserSpaceOwner(spaceID string) {
-
+// IsUserSpaceOwner reports whether username owns the space with the given ID.
+func (r *RepoManager) IsUserSpaceOwner(spaceID string, username string) bool {
+	space, err := r.GetSpaceByID(spaceID)
+	if err != nil {
+		return false
+	}
+	return space.SpaceOwner == username
 }
 
 // CreateUser creates a new user with a hashed password and an optional role.
@@ -93,13 +380,31 @@ func (r *RepoManager) GetTotalVideosOnSpace(spaceID string) {
 
 }
 
-// CreateUser creates a new user with a hashed password and an optional role.
-func (r *RepoManager) TransferSpaceOwnership(space *datatypes.SpaceData, newOwner string) error {
+// TransferSpaceOwnership makes newOwner the owner of a space. The new owner must be an
+// existing user and is added to the space members if not already one of them.
+func (r *RepoManager) TransferSpaceOwnership(spaceID string, newOwner string) error {
 	if !r.IsDataStorageInitialized() {
 		return fmt.Errorf("data storage is not initialized")
 	}
 
-	return nil
+	if _, err := r.diskDataStorage.GetUserByUsername(newOwner); err != nil {
+		return fmt.Errorf("new owner: %w", err)
+	}
+
+	space, err := r.GetSpaceByID(spaceID)
+	if err != nil {
+		return err
+	}
+	if space.SpaceOwner == newOwner {
+		return nil
+	}
+
+	space.SpaceOwner = newOwner
+	if !slices.Contains(space.MemberIds, newOwner) {
+		space.MemberIds = append(space.MemberIds, newOwner)
+	}
+
+	return r.diskDataStorage.UpdateSpace(space.SpaceName, space)
 }
 
 func (r *RepoManager) GetVideoCountInSpace(spacePath string) (int, error) {
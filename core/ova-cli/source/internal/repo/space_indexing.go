@@ -3,6 +3,8 @@ package repo
 import (
 	"fmt"
 	"ova-cli/source/internal/datatypes"
+	"path/filepath"
+	"sync"
 )
 
 func (r *RepoManager) ScanAndAddAllSpaces() error {
@@ -72,3 +74,72 @@ func (r *RepoManager) convertGroupScanToSpaceGroup(groupScan GroupScan) datatype
 func (r *RepoManager) IndexMultiSpaces(spacePaths []string) {
 
 }
+
+// IndexAllSpaces registers the spaces found on disk and indexes every video in them that is
+// not indexed yet. progress, when set, is called with the space being indexed and its
+// completion percentage.
+func (r *RepoManager) IndexAllSpaces(progress func(space string, percent int)) (*datatypes.IndexSummary, error) {
+	if !r.IsDataStorageInitialized() {
+		return nil, fmt.Errorf("data storage is not initialized")
+	}
+
+	r.ScanAndAddAllSpaces()
+
+	spaces, err := r.ScanDiskForSpaces()
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", r.GetRootPath(), err)
+	}
+	videos, err := r.diskDataStorage.GetAllVideos()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list videos: %w", err)
+	}
+	indexed := map[string]bool{}
+	for _, video := range videos {
+		indexed[GetVideoRelativePath(&video)] = true
+	}
+
+	summary := &datatypes.IndexSummary{Spaces: len(spaces)}
+	for _, space := range spaces {
+		var pending []string
+		for _, rel := range r.GetVideosFromSpaceScan(space) {
+			summary.VideosFound++
+			if indexed[filepath.ToSlash(rel)] {
+				summary.Skipped++
+				continue
+			}
+			// Scans are relative to the root, which need not be the working directory
+			pending = append(pending, filepath.Join(r.GetRootPath(), rel))
+		}
+		if len(pending) == 0 {
+			continue
+		}
+
+		progressChan := make(chan int)
+		errorChan := make(chan error)
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for percent := range progressChan {
+				if progress != nil {
+					progress(space.Space, percent)
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for err := range errorChan {
+				summary.Errors = append(summary.Errors, err.Error())
+			}
+		}()
+
+		added, err := r.IndexMultiVideos(pending, progressChan, errorChan)
+		wg.Wait()
+		if err != nil {
+			return summary, fmt.Errorf("failed to index space %s: %w", space.Space, err)
+		}
+		summary.Indexed += len(added)
+	}
+	summary.Failed = len(summary.Errors)
+	return summary, nil
+}
@@ -0,0 +1,283 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"ova-cli/source/internal/datatypes"
+	"ova-cli/source/internal/utils"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// subRepoIDSeparator separates the sub repository name from the video ID of one of its videos,
+// e.g. "archive:3fa1...". Plain video IDs are hex hashes and never contain it.
+const subRepoIDSeparator = ":"
+
+// ErrSubRepositoryOffline is returned when a video belongs to an attached repository
+// that cannot be reached, e.g. because its drive is unplugged.
+var ErrSubRepositoryOffline = errors.New("sub repository is offline")
+
+// NamespacedVideoID returns the ID under which a video of the named sub repository is exposed.
+func NamespacedVideoID(subRepoName, videoID string) string {
+	return subRepoName + subRepoIDSeparator + videoID
+}
+
+// SplitNamespacedVideoID splits a namespaced video ID into the sub repository name and the
+// video ID local to that repository. ok is false for videos of this repository.
+func SplitNamespacedVideoID(videoID string) (subRepoName string, localID string, ok bool) {
+	return strings.Cut(videoID, subRepoIDSeparator)
+}
+
+// activeSubRepositories returns the attached repositories whose videos are merged into this library.
+// Nesting is not supported, so a repository opened as a child never merges its own children.
+func (r *RepoManager) activeSubRepositories() []datatypes.SubRepository {
+	if r.parent != nil {
+		return nil
+	}
+	return r.configs.SubRepositories
+}
+
+// GetSubRepositories returns the repositories attached to this one.
+func (r *RepoManager) GetSubRepositories() []datatypes.SubRepository {
+	return slices.Clone(r.configs.SubRepositories)
+}
+
+// AttachSubRepository registers another ova repository under name and merges its videos into this library.
+func (r *RepoManager) AttachSubRepository(name string, path string) error {
+	if r.parent != nil {
+		return fmt.Errorf("sub repositories cannot be nested")
+	}
+	if name == "" || utils.ToSlug(name) != name {
+		return fmt.Errorf("invalid name %q: use lowercase letters, digits and hyphens", name)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve absolute path: %w", err)
+	}
+	if !isRepoReachable(absPath) {
+		return fmt.Errorf("%s is not an ova repository", absPath)
+	}
+
+	// A repository inside this one would get its videos indexed twice
+	if rel, err := filepath.Rel(r.rootDir, absPath); err == nil && !strings.HasPrefix(rel, "..") {
+		return fmt.Errorf("%s is inside this repository", absPath)
+	}
+	if rel, err := filepath.Rel(absPath, r.rootDir); err == nil && !strings.HasPrefix(rel, "..") {
+		return fmt.Errorf("%s contains this repository", absPath)
+	}
+
+	for _, sub := range r.configs.SubRepositories {
+		if sub.Name == name {
+			return fmt.Errorf("a sub repository named %q is already attached", name)
+		}
+		if sub.Path == absPath {
+			return fmt.Errorf("%s is already attached as %q", absPath, sub.Name)
+		}
+	}
+
+	cfg := r.configs
+	cfg.SubRepositories = append(slices.Clone(cfg.SubRepositories), datatypes.SubRepository{
+		Name:       name,
+		Path:       absPath,
+		AttachedAt: time.Now().UTC(),
+	})
+	if err := r.SaveRepoConfig(&cfg); err != nil {
+		return err
+	}
+	r.configs = cfg
+
+	if _, err := r.getSubRepository(name); err != nil {
+		return fmt.Errorf("attached %q but failed to open it: %w", name, err)
+	}
+
+	return r.CacheLatestVideos()
+}
+
+// DetachSubRepository removes an attached repository. Its own data is left untouched.
+func (r *RepoManager) DetachSubRepository(name string) error {
+	idx := slices.IndexFunc(r.configs.SubRepositories, func(sub datatypes.SubRepository) bool {
+		return sub.Name == name
+	})
+	if idx < 0 {
+		return fmt.Errorf("sub repository %q not found", name)
+	}
+
+	cfg := r.configs
+	cfg.SubRepositories = slices.Delete(slices.Clone(cfg.SubRepositories), idx, idx+1)
+	if err := r.SaveRepoConfig(&cfg); err != nil {
+		return err
+	}
+	r.configs = cfg
+
+	r.subReposMu.Lock()
+	delete(r.subRepos, name)
+	r.subReposMu.Unlock()
+
+	return r.CacheLatestVideos()
+}
+
+// LoadSubRepositories opens every attached repository that is reachable.
+// Unreachable ones are skipped and retried on the next access.
+func (r *RepoManager) LoadSubRepositories() {
+	for _, sub := range r.activeSubRepositories() {
+		if _, err := r.getSubRepository(sub.Name); err != nil {
+			fmt.Printf("Warning: sub repository %s (%s) is unavailable: %v\n", sub.Name, sub.Path, err)
+		}
+	}
+}
+
+// RefreshSubRepositories re-checks which attached repositories are reachable and
+// rebuilds the video cache when one went offline or came back.
+func (r *RepoManager) RefreshSubRepositories() error {
+	changed := false
+	for _, sub := range r.activeSubRepositories() {
+		r.subReposMu.Lock()
+		_, wasOnline := r.subRepos[sub.Name]
+		r.subReposMu.Unlock()
+
+		_, err := r.getSubRepository(sub.Name)
+		if wasOnline != (err == nil) {
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+	return r.CacheLatestVideos()
+}
+
+// GetSubRepositoryStatuses reports for every attached repository whether it is reachable.
+func (r *RepoManager) GetSubRepositoryStatuses() []datatypes.SubRepositoryStatus {
+	statuses := make([]datatypes.SubRepositoryStatus, 0, len(r.activeSubRepositories()))
+	for _, sub := range r.activeSubRepositories() {
+		status := datatypes.SubRepositoryStatus{Name: sub.Name, Path: sub.Path}
+
+		child, err := r.getSubRepository(sub.Name)
+		if err == nil {
+			status.TotalVideos, err = child.GetTotalIndexedVideoCount()
+		}
+		if err != nil {
+			status.Error = err.Error()
+		} else {
+			status.Online = true
+		}
+
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// GetLibraryVideos returns the videos of this repository together with those of every
+// reachable sub repository, the latter with namespaced IDs.
+func (r *RepoManager) GetLibraryVideos() ([]datatypes.VideoData, error) {
+	videos, err := r.GetAllIndexedVideos()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sub := range r.activeSubRepositories() {
+		child, err := r.getSubRepository(sub.Name)
+		if err != nil {
+			continue
+		}
+		childVideos, err := child.GetAllIndexedVideos()
+		if err != nil {
+			continue
+		}
+		videos = append(videos, namespaceVideos(sub.Name, childVideos)...)
+	}
+
+	return videos, nil
+}
+
+// getSubRepository returns the opened manager of the named sub repository,
+// opening it on first use and dropping it when its path is no longer reachable.
+func (r *RepoManager) getSubRepository(name string) (*RepoManager, error) {
+	subRepos := r.activeSubRepositories()
+	idx := slices.IndexFunc(subRepos, func(sub datatypes.SubRepository) bool {
+		return sub.Name == name
+	})
+	if idx < 0 {
+		return nil, fmt.Errorf("sub repository %q not found", name)
+	}
+	sub := subRepos[idx]
+
+	r.subReposMu.Lock()
+	defer r.subReposMu.Unlock()
+
+	if !isRepoReachable(sub.Path) {
+		delete(r.subRepos, name)
+		return nil, ErrSubRepositoryOffline
+	}
+
+	if child, ok := r.subRepos[name]; ok {
+		return child, nil
+	}
+
+	child, err := newRepoManager(sub.Path, r, RepoOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSubRepositoryOffline, err)
+	}
+
+	// Changes of the child's videos are changes of this library
+	child.SubscribeVideoEvents(func(event VideoEvent) {
+		r.publishVideoEvent(event.namespaced(name))
+	})
+
+	if r.subRepos == nil {
+		r.subRepos = make(map[string]*RepoManager)
+	}
+	r.subRepos[name] = child
+	return child, nil
+}
+
+// resolveVideoOwner returns the repository a video belongs to and its ID within that repository.
+func (r *RepoManager) resolveVideoOwner(videoID string) (*RepoManager, string, error) {
+	name, localID, ok := SplitNamespacedVideoID(videoID)
+	if !ok {
+		return r, videoID, nil
+	}
+
+	child, err := r.getSubRepository(name)
+	if err != nil {
+		return nil, "", err
+	}
+	return child, localID, nil
+}
+
+// artefactOwner returns the repository whose storage holds the artefacts of a video, such as
+// its thumbnail or subtitles, and the video's ID within it. Artefacts of sub repository videos
+// live in that repository's storage. Unlike resolveVideoOwner it never opens the sub
+// repository, since building a path only needs its root directory.
+func (r *RepoManager) artefactOwner(videoID string) (*RepoManager, string) {
+	name, localID, ok := SplitNamespacedVideoID(videoID)
+	if !ok {
+		return r, videoID
+	}
+
+	for _, sub := range r.activeSubRepositories() {
+		if sub.Name == name {
+			return &RepoManager{rootDir: sub.Path}, localID
+		}
+	}
+	return r, videoID
+}
+
+// namespaceVideos rewrites the IDs of a sub repository's videos into their namespaced form.
+func namespaceVideos(subRepoName string, videos []datatypes.VideoData) []datatypes.VideoData {
+	for i := range videos {
+		videos[i].VideoID = NamespacedVideoID(subRepoName, videos[i].VideoID)
+	}
+	return videos
+}
+
+// isRepoReachable reports whether path holds an initialized ova repository.
+func isRepoReachable(path string) bool {
+	_, err := os.Stat(filepath.Join(path, ".ova-repo", "configs.json"))
+	return err == nil
+}
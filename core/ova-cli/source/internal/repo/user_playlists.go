@@ -10,6 +10,13 @@ func (r *RepoManager) AddPlaylistToUser(username string, pl *datatypes.PlaylistD
 	if !r.IsDataStorageInitialized() {
 		return fmt.Errorf("data storage is not initialized")
 	}
+	if pl.IsSmart() {
+		if err := pl.Rules.Validate(); err != nil {
+			return err
+		}
+		// Smart playlists never store their videos
+		pl.VideoIDs = []string{}
+	}
 	return r.diskDataStorage.AddPlaylistToUser(username, pl)
 }
 
@@ -2,34 +2,131 @@ package repo
 
 import (
 	"fmt"
+	"ova-cli/source/internal/datatypes"
 )
 
-// AddVideoToPlaylist adds a video ID to a specific playlist.
+// AddVideoToPlaylist adds a video ID to a specific playlist. A video already in it is left in place.
 func (r *RepoManager) AddVideoToPlaylist(username, slug, videoID string) error {
 	if !r.IsDataStorageInitialized() {
 		return fmt.Errorf("data storage is not initialized")
 	}
+	if err := r.requireVideosExist([]string{videoID}); err != nil {
+		return err
+	}
 	return r.diskDataStorage.AddVideoToPlaylist(username, slug, videoID)
 }
 
-// RemoveVideoFromPlaylist removes a video ID from a specific playlist.
+// requireVideosExist fails unless every video is in the library. Videos of attached
+// repositories are looked up in their own repository.
+func (r *RepoManager) requireVideosExist(videoIDs []string) error {
+	for _, videoID := range videoIDs {
+		if _, err := r.GetVideoByID(videoID); err != nil {
+			return fmt.Errorf("video %q not found", videoID)
+		}
+	}
+	return nil
+}
+
+// RemoveVideoFromPlaylist removes a video ID from a specific playlist. Removing a video
+// that is not in it succeeds without changes.
 func (r *RepoManager) RemoveVideoFromPlaylist(username, slug, videoID string) error {
 	if !r.IsDataStorageInitialized() {
 		return fmt.Errorf("data storage is not initialized")
 	}
-	return r.diskDataStorage.RemoveVideoFromPlaylist(username, slug, videoID)
+	_, err := r.diskDataStorage.RemoveVideosFromPlaylist(username, slug, []string{videoID})
+	return err
 }
 
+// MoveVideoInPlaylist moves a video to index within one of the user's playlists.
+// If revision is set the move fails with datatypes.ErrPlaylistRevisionConflict
+// when the playlist changed since that revision.
+func (r *RepoManager) MoveVideoInPlaylist(username, slug, videoID string, index int, revision *int) error {
+	if !r.IsDataStorageInitialized() {
+		return fmt.Errorf("data storage is not initialized")
+	}
+	return r.diskDataStorage.MovePlaylistVideo(username, slug, videoID, index, revision)
+}
+
+// InsertVideosIntoPlaylist inserts videos at index in one of the user's playlists (a negative
+// index appends). Videos already in the playlist are skipped. Returns the number inserted.
+func (r *RepoManager) InsertVideosIntoPlaylist(username, slug string, videoIDs []string, index int) (int, error) {
+	if !r.IsDataStorageInitialized() {
+		return 0, fmt.Errorf("data storage is not initialized")
+	}
+	if err := r.requireVideosExist(videoIDs); err != nil {
+		return 0, err
+	}
+	return r.diskDataStorage.InsertVideosIntoPlaylist(username, slug, videoIDs, index)
+}
+
+// RemoveVideosFromPlaylist removes many videos from one of the user's playlists at once.
+func (r *RepoManager) RemoveVideosFromPlaylist(username, slug string, videoIDs []string) (int, error) {
+	if !r.IsDataStorageInitialized() {
+		return 0, fmt.Errorf("data storage is not initialized")
+	}
+	return r.diskDataStorage.RemoveVideosFromPlaylist(username, slug, videoIDs)
+}
+
+// DeduplicatePlaylist drops repeated videos from one of the user's playlists.
+func (r *RepoManager) DeduplicatePlaylist(username, slug string) (int, error) {
+	if !r.IsDataStorageInitialized() {
+		return 0, fmt.Errorf("data storage is not initialized")
+	}
+	return r.diskDataStorage.DeduplicatePlaylist(username, slug)
+}
+
+// CopyPlaylistVideos copies videos from srcOwner's playlist into one of the user's playlists.
+// The source may be a playlist shared with the user. A nil videoIDs copies all of it.
+func (r *RepoManager) CopyPlaylistVideos(username, srcOwner, srcSlug, dstSlug string, videoIDs []string, index int) (int, error) {
+	if err := r.requirePlaylistPermission(username, srcOwner, srcSlug, datatypes.PlaylistPermissionView); err != nil {
+		return 0, err
+	}
+	return r.diskDataStorage.CopyPlaylistVideos(srcOwner, srcSlug, username, dstSlug, videoIDs, index)
+}
+
+// GetUserPlaylistContentVideosInRange returns a range of a playlist's videos.
+// Smart playlists are expanded from their rules at read time.
 func (r *RepoManager) GetUserPlaylistContentVideosInRange(username, playlistSlug string, start, end int) ([]string, error) {
 	if !r.IsDataStorageInitialized() {
 		return nil, fmt.Errorf("data storage is not initialized")
 	}
-	return r.diskDataStorage.GetUserPlaylistContentVideosInRange(username, playlistSlug, start, end)
+
+	playlist, err := r.diskDataStorage.GetUserPlaylist(username, playlistSlug)
+	if err != nil {
+		return nil, err
+	}
+	if !playlist.IsSmart() {
+		return r.diskDataStorage.GetUserPlaylistContentVideosInRange(username, playlistSlug, start, end)
+	}
+
+	videoIDs, err := r.ExpandSmartPlaylist(username, playlist.Rules)
+	if err != nil {
+		return nil, err
+	}
+	if start < 0 || end > len(videoIDs) || start >= end {
+		return nil, fmt.Errorf("invalid range [%d, %d)", start, end)
+	}
+	return videoIDs[start:end], nil
+}
+
+// GetUserPlaylistVideoIDs returns the videos of a playlist in order, with smart playlists
+// expanded from their rules.
+func (r *RepoManager) GetUserPlaylistVideoIDs(username, playlistSlug string) ([]string, error) {
+	if !r.IsDataStorageInitialized() {
+		return nil, fmt.Errorf("data storage is not initialized")
+	}
+	return r.getPlaylistVideoIDs(username, playlistSlug)
 }
 
+// GetUserPlaylistContentVideosCount returns the number of videos in a playlist.
 func (r *RepoManager) GetUserPlaylistContentVideosCount(username, playlistSlug string) (int, error) {
 	if !r.IsDataStorageInitialized() {
 		return 0, fmt.Errorf("data storage is not initialized")
 	}
-	return r.diskDataStorage.GetUserPlaylistContentVideosCount(username, playlistSlug)
+
+	videoIDs, err := r.getPlaylistVideoIDs(username, playlistSlug)
+	if err != nil {
+		return 0, err
+	}
+	return len(videoIDs), nil
 }
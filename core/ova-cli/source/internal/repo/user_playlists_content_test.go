@@ -0,0 +1,43 @@
+package repo
+
+import (
+	"slices"
+	"testing"
+
+	"ova-cli/source/internal/datatypes"
+)
+
+func TestAddVideoToPlaylistOfSubRepository(t *testing.T) {
+	r, _ := newTestRepo(t)
+	child, _ := newTestRepo(t)
+	video, err := child.IndexVideo(writeTestVideo(t, child, "Movies/movie.mp4", "movie"))
+	if err != nil {
+		t.Fatalf("IndexVideo() error = %v", err)
+	}
+	if err := r.AttachSubRepository("archive", child.GetRootPath()); err != nil {
+		t.Fatalf("AttachSubRepository() error = %v", err)
+	}
+
+	if _, err := r.CreateUser("alice", "secret", ""); err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	if err := r.AddPlaylistToUser("alice", &datatypes.PlaylistData{Title: "Mix", Slug: "mix", VideoIDs: []string{}}); err != nil {
+		t.Fatalf("AddPlaylistToUser() error = %v", err)
+	}
+
+	videoID := NamespacedVideoID("archive", video.VideoID)
+	if err := r.AddVideoToPlaylist("alice", "mix", videoID); err != nil {
+		t.Fatalf("AddVideoToPlaylist(%q) error = %v", videoID, err)
+	}
+	if _, err := r.InsertVideosIntoPlaylist("alice", "mix", []string{"archive:missing"}, -1); err == nil {
+		t.Error("InsertVideosIntoPlaylist() of an unknown sub repository video succeeded")
+	}
+
+	playlist, err := r.GetUserPlaylist("alice", "mix")
+	if err != nil {
+		t.Fatalf("GetUserPlaylist() error = %v", err)
+	}
+	if !slices.Equal(playlist.VideoIDs, []string{videoID}) {
+		t.Errorf("playlist videos = %v, want [%s]", playlist.VideoIDs, videoID)
+	}
+}
@@ -0,0 +1,228 @@
+package repo
+
+import (
+	"fmt"
+	"ova-cli/source/internal/datatypes"
+	"slices"
+	"sort"
+)
+
+// SharedPlaylist is a playlist of another user that a user has access to.
+type SharedPlaylist struct {
+	Owner       string                 `json:"owner"`
+	Permission  string                 `json:"permission"`
+	Playlist    datatypes.PlaylistData `json:"playlist"`
+	TotalVideos int                    `json:"totalVideos"`
+}
+
+// SharePlaylistWithUser grants collaborator view or edit permission on one of owner's playlists.
+func (r *RepoManager) SharePlaylistWithUser(owner, slug, collaborator, permission string) error {
+	if !r.IsDataStorageInitialized() {
+		return fmt.Errorf("data storage is not initialized")
+	}
+	if !datatypes.IsValidPlaylistPermission(permission) {
+		return fmt.Errorf("invalid permission %q", permission)
+	}
+	if collaborator == owner {
+		return fmt.Errorf("the owner cannot be a collaborator")
+	}
+	if _, err := r.diskDataStorage.GetUserByUsername(collaborator); err != nil {
+		return err
+	}
+	return r.diskDataStorage.SetPlaylistCollaborator(owner, slug, collaborator, permission)
+}
+
+// UnsharePlaylistWithUser revokes a collaborator's access to one of owner's playlists.
+func (r *RepoManager) UnsharePlaylistWithUser(owner, slug, collaborator string) error {
+	if !r.IsDataStorageInitialized() {
+		return fmt.Errorf("data storage is not initialized")
+	}
+	return r.diskDataStorage.RemovePlaylistCollaborator(owner, slug, collaborator)
+}
+
+// SharePlaylistWithSpace grants every member of a space view or edit permission on one of owner's playlists.
+func (r *RepoManager) SharePlaylistWithSpace(owner, slug, spaceID, permission string) error {
+	if !r.IsDataStorageInitialized() {
+		return fmt.Errorf("data storage is not initialized")
+	}
+	if !datatypes.IsValidPlaylistPermission(permission) {
+		return fmt.Errorf("invalid permission %q", permission)
+	}
+	if !r.SpaceExists(spaceID) {
+		return fmt.Errorf("space with ID %q not found", spaceID)
+	}
+	return r.diskDataStorage.SetPlaylistSpaceShare(owner, slug, spaceID, permission)
+}
+
+// UnsharePlaylistWithSpace revokes the access of a space's members to one of owner's playlists.
+func (r *RepoManager) UnsharePlaylistWithSpace(owner, slug, spaceID string) error {
+	if !r.IsDataStorageInitialized() {
+		return fmt.Errorf("data storage is not initialized")
+	}
+	return r.diskDataStorage.RemovePlaylistSpaceShare(owner, slug, spaceID)
+}
+
+// GetPlaylistPermission returns the permission user has on owner's playlist.
+// Grants to the user directly and through space membership are combined; the highest wins.
+func (r *RepoManager) GetPlaylistPermission(user, owner, slug string) (string, error) {
+	if !r.IsDataStorageInitialized() {
+		return "", fmt.Errorf("data storage is not initialized")
+	}
+
+	playlist, err := r.diskDataStorage.GetUserPlaylist(owner, slug)
+	if err != nil {
+		return "", err
+	}
+
+	spaces, err := r.diskDataStorage.GetAllSpaces()
+	if err != nil {
+		return "", fmt.Errorf("failed to load spaces: %w", err)
+	}
+
+	permission := playlistPermissionFor(user, owner, playlist, spaceMembershipsByID(spaces))
+	if permission == "" {
+		return "", fmt.Errorf("playlist %q of user %q is not shared with %q", slug, owner, user)
+	}
+	return permission, nil
+}
+
+// GetPlaylistsSharedWithUser lists the playlists of other users that username can access.
+func (r *RepoManager) GetPlaylistsSharedWithUser(username string) ([]SharedPlaylist, error) {
+	if !r.IsDataStorageInitialized() {
+		return nil, fmt.Errorf("data storage is not initialized")
+	}
+
+	allPlaylists, err := r.diskDataStorage.GetAllPlaylists()
+	if err != nil {
+		return nil, err
+	}
+
+	spaces, err := r.diskDataStorage.GetAllSpaces()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load spaces: %w", err)
+	}
+	memberships := spaceMembershipsByID(spaces)
+
+	shared := []SharedPlaylist{}
+	for owner, playlists := range allPlaylists {
+		if owner == username {
+			continue
+		}
+		for _, playlist := range playlists {
+			permission := playlistPermissionFor(username, owner, &playlist, memberships)
+			if permission == "" {
+				continue
+			}
+
+			totalVideos := len(playlist.VideoIDs)
+			if playlist.IsSmart() {
+				totalVideos, _ = r.GetUserPlaylistContentVideosCount(owner, playlist.Slug)
+			}
+
+			shared = append(shared, SharedPlaylist{
+				Owner:       owner,
+				Permission:  permission,
+				Playlist:    playlist,
+				TotalVideos: totalVideos,
+			})
+		}
+	}
+
+	sort.Slice(shared, func(i, j int) bool {
+		if shared[i].Owner != shared[j].Owner {
+			return shared[i].Owner < shared[j].Owner
+		}
+		return shared[i].Playlist.Order < shared[j].Playlist.Order
+	})
+	return shared, nil
+}
+
+// AddVideoToSharedPlaylist adds a video to owner's playlist on behalf of user, who needs edit permission.
+// Edits name videos by ID and adding a video already in the playlist changes nothing, so
+// collaborators editing at the same time never conflict.
+func (r *RepoManager) AddVideoToSharedPlaylist(user, owner, slug, videoID string) error {
+	if err := r.requirePlaylistPermission(user, owner, slug, datatypes.PlaylistPermissionEdit); err != nil {
+		return err
+	}
+	return r.AddVideoToPlaylist(owner, slug, videoID)
+}
+
+// RemoveVideoFromSharedPlaylist removes a video from owner's playlist on behalf of user, who needs edit permission.
+func (r *RepoManager) RemoveVideoFromSharedPlaylist(user, owner, slug, videoID string) error {
+	if err := r.requirePlaylistPermission(user, owner, slug, datatypes.PlaylistPermissionEdit); err != nil {
+		return err
+	}
+	return r.RemoveVideoFromPlaylist(owner, slug, videoID)
+}
+
+// MoveVideoInSharedPlaylist moves a video within owner's playlist on behalf of user, who needs edit permission.
+// revision is optional, see MoveVideoInPlaylist.
+func (r *RepoManager) MoveVideoInSharedPlaylist(user, owner, slug, videoID string, index int, revision *int) error {
+	if err := r.requirePlaylistPermission(user, owner, slug, datatypes.PlaylistPermissionEdit); err != nil {
+		return err
+	}
+	return r.MoveVideoInPlaylist(owner, slug, videoID, index, revision)
+}
+
+// GetSharedPlaylistContentVideosInRange returns a range of owner's playlist on behalf of user, who needs view permission.
+func (r *RepoManager) GetSharedPlaylistContentVideosInRange(user, owner, slug string, start, end int) ([]string, error) {
+	if err := r.requirePlaylistPermission(user, owner, slug, datatypes.PlaylistPermissionView); err != nil {
+		return nil, err
+	}
+	return r.GetUserPlaylistContentVideosInRange(owner, slug, start, end)
+}
+
+// GetSharedPlaylistContentVideosCount returns the size of owner's playlist on behalf of user, who needs view permission.
+func (r *RepoManager) GetSharedPlaylistContentVideosCount(user, owner, slug string) (int, error) {
+	if err := r.requirePlaylistPermission(user, owner, slug, datatypes.PlaylistPermissionView); err != nil {
+		return 0, err
+	}
+	return r.GetUserPlaylistContentVideosCount(owner, slug)
+}
+
+// requirePlaylistPermission fails unless user has at least the required permission on owner's playlist.
+func (r *RepoManager) requirePlaylistPermission(user, owner, slug, required string) error {
+	permission, err := r.GetPlaylistPermission(user, owner, slug)
+	if err != nil {
+		return err
+	}
+	if datatypes.PlaylistPermissionRank(permission) < datatypes.PlaylistPermissionRank(required) {
+		return fmt.Errorf("user %q has only %s permission on playlist %q", user, permission, slug)
+	}
+	return nil
+}
+
+// playlistPermissionFor returns the highest permission user has on a playlist of owner, or "".
+func playlistPermissionFor(user, owner string, playlist *datatypes.PlaylistData, memberships map[string][]string) string {
+	if user == owner {
+		return datatypes.PlaylistPermissionOwner
+	}
+
+	best := ""
+	grant := func(permission string) {
+		if datatypes.PlaylistPermissionRank(permission) > datatypes.PlaylistPermissionRank(best) {
+			best = permission
+		}
+	}
+
+	for _, collaborator := range playlist.Collaborators {
+		if collaborator.Username == user {
+			grant(collaborator.Permission)
+		}
+	}
+	for _, share := range playlist.SharedSpaces {
+		if slices.Contains(memberships[share.SpaceID], user) {
+			grant(share.Permission)
+		}
+	}
+	return best
+}
+
+// spaceMembershipsByID maps space IDs to their members, the owner included.
+func spaceMembershipsByID(spaces map[string]datatypes.SpaceData) map[string][]string {
+	memberships := make(map[string][]string, len(spaces))
+	for _, space := range spaces {
+		memberships[space.SpaceId] = append(slices.Clone(space.MemberIds), space.SpaceOwner)
+	}
+	return memberships
+}
@@ -0,0 +1,84 @@
+package repo
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"ova-cli/source/internal/datatypes"
+)
+
+func TestSharedPlaylistEdits(t *testing.T) {
+	r, _ := newTestRepo(t)
+	var ids []string
+	for _, name := range []string{"a", "b", "c"} {
+		video, err := r.IndexVideo(writeTestVideo(t, r, "Movies/"+name+".mp4", name))
+		if err != nil {
+			t.Fatalf("IndexVideo() error = %v", err)
+		}
+		ids = append(ids, video.VideoID)
+	}
+	for _, user := range []string{"owner", "bob", "carol"} {
+		if _, err := r.CreateUser(user, "secret", ""); err != nil {
+			t.Fatalf("CreateUser(%q) error = %v", user, err)
+		}
+	}
+	if err := r.AddPlaylistToUser("owner", &datatypes.PlaylistData{Title: "Mix", Slug: "mix", VideoIDs: []string{}}); err != nil {
+		t.Fatalf("AddPlaylistToUser() error = %v", err)
+	}
+	for _, user := range []string{"bob", "carol"} {
+		if err := r.SharePlaylistWithUser("owner", "mix", user, datatypes.PlaylistPermissionEdit); err != nil {
+			t.Fatalf("SharePlaylistWithUser(%q) error = %v", user, err)
+		}
+	}
+
+	// Collaborators adding different videos from the same revision both succeed
+	if err := r.AddVideoToSharedPlaylist("bob", "owner", "mix", ids[0]); err != nil {
+		t.Fatalf("AddVideoToSharedPlaylist(bob) error = %v", err)
+	}
+	if err := r.AddVideoToSharedPlaylist("carol", "owner", "mix", ids[1]); err != nil {
+		t.Fatalf("AddVideoToSharedPlaylist(carol) error = %v", err)
+	}
+	if err := r.AddVideoToPlaylist("owner", "mix", ids[2]); err != nil {
+		t.Fatalf("AddVideoToPlaylist(owner) error = %v", err)
+	}
+
+	playlist, _ := r.GetUserPlaylist("owner", "mix")
+	if !slices.Equal(playlist.VideoIDs, ids) {
+		t.Fatalf("playlist videos = %v, want %v", playlist.VideoIDs, ids)
+	}
+	revision := playlist.Revision
+
+	// Edits that change nothing keep the revision
+	if err := r.AddVideoToSharedPlaylist("bob", "owner", "mix", ids[0]); err != nil {
+		t.Fatalf("repeated AddVideoToSharedPlaylist() error = %v", err)
+	}
+	if err := r.RemoveVideoFromSharedPlaylist("carol", "owner", "mix", "missing"); err != nil {
+		t.Fatalf("RemoveVideoFromSharedPlaylist() of an absent video error = %v", err)
+	}
+	if err := r.MoveVideoInSharedPlaylist("bob", "owner", "mix", ids[1], 1, &revision); err != nil {
+		t.Fatalf("MoveVideoInSharedPlaylist() in place error = %v", err)
+	}
+	if playlist, _ = r.GetUserPlaylist("owner", "mix"); playlist.Revision != revision {
+		t.Errorf("revision = %d after no-op edits, want %d", playlist.Revision, revision)
+	}
+
+	// A reorder based on a stale revision conflicts, one without a revision does not
+	if err := r.RemoveVideoFromSharedPlaylist("carol", "owner", "mix", ids[2]); err != nil {
+		t.Fatalf("RemoveVideoFromSharedPlaylist() error = %v", err)
+	}
+	err := r.MoveVideoInSharedPlaylist("bob", "owner", "mix", ids[1], 0, &revision)
+	if !errors.Is(err, datatypes.ErrPlaylistRevisionConflict) {
+		t.Errorf("MoveVideoInSharedPlaylist() at stale revision error = %v, want a revision conflict", err)
+	}
+	if err := r.MoveVideoInSharedPlaylist("bob", "owner", "mix", ids[1], 0, nil); err != nil {
+		t.Fatalf("MoveVideoInSharedPlaylist() error = %v", err)
+	}
+	if playlist, _ = r.GetUserPlaylist("owner", "mix"); !slices.Equal(playlist.VideoIDs, []string{ids[1], ids[0]}) {
+		t.Errorf("playlist videos = %v, want [%s %s]", playlist.VideoIDs, ids[1], ids[0])
+	}
+
+	if err := r.AddVideoToSharedPlaylist("dave", "owner", "mix", ids[2]); err == nil {
+		t.Error("AddVideoToSharedPlaylist() by a user without access succeeded")
+	}
+}
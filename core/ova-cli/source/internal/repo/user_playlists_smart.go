@@ -0,0 +1,117 @@
+package repo
+
+import (
+	"fmt"
+	"ova-cli/source/internal/datatypes"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// UpdatePlaylistRules replaces the rules of a user's smart playlist.
+func (r *RepoManager) UpdatePlaylistRules(username, slug string, rules datatypes.SmartPlaylistRules) error {
+	if !r.IsDataStorageInitialized() {
+		return fmt.Errorf("data storage is not initialized")
+	}
+	if err := rules.Validate(); err != nil {
+		return err
+	}
+	return r.diskDataStorage.UpdatePlaylistRules(username, slug, &rules)
+}
+
+// ExpandSmartPlaylist computes the video IDs matching rules for the given user.
+// Videos of attached repositories are included, those of archived spaces are not.
+func (r *RepoManager) ExpandSmartPlaylist(username string, rules *datatypes.SmartPlaylistRules) ([]string, error) {
+	if !r.IsDataStorageInitialized() {
+		return nil, fmt.Errorf("data storage is not initialized")
+	}
+
+	videos, err := r.GetLibraryVideos()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load videos: %w", err)
+	}
+	videos = r.withoutArchivedVideos(videos)
+
+	var watched []string
+	if rules.UnwatchedOnly {
+		watched, err = r.diskDataStorage.GetUserWatchedVideos(username)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var addedAfter time.Time
+	if rules.AddedWithinDays > 0 {
+		addedAfter = time.Now().UTC().AddDate(0, 0, -rules.AddedWithinDays)
+	}
+
+	matched := make([]datatypes.VideoData, 0, len(videos))
+	for _, video := range videos {
+		if rules.Space != "" && video.OwnedSpace != rules.Space {
+			continue
+		}
+		if rules.MinDurationSec > 0 && video.Codecs.DurationSec < rules.MinDurationSec {
+			continue
+		}
+		if rules.MaxDurationSec > 0 && video.Codecs.DurationSec > rules.MaxDurationSec {
+			continue
+		}
+		if !addedAfter.IsZero() && video.UploadedAt.Before(addedAfter) {
+			continue
+		}
+		if !hasAllTags(video.Tags, rules.Tags) {
+			continue
+		}
+		if rules.UnwatchedOnly && slices.Contains(watched, video.VideoID) {
+			continue
+		}
+		matched = append(matched, video)
+	}
+
+	sortSmartPlaylistVideos(matched, rules.SortBy, rules.Ascending)
+
+	if rules.Limit > 0 && len(matched) > rules.Limit {
+		matched = matched[:rules.Limit]
+	}
+
+	ids := make([]string, len(matched))
+	for i, video := range matched {
+		ids[i] = video.VideoID
+	}
+	return ids, nil
+}
+
+// getPlaylistVideoIDs returns the videos of a playlist, expanding the rules of smart playlists.
+func (r *RepoManager) getPlaylistVideoIDs(username, slug string) ([]string, error) {
+	playlist, err := r.diskDataStorage.GetUserPlaylist(username, slug)
+	if err != nil {
+		return nil, err
+	}
+	if !playlist.IsSmart() {
+		return playlist.VideoIDs, nil
+	}
+	return r.ExpandSmartPlaylist(username, playlist.Rules)
+}
+
+// hasAllTags reports whether tags contains every entry of required, ignoring case.
+func hasAllTags(tags []string, required []string) bool {
+	for _, want := range required {
+		if !slices.ContainsFunc(tags, func(tag string) bool {
+			return strings.EqualFold(tag, want)
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// sortSmartPlaylistVideos sorts videos by the given key, newest/largest first unless ascending.
+func sortSmartPlaylistVideos(videos []datatypes.VideoData, sortBy string, ascending bool) {
+	sort.SliceStable(videos, func(i, j int) bool {
+		if ascending {
+			return datatypes.VideoSortLess(sortBy, videos[i], videos[j])
+		}
+		return datatypes.VideoSortLess(sortBy, videos[j], videos[i])
+	})
+}
@@ -0,0 +1,39 @@
+package repo
+
+import (
+	"slices"
+	"testing"
+
+	"ova-cli/source/internal/datatypes"
+)
+
+func TestExpandSmartPlaylistSkipsArchivedSpaces(t *testing.T) {
+	r, _ := newTestRepo(t)
+	for _, name := range []string{"Movies", "Old"} {
+		if err := r.CreateSpace(datatypes.CreateDefaultSpaceData(name, "alice")); err != nil {
+			t.Fatalf("CreateSpace(%q) error = %v", name, err)
+		}
+	}
+	movie, err := r.IndexVideo(writeTestVideo(t, r, "Movies/movie.mp4", "movie"))
+	if err != nil {
+		t.Fatalf("IndexVideo() error = %v", err)
+	}
+	if _, err := r.IndexVideo(writeTestVideo(t, r, "Old/clip.mp4", "clip")); err != nil {
+		t.Fatalf("IndexVideo() error = %v", err)
+	}
+	space, err := r.FindSpace("Old")
+	if err != nil {
+		t.Fatalf("FindSpace() error = %v", err)
+	}
+	if err := r.ArchiveSpace(space.SpaceId); err != nil {
+		t.Fatalf("ArchiveSpace() error = %v", err)
+	}
+
+	ids, err := r.ExpandSmartPlaylist("alice", &datatypes.SmartPlaylistRules{})
+	if err != nil {
+		t.Fatalf("ExpandSmartPlaylist() error = %v", err)
+	}
+	if !slices.Equal(ids, []string{movie.VideoID}) {
+		t.Errorf("ExpandSmartPlaylist() = %v, want only the movie %s", ids, movie.VideoID)
+	}
+}
@@ -0,0 +1,487 @@
+package repo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"os"
+	"ova-cli/source/internal/datatypes"
+	"ova-cli/source/internal/utils"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Playlist exchange formats.
+const (
+	PlaylistFormatM3U8 = "m3u8"
+	PlaylistFormatXSPF = "xspf"
+	PlaylistFormatJSON = "json"
+)
+
+// xspfVideoIdentifierPrefix prefixes video IDs in XSPF <identifier> elements.
+const xspfVideoIdentifierPrefix = "urn:ova:video:"
+
+// PlaylistImportReport describes the outcome of a playlist import.
+type PlaylistImportReport struct {
+	Slug       string                    `json:"slug"`
+	Created    bool                      `json:"created"`
+	Added      []string                  `json:"added"`
+	Duplicates int                       `json:"duplicates"`
+	Unresolved []UnresolvedPlaylistEntry `json:"unresolved"`
+}
+
+// UnresolvedPlaylistEntry is an imported entry that could not be matched to a video.
+type UnresolvedPlaylistEntry struct {
+	Entry  string `json:"entry"`
+	Reason string `json:"reason"`
+}
+
+// playlistEntry is one item of an imported playlist with every identity the source format provides.
+type playlistEntry struct {
+	label    string // What the report shows for this entry
+	videoID  string
+	location string // Relative path, absolute path or stream URL
+	hash     string
+}
+
+type xspfPlaylist struct {
+	XMLName    xml.Name    `xml:"playlist"`
+	Version    string      `xml:"version,attr"`
+	Namespace  string      `xml:"xmlns,attr"`
+	Title      string      `xml:"title,omitempty"`
+	Annotation string      `xml:"annotation,omitempty"`
+	Tracks     []xspfTrack `xml:"trackList>track"`
+}
+
+type xspfTrack struct {
+	Location   string `xml:"location,omitempty"`
+	Identifier string `xml:"identifier,omitempty"`
+	Title      string `xml:"title,omitempty"`
+	Duration   int64  `xml:"duration,omitempty"` // Milliseconds
+}
+
+// DetectPlaylistFormat guesses the format of playlist data from its content.
+func DetectPlaylistFormat(data []byte) (string, error) {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("#EXTM3U")):
+		return PlaylistFormatM3U8, nil
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return PlaylistFormatXSPF, nil
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return PlaylistFormatJSON, nil
+	default:
+		return "", fmt.Errorf("unrecognized playlist format")
+	}
+}
+
+// ExportPlaylist renders a user's playlist in the given format. Stream URLs are built from baseURL,
+// e.g. "http://localhost:4040". It returns the data and its content type.
+func (r *RepoManager) ExportPlaylist(username, slug, format, baseURL string) ([]byte, string, error) {
+	if !r.IsDataStorageInitialized() {
+		return nil, "", fmt.Errorf("data storage is not initialized")
+	}
+
+	playlist, err := r.diskDataStorage.GetUserPlaylist(username, slug)
+	if err != nil {
+		return nil, "", err
+	}
+
+	videoIDs, err := r.getPlaylistVideoIDs(username, slug)
+	if err != nil {
+		return nil, "", err
+	}
+
+	videos := make([]datatypes.VideoData, 0, len(videoIDs))
+	for _, id := range videoIDs {
+		video, err := r.GetVideoByID(id)
+		if err != nil {
+			// Videos of offline repositories can't be described, so they are left out
+			continue
+		}
+		videos = append(videos, *video)
+	}
+
+	baseURL = strings.TrimRight(baseURL, "/")
+	streamURL := func(videoID string) string {
+		return baseURL + "/api/v1/stream/" + url.PathEscape(videoID)
+	}
+
+	switch format {
+	case PlaylistFormatM3U8:
+		var buf bytes.Buffer
+		buf.WriteString("#EXTM3U\n")
+		buf.WriteString("#PLAYLIST:" + playlist.Title + "\n")
+		for _, video := range videos {
+			fmt.Fprintf(&buf, "#EXTINF:%d,%s\n", video.Codecs.DurationSec, video.FileName)
+			buf.WriteString(streamURL(video.VideoID) + "\n")
+		}
+		return buf.Bytes(), "audio/x-mpegurl", nil
+
+	case PlaylistFormatXSPF:
+		doc := xspfPlaylist{
+			Version:    "1",
+			Namespace:  "http://xspf.org/ns/0/",
+			Title:      playlist.Title,
+			Annotation: playlist.Description,
+		}
+		for _, video := range videos {
+			doc.Tracks = append(doc.Tracks, xspfTrack{
+				Location:   streamURL(video.VideoID),
+				Identifier: xspfVideoIdentifierPrefix + video.VideoID,
+				Title:      video.FileName,
+				Duration:   int64(video.Codecs.DurationSec) * 1000,
+			})
+		}
+		data, err := xml.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to encode XSPF: %w", err)
+		}
+		return append([]byte(xml.Header), data...), "application/xspf+xml", nil
+
+	case PlaylistFormatJSON:
+		doc := datatypes.PortablePlaylist{
+			Format:      datatypes.PortablePlaylistFormat,
+			Version:     datatypes.PortablePlaylistVersion,
+			Title:       playlist.Title,
+			Description: playlist.Description,
+			Rules:       playlist.Rules,
+			Items:       []datatypes.PortablePlaylistItem{},
+			ExportedAt:  time.Now().UTC(),
+		}
+		for _, video := range videos {
+			doc.Items = append(doc.Items, datatypes.PortablePlaylistItem{
+				VideoID:     video.VideoID,
+				Path:        GetVideoRelativePath(&video),
+				Hash:        video.ContentHash,
+				Title:       video.FileName,
+				DurationSec: video.Codecs.DurationSec,
+			})
+		}
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to encode playlist: %w", err)
+		}
+		return data, "application/json", nil
+
+	default:
+		return nil, "", fmt.Errorf("unsupported playlist format %q", format)
+	}
+}
+
+// ImportPlaylist reads a playlist in the given format (empty to detect it) into a user's playlists.
+// Entries are matched by video ID, then relative path, then content hash. The videos are appended
+// to the playlist with the given title's slug, which is created if needed; an empty title uses the
+// one stored in the data. Entries that match nothing are listed in the report.
+// hashFiles also matches entries naming a file of this repository that was moved since it was
+// indexed, by hashing the file. Only local imports set it, as it reads the files a playlist names.
+func (r *RepoManager) ImportPlaylist(username string, data []byte, format, title string, hashFiles bool) (*PlaylistImportReport, error) {
+	if !r.IsDataStorageInitialized() {
+		return nil, fmt.Errorf("data storage is not initialized")
+	}
+
+	if format == "" {
+		detected, err := DetectPlaylistFormat(data)
+		if err != nil {
+			return nil, err
+		}
+		format = detected
+	}
+
+	var (
+		entries     []playlistEntry
+		storedTitle string
+		description string
+		rules       *datatypes.SmartPlaylistRules
+		err         error
+	)
+	switch format {
+	case PlaylistFormatM3U8:
+		storedTitle, entries, err = parseM3U8(data)
+	case PlaylistFormatXSPF:
+		storedTitle, description, entries, err = parseXSPF(data)
+	case PlaylistFormatJSON:
+		storedTitle, description, rules, entries, err = parsePortablePlaylist(data)
+	default:
+		return nil, fmt.Errorf("unsupported playlist format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if title == "" {
+		title = storedTitle
+	}
+	if title == "" {
+		title = "Imported Playlist"
+	}
+
+	report := &PlaylistImportReport{
+		Slug:       utils.ToSlug(title),
+		Added:      []string{},
+		Unresolved: []UnresolvedPlaylistEntry{},
+	}
+
+	existing, err := r.diskDataStorage.GetUserPlaylist(username, report.Slug)
+	if err != nil {
+		if _, userErr := r.diskDataStorage.GetUserByUsername(username); userErr != nil {
+			return nil, userErr
+		}
+
+		playlist := datatypes.PlaylistData{
+			Title:       title,
+			Description: description,
+			VideoIDs:    []string{},
+			Slug:        report.Slug,
+			Rules:       rules,
+		}
+		if err := r.AddPlaylistToUser(username, &playlist); err != nil {
+			return nil, err
+		}
+		existing = &playlist
+		report.Created = true
+	}
+
+	// Smart playlists are recreated from their rules; their items are only a snapshot
+	if existing.IsSmart() {
+		return report, nil
+	}
+
+	library, err := r.GetLibraryVideos()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load videos: %w", err)
+	}
+	index := newPlaylistImportIndex(library)
+
+	inPlaylist := make(map[string]bool, len(existing.VideoIDs))
+	for _, id := range existing.VideoIDs {
+		inPlaylist[id] = true
+	}
+
+	for _, entry := range entries {
+		videoID, reason := r.resolvePlaylistEntry(entry, index, hashFiles)
+		if videoID == "" {
+			report.Unresolved = append(report.Unresolved, UnresolvedPlaylistEntry{Entry: entry.label, Reason: reason})
+			continue
+		}
+		if inPlaylist[videoID] {
+			report.Duplicates++
+			continue
+		}
+		if err := r.diskDataStorage.AddVideoToPlaylist(username, report.Slug, videoID); err != nil {
+			report.Unresolved = append(report.Unresolved, UnresolvedPlaylistEntry{Entry: entry.label, Reason: err.Error()})
+			continue
+		}
+		inPlaylist[videoID] = true
+		report.Added = append(report.Added, videoID)
+	}
+
+	return report, nil
+}
+
+// playlistImportIndex looks up library videos by the identities imported entries carry.
+type playlistImportIndex struct {
+	byID   map[string]string
+	byPath map[string]string
+	byHash map[string]string // Keyed by content hash
+}
+
+func newPlaylistImportIndex(videos []datatypes.VideoData) *playlistImportIndex {
+	index := &playlistImportIndex{
+		byID:   make(map[string]string, len(videos)),
+		byPath: make(map[string]string, len(videos)),
+		byHash: make(map[string]string, len(videos)),
+	}
+	for _, video := range videos {
+		index.byID[video.VideoID] = video.VideoID
+
+		// Paths are only unique within one repository
+		if _, _, ok := SplitNamespacedVideoID(video.VideoID); !ok {
+			index.byPath[GetVideoRelativePath(&video)] = video.VideoID
+		}
+		if video.ContentHash != "" {
+			index.byHash[video.ContentHash] = video.VideoID
+		}
+	}
+	return index
+}
+
+// resolvePlaylistEntry finds the library video of an imported entry, or explains why there is none.
+func (r *RepoManager) resolvePlaylistEntry(entry playlistEntry, index *playlistImportIndex, hashFiles bool) (string, string) {
+	if id, ok := index.byID[entry.videoID]; ok && entry.videoID != "" {
+		return id, ""
+	}
+
+	location := entry.location
+	if id := videoIDFromStreamURL(location); id != "" {
+		if found, ok := index.byID[id]; ok {
+			return found, ""
+		}
+	}
+
+	relPath, absPath := r.playlistEntryPaths(location)
+	if relPath != "" {
+		if id, ok := index.byPath[relPath]; ok {
+			return id, ""
+		}
+	}
+
+	if id, ok := index.byHash[entry.hash]; ok && entry.hash != "" {
+		return id, ""
+	}
+
+	// The file may have been moved or renamed since it was indexed: hash its content.
+	// Symlinks are not followed, so only regular files of this repository are read.
+	if hashFiles && absPath != "" {
+		if info, err := os.Lstat(absPath); err == nil && info.Mode().IsRegular() {
+			videoID, err := r.ResolveVideoID(absPath)
+			if err != nil {
+				return "", err.Error()
+			}
+			if id, ok := index.byID[videoID]; ok {
+				return id, ""
+			}
+			return "", "file is not indexed"
+		}
+	}
+
+	return "", "no matching video in the library"
+}
+
+// playlistEntryPaths interprets an entry location as a file inside this repository and
+// returns its slash-separated relative path and absolute path. URLs, locations outside the
+// repository and its .ova-repo folder yield empty paths.
+func (r *RepoManager) playlistEntryPaths(location string) (string, string) {
+	if location == "" {
+		return "", ""
+	}
+	if u, err := url.Parse(location); err == nil && u.Scheme != "" && len(u.Scheme) > 1 {
+		if u.Scheme != "file" {
+			return "", ""
+		}
+		location = u.Path
+	}
+
+	absPath := filepath.FromSlash(location)
+	if !filepath.IsAbs(absPath) {
+		absPath = filepath.Join(r.GetRootPath(), absPath)
+	}
+
+	relPath, err := utils.MakeRelative(r.GetRootPath(), absPath)
+	if err != nil {
+		return "", ""
+	}
+	relPath = path.Clean(filepath.ToSlash(relPath))
+	if relPath == "." || relPath == ".." || strings.HasPrefix(relPath, "../") {
+		return "", ""
+	}
+	if relPath == ".ova-repo" || strings.HasPrefix(relPath, ".ova-repo/") {
+		return "", ""
+	}
+	return relPath, absPath
+}
+
+// videoIDFromStreamURL extracts the video ID from an ova stream URL, or returns "".
+func videoIDFromStreamURL(location string) string {
+	u, err := url.Parse(location)
+	if err != nil || u.Scheme == "" {
+		return ""
+	}
+	const marker = "/api/v1/stream/"
+	idx := strings.Index(u.Path, marker)
+	if idx < 0 {
+		return ""
+	}
+	return strings.Trim(u.Path[idx+len(marker):], "/")
+}
+
+// parseM3U8 reads an extended M3U playlist. Only the location of each entry is used.
+func parseM3U8(data []byte) (string, []playlistEntry, error) {
+	var title string
+	var entries []playlistEntry
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "#PLAYLIST:"):
+			title = strings.TrimSpace(strings.TrimPrefix(line, "#PLAYLIST:"))
+		case strings.HasPrefix(line, "#"):
+			continue
+		default:
+			entries = append(entries, playlistEntry{label: line, location: line})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", nil, fmt.Errorf("failed to read M3U8: %w", err)
+	}
+	return title, entries, nil
+}
+
+// parseXSPF reads an XSPF playlist. Tracks exported by ova carry their video ID as identifier.
+func parseXSPF(data []byte) (string, string, []playlistEntry, error) {
+	var doc xspfPlaylist
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return "", "", nil, fmt.Errorf("failed to parse XSPF: %w", err)
+	}
+
+	entries := make([]playlistEntry, 0, len(doc.Tracks))
+	for _, track := range doc.Tracks {
+		label := track.Location
+		if label == "" {
+			label = track.Title
+		}
+		entries = append(entries, playlistEntry{
+			label:    label,
+			videoID:  strings.TrimPrefix(track.Identifier, xspfVideoIdentifierPrefix),
+			location: track.Location,
+		})
+	}
+	return doc.Title, doc.Annotation, entries, nil
+}
+
+// parsePortablePlaylist reads ova's JSON playlist format.
+func parsePortablePlaylist(data []byte) (string, string, *datatypes.SmartPlaylistRules, []playlistEntry, error) {
+	var doc datatypes.PortablePlaylist
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return "", "", nil, nil, fmt.Errorf("failed to parse playlist: %w", err)
+	}
+	if doc.Format != datatypes.PortablePlaylistFormat {
+		return "", "", nil, nil, fmt.Errorf("not an ova playlist (format %q)", doc.Format)
+	}
+	if doc.Version > datatypes.PortablePlaylistVersion {
+		return "", "", nil, nil, fmt.Errorf("playlist format version %d is newer than supported version %d",
+			doc.Version, datatypes.PortablePlaylistVersion)
+	}
+	if doc.Rules != nil {
+		if err := doc.Rules.Validate(); err != nil {
+			return "", "", nil, nil, err
+		}
+	}
+
+	entries := make([]playlistEntry, 0, len(doc.Items))
+	for i, item := range doc.Items {
+		label := item.Path
+		if label == "" {
+			label = item.Title
+		}
+		if label == "" {
+			label = "#" + strconv.Itoa(i+1)
+		}
+		entries = append(entries, playlistEntry{
+			label:    label,
+			videoID:  item.VideoID,
+			location: item.Path,
+			hash:     item.Hash,
+		})
+	}
+	return doc.Title, doc.Description, doc.Rules, entries, nil
+}
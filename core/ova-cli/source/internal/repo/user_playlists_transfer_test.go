@@ -0,0 +1,124 @@
+package repo
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ova-cli/source/internal/datatypes"
+)
+
+func TestPlaylistEntryPaths(t *testing.T) {
+	r, _ := newTestRepo(t)
+	root := r.GetRootPath()
+	outside := filepath.Join(filepath.Dir(root), "elsewhere", "movie.mp4")
+
+	tests := []struct {
+		location string
+		wantRel  string
+	}{
+		{"Movies/movie.mp4", "Movies/movie.mp4"},
+		{filepath.Join(root, "Movies", "movie.mp4"), "Movies/movie.mp4"},
+		{"file://" + filepath.ToSlash(filepath.Join(root, "Movies", "movie.mp4")), "Movies/movie.mp4"},
+		{"Movies/../movie.mp4", "movie.mp4"},
+		{outside, ""},
+		{"../elsewhere/movie.mp4", ""},
+		{"file:///etc/passwd", ""},
+		{".ova-repo/configs.json", ""},
+		{filepath.Join(root, ".ova-repo", "storage"), ""},
+		{"http://example.com/movie.mp4", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		rel, abs := r.playlistEntryPaths(tt.location)
+		if rel != tt.wantRel {
+			t.Errorf("playlistEntryPaths(%q) rel = %q, want %q", tt.location, rel, tt.wantRel)
+		}
+		if (rel == "") != (abs == "") {
+			t.Errorf("playlistEntryPaths(%q) = (%q, %q), want both paths or neither", tt.location, rel, abs)
+		}
+	}
+}
+
+func TestImportPlaylistHashesOnlyLocalFiles(t *testing.T) {
+	r, _ := newTestRepo(t)
+	if _, err := r.CreateUser("alice", "secret", ""); err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	video, err := r.IndexVideo(writeTestVideo(t, r, "Movies/movie.mp4", "movie"))
+	if err != nil {
+		t.Fatalf("IndexVideo() error = %v", err)
+	}
+
+	// The indexed file was renamed since, so only its content identifies it
+	moved := filepath.Join(r.GetRootPath(), "Movies", "renamed.mp4")
+	if err := os.Rename(filepath.Join(r.GetRootPath(), "Movies", "movie.mp4"), moved); err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(t.TempDir(), "secret.mp4")
+	if err := os.WriteFile(outside, []byte("fake video movie"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("#EXTM3U\nMovies/renamed.mp4\n" + outside + "\n")
+
+	report, err := r.ImportPlaylist("alice", data, PlaylistFormatM3U8, "Remote", false)
+	if err != nil {
+		t.Fatalf("ImportPlaylist() error = %v", err)
+	}
+	if len(report.Added) != 0 || len(report.Unresolved) != 2 {
+		t.Errorf("remote import = %+v, want both entries unresolved without hashing", report)
+	}
+
+	report, err = r.ImportPlaylist("alice", data, PlaylistFormatM3U8, "Local", true)
+	if err != nil {
+		t.Fatalf("ImportPlaylist() error = %v", err)
+	}
+	if len(report.Added) != 1 || report.Added[0] != video.VideoID {
+		t.Errorf("local import added %v, want the renamed video %s", report.Added, video.VideoID)
+	}
+	if len(report.Unresolved) != 1 || report.Unresolved[0].Reason != "no matching video in the library" {
+		t.Errorf("local import unresolved = %+v, want the file outside the repository unmatched", report.Unresolved)
+	}
+}
+
+func TestPortablePlaylistMatchesByContentHash(t *testing.T) {
+	r, _ := newTestRepo(t)
+	if _, err := r.CreateUser("alice", "secret", ""); err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	video, err := r.IndexVideo(writeTestVideo(t, r, "Movies/movie.mp4", "movie"))
+	if err != nil {
+		t.Fatalf("IndexVideo() error = %v", err)
+	}
+	if err := r.AddPlaylistToUser("alice", &datatypes.PlaylistData{Title: "Mix", Slug: "mix", VideoIDs: []string{video.VideoID}}); err != nil {
+		t.Fatalf("AddPlaylistToUser() error = %v", err)
+	}
+
+	data, _, err := r.ExportPlaylist("alice", "mix", PlaylistFormatJSON, "")
+	if err != nil {
+		t.Fatalf("ExportPlaylist() error = %v", err)
+	}
+	var doc datatypes.PortablePlaylist
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Items) != 1 || doc.Items[0].Hash != video.ContentHash || doc.Items[0].VideoID != video.VideoID {
+		t.Fatalf("exported items = %+v, want the video's ID and content hash", doc.Items)
+	}
+
+	// Another repository knows the file under a different ID and path
+	doc.Items[0].VideoID = "elsewhere"
+	doc.Items[0].Path = "Old/name.mp4"
+	data, err = json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := r.ImportPlaylist("alice", data, PlaylistFormatJSON, "Copy", false)
+	if err != nil {
+		t.Fatalf("ImportPlaylist() error = %v", err)
+	}
+	if len(report.Added) != 1 || report.Added[0] != video.VideoID {
+		t.Errorf("import added %v, unresolved %+v; want %s matched by content hash", report.Added, report.Unresolved, video.VideoID)
+	}
+}
@@ -12,6 +12,11 @@ func (r *RepoManager) DeleteSession(sessionID string) error {
 	return r.sessionDataStorage.DeleteSession(sessionID)
 }
 
+// DeleteUserSessions signs a user out of every session and returns how many were removed.
+func (r *RepoManager) DeleteUserSessions(username string) (int, error) {
+	return r.sessionDataStorage.DeleteUserSessions(username)
+}
+
 func (r *RepoManager) SaveUserSessionOnDisk() error {
 	return r.sessionDataStorage.SaveOnDisk()
 }
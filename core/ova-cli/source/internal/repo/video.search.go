@@ -3,6 +3,7 @@ package repo
 import (
 	"fmt"
 	"ova-cli/source/internal/datatypes"
+	"slices"
 )
 
 // GetSimilarVideos returns videos similar to the one identified by videoID.
@@ -10,6 +11,20 @@ func (r *RepoManager) GetSimilarVideos(videoID string) ([]datatypes.VideoData, e
 	if !r.IsDataStorageInitialized() {
 		return nil, fmt.Errorf("data storage is not initialized")
 	}
+
+	owner, localID, err := r.resolveVideoOwner(videoID)
+	if err != nil {
+		return nil, err
+	}
+	if owner != r {
+		subRepoName, _, _ := SplitNamespacedVideoID(videoID)
+		videos, err := owner.GetSimilarVideos(localID)
+		if err != nil {
+			return nil, err
+		}
+		return namespaceVideos(subRepoName, videos), nil
+	}
+
 	return r.diskDataStorage.GetSimilarVideos(videoID)
 }
 
@@ -18,7 +33,28 @@ func (r *RepoManager) SearchVideos(criteria datatypes.VideoSearchCriteria) ([]da
 	if !r.IsDataStorageInitialized() {
 		return nil, fmt.Errorf("data storage is not initialized")
 	}
-	return r.diskDataStorage.SearchVideos(criteria)
+
+	videos, err := r.diskDataStorage.SearchVideos(criteria)
+	if err != nil {
+		return nil, err
+	}
+
+	videos = r.withoutArchivedVideos(videos)
+
+	// Offline sub repositories are left out of the results
+	for _, sub := range r.activeSubRepositories() {
+		child, err := r.getSubRepository(sub.Name)
+		if err != nil {
+			continue
+		}
+		childVideos, err := child.SearchVideos(criteria)
+		if err != nil {
+			continue
+		}
+		videos = append(videos, namespaceVideos(sub.Name, childVideos)...)
+	}
+
+	return videos, nil
 }
 
 // GetSearchSuggestions fetches video titles based on a partial query.
@@ -28,5 +64,26 @@ func (r *RepoManager) GetSearchSuggestions(query string) ([]string, error) {
 	}
 
 	// Delegate the suggestion fetching to the appropriate data storage
-	return r.diskDataStorage.GetSearchSuggestions(query)
+	suggestions, err := r.diskDataStorage.GetSearchSuggestions(query)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sub := range r.activeSubRepositories() {
+		child, err := r.getSubRepository(sub.Name)
+		if err != nil {
+			continue
+		}
+		childSuggestions, err := child.GetSearchSuggestions(query)
+		if err != nil {
+			continue
+		}
+		for _, suggestion := range childSuggestions {
+			if !slices.Contains(suggestions, suggestion) {
+				suggestions = append(suggestions, suggestion)
+			}
+		}
+	}
+
+	return suggestions, nil
 }
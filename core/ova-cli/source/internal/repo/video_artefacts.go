@@ -0,0 +1,112 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// GetVideoArtefactPaths returns every generated file or folder that belongs to a video:
+// thumbnail, preview clip, preview thumbnails (sprites + VTT), the marker file, subtitle tracks,
+// single-audio copies and the copy with embedded chapters.
+// Paths are returned whether or not they exist on disk.
+func (r *RepoManager) GetVideoArtefactPaths(videoID string) []string {
+	// All artefacts are sharded by the first two characters of the ID
+	if len(videoID) < 2 {
+		return nil
+	}
+
+	return []string{
+		r.GetThumbnailFilePathByVideoID(videoID),
+		r.GetPreviewFilePathByVideoID(videoID),
+		r.GetPreviewThumbnailsFolderPathByVideoID(videoID),
+		r.GetVideoMarkerFilePathByVideoID(videoID),
+		r.GetSubtitlesFolderPathByVideoID(videoID),
+		r.GetAudioVariantsFolderPathByVideoID(videoID),
+		r.GetChapterVariantsFolderPathByVideoID(videoID),
+	}
+}
+
+// DeleteVideoArtefacts removes all generated artefacts of a video from the storage folder.
+// Missing artefacts are ignored; every other failure is collected and returned together.
+func (r *RepoManager) DeleteVideoArtefacts(videoID string) error {
+	var errs []error
+	for _, path := range r.GetVideoArtefactPaths(videoID) {
+		if err := os.RemoveAll(path); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, fmt.Errorf("failed to delete %s: %w", path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// getArtefactDirs returns the storage folders holding per-video artefacts. Every folder is
+// sharded as <dir>/<first two characters of the ID>/<ID>[.ext].
+func (r *RepoManager) getArtefactDirs() []string {
+	return []string{
+		r.getThumbsDir(),
+		r.getPreviewsDir(),
+		r.GetPreviewThumbnailsDir(),
+		r.GetVideoMarkerDir(),
+		r.GetSubtitlesDir(),
+		r.GetAudioVariantsDir(),
+		r.GetChapterVariantsDir(),
+	}
+}
+
+// FindOrphanedArtefacts returns the artefacts in this repository's storage whose video is
+// no longer indexed. Artefacts of attached repositories are left to those repositories.
+// Indexing writes a video's thumbnail and preview before storing the video, so artefacts
+// changed within unfinishedArtefactAge are left out.
+func (r *RepoManager) FindOrphanedArtefacts() ([]string, error) {
+	if !r.IsDataStorageInitialized() {
+		return nil, fmt.Errorf("data storage is not initialized")
+	}
+
+	videos, err := r.diskDataStorage.GetAllVideos()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list videos: %w", err)
+	}
+	indexed := make(map[string]bool, len(videos))
+	for _, v := range videos {
+		indexed[v.VideoID] = true
+	}
+
+	cutoff := time.Now().Add(-unfinishedArtefactAge)
+
+	var orphans []string
+	for _, dir := range r.getArtefactDirs() {
+		shards, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+		}
+		for _, shard := range shards {
+			if !shard.IsDir() {
+				continue
+			}
+			entries, err := os.ReadDir(filepath.Join(dir, shard.Name()))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", filepath.Join(dir, shard.Name()), err)
+			}
+			for _, entry := range entries {
+				videoID := entry.Name()
+				if !entry.IsDir() {
+					videoID = strings.TrimSuffix(videoID, filepath.Ext(videoID))
+				}
+				if indexed[videoID] {
+					continue
+				}
+				if info, err := entry.Info(); err != nil || info.ModTime().After(cutoff) {
+					continue
+				}
+				orphans = append(orphans, filepath.Join(dir, shard.Name(), entry.Name()))
+			}
+		}
+	}
+	return orphans, nil
+}
@@ -0,0 +1,46 @@
+package repo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFindOrphanedArtefacts(t *testing.T) {
+	r, _ := newTestRepo(t)
+	video, err := r.IndexVideo(writeTestVideo(t, r, "Movies/movie.mp4", "movie"))
+	if err != nil {
+		t.Fatalf("IndexVideo() error = %v", err)
+	}
+
+	// A thumbnail without a stored video, as written midway through indexing
+	orphanID := "ab" + video.VideoID[2:]
+	orphan := r.GetThumbnailFilePathByVideoID(orphanID)
+	if err := os.MkdirAll(filepath.Dir(orphan), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(orphan, []byte("thumb"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	orphans, err := r.FindOrphanedArtefacts()
+	if err != nil {
+		t.Fatalf("FindOrphanedArtefacts() error = %v", err)
+	}
+	if len(orphans) != 0 {
+		t.Errorf("FindOrphanedArtefacts() = %v, want fresh artefacts left out", orphans)
+	}
+
+	old := time.Now().Add(-2 * unfinishedArtefactAge)
+	if err := os.Chtimes(orphan, old, old); err != nil {
+		t.Fatal(err)
+	}
+	orphans, err = r.FindOrphanedArtefacts()
+	if err != nil {
+		t.Fatalf("FindOrphanedArtefacts() error = %v", err)
+	}
+	if len(orphans) != 1 || orphans[0] != orphan {
+		t.Errorf("FindOrphanedArtefacts() = %v, want [%s]", orphans, orphan)
+	}
+}
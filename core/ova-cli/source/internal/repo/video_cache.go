@@ -4,36 +4,41 @@ import (
 	"fmt"
 )
 
-// CacheLatestVideos caches the latest videos by upload date into memory.
+// CacheLatestVideos rebuilds the memory cache of the library, sorted by every sort key.
+// Single changes reach the cache through updateVideoCache instead.
 func (r *RepoManager) CacheLatestVideos() error {
 	// Ensure that the data storage is initialized
 	if !r.IsDataStorageInitialized() {
 		return fmt.Errorf("data storage is not initialized")
 	}
 
-	// Get all videos from disk storage (handle any potential errors)
-	allVideos, err := r.GetAllIndexedVideos()
+	// Get all videos from disk storage, including those of attached repositories
+	allVideos, err := r.GetLibraryVideos()
 	if err != nil {
 		return fmt.Errorf("failed to get all videos from disk storage: %w", err)
 	}
 
-	// Cache videos by upload date into memory storage
-	if err := r.memoryDataStorage.CacheVideosByUploadDate(allVideos); err != nil {
-		return fmt.Errorf("failed to cache videos by upload date: %w", err)
+	// Videos of archived spaces are not listed
+	allVideos = r.withoutArchivedVideos(allVideos)
+
+	// Cache videos sorted by every sort key into memory storage
+	if err := r.memoryDataStorage.CacheVideos(allVideos); err != nil {
+		return fmt.Errorf("failed to cache videos: %w", err)
 	}
 
 	return nil
 }
 
-// GetSortedVideosByRange retrieves video IDs within a specific range, sorted by upload date.
-func (r *RepoManager) GetSortedVideosByRange(start, end int) ([]string, error) {
+// GetSortedVideosByRange retrieves video IDs within a specific range, sorted by one of the
+// datatypes.VideoSortKeys, newest/largest first unless ascending.
+func (r *RepoManager) GetSortedVideosByRange(sortBy string, ascending bool, start, end int) ([]string, error) {
 	// Ensure that the data storage is initialized
 	if !r.IsDataStorageInitialized() {
 		return nil, fmt.Errorf("data storage is not initialized")
 	}
 
 	// Fetch the video IDs in the given range from memory storage
-	videoIDsInRange, err := r.memoryDataStorage.GetSortedVideosInRange(start, end)
+	videoIDsInRange, err := r.memoryDataStorage.GetSortedVideosInRange(sortBy, ascending, start, end)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve video IDs in range: %w", err)
 	}
@@ -70,3 +75,27 @@ func (r *RepoManager) GetAllCachedVideos() ([]string, error) {
 
 	return videoIds, nil
 }
+
+// updateVideoCache applies a video event to the memory cache.
+func (r *RepoManager) updateVideoCache(event VideoEvent) {
+	if event.Video != nil && r.IsVideoArchived(event.VideoID) {
+		// Archived videos are not listed, whatever happened to them
+		event = VideoEvent{Type: VideoDeleted, VideoID: event.VideoID}
+	}
+
+	var err error
+	switch event.Type {
+	case VideoIndexed:
+		err = r.memoryDataStorage.AddCachedVideo(*event.Video)
+	case VideoUpdated:
+		// A video missing from the cache is added, so an update never loses it
+		if err = r.memoryDataStorage.UpdateCachedVideo(*event.Video); err != nil {
+			err = r.memoryDataStorage.AddCachedVideo(*event.Video)
+		}
+	case VideoDeleted:
+		err = r.memoryDataStorage.RemoveCachedVideo(event.VideoID)
+	}
+	if err != nil {
+		fmt.Printf("Warning: failed to update the video cache for %s: %v\n", event.VideoID, err)
+	}
+}
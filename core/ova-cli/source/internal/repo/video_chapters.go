@@ -0,0 +1,438 @@
+package repo
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"ova-cli/source/internal/datatypes"
+	"ova-cli/source/internal/filehash"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Chapter formats markers can be imported from and exported to.
+const (
+	ChapterFormatWebVTT     = "webvtt"
+	ChapterFormatYouTube    = "youtube"    // "00:00 Title" lines as used in video descriptions
+	ChapterFormatFFMetadata = "ffmetadata" // ffmpeg's FFMETADATA1 file
+	ChapterFormatContainer  = "container"  // Chapters stored in the video file itself (import only)
+)
+
+// youtubeChapterLine matches "00:00 Title", "1:02:03 - Title", "(12:30) Title" and similar.
+var youtubeChapterLine = regexp.MustCompile(`^\s*(?:[-*•]\s*)?[\[(]?(\d{1,2}(?::\d{2}){1,2})[\])]?\s*(?:[-–—:|]\s*)?(.+?)\s*$`)
+
+// DetectChapterFormat guesses the chapter format of data from its content.
+func DetectChapterFormat(data []byte) string {
+	content := strings.TrimPrefix(strings.TrimSpace(string(data)), "\ufeff")
+	switch {
+	case strings.HasPrefix(content, "WEBVTT"):
+		return ChapterFormatWebVTT
+	case strings.HasPrefix(content, ";FFMETADATA"):
+		return ChapterFormatFFMetadata
+	default:
+		return ChapterFormatYouTube
+	}
+}
+
+// ImportChapters reads chapters into a video's markers. For the container format data is
+// ignored and the chapters are read from the video file. With replace the existing markers
+// are dropped; otherwise imported chapters are added, skipping ones already present.
+func (r *RepoManager) ImportChapters(videoID, format string, data []byte, replace bool) ([]datatypes.VideoMarker, error) {
+	if format == "" {
+		format = DetectChapterFormat(data)
+	}
+
+	var (
+		imported []datatypes.VideoMarker
+		err      error
+	)
+	switch format {
+	case ChapterFormatWebVTT:
+		imported, err = parseMarkersVTT(string(data))
+	case ChapterFormatYouTube:
+		imported, err = parseYouTubeChapters(string(data))
+	case ChapterFormatFFMetadata:
+		imported, err = parseFFMetadataChapters(string(data))
+	case ChapterFormatContainer:
+		imported, err = r.readContainerChapters(videoID)
+	default:
+		return nil, fmt.Errorf("unsupported chapter format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(imported) == 0 {
+		return nil, fmt.Errorf("no chapters found")
+	}
+
+	sortMarkers(imported)
+	dropImplicitMarkerEnds(imported, r.getVideoDurationMs(videoID))
+
+	for i := range imported {
+		imported[i].Normalize()
+		if err := imported[i].Validate(); err != nil {
+			return nil, fmt.Errorf("chapter %d: %w", i+1, err)
+		}
+	}
+
+	err = r.editMarkers(videoID, func(markers []datatypes.VideoMarker) ([]datatypes.VideoMarker, error) {
+		if replace {
+			markers = markers[:0]
+		}
+
+		ids := make(map[string]bool, len(markers))
+		for _, m := range markers {
+			ids[m.ID] = true
+		}
+
+		for _, chapter := range imported {
+			duplicate := false
+			for _, m := range markers {
+				if m.StartMs == chapter.StartMs && m.Title == chapter.Title {
+					duplicate = true
+					break
+				}
+			}
+			if duplicate {
+				continue
+			}
+
+			// Imported VTT files may carry IDs; keep them unless they collide
+			if chapter.ID == "" || ids[chapter.ID] || strings.HasPrefix(chapter.ID, "cue-") {
+				chapter.ID = datatypes.NewMarkerID()
+			}
+			ids[chapter.ID] = true
+			markers = append(markers, chapter)
+		}
+		return markers, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetMarkersForVideo(videoID)
+}
+
+// ExportChapters renders a video's markers in one of the chapter formats.
+func (r *RepoManager) ExportChapters(videoID, format string) ([]byte, error) {
+	markers, err := r.GetMarkersForVideo(videoID)
+	if err != nil {
+		return nil, err
+	}
+	durationMs := r.getVideoDurationMs(videoID)
+
+	switch format {
+	case ChapterFormatWebVTT:
+		content, err := renderMarkersVTT(markers, durationMs, false)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(content), nil
+	case ChapterFormatYouTube:
+		return []byte(renderYouTubeChapters(markers)), nil
+	case ChapterFormatFFMetadata:
+		return []byte(renderFFMetadataChapters(markers, durationMs)), nil
+	default:
+		return nil, fmt.Errorf("unsupported chapter export format %q", format)
+	}
+}
+
+// EmbedChapters writes a copy of a video to outputPath with its markers stored as
+// container chapters. The indexed file is left untouched, since video IDs are derived
+// from file content and changing it would orphan the video's metadata.
+func (r *RepoManager) EmbedChapters(videoID, outputPath string) error {
+	sourcePath, err := r.GetVideoFilePathByID(videoID)
+	if err != nil {
+		return err
+	}
+
+	absOutput, err := filepath.Abs(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve absolute path: %w", err)
+	}
+	if absOutput == sourcePath {
+		return fmt.Errorf("output must differ from the indexed video file")
+	}
+
+	markers, err := r.GetMarkersForVideo(videoID)
+	if err != nil {
+		return err
+	}
+	if len(markers) == 0 {
+		return fmt.Errorf("video %q has no markers", videoID)
+	}
+
+	return r.embedMarkers(videoID, sourcePath, markers, absOutput)
+}
+
+// GetVideoFilePathWithChapters returns the path of a file holding the video with its markers
+// embedded as container chapters, for downloads. Videos without markers are served as they
+// are; otherwise a copy is made once per set of markers and kept with the video's other
+// artefacts, replacing the copy made for earlier markers.
+func (r *RepoManager) GetVideoFilePathWithChapters(videoID string) (string, error) {
+	videoPath, err := r.GetVideoFilePathByID(videoID)
+	if err != nil {
+		return "", err
+	}
+
+	markers, err := r.GetMarkersForVideo(videoID)
+	if err != nil || len(markers) == 0 {
+		return videoPath, nil
+	}
+
+	// The copy is named after its chapters, so edited markers never serve a stale copy
+	chapters := renderFFMetadataChapters(markers, r.getVideoDurationMs(videoID))
+	variantDir := r.GetChapterVariantsFolderPathByVideoID(videoID)
+	variantPath := filepath.Join(variantDir, filehash.XXH3Hash([]byte(chapters))[:16]+filepath.Ext(videoPath))
+	if _, err := os.Stat(variantPath); err == nil {
+		return variantPath, nil
+	}
+
+	// Mux to a temporary name first so concurrent requests never serve a partial file
+	if err := os.MkdirAll(variantDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create chapter variant directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(variantDir, "*.tmp"+filepath.Ext(videoPath))
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	if err := r.embedMarkers(videoID, videoPath, markers, tmp.Name()); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), variantPath); err != nil {
+		return "", fmt.Errorf("failed to store chapter variant: %w", err)
+	}
+
+	// Drop copies made for earlier markers
+	stale, _ := filepath.Glob(filepath.Join(variantDir, "*"+filepath.Ext(videoPath)))
+	for _, path := range stale {
+		if path != variantPath && !strings.Contains(filepath.Base(path), ".tmp") {
+			os.Remove(path)
+		}
+	}
+	return variantPath, nil
+}
+
+// embedMarkers muxes markers as container chapters into a copy of sourcePath at outputPath.
+func (r *RepoManager) embedMarkers(videoID, sourcePath string, markers []datatypes.VideoMarker, outputPath string) error {
+	metadata, err := os.CreateTemp("", "ova-chapters-*.txt")
+	if err != nil {
+		return fmt.Errorf("failed to create metadata file: %w", err)
+	}
+	defer os.Remove(metadata.Name())
+
+	_, err = metadata.WriteString(renderFFMetadataChapters(markers, r.getVideoDurationMs(videoID)))
+	if closeErr := metadata.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write metadata file: %w", err)
+	}
+
+	return r.mediaToolchain.EmbedChapters(sourcePath, metadata.Name(), outputPath)
+}
+
+// readContainerChapters reads the chapters stored in a video's file.
+func (r *RepoManager) readContainerChapters(videoID string) ([]datatypes.VideoMarker, error) {
+	videoPath, err := r.GetVideoFilePathByID(videoID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.mediaToolchain.Chapters(videoPath)
+}
+
+// dropImplicitMarkerEnds clears ends that only repeat the next chapter's start or the
+// end of the video, so they keep following those when markers are edited later.
+func dropImplicitMarkerEnds(markers []datatypes.VideoMarker, videoDurationMs int64) {
+	for i := range markers {
+		if markers[i].EndMs <= markers[i].StartMs {
+			markers[i].EndMs = 0
+			continue
+		}
+		if i+1 < len(markers) && markers[i].EndMs == markers[i+1].StartMs {
+			markers[i].EndMs = 0
+		} else if i+1 == len(markers) && videoDurationMs > 0 && markers[i].EndMs >= videoDurationMs {
+			markers[i].EndMs = 0
+		}
+	}
+}
+
+// parseYouTubeChapters reads "00:00 Title" lines, ignoring every other line.
+func parseYouTubeChapters(content string) ([]datatypes.VideoMarker, error) {
+	var markers []datatypes.VideoMarker
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		match := youtubeChapterLine.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+		startMs, err := datatypes.ParseVTTToMs(match[1])
+		if err != nil {
+			return nil, err
+		}
+		markers = append(markers, datatypes.VideoMarker{StartMs: startMs, Title: match[2]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read chapters: %w", err)
+	}
+	return markers, nil
+}
+
+// renderYouTubeChapters writes markers as "00:00 Title" lines.
+// Hours are only shown when the last marker starts after the first hour.
+func renderYouTubeChapters(markers []datatypes.VideoMarker) string {
+	withHours := len(markers) > 0 && markers[len(markers)-1].StartMs >= 3600000
+
+	var sb strings.Builder
+	for _, marker := range markers {
+		totalSec := marker.StartMs / 1000
+		if withHours {
+			fmt.Fprintf(&sb, "%d:%02d:%02d %s\n", totalSec/3600, (totalSec%3600)/60, totalSec%60, marker.Title)
+		} else {
+			fmt.Fprintf(&sb, "%02d:%02d %s\n", totalSec/60, totalSec%60, marker.Title)
+		}
+	}
+	return sb.String()
+}
+
+// parseFFMetadataChapters reads the [CHAPTER] sections of an FFMETADATA1 file.
+func parseFFMetadataChapters(content string) ([]datatypes.VideoMarker, error) {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.TrimPrefix(content, "\ufeff")
+	if !strings.HasPrefix(content, ";FFMETADATA") {
+		return nil, fmt.Errorf("invalid FFMETADATA file: missing ;FFMETADATA1 header")
+	}
+
+	// Escaped newlines continue the value on the next line
+	var lines []string
+	var pending string
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasSuffix(line, `\`) && !strings.HasSuffix(line, `\\`) {
+			pending += strings.TrimSuffix(line, `\`) + "\n"
+			continue
+		}
+		lines = append(lines, pending+line)
+		pending = ""
+	}
+
+	type chapter struct {
+		num, den   int64
+		start, end int64
+		title      string
+	}
+	var chapters []chapter
+	var current *chapter
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, ";") || strings.HasPrefix(trimmed, "#"):
+			continue
+		case strings.HasPrefix(trimmed, "["):
+			if trimmed == "[CHAPTER]" {
+				chapters = append(chapters, chapter{num: 1, den: 1000000000})
+				current = &chapters[len(chapters)-1]
+			} else {
+				current = nil // [STREAM] and other sections
+			}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		key, value := splitFFMetadataLine(line)
+		switch strings.ToUpper(key) {
+		case "TIMEBASE":
+			numStr, denStr, ok := strings.Cut(value, "/")
+			num, errNum := strconv.ParseInt(numStr, 10, 64)
+			den, errDen := strconv.ParseInt(denStr, 10, 64)
+			if !ok || errNum != nil || errDen != nil || num <= 0 || den <= 0 {
+				return nil, fmt.Errorf("invalid TIMEBASE %q", value)
+			}
+			current.num, current.den = num, den
+		case "START":
+			v, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid START %q: %w", value, err)
+			}
+			current.start = v
+		case "END":
+			v, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid END %q: %w", value, err)
+			}
+			current.end = v
+		case "TITLE":
+			current.title = value
+		}
+	}
+
+	markers := make([]datatypes.VideoMarker, 0, len(chapters))
+	for i, ch := range chapters {
+		title := strings.ReplaceAll(ch.title, "\n", " ")
+		if title == "" {
+			title = fmt.Sprintf("Chapter %d", i+1)
+		}
+		markers = append(markers, datatypes.VideoMarker{
+			StartMs: ch.start * ch.num * 1000 / ch.den,
+			EndMs:   ch.end * ch.num * 1000 / ch.den,
+			Title:   title,
+		})
+	}
+	return markers, nil
+}
+
+// splitFFMetadataLine splits "key=value" at the first unescaped '=' and unescapes both parts.
+func splitFFMetadataLine(line string) (string, string) {
+	escaped := false
+	for i, c := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '=':
+			return unescapeFFMetadata(line[:i]), unescapeFFMetadata(line[i+1:])
+		}
+	}
+	return unescapeFFMetadata(line), ""
+}
+
+func unescapeFFMetadata(s string) string {
+	var sb strings.Builder
+	escaped := false
+	for _, c := range s {
+		if !escaped && c == '\\' {
+			escaped = true
+			continue
+		}
+		escaped = false
+		sb.WriteRune(c)
+	}
+	return sb.String()
+}
+
+// ffmetadataEscaper escapes the characters FFMETADATA gives a special meaning.
+var ffmetadataEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, `;`, `\;`, `#`, `\#`, "\n", "\\\n")
+
+// renderFFMetadataChapters writes markers as an FFMETADATA1 file with millisecond timebase.
+func renderFFMetadataChapters(markers []datatypes.VideoMarker, videoDurationMs int64) string {
+	sortMarkers(markers)
+	ends := markerEndsMs(markers, videoDurationMs)
+
+	var sb strings.Builder
+	sb.WriteString(";FFMETADATA1\n")
+	for i, marker := range markers {
+		sb.WriteString("\n[CHAPTER]\nTIMEBASE=1/1000\n")
+		fmt.Fprintf(&sb, "START=%d\nEND=%d\n", marker.StartMs, ends[i])
+		sb.WriteString("title=" + ffmetadataEscaper.Replace(marker.Title) + "\n")
+	}
+	return sb.String()
+}
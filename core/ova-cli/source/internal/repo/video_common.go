@@ -17,7 +17,11 @@ func (r *RepoManager) AddVideo(video datatypes.VideoData) error {
 	}
 
 	// Add video to database
-	return r.diskDataStorage.AddVideo(video)
+	if err := r.diskDataStorage.AddVideo(video); err != nil {
+		return err
+	}
+	r.videoIndexed(video)
+	return nil
 }
 
 // AddVideo adds a new video if it does not already exist.
@@ -95,7 +99,17 @@ func (r *RepoManager) DeleteAllVideos() error {
 	if !r.IsDataStorageInitialized() {
 		return fmt.Errorf("data storage is not initialized")
 	}
-	return r.diskDataStorage.DeleteAllVideos()
+	videos, err := r.diskDataStorage.GetAllVideos()
+	if err != nil {
+		return err
+	}
+	if err := r.diskDataStorage.DeleteAllVideos(); err != nil {
+		return err
+	}
+	for _, video := range videos {
+		r.videoDeleted(video.VideoID)
+	}
+	return nil
 }
 
 // GetIndxedVideosOnSpace returns all videos inside specified folder.
@@ -111,7 +125,11 @@ func (r *RepoManager) UpdateVideoLocalPath(videoID, newPath string) error {
 	if !r.IsDataStorageInitialized() {
 		return fmt.Errorf("data storage is not initialized")
 	}
-	return r.diskDataStorage.UpdateVideoLocalPath(videoID, newPath)
+	if err := r.diskDataStorage.UpdateVideoLocalPath(videoID, newPath); err != nil {
+		return err
+	}
+	r.videoUpdatedByID(videoID)
+	return nil
 }
 
 // GetTotalIndexedVideoCount returns total number of videos.
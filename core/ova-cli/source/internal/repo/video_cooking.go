@@ -2,6 +2,8 @@ package repo
 
 import (
 	"fmt"
+	"ova-cli/source/internal/datatypes"
+	"path/filepath"
 	"runtime"
 	"sync"
 )
@@ -33,7 +35,7 @@ func (r *RepoManager) IsVideoCooked(VideoID string) bool {
 func (r *RepoManager) CookOneVideo(VideoPath string) error {
 
 	// Ensure the video has a valid ID before cooking
-	videoID, err := r.GenerateVideoID(VideoPath)
+	videoID, err := r.ResolveVideoID(VideoPath)
 	if err != nil {
 		return fmt.Errorf("failed to generate video ID for %s: %v", VideoPath, err)
 	}
@@ -110,3 +112,53 @@ func (r *RepoManager) CookMultiVideos(VideoPaths []string, progressChan chan int
 	// Do not close progressChan or errorChan here; let the caller close them if needed
 	return nil
 }
+
+// CookAllVideos generates the preview thumbnails of every indexed video that has none yet.
+// progress, when set, is called with the completion percentage.
+func (r *RepoManager) CookAllVideos(progress func(percent int)) (*datatypes.CookSummary, error) {
+	videos, err := r.GetAllIndexedVideos()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list videos: %w", err)
+	}
+
+	summary := &datatypes.CookSummary{VideosFound: len(videos)}
+	var pending []string
+	for _, video := range videos {
+		if r.IsVideoCooked(video.VideoID) {
+			summary.Skipped++
+			continue
+		}
+		pending = append(pending, filepath.Join(r.GetRootPath(), filepath.FromSlash(GetVideoRelativePath(&video))))
+	}
+	if len(pending) == 0 {
+		return summary, nil
+	}
+
+	progressChan := make(chan int)
+	errorChan := make(chan error)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for percent := range progressChan {
+			if progress != nil {
+				progress(percent)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for err := range errorChan {
+			summary.Errors = append(summary.Errors, err.Error())
+		}
+	}()
+
+	err = r.CookMultiVideos(pending, progressChan, errorChan)
+	close(progressChan)
+	close(errorChan)
+	wg.Wait()
+
+	summary.Failed = len(summary.Errors)
+	summary.Cooked = len(pending) - summary.Failed
+	return summary, err
+}
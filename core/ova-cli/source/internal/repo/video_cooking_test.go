@@ -0,0 +1,93 @@
+package repo
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestCookAllVideos(t *testing.T) {
+	r, tools := newTestRepo(t)
+
+	var ids []string
+	for _, name := range []string{"first", "second"} {
+		video, err := r.IndexVideo(writeTestVideo(t, r, "Movies/"+name+".mp4", name))
+		if err != nil {
+			t.Fatalf("IndexVideo(%s) error = %v", name, err)
+		}
+		ids = append(ids, video.VideoID)
+	}
+
+	var percents []int
+	summary, err := r.CookAllVideos(func(percent int) { percents = append(percents, percent) })
+	if err != nil {
+		t.Fatalf("CookAllVideos() error = %v", err)
+	}
+	if summary.VideosFound != 2 || summary.Cooked != 2 || summary.Skipped != 0 || summary.Failed != 0 {
+		t.Errorf("CookAllVideos() = %+v, want both videos cooked", summary)
+	}
+	if len(percents) == 0 || percents[len(percents)-1] != 100 {
+		t.Errorf("progress = %v, want it to end at 100", percents)
+	}
+
+	for _, id := range ids {
+		dir := r.GetPreviewThumbnailsFolderPathByVideoID(id)
+		if !r.IsVideoCooked(id) {
+			t.Errorf("video %s is not cooked", id)
+		}
+		// A one minute video with a keyframe every two seconds fills two 5x5 sheets
+		for _, sheet := range []string{"thumb_L0_001.jpg", "thumb_L0_002.jpg"} {
+			if !fileExists(filepath.Join(dir, sheet)) {
+				t.Errorf("sprite sheet %s of %s is missing", sheet, id)
+			}
+		}
+		if fileExists(filepath.Join(dir, "keyframes")) {
+			t.Errorf("keyframes of %s were not cleaned up", id)
+		}
+	}
+
+	// Cooked videos are skipped without running the tools again
+	calls := len(tools.Calls())
+	summary, err = r.CookAllVideos(nil)
+	if err != nil {
+		t.Fatalf("second CookAllVideos() error = %v", err)
+	}
+	if summary.Skipped != 2 || summary.Cooked != 0 {
+		t.Errorf("second CookAllVideos() = %+v, want both videos skipped", summary)
+	}
+	if len(tools.Calls()) != calls {
+		t.Errorf("second CookAllVideos() ran the toolchain: %v", tools.Calls()[calls:])
+	}
+}
+
+func TestCookAllVideosToolchainFailures(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+	}{
+		{name: "keyframes fail", method: "Keyframes"},
+		{name: "sprite sheets fail", method: "SpriteSheets"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, tools := newTestRepo(t)
+			video, err := r.IndexVideo(writeTestVideo(t, r, "Movies/movie.mp4", tt.name))
+			if err != nil {
+				t.Fatalf("IndexVideo() error = %v", err)
+			}
+
+			tools.FailOn(tt.method, errors.New("tool crashed"))
+			summary, err := r.CookAllVideos(nil)
+			if err != nil {
+				t.Fatalf("CookAllVideos() error = %v", err)
+			}
+			if summary.Failed != 1 || summary.Cooked != 0 || len(summary.Errors) != 1 {
+				t.Errorf("CookAllVideos() = %+v, want one failure", summary)
+			}
+			if r.IsVideoCooked(video.VideoID) {
+				t.Error("video counts as cooked after a failure")
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package repo
+
+import (
+	"fmt"
+
+	"ova-cli/source/internal/datatypes"
+)
+
+// RecordVideoDownload counts one download of a video and announces the updated video.
+func (r *RepoManager) RecordVideoDownload(videoID string) (*datatypes.VideoData, error) {
+	if !r.IsDataStorageInitialized() {
+		return nil, fmt.Errorf("data storage is not initialized")
+	}
+
+	owner, localID, err := r.resolveVideoOwner(videoID)
+	if err != nil {
+		return nil, err
+	}
+	if owner != r {
+		video, err := owner.RecordVideoDownload(localID)
+		if err != nil {
+			return nil, err
+		}
+		video.VideoID = videoID
+		return video, nil
+	}
+
+	r.downloadsMu.Lock()
+	defer r.downloadsMu.Unlock()
+
+	video, err := r.diskDataStorage.GetVideoByID(videoID)
+	if err != nil {
+		return nil, fmt.Errorf("video %q not found", videoID)
+	}
+
+	video.TotalDownloads++
+	if err := r.diskDataStorage.UpdateVideo(*video); err != nil {
+		return nil, fmt.Errorf("failed to save download count: %w", err)
+	}
+	r.videoUpdated(*video)
+	return video, nil
+}
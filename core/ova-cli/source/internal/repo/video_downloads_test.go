@@ -0,0 +1,37 @@
+package repo
+
+import "testing"
+
+func TestRecordVideoDownload(t *testing.T) {
+	r, _ := newTestRepo(t)
+	video, err := r.IndexVideo(writeTestVideo(t, r, "Movies/movie.mp4", "movie"))
+	if err != nil {
+		t.Fatalf("IndexVideo() error = %v", err)
+	}
+
+	var updates []VideoEvent
+	r.SubscribeVideoEvents(func(event VideoEvent) {
+		updates = append(updates, event)
+	})
+
+	for range 2 {
+		if _, err := r.RecordVideoDownload(video.VideoID); err != nil {
+			t.Fatalf("RecordVideoDownload() error = %v", err)
+		}
+	}
+
+	stored, err := r.GetVideoByID(video.VideoID)
+	if err != nil {
+		t.Fatalf("GetVideoByID() error = %v", err)
+	}
+	if stored.TotalDownloads != 2 {
+		t.Errorf("TotalDownloads = %d, want 2", stored.TotalDownloads)
+	}
+	if len(updates) != 2 || updates[1].Type != VideoUpdated || updates[1].Video.TotalDownloads != 2 {
+		t.Errorf("events = %+v, want two updates ending at 2 downloads", updates)
+	}
+
+	if _, err := r.RecordVideoDownload("missing"); err == nil {
+		t.Error("RecordVideoDownload() of an unknown video succeeded")
+	}
+}
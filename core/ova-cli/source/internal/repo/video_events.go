@@ -0,0 +1,86 @@
+package repo
+
+import (
+	"sync"
+
+	"ova-cli/source/internal/datatypes"
+)
+
+// VideoEventType tells what happened to a video.
+type VideoEventType string
+
+const (
+	VideoIndexed VideoEventType = "indexed" // Added to the repository by indexing, importing or restoring it
+	VideoUpdated VideoEventType = "updated" // Its metadata changed
+	VideoDeleted VideoEventType = "deleted" // Removed from the repository
+)
+
+// VideoEvent describes a change of one video. Video holds the data after the change and is
+// nil for deletions.
+type VideoEvent struct {
+	Type    VideoEventType
+	VideoID string
+	Video   *datatypes.VideoData
+}
+
+// videoEventBus delivers the video events of a repository to the handlers subscribed in
+// this process, e.g. the in-memory video cache.
+type videoEventBus struct {
+	mu       sync.RWMutex
+	handlers []func(VideoEvent)
+}
+
+// SubscribeVideoEvents registers handler to be called after every change of a video of this
+// repository. Handlers run synchronously, in the order they subscribed, so the change is
+// visible to them before the call that made it returns; they must not block.
+func (r *RepoManager) SubscribeVideoEvents(handler func(VideoEvent)) {
+	r.videoEvents.mu.Lock()
+	defer r.videoEvents.mu.Unlock()
+	r.videoEvents.handlers = append(r.videoEvents.handlers, handler)
+}
+
+func (r *RepoManager) publishVideoEvent(event VideoEvent) {
+	r.videoEvents.mu.RLock()
+	handlers := r.videoEvents.handlers
+	r.videoEvents.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
+
+// videoIndexed announces a video added to the repository.
+func (r *RepoManager) videoIndexed(video datatypes.VideoData) {
+	r.publishVideoEvent(VideoEvent{Type: VideoIndexed, VideoID: video.VideoID, Video: &video})
+}
+
+// videoUpdated announces new metadata of a video.
+func (r *RepoManager) videoUpdated(video datatypes.VideoData) {
+	r.publishVideoEvent(VideoEvent{Type: VideoUpdated, VideoID: video.VideoID, Video: &video})
+}
+
+// videoUpdatedByID announces a change made in storage without the caller holding the
+// result, such as a new tag. The video is read back to announce its current data.
+func (r *RepoManager) videoUpdatedByID(videoID string) {
+	video, err := r.diskDataStorage.GetVideoByID(videoID)
+	if err != nil {
+		return
+	}
+	r.videoUpdated(*video)
+}
+
+// videoDeleted announces a video removed from the repository.
+func (r *RepoManager) videoDeleted(videoID string) {
+	r.publishVideoEvent(VideoEvent{Type: VideoDeleted, VideoID: videoID})
+}
+
+// namespaced returns the event as the parent of the named sub repository publishes it.
+func (e VideoEvent) namespaced(subRepoName string) VideoEvent {
+	e.VideoID = NamespacedVideoID(subRepoName, e.VideoID)
+	if e.Video != nil {
+		video := *e.Video
+		video.VideoID = e.VideoID
+		e.Video = &video
+	}
+	return e
+}
@@ -0,0 +1,148 @@
+package repo
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"ova-cli/source/internal/datatypes"
+	"ova-cli/source/internal/filehash"
+	"path/filepath"
+	"strconv"
+)
+
+// fingerprintFrames is the number of evenly spaced frames hashed per video.
+const fingerprintFrames = 9
+
+// fingerprintPositions returns the timestamps of the frames a fingerprint is made of,
+// leaving out the very start and end where intros and fades differ between copies.
+func fingerprintPositions(durationSec float64) []float64 {
+	positions := make([]float64, fingerprintFrames)
+	for i := range positions {
+		positions[i] = durationSec * float64(i+1) / float64(fingerprintFrames+1)
+	}
+	return positions
+}
+
+// fingerprintFromKeyframes builds a fingerprint from the keyframes extracted while cooking,
+// hashing the keyframe closest to each fingerprint position. keyframeTimes[i] is the
+// timestamp of keyframe_<i+1>.jpg.
+func fingerprintFromKeyframes(keyframeDir string, keyframeTimes []float64, durationSec float64) (*datatypes.VideoFingerprint, error) {
+	if len(keyframeTimes) == 0 || durationSec <= 0 {
+		return nil, fmt.Errorf("no keyframes to fingerprint")
+	}
+
+	fingerprint := &datatypes.VideoFingerprint{DurationSec: durationSec}
+	for _, position := range fingerprintPositions(durationSec) {
+		closest := 0
+		for i, t := range keyframeTimes {
+			if math.Abs(t-position) < math.Abs(keyframeTimes[closest]-position) {
+				closest = i
+			}
+		}
+
+		hash, err := filehash.DHashImageFile(filepath.Join(keyframeDir, fmt.Sprintf("keyframe_%04d.jpg", closest+1)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash keyframe: %w", err)
+		}
+		fingerprint.FrameHashes = append(fingerprint.FrameHashes, strconv.FormatUint(hash, 16))
+	}
+	return fingerprint, nil
+}
+
+// FingerprintVideo computes and stores the fingerprint of a video by grabbing frames at the
+// fingerprint positions. Cooking fingerprints videos on its own; this is for videos cooked
+// before fingerprints were recorded.
+func (r *RepoManager) FingerprintVideo(videoID string) (*datatypes.VideoFingerprint, error) {
+	if !r.IsDataStorageInitialized() {
+		return nil, fmt.Errorf("data storage is not initialized")
+	}
+
+	video, err := r.diskDataStorage.GetVideoByID(videoID)
+	if err != nil {
+		return nil, err
+	}
+	videoPath, err := r.GetVideoFilePathByID(videoID)
+	if err != nil {
+		return nil, err
+	}
+
+	durationSec, err := r.GetVideoDuration(videoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	frameDir, err := os.MkdirTemp("", "ova-fingerprint-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(frameDir)
+
+	fingerprint := &datatypes.VideoFingerprint{DurationSec: durationSec}
+	for i, position := range fingerprintPositions(durationSec) {
+		framePath := filepath.Join(frameDir, fmt.Sprintf("frame_%02d.jpg", i))
+		if err := r.mediaToolchain.Thumbnail(videoPath, framePath, position); err != nil {
+			return nil, fmt.Errorf("failed to grab frame at %.1fs: %w", position, err)
+		}
+		hash, err := filehash.DHashImageFile(framePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash frame at %.1fs: %w", position, err)
+		}
+		fingerprint.FrameHashes = append(fingerprint.FrameHashes, strconv.FormatUint(hash, 16))
+	}
+
+	video.Fingerprint = fingerprint
+	if err := r.diskDataStorage.UpdateVideo(*video); err != nil {
+		return nil, fmt.Errorf("failed to save video metadata: %w", err)
+	}
+	r.videoUpdated(*video)
+	return fingerprint, nil
+}
+
+// fingerprintCookedVideo computes and stores a fingerprint from the keyframes extracted while cooking.
+func (r *RepoManager) fingerprintCookedVideo(videoID, videoPath, keyframeDir string, keyframeTimes []float64) error {
+	durationSec, err := r.GetVideoDuration(videoPath)
+	if err != nil {
+		return err
+	}
+	fingerprint, err := fingerprintFromKeyframes(keyframeDir, keyframeTimes, durationSec)
+	if err != nil {
+		return err
+	}
+
+	video, err := r.diskDataStorage.GetVideoByID(videoID)
+	if err != nil {
+		return err
+	}
+	video.Fingerprint = fingerprint
+	if err := r.diskDataStorage.UpdateVideo(*video); err != nil {
+		return err
+	}
+	r.videoUpdated(*video)
+	return nil
+}
+
+// fingerprintSimilarity compares two fingerprints frame by frame and returns 1 for identical
+// frames down to about 0.5 for unrelated ones. Videos whose durations differ by more than
+// two seconds or 2% are different videos and score 0.
+func fingerprintSimilarity(a, b *datatypes.VideoFingerprint) float64 {
+	tolerance := math.Max(2, 0.02*math.Max(a.DurationSec, b.DurationSec))
+	if math.Abs(a.DurationSec-b.DurationSec) > tolerance {
+		return 0
+	}
+
+	n := min(len(a.FrameHashes), len(b.FrameHashes))
+	if n == 0 {
+		return 0
+	}
+
+	var total float64
+	for i := 0; i < n; i++ {
+		ha, errA := strconv.ParseUint(a.FrameHashes[i], 16, 64)
+		hb, errB := strconv.ParseUint(b.FrameHashes[i], 16, 64)
+		if errA != nil || errB != nil {
+			return 0
+		}
+		total += 1 - float64(filehash.HammingDistance(ha, hb))/64
+	}
+	return total / float64(n)
+}
@@ -0,0 +1,241 @@
+package repo
+
+import (
+	"fmt"
+	"os"
+	"ova-cli/source/internal/datatypes"
+	"ova-cli/source/internal/filehash"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// collisionSuffixLength is how many characters of the content hash are appended to the
+// partial hash when two different files share their first and last 5 MB.
+const collisionSuffixLength = 16
+
+// ResolveVideoID returns the ID of the video stored in a file. The partial hash is the ID of
+// the first file indexed with it; files whose partial hash collided with another video carry
+// a suffix derived from their full content. Whenever an indexed video shares the partial hash,
+// the whole file is read and compared with its content hash, so a different file with the same
+// first and last 5 MB never resolves to it. For files that are not indexed it returns the ID
+// they would get.
+func (r *RepoManager) ResolveVideoID(absoluteVideoPath string) (string, error) {
+	partialID, err := r.GenerateVideoID(absoluteVideoPath)
+	if err != nil {
+		return "", err
+	}
+
+	candidates, err := r.getVideosByPartialID(partialID)
+	if err != nil {
+		return "", err
+	}
+	if !slices.ContainsFunc(candidates, func(video datatypes.VideoData) bool { return video.VideoID == partialID }) {
+		// The partial hash is free, so the file is either unindexed or indexed under it
+		return partialID, nil
+	}
+
+	contentHash, size, err := filehash.XXH3FullFileHash(absoluteVideoPath)
+	if err != nil {
+		return "", fmt.Errorf("filehash compute failed for %s: %w", absoluteVideoPath, err)
+	}
+
+	for _, video := range candidates {
+		// Videos indexed before sizes were recorded match any size
+		if video.FileSize != 0 && video.FileSize != size {
+			continue
+		}
+
+		existingHash := video.ContentHash
+		if existingHash == "" {
+			// Indexed before content hashes were recorded: hash its file now. If the file is
+			// gone the videos cannot be told apart, so the match stays unverified.
+			existingHash = r.recordContentHash(video)
+			if existingHash == "" {
+				return video.VideoID, nil
+			}
+		}
+		if existingHash == contentHash {
+			return video.VideoID, nil
+		}
+	}
+	return collisionVideoID(partialID, contentHash), nil
+}
+
+// newVideoIdentity computes the ID, size and content hash of a file about to be indexed.
+// It fails if the same content is already indexed, and picks a collision ID if a different
+// file already uses the partial hash.
+func (r *RepoManager) newVideoIdentity(absoluteVideoPath string) (string, int64, string, error) {
+	partialID, err := r.GenerateVideoID(absoluteVideoPath)
+	if err != nil {
+		return "", 0, "", err
+	}
+	contentHash, size, err := filehash.XXH3FullFileHash(absoluteVideoPath)
+	if err != nil {
+		return "", 0, "", fmt.Errorf("filehash compute failed for %s: %w", absoluteVideoPath, err)
+	}
+
+	candidates, err := r.getVideosByPartialID(partialID)
+	if err != nil {
+		return "", 0, "", err
+	}
+
+	videoID := partialID
+	for _, video := range candidates {
+		if video.VideoID == partialID {
+			videoID = collisionVideoID(partialID, contentHash)
+		}
+
+		existingHash := video.ContentHash
+		if existingHash == "" {
+			// Indexed before content hashes were recorded: hash its file now. If the file is
+			// gone the videos cannot be told apart, so keep treating them as the same one.
+			existingHash = r.recordContentHash(video)
+			if existingHash == "" {
+				return "", 0, "", fmt.Errorf("video with ID %s is already indexed", video.VideoID)
+			}
+		}
+		if existingHash == contentHash {
+			return "", 0, "", fmt.Errorf("video with ID %s is already indexed", video.VideoID)
+		}
+	}
+	return videoID, size, contentHash, nil
+}
+
+// getVideosByPartialID returns the indexed videos whose ID is the partial hash or derives from it.
+func (r *RepoManager) getVideosByPartialID(partialID string) ([]datatypes.VideoData, error) {
+	videos, err := r.diskDataStorage.GetAllVideos()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list videos: %w", err)
+	}
+
+	var matches []datatypes.VideoData
+	for _, video := range videos {
+		if video.VideoID == partialID || strings.HasPrefix(video.VideoID, partialID+"-") {
+			matches = append(matches, video)
+		}
+	}
+	return matches, nil
+}
+
+// recordContentHash hashes the file of a video indexed before content hashes were stored and
+// saves the result. It returns an empty string if the file cannot be read.
+func (r *RepoManager) recordContentHash(video datatypes.VideoData) string {
+	videoPath, err := r.GetVideoFilePathByID(video.VideoID)
+	if err != nil {
+		return ""
+	}
+	contentHash, size, err := filehash.XXH3FullFileHash(videoPath)
+	if err != nil {
+		return ""
+	}
+
+	video.ContentHash = contentHash
+	video.FileSize = size
+	if err := r.diskDataStorage.UpdateVideo(video); err != nil {
+		fmt.Printf("Warning: failed to record content hash of %s: %v\n", video.VideoID, err)
+	} else {
+		r.videoUpdated(video)
+	}
+	return contentHash
+}
+
+func collisionVideoID(partialID, contentHash string) string {
+	return partialID + "-" + contentHash[:collisionSuffixLength]
+}
+
+// VerifyVideos hashes every video file of this repository in full and compares the result
+// with the size and hash stored when it was indexed, to find bit rot and edited files.
+// Videos without a stored hash get one recorded. When accept is set, the stored size and
+// hash of modified or corrupted videos are replaced with the current ones.
+func (r *RepoManager) VerifyVideos(accept bool) ([]datatypes.VideoVerification, error) {
+	if !r.IsDataStorageInitialized() {
+		return nil, fmt.Errorf("data storage is not initialized")
+	}
+
+	videos, err := r.diskDataStorage.GetAllVideos()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list videos: %w", err)
+	}
+
+	results := make([]datatypes.VideoVerification, len(videos))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	// Hashing is mostly I/O bound, so a few files are read at the same time
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = r.verifyVideo(videos[i])
+			}
+		}()
+	}
+	for i := range videos {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	// Store hashes one by one, since every update rewrites the storage file
+	for i, result := range results {
+		update := result.Status == datatypes.VerifyRecorded ||
+			accept && (result.Status == datatypes.VerifyModified || result.Status == datatypes.VerifyCorrupted)
+		if !update {
+			continue
+		}
+
+		video := videos[i]
+		video.FileSize = result.ActualSize
+		video.ContentHash = result.ActualHash
+		if err := r.diskDataStorage.UpdateVideo(video); err != nil {
+			return results, fmt.Errorf("failed to save hash of %s: %w", video.VideoID, err)
+		}
+		r.videoUpdated(video)
+	}
+	return results, nil
+}
+
+// verifyVideo hashes one video file and compares it with the stored identity.
+func (r *RepoManager) verifyVideo(video datatypes.VideoData) datatypes.VideoVerification {
+	result := datatypes.VideoVerification{
+		VideoID:      video.VideoID,
+		ExpectedSize: video.FileSize,
+		ExpectedHash: video.ContentHash,
+	}
+
+	videoPath, err := r.GetVideoFilePathByID(video.VideoID)
+	if err != nil {
+		result.Status = datatypes.VerifyFailed
+		result.Error = err.Error()
+		return result
+	}
+	result.Path = videoPath
+
+	contentHash, size, err := filehash.XXH3FullFileHash(videoPath)
+	switch {
+	case os.IsNotExist(err):
+		result.Status = datatypes.VerifyMissing
+		return result
+	case err != nil:
+		result.Status = datatypes.VerifyFailed
+		result.Error = err.Error()
+		return result
+	}
+	result.ActualSize = size
+	result.ActualHash = contentHash
+
+	switch {
+	case video.ContentHash == "":
+		result.Status = datatypes.VerifyRecorded
+	case video.FileSize != 0 && video.FileSize != size:
+		result.Status = datatypes.VerifyModified
+	case video.ContentHash != contentHash:
+		result.Status = datatypes.VerifyCorrupted
+	default:
+		result.Status = datatypes.VerifyOK
+	}
+	return result
+}
@@ -0,0 +1,72 @@
+package repo
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestResolveVideoID(t *testing.T) {
+	r, _ := newTestRepo(t)
+
+	// Same size and same first and last 5 MB, like two screen captures of one template
+	content := make([]byte, 11<<20)
+	changedMiddle := slices.Clone(content)
+	changedMiddle[len(changedMiddle)/2] = 1
+
+	write := func(name string, data []byte) string {
+		path := filepath.Join(r.GetRootPath(), "Captures", name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	firstPath := write("first.mp4", content)
+	secondPath := write("second.mp4", changedMiddle)
+
+	first, err := r.IndexVideo(firstPath)
+	if err != nil {
+		t.Fatalf("IndexVideo() error = %v", err)
+	}
+
+	if id, err := r.ResolveVideoID(firstPath); err != nil || id != first.VideoID {
+		t.Errorf("ResolveVideoID(first) = %q, %v; want %q", id, err, first.VideoID)
+	}
+	unindexedID, err := r.ResolveVideoID(secondPath)
+	if err != nil || unindexedID == first.VideoID {
+		t.Errorf("ResolveVideoID(second) = %q, %v; want an ID other than the first video's", unindexedID, err)
+	}
+
+	second, err := r.IndexVideo(secondPath)
+	if err != nil {
+		t.Fatalf("IndexVideo(second) error = %v", err)
+	}
+	if second.VideoID != unindexedID {
+		t.Errorf("second video indexed as %q, resolved beforehand as %q", second.VideoID, unindexedID)
+	}
+	if id, err := r.ResolveVideoID(secondPath); err != nil || id != second.VideoID {
+		t.Errorf("ResolveVideoID(second) = %q, %v; want %q", id, err, second.VideoID)
+	}
+
+	// A video indexed before content hashes were stored is confirmed and gets its hash recorded
+	legacy := first
+	legacy.ContentHash = ""
+	legacy.FileSize = 0
+	if err := r.diskDataStorage.UpdateVideo(legacy); err != nil {
+		t.Fatal(err)
+	}
+	if id, err := r.ResolveVideoID(secondPath); err != nil || id != second.VideoID {
+		t.Errorf("ResolveVideoID(second) next to a legacy video = %q, %v; want %q", id, err, second.VideoID)
+	}
+	stored, err := r.GetVideoByID(first.VideoID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.ContentHash != first.ContentHash {
+		t.Errorf("legacy content hash = %q, want it recorded as %q", stored.ContentHash, first.ContentHash)
+	}
+}
@@ -34,16 +34,12 @@ func (r *RepoManager) IndexVideo(absolutePath string) (datatypes.VideoData, erro
 
 	pathSegments := utils.GetPathSegments(filepath.Dir(relativePath))
 
-	// 4. Generate unique video ID
-	videoID, err := r.GenerateVideoID(absolutePath)
+	// 4. Generate unique video ID from the partial hash, file size and full content hash
+	videoID, fileSize, contentHash, err := r.newVideoIdentity(absolutePath)
 	if err != nil {
 		return datatypes.VideoData{}, err
 	}
 
-	if r.CheckVideoIndexedByID(videoID) {
-		return datatypes.VideoData{}, fmt.Errorf("video with ID %s is already indexed", videoID)
-	}
-
 	codec, err := r.GetVideoCodect(absolutePath)
 	if err != nil {
 		return datatypes.VideoData{}, fmt.Errorf("failed to get codecs for file: %w", err)
@@ -65,6 +61,8 @@ func (r *RepoManager) IndexVideo(absolutePath string) (datatypes.VideoData, erro
 	videoData := datatypes.NewVideoData(videoID)
 	videoData.FileName = title
 	videoData.Codecs = codec
+	videoData.FileSize = fileSize
+	videoData.ContentHash = contentHash
 	videoData.OwnedSpace = pathSegments.Root
 	videoData.OwnedGroup = pathSegments.Subroot
 
@@ -74,6 +72,12 @@ func (r *RepoManager) IndexVideo(absolutePath string) (datatypes.VideoData, erro
 	if err := r.diskDataStorage.AddVideo(videoData); err != nil {
 		return datatypes.VideoData{}, fmt.Errorf("failed to save video metadata: %w", err)
 	}
+	r.videoIndexed(videoData)
+
+	// 9. Convert sidecar and embedded subtitles to WebVTT
+	if _, err := r.DiscoverSubtitles(videoID, absolutePath); err != nil {
+		fmt.Printf("Warning: some subtitles of %s could not be imported: %v\n", absolutePath, err)
+	}
 
 	return videoData, nil
 }
@@ -134,7 +138,7 @@ func (r *RepoManager) UnIndexVideo(videoPath string) error {
 	}
 
 	// 1. Compute video ID
-	videoID, err := r.GenerateVideoID(videoPath)
+	videoID, err := r.ResolveVideoID(videoPath)
 	if err != nil {
 		return fmt.Errorf("failed to compute video ID: %w", err)
 	}
@@ -150,6 +154,7 @@ func (r *RepoManager) UnIndexVideo(videoPath string) error {
 	if err := r.diskDataStorage.DeleteVideoByID(videoID); err != nil {
 		return fmt.Errorf("failed to remove video metadata: %w", err)
 	}
+	r.videoDeleted(videoID)
 
 	fmt.Printf("Unregistered video: %s (ID: %s)\n", videoPath, videoID)
 	return nil
@@ -0,0 +1,120 @@
+package repo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"ova-cli/source/internal/toolchain/faketools"
+)
+
+// newTestRepo opens a repository in a temporary folder that processes media with the fake toolchain.
+func newTestRepo(t *testing.T) (*RepoManager, *faketools.FakeTools) {
+	t.Helper()
+	tools := faketools.NewFakeTools()
+	r, err := NewRepoManagerWithOptions(t.TempDir(), RepoOptions{MediaToolchain: tools})
+	if err != nil {
+		t.Fatalf("NewRepoManagerWithOptions() error = %v", err)
+	}
+	return r, tools
+}
+
+// writeTestVideo writes a video file below the repository root; content makes its ID unique.
+func writeTestVideo(t *testing.T, r *RepoManager, relPath, content string) string {
+	t.Helper()
+	path := filepath.Join(r.GetRootPath(), filepath.FromSlash(relPath))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("fake video "+content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestIndexVideo(t *testing.T) {
+	r, tools := newTestRepo(t)
+	path := writeTestVideo(t, r, "Movies/Classics/movie.mp4", "movie")
+
+	video, err := r.IndexVideo(path)
+	if err != nil {
+		t.Fatalf("IndexVideo() error = %v", err)
+	}
+
+	if video.FileName != "movie" || video.OwnedSpace != "Movies" || video.OwnedGroup != "Classics" {
+		t.Errorf("IndexVideo() = name %q, space %q, group %q; want movie, Movies, Classics",
+			video.FileName, video.OwnedSpace, video.OwnedGroup)
+	}
+	if video.Codecs.DurationSec != 60 || video.Codecs.Resolution.Width != 1280 || len(video.Codecs.Streams) != 2 {
+		t.Errorf("IndexVideo() codecs = %+v, want the fake toolchain's one minute 1280x720 video", video.Codecs)
+	}
+	if !fileExists(r.GetThumbnailFilePathByVideoID(video.VideoID)) {
+		t.Error("thumbnail was not generated")
+	}
+	if !fileExists(r.GetPreviewFilePathByVideoID(video.VideoID)) {
+		t.Error("preview was not generated")
+	}
+
+	stored, err := r.GetVideoByID(video.VideoID)
+	if err != nil {
+		t.Fatalf("GetVideoByID() error = %v", err)
+	}
+	if stored.FileSize != video.FileSize || stored.ContentHash == "" {
+		t.Errorf("stored video = %+v, want size %d and a content hash", stored, video.FileSize)
+	}
+
+	for _, call := range []string{"Probe movie.mp4", "Thumbnail movie.mp4", "Preview movie.mp4"} {
+		if !slices.Contains(tools.Calls(), call) {
+			t.Errorf("toolchain calls %v do not include %q", tools.Calls(), call)
+		}
+	}
+
+	if _, err := r.IndexVideo(path); err == nil {
+		t.Error("indexing the same video twice succeeded")
+	}
+}
+
+func TestIndexVideoToolchainFailures(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+	}{
+		{name: "probe fails", method: "Probe"},
+		{name: "thumbnail fails", method: "Thumbnail"},
+		{name: "preview fails", method: "Preview"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, tools := newTestRepo(t)
+			path := writeTestVideo(t, r, "Movies/movie.mp4", tt.name)
+
+			failure := errors.New("tool crashed")
+			tools.FailOn(tt.method, failure)
+
+			if _, err := r.IndexVideo(path); !errors.Is(err, failure) {
+				t.Fatalf("IndexVideo() error = %v, want %v", err, failure)
+			}
+			videos, err := r.GetAllIndexedVideos()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(videos) != 0 {
+				t.Errorf("%d videos stored after a failed index, want none", len(videos))
+			}
+
+			// The same file indexes once the tool works again
+			tools.FailOn(tt.method, nil)
+			if _, err := r.IndexVideo(path); err != nil {
+				t.Errorf("IndexVideo() after recovery error = %v", err)
+			}
+		})
+	}
+}
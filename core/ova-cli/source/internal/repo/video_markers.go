@@ -1,132 +1,185 @@
 package repo
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"ova-cli/source/internal/datatypes"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"sort"
 	"strings"
 )
 
-func (r *RepoManager) AddMarkerToVideo(videoID string, marker datatypes.VideoMarker) error {
-	// Calculate file path for the VTT marker file
-	filePath := r.GetVideoMarkerFilePathByVideoID(videoID)
+// Markers are stored as a WebVTT chapters file so players can load it directly.
+// Each cue carries the marker ID as its identifier, and the fields VTT has no place
+// for are kept in a NOTE block ahead of the cue, which players ignore:
+//
+//	NOTE ova-marker {"id":"...","description":"...","color":"#ff8800"}
+//
+//	<id>
+//	00:01:05.250 --> 00:02:00.000
+//	Title
+const markerNotePrefix = "NOTE ova-marker "
 
-	// Ensure the directory exists before adding the marker
-	dir := filepath.Dir(filePath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create marker directory %s: %w", dir, err)
+// markerIDPattern matches the marker IDs clients may choose. Each ID is written as a cue
+// identifier line, so it must be one word that cannot be mistaken for a timing line.
+// IDs starting with NOTE are rejected as well, since they would read back as a comment.
+var markerIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// fallbackMarkerLengthMs is the cue length of a last marker when the video duration is unknown.
+const fallbackMarkerLengthMs = 15000
+
+// markerNote holds the marker fields that are not part of a VTT cue.
+type markerNote struct {
+	ID          string `json:"id"`
+	EndMs       int64  `json:"endMs,omitempty"` // Only set when the end was chosen explicitly
+	Description string `json:"description,omitempty"`
+	Color       string `json:"color,omitempty"`
+	Category    string `json:"category,omitempty"`
+	Author      string `json:"author,omitempty"`
+}
+
+// AddMarkerToVideo adds a marker to a video and returns it with its new ID.
+func (r *RepoManager) AddMarkerToVideo(videoID string, marker datatypes.VideoMarker) (*datatypes.VideoMarker, error) {
+	marker.Normalize()
+	if err := marker.Validate(); err != nil {
+		return nil, err
 	}
+	marker.ID = datatypes.NewMarkerID()
 
-	// Fetch existing markers and add the new one
-	markers, err := r.GetMarkersForVideo(videoID)
+	err := r.editMarkers(videoID, func(markers []datatypes.VideoMarker) ([]datatypes.VideoMarker, error) {
+		return append(markers, marker), nil
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	markers = append(markers, marker)
-	return r.saveMarkersToVTT(videoID, markers)
+	return &marker, nil
 }
 
+// GetMarkersForVideo returns a video's markers ordered by start time.
 func (r *RepoManager) GetMarkersForVideo(videoID string) ([]datatypes.VideoMarker, error) {
-	// Calculate the file path for the VTT marker file dynamically
-	filePath := r.GetVideoMarkerFilePathByVideoID(videoID)
+	r.markersMu.Lock()
+	defer r.markersMu.Unlock()
+	return r.readMarkersFromVTT(videoID)
+}
 
-	// Read the VTT file and extract markers
-	data, err := os.ReadFile(filePath)
+// GetMarkerByID returns one marker of a video.
+func (r *RepoManager) GetMarkerByID(videoID, markerID string) (*datatypes.VideoMarker, error) {
+	markers, err := r.GetMarkersForVideo(videoID)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return []datatypes.VideoMarker{}, nil // Return empty list if the file does not exist
-		}
 		return nil, err
 	}
+	idx := slices.IndexFunc(markers, func(m datatypes.VideoMarker) bool { return m.ID == markerID })
+	if idx < 0 {
+		return nil, fmt.Errorf("marker %q not found for video %q", markerID, videoID)
+	}
+	return &markers[idx], nil
+}
 
-	content := string(data)
-	lines := strings.Split(content, "\n")
-
-	var markers []datatypes.VideoMarker
-	state := 0 // 0: initial, 1: after WEBVTT/blank, 2: reading title
-	var currentStartVTT string
-	var currentTitle string
+// UpdateMarker replaces the fields of an existing marker. The ID is kept, and so is
+// the author when the update does not name one.
+func (r *RepoManager) UpdateMarker(videoID, markerID string, marker datatypes.VideoMarker) (*datatypes.VideoMarker, error) {
+	marker.Normalize()
+	if err := marker.Validate(); err != nil {
+		return nil, err
+	}
+	marker.ID = markerID
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
+	err := r.editMarkers(videoID, func(markers []datatypes.VideoMarker) ([]datatypes.VideoMarker, error) {
+		idx := slices.IndexFunc(markers, func(m datatypes.VideoMarker) bool { return m.ID == markerID })
+		if idx < 0 {
+			return nil, fmt.Errorf("marker %q not found for video %q", markerID, videoID)
 		}
-
-		switch state {
-		case 0:
-			if line == "WEBVTT" {
-				state = 1
-				continue
-			} else {
-				return nil, fmt.Errorf("invalid VTT file: missing WEBVTT header")
-			}
-		case 1: // Expecting cue timing (e.g., "00:00:00.000 --> 00:00:10.000")
-			if strings.Contains(line, "-->") {
-				parts := strings.Split(line, " --> ")
-				if len(parts) != 2 {
-					return nil, fmt.Errorf("invalid cue timing: %s", line)
-				}
-				currentStartVTT = parts[0]
-				state = 2
-			} else {
-				continue // Skip cue id or other non-timing lines
-			}
-		case 2: // Expecting marker title
-			currentTitle = line
-			h, m, s, err := datatypes.ParseVTTToHMS(currentStartVTT)
-			if err != nil {
-				return nil, fmt.Errorf("failed to parse VTT timestamp '%s': %w", currentStartVTT, err)
-			}
-			markers = append(markers, datatypes.VideoMarker{
-				Hour:   h,
-				Minute: m,
-				Second: s,
-				Title:  currentTitle,
-			})
-			state = 1
+		if marker.Author == "" {
+			marker.Author = markers[idx].Author
 		}
+		markers[idx] = marker
+		return markers, nil
+	})
+	if err != nil {
+		return nil, err
 	}
-
-	return markers, nil
+	return &marker, nil
 }
 
-func (r *RepoManager) DeleteMarkerFromVideo(videoID string, markerToDelete datatypes.VideoMarker) error {
-	// Get markers for the video
-	markers, err := r.GetMarkersForVideo(videoID)
+// ReplaceMarkers replaces all markers of a video in one write. Markers keep the ID they
+// are given, so clients can resubmit an edited list; markers without one get a new ID.
+func (r *RepoManager) ReplaceMarkers(videoID string, markers []datatypes.VideoMarker) ([]datatypes.VideoMarker, error) {
+	seen := make(map[string]bool, len(markers))
+	replacement := make([]datatypes.VideoMarker, 0, len(markers))
+	for _, marker := range markers {
+		marker.Normalize()
+		if err := marker.Validate(); err != nil {
+			return nil, err
+		}
+		if marker.ID == "" {
+			marker.ID = datatypes.NewMarkerID()
+		}
+		if !markerIDPattern.MatchString(marker.ID) || strings.HasPrefix(marker.ID, "NOTE") {
+			return nil, fmt.Errorf("invalid marker ID %q: use up to 64 letters, digits, hyphens and underscores, not starting with NOTE", marker.ID)
+		}
+		if seen[marker.ID] {
+			return nil, fmt.Errorf("duplicate marker ID %q", marker.ID)
+		}
+		seen[marker.ID] = true
+		replacement = append(replacement, marker)
+	}
+
+	err := r.editMarkers(videoID, func([]datatypes.VideoMarker) ([]datatypes.VideoMarker, error) {
+		return replacement, nil
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return r.GetMarkersForVideo(videoID)
+}
+
+// DeleteMarkerByID removes one marker of a video.
+func (r *RepoManager) DeleteMarkerByID(videoID, markerID string) error {
+	return r.editMarkers(videoID, func(markers []datatypes.VideoMarker) ([]datatypes.VideoMarker, error) {
+		idx := slices.IndexFunc(markers, func(m datatypes.VideoMarker) bool { return m.ID == markerID })
+		if idx < 0 {
+			return nil, fmt.Errorf("marker %q not found for video %q", markerID, videoID)
+		}
+		return slices.Delete(markers, idx, idx+1), nil
+	})
+}
 
-	// Format the timestamp for the marker to be deleted
+// DeleteMarkerFromVideo removes the marker starting in the same whole second as markerToDelete.
+// It fails when several markers start in that second; use DeleteMarkerByID for those.
+func (r *RepoManager) DeleteMarkerFromVideo(videoID string, markerToDelete datatypes.VideoMarker) error {
+	markerToDelete.StartMs = 0
+	markerToDelete.Normalize()
+	targetSecond := markerToDelete.StartMs / 1000
 	targetVTTTimestamp := datatypes.FormatHMSToVTT(markerToDelete.Hour, markerToDelete.Minute, markerToDelete.Second)
 
-	// Filter out the marker to delete
-	var filtered []datatypes.VideoMarker
-	var markerDeleted bool
-	for _, m := range markers {
-		storedVTTTimestamp := datatypes.FormatHMSToVTT(m.Hour, m.Minute, m.Second)
-		if storedVTTTimestamp == targetVTTTimestamp {
-			// Skip the marker to delete
-			markerDeleted = true
-			continue
+	return r.editMarkers(videoID, func(markers []datatypes.VideoMarker) ([]datatypes.VideoMarker, error) {
+		matches := 0
+		filtered := markers[:0]
+		for _, m := range markers {
+			if m.StartMs/1000 == targetSecond {
+				matches++
+				continue
+			}
+			filtered = append(filtered, m)
 		}
-		// Keep other markers
-		filtered = append(filtered, m)
-	}
-
-	if !markerDeleted {
-		return fmt.Errorf("marker with timestamp '%s' not found for deletion", targetVTTTimestamp)
-	}
 
-	// Re-save the remaining markers back to the VTT file
-	return r.saveMarkersToVTT(videoID, filtered)
+		switch {
+		case matches == 0:
+			return nil, fmt.Errorf("marker with timestamp '%s' not found for deletion", targetVTTTimestamp)
+		case matches > 1:
+			return nil, fmt.Errorf("%d markers start at '%s'; delete them by ID", matches, targetVTTTimestamp)
+		}
+		return filtered, nil
+	})
 }
 
 func (r *RepoManager) DeleteAllMarkersFromVideo(videoID string) error {
+	r.markersMu.Lock()
+	defer r.markersMu.Unlock()
+
 	// Calculate the file path for the VTT marker file
 	filePath := r.GetVideoMarkerFilePathByVideoID(videoID)
 	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
@@ -135,12 +188,39 @@ func (r *RepoManager) DeleteAllMarkersFromVideo(videoID string) error {
 	return nil
 }
 
-func (r *RepoManager) saveMarkersToVTT(videoID string, markers []datatypes.VideoMarker) error {
-	// Sort markers by total seconds
-	sort.Slice(markers, func(i, j int) bool {
-		return markers[i].ConvertToSeconds() < markers[j].ConvertToSeconds()
-	})
+// editMarkers reads a video's markers, applies fn and writes the result, all under the markers lock.
+func (r *RepoManager) editMarkers(videoID string, fn func([]datatypes.VideoMarker) ([]datatypes.VideoMarker, error)) error {
+	r.markersMu.Lock()
+	defer r.markersMu.Unlock()
+
+	markers, err := r.readMarkersFromVTT(videoID)
+	if err != nil {
+		return err
+	}
+	markers, err = fn(markers)
+	if err != nil {
+		return err
+	}
+	return r.saveMarkersToVTT(videoID, markers)
+}
+
+// readMarkersFromVTT reads and parses a video's marker file.
+func (r *RepoManager) readMarkersFromVTT(videoID string) ([]datatypes.VideoMarker, error) {
+	// Calculate the file path for the VTT marker file dynamically
+	filePath := r.GetVideoMarkerFilePathByVideoID(videoID)
+
+	// Read the VTT file and extract markers
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []datatypes.VideoMarker{}, nil // Return empty list if the file does not exist
+		}
+		return nil, err
+	}
+	return parseMarkersVTT(string(data))
+}
 
+func (r *RepoManager) saveMarkersToVTT(videoID string, markers []datatypes.VideoMarker) error {
 	// Use GetVideoMarkerFilePathByVideoID to get the correct file path
 	filePath := r.GetVideoMarkerFilePathByVideoID(videoID)
 
@@ -150,54 +230,155 @@ func (r *RepoManager) saveMarkersToVTT(videoID string, markers []datatypes.Video
 		return fmt.Errorf("failed to create directory %s: %w", dir, err)
 	}
 
-	// Get video duration in seconds from video data
-	var videoDuration float64 = 600 // fallback default to 10 minutes
+	content, err := renderMarkersVTT(markers, r.getVideoDurationMs(videoID), true)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, []byte(content), 0644)
+}
+
+// getVideoDurationMs returns the indexed duration of a video, or 0 when it is unknown.
+func (r *RepoManager) getVideoDurationMs(videoID string) int64 {
 	videoData, err := r.GetVideoByID(videoID)
-	if err == nil && videoData != nil {
-		if videoData.Codecs.DurationSec > 0 {
-			videoDuration = float64(videoData.Codecs.DurationSec)
-		}
+	if err != nil || videoData == nil {
+		return 0
 	}
+	return int64(videoData.Codecs.DurationSec) * 1000
+}
+
+// parseMarkersVTT parses WebVTT chapter cues. Cues without an identifier, such as those
+// written before markers had IDs, get positional IDs ("cue-1", "cue-2", ...) that are
+// persisted on the next write.
+func parseMarkersVTT(content string) ([]datatypes.VideoMarker, error) {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.TrimPrefix(content, "\ufeff")
+	if !strings.HasPrefix(content, "WEBVTT") {
+		return nil, fmt.Errorf("invalid VTT file: missing WEBVTT header")
+	}
+
+	notes := make(map[string]markerNote)
+	markers := []datatypes.VideoMarker{}
+
+	// Blocks are separated by blank lines; the first one is the header
+	for _, block := range strings.Split(content, "\n\n")[1:] {
+		lines := strings.Split(strings.TrimSpace(block), "\n")
+		if len(lines) == 0 || lines[0] == "" {
+			continue
+		}
+
+		if strings.HasPrefix(lines[0], "NOTE") {
+			if payload, ok := strings.CutPrefix(lines[0], markerNotePrefix); ok {
+				var note markerNote
+				if err := json.Unmarshal([]byte(payload), &note); err == nil && note.ID != "" {
+					notes[note.ID] = note
+				}
+			}
+			continue
+		}
+
+		// A cue is an optional identifier line, the timing line and the payload
+		var id string
+		if !strings.Contains(lines[0], "-->") {
+			id = strings.TrimSpace(lines[0])
+			lines = lines[1:]
+		}
+		if len(lines) < 2 || !strings.Contains(lines[0], "-->") {
+			continue
+		}
+
+		start, _, _ := strings.Cut(lines[0], "-->")
+		startMs, err := datatypes.ParseVTTToMs(start)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse VTT timestamp '%s': %w", strings.TrimSpace(start), err)
+		}
 
-	// Calculate offset based on a ratio of video duration (e.g., 3%)
-	const ratio = 0.03
-	const minOffset = 0.2  // minimum 0.2 seconds offset
-	const maxOffset = 15.0 // max 15 seconds offset
+		if id == "" {
+			id = fmt.Sprintf("cue-%d", len(markers)+1)
+		}
 
-	offsetSeconds := videoDuration * ratio
-	if offsetSeconds < minOffset {
-		offsetSeconds = minOffset
-	} else if offsetSeconds > maxOffset {
-		offsetSeconds = maxOffset
+		marker := datatypes.VideoMarker{
+			ID:      id,
+			StartMs: startMs,
+			Title:   strings.Join(lines[1:], " "),
+		}
+		markers = append(markers, marker)
 	}
 
+	for i := range markers {
+		if note, ok := notes[markers[i].ID]; ok {
+			markers[i].EndMs = note.EndMs
+			markers[i].Description = note.Description
+			markers[i].Color = note.Color
+			markers[i].Category = note.Category
+			markers[i].Author = note.Author
+		}
+		markers[i].Normalize()
+	}
+
+	return markers, nil
+}
+
+// renderMarkersVTT writes markers as WebVTT chapter cues, sorting them by start time.
+// withNotes adds the NOTE blocks holding the fields a cue cannot carry.
+func renderMarkersVTT(markers []datatypes.VideoMarker, videoDurationMs int64, withNotes bool) (string, error) {
+	sortMarkers(markers)
+	ends := markerEndsMs(markers, videoDurationMs)
+
 	var sb strings.Builder
 	sb.WriteString("WEBVTT\n\n")
 
-	// Write markers to the VTT file
-	for _, marker := range markers {
-		startSeconds := marker.ConvertToSeconds()
-		endSeconds := startSeconds + offsetSeconds
-
-		// Format start and end time with milliseconds
-		totalStartMillis := int(startSeconds * 1000)
-		startH := totalStartMillis / (3600 * 1000)
-		startM := (totalStartMillis % (3600 * 1000)) / (60 * 1000)
-		startS := (totalStartMillis % (60 * 1000)) / 1000
-		startMS := totalStartMillis % 1000
-		startVTT := fmt.Sprintf("%02d:%02d:%02d.%03d", startH, startM, startS, startMS)
-
-		// Format end time with milliseconds
-		totalEndMillis := int(endSeconds * 1000)
-		endH := totalEndMillis / (3600 * 1000)
-		endM := (totalEndMillis % (3600 * 1000)) / (60 * 1000)
-		endS := (totalEndMillis % (60 * 1000)) / 1000
-		endMS := totalEndMillis % 1000
-		endVTT := fmt.Sprintf("%02d:%02d:%02d.%03d", endH, endM, endS, endMS)
-
-		sb.WriteString(fmt.Sprintf("%s --> %s\n", startVTT, endVTT))
+	for i, marker := range markers {
+		if withNotes {
+			note, err := json.Marshal(markerNote{
+				ID:          marker.ID,
+				EndMs:       marker.EndMs,
+				Description: marker.Description,
+				Color:       marker.Color,
+				Category:    marker.Category,
+				Author:      marker.Author,
+			})
+			if err != nil {
+				return "", fmt.Errorf("failed to encode marker %q: %w", marker.ID, err)
+			}
+			sb.WriteString(markerNotePrefix + string(note) + "\n\n")
+		}
+
+		sb.WriteString(marker.ID + "\n")
+		sb.WriteString(fmt.Sprintf("%s --> %s\n", datatypes.FormatMsToVTT(marker.StartMs), datatypes.FormatMsToVTT(ends[i])))
 		sb.WriteString(marker.Title + "\n\n")
 	}
 
-	return os.WriteFile(filePath, []byte(sb.String()), 0644)
+	return sb.String(), nil
+}
+
+// sortMarkers orders markers by start time, keeping the order of markers that start together.
+func sortMarkers(markers []datatypes.VideoMarker) {
+	sort.SliceStable(markers, func(i, j int) bool {
+		return markers[i].StartMs < markers[j].StartMs
+	})
+}
+
+// markerEndsMs returns the end of each sorted marker. Markers without an explicit end
+// run until the next one starts or the video ends.
+func markerEndsMs(markers []datatypes.VideoMarker, videoDurationMs int64) []int64 {
+	ends := make([]int64, len(markers))
+	for i, marker := range markers {
+		endMs := marker.EndMs
+		if endMs == 0 {
+			for _, next := range markers[i+1:] {
+				if next.StartMs > marker.StartMs {
+					endMs = next.StartMs
+					break
+				}
+			}
+		}
+		if endMs == 0 && videoDurationMs > marker.StartMs {
+			endMs = videoDurationMs
+		}
+		if endMs == 0 {
+			endMs = marker.StartMs + fallbackMarkerLengthMs
+		}
+		ends[i] = endMs
+	}
+	return ends
 }
@@ -0,0 +1,43 @@
+package repo
+
+import (
+	"strings"
+	"testing"
+
+	"ova-cli/source/internal/datatypes"
+)
+
+func TestReplaceMarkersRoundTrip(t *testing.T) {
+	r, _ := newTestRepo(t)
+	video, err := r.IndexVideo(writeTestVideo(t, r, "Movies/movie.mp4", "movie"))
+	if err != nil {
+		t.Fatalf("IndexVideo() error = %v", err)
+	}
+
+	markers := []datatypes.VideoMarker{
+		{ID: "intro_1", StartMs: 0, Title: "Intro", Color: "#ff8800"},
+		{ID: "NOTABLE-scene", StartMs: 5000, Title: "Scene"},
+		{StartMs: 30000, Title: "Credits"},
+	}
+	if _, err := r.ReplaceMarkers(video.VideoID, markers); err != nil {
+		t.Fatalf("ReplaceMarkers() error = %v", err)
+	}
+
+	got, err := r.GetMarkersForVideo(video.VideoID)
+	if err != nil {
+		t.Fatalf("GetMarkersForVideo() error = %v", err)
+	}
+	if len(got) != 3 || got[0].ID != "intro_1" || got[0].Color != "#ff8800" || got[1].ID != "NOTABLE-scene" || got[2].ID == "" {
+		t.Errorf("markers read back = %+v, want intro_1, NOTABLE-scene and a generated ID", got)
+	}
+
+	for _, id := range []string{"NOTE", "NOTE-1", " ", "two words", "a\nb", "a-->b", strings.Repeat("x", 65)} {
+		marker := datatypes.VideoMarker{ID: id, StartMs: 1000, Title: "Bad"}
+		if _, err := r.ReplaceMarkers(video.VideoID, []datatypes.VideoMarker{marker}); err == nil {
+			t.Errorf("ReplaceMarkers() accepted marker ID %q", id)
+		}
+	}
+	if got, _ := r.GetMarkersForVideo(video.VideoID); len(got) != 3 {
+		t.Errorf("rejected replacements changed the markers to %+v", got)
+	}
+}
@@ -0,0 +1,132 @@
+package repo
+
+import (
+	"fmt"
+	"os"
+	"ova-cli/source/internal/datatypes"
+	"ova-cli/source/internal/utils"
+	"path/filepath"
+	"strings"
+)
+
+// MoveVideo moves or renames the file of a video within the repository and updates
+// its space, group and title to match. destination is a file or folder path,
+// absolute or relative to the repository root; a folder keeps the file name and a
+// name without extension gets the video's own. The video ID does not change, so
+// tags, markers, playlists, saved and watched lists keep pointing at the video.
+// If the metadata cannot be updated the file is moved back.
+func (r *RepoManager) MoveVideo(videoID, destination string) (*datatypes.VideoData, error) {
+	if !r.IsDataStorageInitialized() {
+		return nil, fmt.Errorf("data storage is not initialized")
+	}
+	if _, _, ok := SplitNamespacedVideoID(videoID); ok {
+		return nil, fmt.Errorf("video %s belongs to an attached repository", videoID)
+	}
+
+	video, err := r.diskDataStorage.GetVideoByID(videoID)
+	if err != nil {
+		return nil, fmt.Errorf("video %s not found: %w", videoID, err)
+	}
+	sourcePath, err := r.GetVideoFilePathByID(videoID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(sourcePath); err != nil {
+		return nil, fmt.Errorf("video file %s is missing: %w", sourcePath, err)
+	}
+
+	targetPath, relativePath, err := r.resolveMoveDestination(sourcePath, destination)
+	if err != nil {
+		return nil, err
+	}
+	if targetPath == sourcePath {
+		return video, nil
+	}
+	if _, err := os.Stat(targetPath); err == nil {
+		return nil, fmt.Errorf("%s already exists", relativePath)
+	}
+
+	// Remember the first folder that has to be created so a rollback can remove it again
+	createdDir := ""
+	for dir := filepath.Dir(targetPath); !r.FolderExists(dir); dir = filepath.Dir(dir) {
+		createdDir = dir
+	}
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create destination folder: %w", err)
+	}
+	removeCreatedDirs := func() {
+		if createdDir == "" {
+			return
+		}
+		for dir := filepath.Dir(targetPath); ; dir = filepath.Dir(dir) {
+			if os.Remove(dir) != nil || dir == createdDir {
+				return
+			}
+		}
+	}
+
+	if err := os.Rename(sourcePath, targetPath); err != nil {
+		removeCreatedDirs()
+		return nil, fmt.Errorf("failed to move video file: %w", err)
+	}
+
+	moved := *video
+	segments := utils.GetPathSegments(filepath.Dir(relativePath))
+	moved.FileName = strings.TrimSuffix(filepath.Base(targetPath), filepath.Ext(targetPath))
+	moved.OwnedSpace = segments.Root
+	moved.OwnedGroup = segments.Subroot
+
+	if err := r.diskDataStorage.UpdateVideoLocation(moved, relativePath); err != nil {
+		if rbErr := os.Rename(targetPath, sourcePath); rbErr != nil {
+			return nil, fmt.Errorf("failed to update video metadata: %v (rollback failed: %v)", err, rbErr)
+		}
+		removeCreatedDirs()
+		return nil, fmt.Errorf("failed to update video metadata: %w", err)
+	}
+
+	r.videoUpdated(moved)
+	return &moved, nil
+}
+
+// resolveMoveDestination turns a move destination into an absolute file path and the
+// same path relative to the repository root, rejecting anything outside the repository.
+func (r *RepoManager) resolveMoveDestination(sourcePath, destination string) (string, string, error) {
+	if strings.TrimSpace(destination) == "" {
+		return "", "", fmt.Errorf("destination cannot be empty")
+	}
+
+	root := r.GetRootPath()
+	isFolder := strings.HasSuffix(filepath.ToSlash(destination), "/")
+	target := filepath.FromSlash(destination)
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(root, target)
+	}
+	target = filepath.Clean(target)
+
+	if isFolder || r.FolderExists(target) {
+		target = filepath.Join(target, filepath.Base(sourcePath))
+	}
+
+	// The extension must match exactly, so the stored format never disagrees with the file name
+	sourceExt := filepath.Ext(sourcePath)
+	switch ext := filepath.Ext(target); {
+	case ext == "":
+		target += sourceExt
+	case ext != sourceExt:
+		return "", "", fmt.Errorf("cannot change the extension from %s to %s; moving does not convert the video", sourceExt, ext)
+	}
+
+	relativePath, err := filepath.Rel(root, target)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve destination: %w", err)
+	}
+	relativePath = filepath.ToSlash(relativePath)
+	if relativePath == ".." || strings.HasPrefix(relativePath, "../") {
+		return "", "", fmt.Errorf("destination %s is outside the repository", destination)
+	}
+	if first := strings.SplitN(relativePath, "/", 2)[0]; first == ".ova-repo" {
+		return "", "", fmt.Errorf("destination %s is inside the repository's data folder", destination)
+	}
+
+	return target, relativePath, nil
+}
@@ -0,0 +1,52 @@
+package repo
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestMoveVideo(t *testing.T) {
+	tests := []struct {
+		name        string
+		destination string
+		want        string
+		wantErr     bool
+	}{
+		{name: "rename", destination: "Movies/renamed.mp4", want: "Movies/renamed.mp4"},
+		{name: "name without extension", destination: "Movies/renamed", want: "Movies/renamed.mp4"},
+		{name: "into folder", destination: "Archive/", want: "Archive/movie.mp4"},
+		{name: "extension in other case", destination: "Movies/renamed.MP4", wantErr: true},
+		{name: "other extension", destination: "Movies/renamed.mkv", wantErr: true},
+		{name: "outside repository", destination: "../movie.mp4", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newTestRepo(t)
+			video, err := r.IndexVideo(writeTestVideo(t, r, "Movies/movie.mp4", tt.name))
+			if err != nil {
+				t.Fatalf("IndexVideo() error = %v", err)
+			}
+
+			moved, err := r.MoveVideo(video.VideoID, tt.destination)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("MoveVideo(%q) succeeded, want an error", tt.destination)
+				}
+				if !fileExists(filepath.Join(r.GetRootPath(), "Movies", "movie.mp4")) {
+					t.Error("video file moved despite the error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MoveVideo(%q) error = %v", tt.destination, err)
+			}
+			if got := GetVideoRelativePath(moved); got != tt.want {
+				t.Errorf("MoveVideo(%q) path = %q, want %q", tt.destination, got, tt.want)
+			}
+			if !fileExists(filepath.Join(r.GetRootPath(), filepath.FromSlash(tt.want))) {
+				t.Errorf("file was not moved to %s", tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"ova-cli/source/internal/datatypes"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// DefaultNearDuplicateThreshold is the similarity above which two videos are reported as
+// near-duplicates when no threshold is given. Unrelated videos score around 0.5.
+const DefaultNearDuplicateThreshold = 0.9
+
+// FindNearDuplicates compares the fingerprints of every video in this repository and groups
+// those at least threshold similar. Unlike ScanDiskForDuplicateVideos it also finds
+// re-encodes and other resolutions of the same video. Videos without a fingerprint are skipped.
+func (r *RepoManager) FindNearDuplicates(threshold float64) (datatypes.NearDuplicateReport, error) {
+	report := datatypes.NearDuplicateReport{Threshold: threshold, Groups: []datatypes.NearDuplicateGroup{}}
+	if !r.IsDataStorageInitialized() {
+		return report, fmt.Errorf("data storage is not initialized")
+	}
+	if threshold <= 0 || threshold > 1 {
+		return report, fmt.Errorf("threshold must be between 0 and 1")
+	}
+
+	all, err := r.diskDataStorage.GetAllVideos()
+	if err != nil {
+		return report, fmt.Errorf("failed to list videos: %w", err)
+	}
+	var videos []datatypes.VideoData
+	for _, video := range all {
+		if video.Fingerprint != nil && len(video.Fingerprint.FrameHashes) > 0 {
+			videos = append(videos, video)
+		}
+	}
+	report.Fingerprinted = len(videos)
+	report.Skipped = len(all) - len(videos)
+
+	// Link every similar pair; connected videos form one group
+	parent := make([]int, len(videos))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i := range videos {
+		for j := i + 1; j < len(videos); j++ {
+			if fingerprintSimilarity(videos[i].Fingerprint, videos[j].Fingerprint) >= threshold {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	members := map[int][]datatypes.VideoData{}
+	for i, video := range videos {
+		root := find(i)
+		members[root] = append(members[root], video)
+	}
+
+	for _, group := range members {
+		if len(group) < 2 {
+			continue
+		}
+
+		// Suggest keeping the best copy: highest resolution, then the largest file
+		sort.Slice(group, func(a, b int) bool {
+			pa := group[a].Codecs.Resolution.Width * group[a].Codecs.Resolution.Height
+			pb := group[b].Codecs.Resolution.Width * group[b].Codecs.Resolution.Height
+			if pa != pb {
+				return pa > pb
+			}
+			return group[a].FileSize > group[b].FileSize
+		})
+		keep := group[0]
+
+		result := datatypes.NearDuplicateGroup{KeepVideoID: keep.VideoID}
+		for _, video := range group {
+			result.Videos = append(result.Videos, datatypes.NearDuplicateVideo{
+				VideoID:     video.VideoID,
+				FileName:    video.FileName,
+				Path:        GetVideoRelativePath(&video),
+				DurationSec: video.Codecs.DurationSec,
+				Resolution:  video.Codecs.Resolution,
+				FileSize:    video.FileSize,
+				Similarity:  fingerprintSimilarity(keep.Fingerprint, video.Fingerprint),
+			})
+		}
+		sort.SliceStable(result.Videos[1:], func(a, b int) bool {
+			return result.Videos[a+1].Similarity > result.Videos[b+1].Similarity
+		})
+		report.Groups = append(report.Groups, result)
+	}
+
+	sort.Slice(report.Groups, func(a, b int) bool {
+		return report.Groups[a].KeepVideoID < report.Groups[b].KeepVideoID
+	})
+	return report, nil
+}
+
+// MergeDuplicateVideos keeps one copy of a video and removes the others from the library.
+// Tags of the removed copies are added to the kept one, and the kept copy takes their place
+// in every playlist and saved list. Users who watched a removed copy keep that in their
+// history, with the most recent progress carried over to the kept copy. When deleteFiles is set the removed copies' files are
+// deleted too; otherwise they stay on disk and show up as unindexed.
+func (r *RepoManager) MergeDuplicateVideos(keepID string, duplicateIDs []string, deleteFiles bool) (datatypes.DuplicateMergeReport, error) {
+	report := datatypes.DuplicateMergeReport{KeptVideoID: keepID, RemovedVideoIDs: []string{}, FilesDeleted: []string{}}
+	if !r.IsDataStorageInitialized() {
+		return report, fmt.Errorf("data storage is not initialized")
+	}
+	if len(duplicateIDs) == 0 {
+		return report, fmt.Errorf("no duplicates given")
+	}
+
+	// Validate everything before changing anything
+	keep, err := r.diskDataStorage.GetVideoByID(keepID)
+	if err != nil {
+		return report, fmt.Errorf("video %s not found: %w", keepID, err)
+	}
+	var duplicates []*datatypes.VideoData
+	for _, id := range duplicateIDs {
+		if id == keepID {
+			return report, fmt.Errorf("video %s cannot be both kept and removed", id)
+		}
+		if _, _, ok := SplitNamespacedVideoID(id); ok {
+			return report, fmt.Errorf("video %s belongs to an attached repository", id)
+		}
+		video, err := r.diskDataStorage.GetVideoByID(id)
+		if err != nil {
+			return report, fmt.Errorf("video %s not found: %w", id, err)
+		}
+		duplicates = append(duplicates, video)
+	}
+
+	// Tags
+	for _, video := range duplicates {
+		for _, tag := range video.Tags {
+			if slices.ContainsFunc(keep.Tags, func(t string) bool { return strings.EqualFold(t, tag) }) {
+				continue
+			}
+			if err := r.diskDataStorage.AddTagToVideo(keepID, tag); err != nil {
+				return report, fmt.Errorf("failed to add tag %q: %w", tag, err)
+			}
+			keep.Tags = append(keep.Tags, tag)
+			report.TagsAdded++
+		}
+	}
+	if report.TagsAdded > 0 {
+		r.videoUpdatedByID(keepID)
+	}
+
+	isDuplicate := func(id string) bool { return slices.Contains(duplicateIDs, id) }
+
+	// Playlists: the kept copy takes the position of the first duplicate
+	playlists, err := r.diskDataStorage.GetAllPlaylists()
+	if err != nil {
+		return report, fmt.Errorf("failed to load playlists: %w", err)
+	}
+	for username, userPlaylists := range playlists {
+		for _, playlist := range userPlaylists {
+			index := slices.IndexFunc(playlist.VideoIDs, isDuplicate)
+			if playlist.Rules != nil || index < 0 {
+				continue
+			}
+			if !slices.Contains(playlist.VideoIDs, keepID) {
+				if _, err := r.diskDataStorage.InsertVideosIntoPlaylist(username, playlist.Slug, []string{keepID}, index); err != nil {
+					return report, fmt.Errorf("failed to update playlist %s of %s: %w", playlist.Slug, username, err)
+				}
+			}
+			report.PlaylistsUpdated++
+		}
+	}
+
+	// Saved videos
+	users, err := r.diskDataStorage.GetAllUsers()
+	if err != nil {
+		return report, fmt.Errorf("failed to load users: %w", err)
+	}
+	for _, user := range users {
+		saved, err := r.diskDataStorage.GetUserSavedVideos(user.Username)
+		if err != nil || !slices.ContainsFunc(saved, isDuplicate) {
+			continue
+		}
+		if !slices.Contains(saved, keepID) {
+			if err := r.diskDataStorage.AddVideoToSaved(user.Username, keepID); err != nil {
+				return report, fmt.Errorf("failed to update saved videos of %s: %w", user.Username, err)
+			}
+		}
+		report.SavedUpdated++
+	}
+
+	// Watch history and progress
+	for _, user := range users {
+		changed, err := r.mergeWatchHistory(user.Username, keepID, duplicateIDs)
+		if err != nil {
+			report.Errors = append(report.Errors, err.Error())
+		}
+		if changed {
+			report.WatchHistoryUpdated++
+		}
+	}
+
+	// Remove the duplicates and whatever still references them
+	for _, video := range duplicates {
+		videoPath, pathErr := r.GetVideoFilePathByID(video.VideoID)
+
+		if err := r.diskDataStorage.RemoveVideoFromAllUsers(video.VideoID); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("failed to remove references to %s: %v", video.VideoID, err))
+			continue
+		}
+		if err := r.DeleteVideoArtefacts(video.VideoID); err != nil {
+			report.Errors = append(report.Errors, err.Error())
+		}
+		if err := r.diskDataStorage.DeleteVideoByID(video.VideoID); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("failed to delete %s: %v", video.VideoID, err))
+			continue
+		}
+		if err := r.diskDataStorage.RemoveVideoIDFromSpaces(video.VideoID); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("failed to remove %s from its space: %v", video.VideoID, err))
+		}
+		report.RemovedVideoIDs = append(report.RemovedVideoIDs, video.VideoID)
+		r.videoDeleted(video.VideoID)
+
+		if deleteFiles && pathErr == nil {
+			if err := os.Remove(videoPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+				report.Errors = append(report.Errors, fmt.Sprintf("failed to delete %s: %v", videoPath, err))
+				continue
+			}
+			report.FilesDeleted = append(report.FilesDeleted, videoPath)
+		}
+	}
+
+	return report, nil
+}
+
+// mergeWatchHistory moves username's history of the duplicates to keepID. The kept copy counts
+// as watched when any duplicate was, and takes the most recent progress unless its own is newer.
+// It reports whether anything changed.
+func (r *RepoManager) mergeWatchHistory(username, keepID string, duplicateIDs []string) (bool, error) {
+	changed := false
+
+	watched, err := r.diskDataStorage.GetUserWatchedVideos(username)
+	if err == nil && !slices.Contains(watched, keepID) &&
+		slices.ContainsFunc(watched, func(id string) bool { return slices.Contains(duplicateIDs, id) }) {
+		if err := r.diskDataStorage.AddVideoToWatched(username, keepID); err != nil {
+			return false, fmt.Errorf("failed to update watch history of %s: %w", username, err)
+		}
+		changed = true
+	}
+
+	var latest *datatypes.WatchProgress
+	for _, id := range duplicateIDs {
+		progress, err := r.diskDataStorage.GetWatchProgress(username, id)
+		if err == nil && (latest == nil || progress.LastWatchedAt.After(latest.LastWatchedAt)) {
+			latest = progress
+		}
+	}
+	if latest == nil {
+		return changed, nil
+	}
+	if current, err := r.diskDataStorage.GetWatchProgress(username, keepID); err == nil && !latest.LastWatchedAt.After(current.LastWatchedAt) {
+		return changed, nil
+	}
+	progress := *latest
+	progress.VideoID = keepID
+	if err := r.diskDataStorage.SaveWatchProgress(username, progress); err != nil {
+		return changed, fmt.Errorf("failed to update watch progress of %s: %w", username, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,81 @@
+package repo
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"ova-cli/source/internal/datatypes"
+)
+
+func TestMergeDuplicateVideosCleansSpacesAndMovesHistory(t *testing.T) {
+	r, _ := newTestRepo(t)
+	if err := r.CreateSpace(datatypes.CreateDefaultSpaceData("Movies", "alice")); err != nil {
+		t.Fatalf("CreateSpace() error = %v", err)
+	}
+	keep, err := r.IndexVideo(writeTestVideo(t, r, "Movies/movie.mp4", "movie"))
+	if err != nil {
+		t.Fatalf("IndexVideo() error = %v", err)
+	}
+	duplicate, err := r.IndexVideo(writeTestVideo(t, r, "Movies/movie-720p.mp4", "movie 720p"))
+	if err != nil {
+		t.Fatalf("IndexVideo() error = %v", err)
+	}
+	if _, err := r.CreateUser("alice", "secret", ""); err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+
+	if !spaceListsVideo(t, r, "Movies", duplicate.VideoID) {
+		t.Fatalf("space Movies does not list %s before the merge", duplicate.VideoID)
+	}
+
+	storage := r.diskDataStorage
+	if err := storage.AddVideoToWatched("alice", duplicate.VideoID); err != nil {
+		t.Fatalf("AddVideoToWatched() error = %v", err)
+	}
+	watchedAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := storage.SaveWatchProgress("alice", datatypes.WatchProgress{
+		VideoID: duplicate.VideoID, PositionSec: 42, DurationSec: 100, LastWatchedAt: watchedAt,
+	}); err != nil {
+		t.Fatalf("SaveWatchProgress() error = %v", err)
+	}
+
+	report, err := r.MergeDuplicateVideos(keep.VideoID, []string{duplicate.VideoID}, false)
+	if err != nil {
+		t.Fatalf("MergeDuplicateVideos() error = %v", err)
+	}
+	if len(report.Errors) > 0 || report.WatchHistoryUpdated != 1 {
+		t.Errorf("MergeDuplicateVideos() report = %+v, want one watch history updated and no errors", report)
+	}
+
+	if spaceListsVideo(t, r, "Movies", duplicate.VideoID) {
+		t.Errorf("space Movies still lists removed duplicate %s", duplicate.VideoID)
+	}
+
+	watched, err := storage.GetUserWatchedVideos("alice")
+	if err != nil {
+		t.Fatalf("GetUserWatchedVideos() error = %v", err)
+	}
+	if !slices.Equal(watched, []string{keep.VideoID}) {
+		t.Errorf("watched = %v, want only the kept copy %s", watched, keep.VideoID)
+	}
+	progress, err := storage.GetWatchProgress("alice", keep.VideoID)
+	if err != nil {
+		t.Fatalf("GetWatchProgress() error = %v", err)
+	}
+	if progress.PositionSec != 42 || !progress.LastWatchedAt.Equal(watchedAt) {
+		t.Errorf("progress = %+v, want the duplicate's position 42 at %v", progress, watchedAt)
+	}
+}
+
+// spaceListsVideo reports whether any group of the named space lists videoID.
+func spaceListsVideo(t *testing.T, r *RepoManager, spaceName, videoID string) bool {
+	t.Helper()
+	space, err := r.FindSpace(spaceName)
+	if err != nil {
+		t.Fatalf("FindSpace(%q) error = %v", spaceName, err)
+	}
+	return slices.ContainsFunc(space.Groups, func(group datatypes.SpaceGroup) bool {
+		return slices.Contains(group.VideoIds, videoID)
+	})
+}
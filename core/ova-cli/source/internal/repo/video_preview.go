@@ -3,7 +3,6 @@ package repo
 import (
 	"fmt"
 	"os"
-	"ova-cli/source/internal/thirdparty"
 	"path/filepath"
 )
 
@@ -27,7 +26,7 @@ func (r *RepoManager) GeneratePreview(videoPath, videoId string) (string, error)
 	centerTime := duration / 2.0
 
 	// 3. Generate preview video
-	if err := thirdparty.GenerateWebMFromVideo(videoPath, outputPath, centerTime, 4.0); err != nil {
+	if err := r.mediaToolchain.Preview(videoPath, outputPath, centerTime, 4.0); err != nil {
 		return "", fmt.Errorf("failed to generate preview for %s: %w", videoPath, err)
 	}
 
@@ -22,7 +22,7 @@ func (r *RepoManager) CheckPreviewThumbnailGenerated(videoID string) bool {
 // GenerateVideoPreviewThumbnails generates sprite sheet thumbnails and VTT files for a single video.
 func (r *RepoManager) GenerateVideoPreviewThumbnails(videoPath string) error {
 	// Use existing method to generate unique video ID (content hash)
-	videoID, err := r.GenerateVideoID(videoPath)
+	videoID, err := r.ResolveVideoID(videoPath)
 	if err != nil {
 		return fmt.Errorf("failed to compute video ID: %w", err)
 	}
@@ -45,28 +45,29 @@ func (r *RepoManager) GenerateVideoPreviewThumbnails(videoPath string) error {
 		return fmt.Errorf("failed to create keyframe dir for %s: %w", filepath.Base(videoPath), err)
 	}
 
-	if err := thirdparty.ExtractKeyframes(videoPath, keyframeDir, 160, 90); err != nil {
+	keyframeTimes, err := r.mediaToolchain.Keyframes(videoPath, keyframeDir, 160, 90)
+	if err != nil {
 		return fmt.Errorf("keyframe extraction error for %s: %w", filepath.Base(videoPath), err)
 	}
+	if len(keyframeTimes) == 0 {
+		return fmt.Errorf("no keyframes found for %s", filepath.Base(videoPath))
+	}
 
 	spritePattern := filepath.Join(videoSpriteDir, "thumb_L0_%03d.jpg")
-	if err := thirdparty.GenerateSpriteSheetsFromFolder(keyframeDir, spritePattern, "5x5", 160, 90); err != nil {
+	if err := r.mediaToolchain.SpriteSheets(keyframeDir, spritePattern, "5x5", 160, 90); err != nil {
 		return fmt.Errorf("sprite generation error for %s: %w", filepath.Base(videoPath), err)
 	}
 
-	keyframeTimes, err := thirdparty.GetKeyframePacketTimestamps(videoPath)
-	if err != nil {
-		return fmt.Errorf("failed to get keyframe timestamps for %s: %w", filepath.Base(videoPath), err)
-	}
-	if len(keyframeTimes) == 0 {
-		return fmt.Errorf("no keyframes found for %s", filepath.Base(videoPath))
-	}
-
 	vttPattern := filepath.Join("/api/v1/preview-thumbnails", videoID, "thumb_L0_%03d.jpg")
 	if err := thirdparty.GenerateVTT(keyframeTimes, "5x5", 160, 90, vttPattern, vttPath, ""); err != nil {
 		return fmt.Errorf("VTT generation error for %s: %w", filepath.Base(videoPath), err)
 	}
 
+	// Fingerprint the video from the same keyframes, for near-duplicate detection
+	if err := r.fingerprintCookedVideo(videoID, videoPath, keyframeDir, keyframeTimes); err != nil {
+		fmt.Printf("Warning: failed to fingerprint %s: %v\n", filepath.Base(videoPath), err)
+	}
+
 	// Clean up keyframes folder
 	if err := os.RemoveAll(keyframeDir); err != nil {
 		// Log but don’t fail
@@ -0,0 +1,106 @@
+package repo
+
+import (
+	"fmt"
+	"ova-cli/source/internal/datatypes"
+	"sort"
+	"time"
+)
+
+// GetWatchCompletionThreshold returns the fraction of a video that must be played for it to count as watched.
+func (r *RepoManager) GetWatchCompletionThreshold() float64 {
+	threshold := r.configs.WatchCompletionThreshold
+	if threshold <= 0 || threshold > 1 {
+		return datatypes.DefaultWatchCompletionThreshold
+	}
+	return threshold
+}
+
+// UpdateWatchProgress records the playback position of a video for a user. When the position
+// passes the completion threshold the video is marked completed and added to the watched list.
+// durationSec may be zero, in which case the indexed duration of the video is used.
+func (r *RepoManager) UpdateWatchProgress(username, videoID string, positionSec, durationSec float64) (*datatypes.WatchProgress, error) {
+	if !r.IsDataStorageInitialized() {
+		return nil, fmt.Errorf("data storage is not initialized")
+	}
+
+	if positionSec < 0 || durationSec < 0 {
+		return nil, fmt.Errorf("position and duration must not be negative")
+	}
+
+	video, err := r.GetVideoByID(videoID)
+	if err != nil {
+		return nil, fmt.Errorf("video %q not found", videoID)
+	}
+
+	if durationSec == 0 {
+		durationSec = float64(video.Codecs.DurationSec)
+	}
+	if durationSec > 0 && positionSec > durationSec {
+		positionSec = durationSec
+	}
+
+	progress := datatypes.WatchProgress{
+		VideoID:       videoID,
+		PositionSec:   positionSec,
+		DurationSec:   durationSec,
+		Completed:     durationSec > 0 && positionSec >= durationSec*r.GetWatchCompletionThreshold(),
+		LastWatchedAt: time.Now().UTC(),
+	}
+
+	if err := r.diskDataStorage.SaveWatchProgress(username, progress); err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+// GetWatchProgress returns the playback progress of a video for a user.
+func (r *RepoManager) GetWatchProgress(username, videoID string) (*datatypes.WatchProgress, error) {
+	if !r.IsDataStorageInitialized() {
+		return nil, fmt.Errorf("data storage is not initialized")
+	}
+	return r.diskDataStorage.GetWatchProgress(username, videoID)
+}
+
+// DeleteWatchProgress forgets the playback progress of a video for a user.
+func (r *RepoManager) DeleteWatchProgress(username, videoID string) error {
+	if !r.IsDataStorageInitialized() {
+		return fmt.Errorf("data storage is not initialized")
+	}
+	return r.diskDataStorage.DeleteWatchProgress(username, videoID)
+}
+
+// GetUserWatchHistory returns the playback progress of every video a user has played,
+// most recently watched first.
+func (r *RepoManager) GetUserWatchHistory(username string) ([]datatypes.WatchProgress, error) {
+	if !r.IsDataStorageInitialized() {
+		return nil, fmt.Errorf("data storage is not initialized")
+	}
+
+	history, err := r.diskDataStorage.GetUserWatchProgress(username)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].LastWatchedAt.After(history[j].LastWatchedAt)
+	})
+	return history, nil
+}
+
+// GetUserContinueWatching returns the videos a user started but did not finish,
+// most recently watched first.
+func (r *RepoManager) GetUserContinueWatching(username string) ([]datatypes.WatchProgress, error) {
+	history, err := r.GetUserWatchHistory(username)
+	if err != nil {
+		return nil, err
+	}
+
+	inProgress := make([]datatypes.WatchProgress, 0, len(history))
+	for _, progress := range history {
+		if !progress.Completed && progress.PositionSec > 0 {
+			inProgress = append(inProgress, progress)
+		}
+	}
+	return inProgress, nil
+}
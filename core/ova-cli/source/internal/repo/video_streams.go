@@ -0,0 +1,139 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"ova-cli/source/internal/datatypes"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ErrAudioTrackNotFound is returned when a requested audio track does not exist in a video.
+var ErrAudioTrackNotFound = errors.New("audio track not found")
+
+// RefreshVideoStreams probes a video file again and stores its stream list and bitrate.
+// Use it for videos indexed before stream metadata was recorded.
+func (r *RepoManager) RefreshVideoStreams(videoID string) (*datatypes.VideoData, error) {
+	if !r.IsDataStorageInitialized() {
+		return nil, fmt.Errorf("data storage is not initialized")
+	}
+
+	if subRepoName, localID, ok := SplitNamespacedVideoID(videoID); ok {
+		child, err := r.getSubRepository(subRepoName)
+		if err != nil {
+			return nil, err
+		}
+		video, err := child.RefreshVideoStreams(localID)
+		if err != nil {
+			return nil, err
+		}
+		video.VideoID = videoID
+		return video, nil
+	}
+
+	video, err := r.diskDataStorage.GetVideoByID(videoID)
+	if err != nil {
+		return nil, err
+	}
+	videoPath, err := r.GetVideoFilePathByID(videoID)
+	if err != nil {
+		return nil, err
+	}
+
+	codecs, err := r.mediaToolchain.Probe(videoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get streams for %s: %w", videoPath, err)
+	}
+	video.Codecs.Bitrate = codecs.Bitrate
+	video.Codecs.Streams = codecs.Streams
+
+	if err := r.diskDataStorage.UpdateVideo(*video); err != nil {
+		return nil, fmt.Errorf("failed to save video metadata: %w", err)
+	}
+	r.videoUpdated(*video)
+	return video, nil
+}
+
+// GetVideoAudioStreams returns the audio streams of a video, probing the file if the
+// video was indexed before stream metadata was recorded.
+func (r *RepoManager) GetVideoAudioStreams(videoID string) ([]datatypes.MediaStream, error) {
+	video, err := r.GetVideoByID(videoID)
+	if err != nil {
+		return nil, err
+	}
+	if len(video.Codecs.Streams) == 0 {
+		if video, err = r.RefreshVideoStreams(videoID); err != nil {
+			return nil, err
+		}
+	}
+	return video.Codecs.StreamsOfType(datatypes.StreamTypeAudio), nil
+}
+
+// GetVideoFilePathWithAudio returns the path of a file that plays the video with the
+// requested audio track. audio is a language code as recorded in the stream list
+// (e.g. "eng") or a container stream index. Videos with a single audio stream are
+// served as they are; otherwise a copy holding only the chosen audio stream is made
+// once and kept with the video's other artefacts.
+func (r *RepoManager) GetVideoFilePathWithAudio(videoID, audio string) (string, error) {
+	videoPath, err := r.GetVideoFilePathByID(videoID)
+	if err != nil {
+		return "", err
+	}
+
+	audioStreams, err := r.GetVideoAudioStreams(videoID)
+	if err != nil {
+		return "", err
+	}
+
+	stream, ok := findAudioStream(audioStreams, audio)
+	if !ok {
+		return "", fmt.Errorf("%w: video %s has no audio track %q", ErrAudioTrackNotFound, videoID, audio)
+	}
+	if len(audioStreams) == 1 {
+		return videoPath, nil
+	}
+
+	variantPath := filepath.Join(r.GetAudioVariantsFolderPathByVideoID(videoID), strconv.Itoa(stream.Index)+filepath.Ext(videoPath))
+	if _, err := os.Stat(variantPath); err == nil {
+		return variantPath, nil
+	}
+
+	// Remux to a temporary name first so concurrent requests never serve a partial file
+	if err := os.MkdirAll(filepath.Dir(variantPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create audio variant directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(variantPath), "*.tmp"+filepath.Ext(videoPath))
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	if err := r.mediaToolchain.RemuxAudio(videoPath, stream.Index, tmp.Name()); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), variantPath); err != nil {
+		return "", fmt.Errorf("failed to store audio variant: %w", err)
+	}
+	return variantPath, nil
+}
+
+// findAudioStream picks the audio stream whose language (case-insensitive) or
+// container index matches audio. The first match wins.
+func findAudioStream(streams []datatypes.MediaStream, audio string) (datatypes.MediaStream, bool) {
+	for _, s := range streams {
+		if s.Language != "" && strings.EqualFold(s.Language, audio) {
+			return s, true
+		}
+	}
+	if index, err := strconv.Atoi(audio); err == nil {
+		for _, s := range streams {
+			if s.Index == index {
+				return s, true
+			}
+		}
+	}
+	return datatypes.MediaStream{}, false
+}
@@ -0,0 +1,382 @@
+package repo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"ova-cli/source/internal/datatypes"
+	"ova-cli/source/internal/thirdparty"
+	"ova-cli/source/internal/utils"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Each video with subtitles has a folder in storage/subtitles holding one WebVTT
+// file per track and a manifest describing the tracks.
+const subtitleManifestFile = "tracks.json"
+
+// subtitleFormats are the sidecar and upload formats that can be converted to WebVTT.
+var subtitleFormats = map[string]bool{
+	"vtt": true,
+	"srt": true,
+	"ass": true,
+	"ssa": true,
+}
+
+// subtitleLanguagePattern matches ISO 639 codes with optional BCP 47 subtags, e.g. "en", "pt-BR".
+var subtitleLanguagePattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
+// GetSubtitleTracks returns the subtitle tracks of a video.
+func (r *RepoManager) GetSubtitleTracks(videoID string) ([]datatypes.SubtitleTrack, error) {
+	r.subtitlesMu.Lock()
+	defer r.subtitlesMu.Unlock()
+	return r.loadSubtitleManifest(videoID)
+}
+
+// GetSubtitleTrack returns one subtitle track of a video and the path of its WebVTT file.
+func (r *RepoManager) GetSubtitleTrack(videoID, trackID string) (*datatypes.SubtitleTrack, string, error) {
+	tracks, err := r.GetSubtitleTracks(videoID)
+	if err != nil {
+		return nil, "", err
+	}
+	idx := slices.IndexFunc(tracks, func(t datatypes.SubtitleTrack) bool { return t.ID == trackID })
+	if idx < 0 {
+		return nil, "", fmt.Errorf("subtitle track %q not found for video %q", trackID, videoID)
+	}
+	return &tracks[idx], r.GetSubtitleTrackFilePath(videoID, trackID), nil
+}
+
+// AddSubtitleTrack stores an uploaded subtitle file as a new track. The format is taken
+// from fileName's extension; srt, vtt, ass and ssa are accepted.
+func (r *RepoManager) AddSubtitleTrack(videoID string, data []byte, fileName, language, label, addedBy string) (*datatypes.SubtitleTrack, error) {
+	if _, err := r.GetVideoByID(videoID); err != nil {
+		return nil, err
+	}
+
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(fileName)), ".")
+	if !subtitleFormats[format] {
+		return nil, fmt.Errorf("unsupported subtitle format %q: use srt, vtt, ass or ssa", format)
+	}
+	if !subtitleLanguagePattern.MatchString(language) {
+		return nil, fmt.Errorf("invalid language %q: use a code like \"en\" or \"pt-BR\"", language)
+	}
+	language = normalizeSubtitleLanguage(language)
+	if label == "" {
+		label = language
+	}
+
+	r.subtitlesMu.Lock()
+	defer r.subtitlesMu.Unlock()
+
+	tracks, err := r.loadSubtitleManifest(videoID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Uploads are numbered per language: upload-en-1, upload-en-2, ...
+	trackID := ""
+	for n := 1; trackID == ""; n++ {
+		candidate := fmt.Sprintf("%s-%s-%d", datatypes.SubtitleSourceUpload, utils.ToSlug(language), n)
+		if !slices.ContainsFunc(tracks, func(t datatypes.SubtitleTrack) bool { return t.ID == candidate }) {
+			trackID = candidate
+		}
+	}
+
+	if err := r.writeSubtitleAsVTT(data, "", format, r.GetSubtitleTrackFilePath(videoID, trackID)); err != nil {
+		return nil, err
+	}
+
+	track := datatypes.SubtitleTrack{
+		ID:             trackID,
+		Language:       language,
+		Label:          label,
+		Source:         datatypes.SubtitleSourceUpload,
+		OriginalFormat: format,
+		AddedBy:        addedBy,
+		AddedAt:        time.Now().UTC(),
+	}
+	tracks = append(tracks, track)
+	if err := r.saveSubtitleManifest(videoID, tracks); err != nil {
+		return nil, err
+	}
+	return &track, nil
+}
+
+// RemoveSubtitleTrack deletes a subtitle track. Sidecar and embedded tracks come back
+// the next time the video's subtitles are discovered.
+func (r *RepoManager) RemoveSubtitleTrack(videoID, trackID string) error {
+	r.subtitlesMu.Lock()
+	defer r.subtitlesMu.Unlock()
+
+	tracks, err := r.loadSubtitleManifest(videoID)
+	if err != nil {
+		return err
+	}
+	idx := slices.IndexFunc(tracks, func(t datatypes.SubtitleTrack) bool { return t.ID == trackID })
+	if idx < 0 {
+		return fmt.Errorf("subtitle track %q not found for video %q", trackID, videoID)
+	}
+
+	if err := os.Remove(r.GetSubtitleTrackFilePath(videoID, trackID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete subtitle file: %w", err)
+	}
+	return r.saveSubtitleManifest(videoID, slices.Delete(tracks, idx, idx+1))
+}
+
+// RefreshSubtitles re-discovers the sidecar and embedded subtitles of an indexed video.
+func (r *RepoManager) RefreshSubtitles(videoID string) (int, error) {
+	videoPath, err := r.GetVideoFilePathByID(videoID)
+	if err != nil {
+		return 0, err
+	}
+	return r.DiscoverSubtitles(videoID, videoPath)
+}
+
+// DiscoverSubtitles finds sidecar subtitle files next to a video (movie.srt, movie.en.srt,
+// movie.de.forced.ass, ...) and text subtitle streams inside it, and stores each as a
+// WebVTT track. Previously discovered tracks are replaced; uploads are kept.
+// It returns the number of tracks found. Tracks that fail to convert are skipped and
+// their errors returned together.
+func (r *RepoManager) DiscoverSubtitles(videoID, videoPath string) (int, error) {
+	var errs []error
+	var found []datatypes.SubtitleTrack
+	now := time.Now().UTC()
+
+	// 1. Sidecar files
+	dir := filepath.Dir(videoPath)
+	base := strings.TrimSuffix(filepath.Base(videoPath), filepath.Ext(videoPath))
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read video folder: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		ext := filepath.Ext(name)
+		format := strings.TrimPrefix(strings.ToLower(ext), ".")
+		if entry.IsDir() || !subtitleFormats[format] || !strings.HasPrefix(name, base+".") {
+			continue
+		}
+
+		suffix := strings.TrimPrefix(name, base+".") // e.g. "en.forced.srt"
+		track := parseSidecarSubtitleName(name, base)
+		track.ID = datatypes.SubtitleSourceSidecar + "-" + utils.ToSlug(strings.ReplaceAll(suffix, ".", "-"))
+		track.Source = datatypes.SubtitleSourceSidecar
+		track.OriginalFormat = format
+		track.SourceFile = name
+		track.AddedAt = now
+
+		sidecarPath := filepath.Join(dir, name)
+		data, err := os.ReadFile(sidecarPath)
+		if err == nil {
+			err = r.writeSubtitleAsVTT(data, sidecarPath, format, r.GetSubtitleTrackFilePath(videoID, track.ID))
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("sidecar %s: %w", name, err))
+			continue
+		}
+		found = append(found, track)
+	}
+
+	// 2. Embedded streams
+	codecs, err := r.mediaToolchain.Probe(videoPath)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to list embedded subtitles: %w", err))
+	}
+	for _, stream := range codecs.StreamsOfType(datatypes.StreamTypeSubtitle) {
+		if !thirdparty.IsTextSubtitleCodec(stream.Codec) {
+			errs = append(errs, fmt.Errorf("stream %d: %s is image based and cannot be converted to WebVTT", stream.Index, stream.Codec))
+			continue
+		}
+
+		language := "und"
+		if subtitleLanguagePattern.MatchString(stream.Language) {
+			language = normalizeSubtitleLanguage(stream.Language)
+		}
+		label := stream.Title
+		if label == "" {
+			label = language
+		}
+
+		track := datatypes.SubtitleTrack{
+			ID:             "stream-" + strconv.Itoa(stream.Index),
+			Language:       language,
+			Label:          label,
+			Source:         datatypes.SubtitleSourceEmbedded,
+			OriginalFormat: stream.Codec,
+			StreamIndex:    stream.Index,
+			Default:        stream.Default,
+			Forced:         stream.Forced,
+			AddedAt:        now,
+		}
+		if err := r.mediaToolchain.ExtractSubtitle(videoPath, stream.Index, r.GetSubtitleTrackFilePath(videoID, track.ID)); err != nil {
+			errs = append(errs, fmt.Errorf("stream %d: %w", stream.Index, err))
+			continue
+		}
+		found = append(found, track)
+	}
+
+	// 3. Merge with uploads, dropping discovered tracks that no longer exist
+	r.subtitlesMu.Lock()
+	defer r.subtitlesMu.Unlock()
+
+	tracks, err := r.loadSubtitleManifest(videoID)
+	if err != nil {
+		return 0, err
+	}
+
+	merged := make([]datatypes.SubtitleTrack, 0, len(tracks)+len(found))
+	for _, t := range tracks {
+		if t.Source == datatypes.SubtitleSourceUpload {
+			merged = append(merged, t)
+			continue
+		}
+		if !slices.ContainsFunc(found, func(f datatypes.SubtitleTrack) bool { return f.ID == t.ID }) {
+			os.Remove(r.GetSubtitleTrackFilePath(videoID, t.ID))
+		}
+	}
+	merged = append(found, merged...)
+
+	if len(merged) == 0 && len(tracks) == 0 {
+		return 0, errors.Join(errs...)
+	}
+	if err := r.saveSubtitleManifest(videoID, merged); err != nil {
+		return 0, err
+	}
+	return len(found), errors.Join(errs...)
+}
+
+// GetSubtitleTrackFilePath returns the path of a track's WebVTT file.
+func (r *RepoManager) GetSubtitleTrackFilePath(videoID, trackID string) string {
+	return filepath.Join(r.GetSubtitlesFolderPathByVideoID(videoID), trackID+".vtt")
+}
+
+func (r *RepoManager) loadSubtitleManifest(videoID string) ([]datatypes.SubtitleTrack, error) {
+	data, err := os.ReadFile(filepath.Join(r.GetSubtitlesFolderPathByVideoID(videoID), subtitleManifestFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []datatypes.SubtitleTrack{}, nil
+		}
+		return nil, fmt.Errorf("failed to read subtitle manifest: %w", err)
+	}
+
+	var tracks []datatypes.SubtitleTrack
+	if err := json.Unmarshal(data, &tracks); err != nil {
+		return nil, fmt.Errorf("failed to parse subtitle manifest: %w", err)
+	}
+	return tracks, nil
+}
+
+func (r *RepoManager) saveSubtitleManifest(videoID string, tracks []datatypes.SubtitleTrack) error {
+	dir := r.GetSubtitlesFolderPathByVideoID(videoID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create subtitle directory %s: %w", dir, err)
+	}
+
+	data, err := json.MarshalIndent(tracks, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode subtitle manifest: %w", err)
+	}
+	return os.WriteFile(filepath.Join(dir, subtitleManifestFile), data, 0644)
+}
+
+// parseSidecarSubtitleName reads the language and flags from the part of a sidecar name
+// between the video name base and the extension, e.g. "en", "pt-BR.forced" or "English SDH".
+// The middle of "movie.srt" is empty, so its track has no language.
+func parseSidecarSubtitleName(name, base string) datatypes.SubtitleTrack {
+	track := datatypes.SubtitleTrack{Language: "und"}
+	middle := strings.TrimPrefix(strings.TrimSuffix(name, filepath.Ext(name)), base)
+
+	var labelParts []string
+	for _, token := range strings.Split(middle, ".") {
+		switch lower := strings.ToLower(token); {
+		case token == "":
+			continue
+		case lower == "forced":
+			track.Forced = true
+		case lower == "default":
+			track.Default = true
+		case track.Language == "und" && subtitleLanguagePattern.MatchString(token):
+			track.Language = normalizeSubtitleLanguage(token)
+		default:
+			labelParts = append(labelParts, token)
+		}
+	}
+
+	track.Label = strings.Join(labelParts, " ")
+	if track.Label == "" {
+		track.Label = track.Language
+	}
+	return track
+}
+
+// normalizeSubtitleLanguage lowercases the primary language subtag: "EN-us" -> "en-us".
+func normalizeSubtitleLanguage(language string) string {
+	primary, rest, found := strings.Cut(language, "-")
+	if !found {
+		return strings.ToLower(primary)
+	}
+	return strings.ToLower(primary) + "-" + rest
+}
+
+// writeSubtitleAsVTT stores subtitle data as a WebVTT file. srcPath is the file the data
+// came from, if any; formats converted by the media toolchain need one and get a temporary
+// copy otherwise.
+func (r *RepoManager) writeSubtitleAsVTT(data []byte, srcPath, format, outputPath string) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create subtitle directory: %w", err)
+	}
+
+	switch format {
+	case "vtt":
+		content := strings.TrimPrefix(string(data), "\ufeff")
+		if !strings.HasPrefix(content, "WEBVTT") {
+			return fmt.Errorf("invalid VTT file: missing WEBVTT header")
+		}
+		return os.WriteFile(outputPath, []byte(content), 0644)
+
+	case "srt":
+		return os.WriteFile(outputPath, []byte(convertSRTToVTT(string(data))), 0644)
+
+	default:
+		if srcPath == "" {
+			tmp, err := os.CreateTemp("", "ova-subtitle-*."+format)
+			if err != nil {
+				return fmt.Errorf("failed to create temporary file: %w", err)
+			}
+			defer os.Remove(tmp.Name())
+
+			_, err = tmp.Write(data)
+			if closeErr := tmp.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return fmt.Errorf("failed to write temporary file: %w", err)
+			}
+			srcPath = tmp.Name()
+		}
+		return r.mediaToolchain.ConvertSubtitle(srcPath, outputPath)
+	}
+}
+
+// convertSRTToVTT converts SubRip subtitles to WebVTT. The formats differ only in the
+// header and in using a comma rather than a dot before the milliseconds.
+func convertSRTToVTT(srt string) string {
+	srt = strings.TrimPrefix(srt, "\ufeff")
+	srt = strings.ReplaceAll(srt, "\r\n", "\n")
+
+	var sb strings.Builder
+	sb.WriteString("WEBVTT\n\n")
+	for _, line := range strings.Split(strings.TrimSpace(srt), "\n") {
+		if start, end, ok := strings.Cut(line, "-->"); ok {
+			line = strings.ReplaceAll(start, ",", ".") + "-->" + strings.ReplaceAll(end, ",", ".")
+		}
+		sb.WriteString(line + "\n")
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
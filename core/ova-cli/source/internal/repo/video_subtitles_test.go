@@ -0,0 +1,25 @@
+package repo
+
+import "testing"
+
+func TestParseSidecarSubtitleName(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		label    string
+		forced   bool
+	}{
+		{"movie.srt", "und", "und", false},
+		{"movie.vtt", "und", "und", false},
+		{"movie.en.srt", "en", "en", false},
+		{"movie.pt-BR.forced.ass", "pt-BR", "pt-BR", true},
+		{"movie.English SDH.srt", "und", "English SDH", false},
+	}
+	for _, tt := range tests {
+		track := parseSidecarSubtitleName(tt.name, "movie")
+		if track.Language != tt.language || track.Label != tt.label || track.Forced != tt.forced {
+			t.Errorf("parseSidecarSubtitleName(%q) = language %q, label %q, forced %v; want %q, %q, %v",
+				tt.name, track.Language, track.Label, track.Forced, tt.language, tt.label, tt.forced)
+		}
+	}
+}
@@ -9,7 +9,11 @@ func (r *RepoManager) AddTagToVideo(videoID, tag string) error {
 	if !r.IsDataStorageInitialized() {
 		return fmt.Errorf("data storage is not initialized")
 	}
-	return r.diskDataStorage.AddTagToVideo(videoID, tag)
+	if err := r.diskDataStorage.AddTagToVideo(videoID, tag); err != nil {
+		return err
+	}
+	r.videoUpdatedByID(videoID)
+	return nil
 }
 
 // RemoveTagFromVideo removes a tag from a video (case-insensitive).
@@ -17,5 +21,9 @@ func (r *RepoManager) RemoveTagFromVideo(videoID, tag string) error {
 	if !r.IsDataStorageInitialized() {
 		return fmt.Errorf("data storage is not initialized")
 	}
-	return r.diskDataStorage.RemoveTagFromVideo(videoID, tag)
+	if err := r.diskDataStorage.RemoveTagFromVideo(videoID, tag); err != nil {
+		return err
+	}
+	r.videoUpdatedByID(videoID)
+	return nil
 }
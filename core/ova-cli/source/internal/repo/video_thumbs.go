@@ -3,7 +3,6 @@ package repo
 import (
 	"fmt"
 	"os"
-	"ova-cli/source/internal/thirdparty"
 	"path/filepath"
 )
 
@@ -27,7 +26,7 @@ func (r *RepoManager) GenerateThumb(videoPath, videoId string) (string, error) {
 	centerTime := duration / 2.0
 
 	// 3. Generate thumbnail image.
-	if err := thirdparty.GenerateImageFromVideo(videoPath, outputPath, centerTime); err != nil {
+	if err := r.mediaToolchain.Thumbnail(videoPath, outputPath, centerTime); err != nil {
 		return "", fmt.Errorf("failed to generate thumbnail for %s: %w", videoPath, err)
 	}
 
@@ -0,0 +1,384 @@
+package repo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"ova-cli/source/internal/datatypes"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+)
+
+// DefaultTrashRetention is how long deleted videos stay restorable when the config does not set it.
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// trashEntryFile is the metadata file inside each video's trash folder. The video file sits
+// next to it and its artefacts under "storage", mirroring the repository storage folder.
+const trashEntryFile = "entry.json"
+
+// GetTrashRetention returns how long deleted videos stay in the trash; zero keeps them forever.
+func (r *RepoManager) GetTrashRetention() time.Duration {
+	days := r.configs.TrashRetentionDays
+	switch {
+	case days < 0:
+		return 0
+	case days == 0:
+		return DefaultTrashRetention
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// getTrashEntryDir returns the trash folder of a video, rejecting IDs that are not a plain folder name.
+func (r *RepoManager) getTrashEntryDir(videoID string) (string, error) {
+	if videoID == "" || videoID == "." || videoID == ".." || filepath.Base(videoID) != videoID {
+		return "", fmt.Errorf("invalid video ID %q", videoID)
+	}
+	return filepath.Join(r.GetTrashDir(), videoID), nil
+}
+
+// TrashVideo soft-deletes a video: its file, artefacts and metadata move to the trash and
+// it disappears from listings, search, spaces and every user's saved videos, history and
+// playlists. RestoreVideo brings all of it back until the retention period ends.
+func (r *RepoManager) TrashVideo(videoID, deletedBy string) (*datatypes.TrashEntry, error) {
+	if !r.IsDataStorageInitialized() {
+		return nil, fmt.Errorf("data storage is not initialized")
+	}
+	if _, _, ok := SplitNamespacedVideoID(videoID); ok {
+		return nil, fmt.Errorf("video %s belongs to an attached repository", videoID)
+	}
+
+	video, err := r.diskDataStorage.GetVideoByID(videoID)
+	if err != nil {
+		return nil, fmt.Errorf("video %s not found: %w", videoID, err)
+	}
+	entryDir, err := r.getTrashEntryDir(videoID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(entryDir); err == nil {
+		return nil, fmt.Errorf("an earlier copy of %s is already in the trash; restore or purge it first", videoID)
+	}
+
+	references, err := r.collectVideoReferences(videoID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &datatypes.TrashEntry{
+		Video:        *video,
+		OriginalPath: GetVideoRelativePath(video),
+		DeletedAt:    time.Now().UTC(),
+		DeletedBy:    deletedBy,
+		References:   references,
+	}
+
+	if err := os.MkdirAll(entryDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create trash folder: %w", err)
+	}
+
+	// Move the file and artefacts, remembering every move so a failure can undo them
+	var moved [][2]string
+	undo := func() {
+		for i := len(moved) - 1; i >= 0; i-- {
+			os.Rename(moved[i][1], moved[i][0])
+		}
+		os.RemoveAll(entryDir)
+	}
+
+	filePath := filepath.Join(r.GetRootPath(), filepath.FromSlash(entry.OriginalPath))
+	if _, err := os.Stat(filePath); err == nil {
+		trashedFile := filepath.Join(entryDir, "video"+video.Codecs.Format)
+		if err := os.Rename(filePath, trashedFile); err != nil {
+			undo()
+			return nil, fmt.Errorf("failed to move video file to the trash: %w", err)
+		}
+		moved = append(moved, [2]string{filePath, trashedFile})
+		entry.HasFile = true
+	}
+
+	for _, artefact := range r.GetVideoArtefactPaths(videoID) {
+		if _, err := os.Stat(artefact); err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(r.GetStoragePath(), artefact)
+		if err != nil {
+			undo()
+			return nil, fmt.Errorf("failed to resolve artefact %s: %w", artefact, err)
+		}
+		trashed := filepath.Join(entryDir, "storage", rel)
+		if err := os.MkdirAll(filepath.Dir(trashed), 0755); err == nil {
+			err = os.Rename(artefact, trashed)
+		}
+		if err != nil {
+			undo()
+			return nil, fmt.Errorf("failed to move %s to the trash: %w", artefact, err)
+		}
+		moved = append(moved, [2]string{artefact, trashed})
+	}
+	entry.Size = pathSize(entryDir)
+
+	if err := writeTrashEntry(entryDir, entry); err != nil {
+		undo()
+		return nil, err
+	}
+	if err := r.diskDataStorage.DeleteVideoByID(videoID); err != nil {
+		undo()
+		return nil, fmt.Errorf("failed to remove video metadata: %w", err)
+	}
+	r.videoDeleted(videoID)
+
+	// The references are recorded in the entry, so failures here only leave dangling IDs behind
+	if err := r.diskDataStorage.RemoveVideoFromAllUsers(videoID); err != nil {
+		fmt.Printf("Warning: failed to remove user references to %s: %v\n", videoID, err)
+	}
+	if err := r.diskDataStorage.RemoveVideoIDFromSpaces(videoID); err != nil {
+		fmt.Printf("Warning: failed to remove %s from its space: %v\n", videoID, err)
+	}
+
+	r.setTrashExpiry(entry)
+	return entry, nil
+}
+
+// collectVideoReferences records which users saved, watched or playlisted a video.
+func (r *RepoManager) collectVideoReferences(videoID string) (datatypes.TrashedVideoReferences, error) {
+	var refs datatypes.TrashedVideoReferences
+
+	users, err := r.diskDataStorage.GetAllUsers()
+	if err != nil {
+		return refs, fmt.Errorf("failed to load users: %w", err)
+	}
+	for _, user := range users {
+		if slices.Contains(user.Favorites, videoID) {
+			refs.SavedBy = append(refs.SavedBy, user.Username)
+		}
+		if slices.Contains(user.Watched, videoID) {
+			refs.WatchedBy = append(refs.WatchedBy, user.Username)
+		}
+		if progress, ok := user.WatchProgress[videoID]; ok {
+			if refs.Progress == nil {
+				refs.Progress = map[string]datatypes.WatchProgress{}
+			}
+			refs.Progress[user.Username] = progress
+		}
+		for _, playlist := range user.Playlists {
+			if index := slices.Index(playlist.VideoIDs, videoID); index >= 0 {
+				refs.Playlists = append(refs.Playlists, datatypes.TrashedPlaylistEntry{Owner: user.Username, Slug: playlist.Slug, Index: index})
+			}
+		}
+	}
+	return refs, nil
+}
+
+// RestoreVideo moves a trashed video back to where it was and puts back its metadata,
+// artefacts, space membership and user references. References to users or playlists that
+// were deleted in the meantime are skipped with a warning.
+func (r *RepoManager) RestoreVideo(videoID string) (*datatypes.VideoData, error) {
+	if !r.IsDataStorageInitialized() {
+		return nil, fmt.Errorf("data storage is not initialized")
+	}
+
+	entryDir, err := r.getTrashEntryDir(videoID)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := readTrashEntry(entryDir)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.diskDataStorage.GetVideoByID(videoID); err == nil {
+		return nil, fmt.Errorf("video %s has been indexed again; purge the trashed copy instead", videoID)
+	}
+
+	filePath := filepath.Join(r.GetRootPath(), filepath.FromSlash(entry.OriginalPath))
+	if entry.HasFile {
+		if _, err := os.Stat(filePath); err == nil {
+			return nil, fmt.Errorf("%s already exists; move it away before restoring", entry.OriginalPath)
+		}
+	}
+
+	// Move everything back, remembering the moves so a failure can return them to the trash
+	var moved [][2]string
+	undo := func() {
+		for i := len(moved) - 1; i >= 0; i-- {
+			os.Rename(moved[i][1], moved[i][0])
+		}
+	}
+
+	if entry.HasFile {
+		trashedFile := filepath.Join(entryDir, "video"+entry.Video.Codecs.Format)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err == nil {
+			err = os.Rename(trashedFile, filePath)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore video file: %w", err)
+		}
+		moved = append(moved, [2]string{trashedFile, filePath})
+	}
+
+	trashedStorage := filepath.Join(entryDir, "storage")
+	for _, artefact := range r.GetVideoArtefactPaths(videoID) {
+		rel, err := filepath.Rel(r.GetStoragePath(), artefact)
+		if err != nil {
+			continue
+		}
+		trashed := filepath.Join(trashedStorage, rel)
+		if _, err := os.Stat(trashed); err != nil {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(artefact), 0755); err == nil {
+			err = os.Rename(trashed, artefact)
+		}
+		if err != nil {
+			undo()
+			return nil, fmt.Errorf("failed to restore %s: %w", artefact, err)
+		}
+		moved = append(moved, [2]string{trashed, artefact})
+	}
+
+	if err := r.diskDataStorage.AddVideo(entry.Video); err != nil {
+		undo()
+		return nil, fmt.Errorf("failed to restore video metadata: %w", err)
+	}
+	r.videoIndexed(entry.Video)
+	if entry.HasFile {
+		// Like indexing, videos outside a registered space have no group to join
+		r.diskDataStorage.AddVideoIDToSpace(videoID, entry.OriginalPath)
+	}
+
+	r.restoreVideoReferences(videoID, entry.References)
+
+	if err := os.RemoveAll(entryDir); err != nil {
+		fmt.Printf("Warning: failed to remove trash folder %s: %v\n", entryDir, err)
+	}
+	return &entry.Video, nil
+}
+
+// restoreVideoReferences puts back the user references recorded when a video was trashed.
+func (r *RepoManager) restoreVideoReferences(videoID string, refs datatypes.TrashedVideoReferences) {
+	for _, username := range refs.SavedBy {
+		if err := r.diskDataStorage.AddVideoToSaved(username, videoID); err != nil {
+			fmt.Printf("Warning: failed to restore saved video of %s: %v\n", username, err)
+		}
+	}
+	for _, username := range refs.WatchedBy {
+		if err := r.diskDataStorage.AddVideoToWatched(username, videoID); err != nil {
+			fmt.Printf("Warning: failed to restore watch history of %s: %v\n", username, err)
+		}
+	}
+	for username, progress := range refs.Progress {
+		if err := r.diskDataStorage.SaveWatchProgress(username, progress); err != nil {
+			fmt.Printf("Warning: failed to restore playback progress of %s: %v\n", username, err)
+		}
+	}
+	for _, entry := range refs.Playlists {
+		if _, err := r.diskDataStorage.InsertVideosIntoPlaylist(entry.Owner, entry.Slug, []string{videoID}, entry.Index); err != nil {
+			fmt.Printf("Warning: failed to restore playlist %s of %s: %v\n", entry.Slug, entry.Owner, err)
+		}
+	}
+}
+
+// ListTrash returns the videos in the trash, most recently deleted first.
+func (r *RepoManager) ListTrash() ([]datatypes.TrashEntry, error) {
+	dirs, err := os.ReadDir(r.GetTrashDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []datatypes.TrashEntry{}, nil
+		}
+		return nil, fmt.Errorf("failed to read trash: %w", err)
+	}
+
+	entries := []datatypes.TrashEntry{}
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		entry, err := readTrashEntry(filepath.Join(r.GetTrashDir(), dir.Name()))
+		if err != nil {
+			fmt.Printf("Warning: %v\n", err)
+			continue
+		}
+		r.setTrashExpiry(entry)
+		entries = append(entries, *entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].DeletedAt.After(entries[j].DeletedAt)
+	})
+	return entries, nil
+}
+
+// PurgeTrash permanently deletes videos from the trash: the given IDs, or every video whose
+// retention period has ended when none are given. all purges the whole trash.
+func (r *RepoManager) PurgeTrash(videoIDs []string, all bool) (datatypes.TrashPurgeReport, error) {
+	report := datatypes.TrashPurgeReport{PurgedVideoIDs: []string{}}
+
+	if len(videoIDs) == 0 {
+		entries, err := r.ListTrash()
+		if err != nil {
+			return report, err
+		}
+		now := time.Now()
+		for _, entry := range entries {
+			if all || (!entry.ExpiresAt.IsZero() && now.After(entry.ExpiresAt)) {
+				videoIDs = append(videoIDs, entry.Video.VideoID)
+			}
+		}
+	}
+
+	for _, videoID := range videoIDs {
+		entryDir, err := r.getTrashEntryDir(videoID)
+		if err != nil {
+			report.Errors = append(report.Errors, err.Error())
+			continue
+		}
+		if _, err := os.Stat(entryDir); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("video %s is not in the trash", videoID))
+			continue
+		}
+		size := pathSize(entryDir)
+		if err := os.RemoveAll(entryDir); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("failed to purge %s: %v", videoID, err))
+			continue
+		}
+		report.PurgedVideoIDs = append(report.PurgedVideoIDs, videoID)
+		report.FreedBytes += size
+	}
+	return report, nil
+}
+
+// setTrashExpiry fills in when an entry will be purged under the current retention setting.
+func (r *RepoManager) setTrashExpiry(entry *datatypes.TrashEntry) {
+	entry.ExpiresAt = time.Time{}
+	if retention := r.GetTrashRetention(); retention > 0 {
+		entry.ExpiresAt = entry.DeletedAt.Add(retention)
+	}
+}
+
+func writeTrashEntry(entryDir string, entry *datatypes.TrashEntry) error {
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode trash entry: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(entryDir, trashEntryFile), data, 0644); err != nil {
+		return fmt.Errorf("failed to write trash entry: %w", err)
+	}
+	return nil
+}
+
+func readTrashEntry(entryDir string) (*datatypes.TrashEntry, error) {
+	data, err := os.ReadFile(filepath.Join(entryDir, trashEntryFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("video %s is not in the trash", filepath.Base(entryDir))
+		}
+		return nil, fmt.Errorf("failed to read trash entry: %w", err)
+	}
+	var entry datatypes.TrashEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("trash entry %s is corrupt: %w", filepath.Base(entryDir), err)
+	}
+	return &entry, nil
+}
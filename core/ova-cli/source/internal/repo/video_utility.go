@@ -3,6 +3,8 @@ package repo
 import (
 	"fmt"
 	"ova-cli/source/internal/datatypes"
+	"path"
+	"path/filepath"
 )
 
 // GetVideoByID returns video data by ID.
@@ -10,9 +12,48 @@ func (r *RepoManager) GetVideoByID(id string) (*datatypes.VideoData, error) {
 	if !r.IsDataStorageInitialized() {
 		return nil, fmt.Errorf("data storage is not initialized")
 	}
+
+	if subRepoName, localID, ok := SplitNamespacedVideoID(id); ok {
+		child, err := r.getSubRepository(subRepoName)
+		if err != nil {
+			return nil, err
+		}
+		video, err := child.GetVideoByID(localID)
+		if err != nil {
+			return nil, err
+		}
+		video.VideoID = id
+		return video, nil
+	}
+
 	return r.diskDataStorage.GetVideoByID(id)
 }
 
+// GetVideoFilePathByID returns the absolute path of a video file, resolving
+// videos of attached repositories against their own root.
+func (r *RepoManager) GetVideoFilePathByID(videoID string) (string, error) {
+	owner, localID, err := r.resolveVideoOwner(videoID)
+	if err != nil {
+		return "", err
+	}
+
+	video, err := owner.GetVideoByID(localID)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(owner.GetRootPath(), filepath.FromSlash(GetVideoRelativePath(video))), nil
+}
+
+// GetVideoRelativePath returns the slash-separated path of a video file relative to its repository root.
+func GetVideoRelativePath(video *datatypes.VideoData) string {
+	folder := video.OwnedSpace
+	if video.OwnedGroup != "" && video.OwnedGroup != "root" {
+		folder = path.Join(folder, filepath.ToSlash(video.OwnedGroup))
+	}
+	return path.Join(folder, video.FileName+video.Codecs.Format)
+}
+
 func (r *RepoManager) GetVideoByPath(path string) (*datatypes.VideoData, error) {
 	if !r.IsDataStorageInitialized() {
 		return nil, fmt.Errorf("data storage is not initialized")
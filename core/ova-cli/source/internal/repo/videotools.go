@@ -4,10 +4,10 @@ import (
 	"fmt"
 	"ova-cli/source/internal/datatypes"
 	"ova-cli/source/internal/filehash"
-	"ova-cli/source/internal/thirdparty"
 )
 
-// GenerateVideoID computes a unique ID for the video based on its content hash.
+// GenerateVideoID computes the partial content hash (first and last 5 MB) a video's ID is based on.
+// It is fast enough for lookups but not unique; use ResolveVideoID to find the ID of a file.
 func (r *RepoManager) GenerateVideoID(absoluteVideoPath string) (string, error) {
 	videoID, err := filehash.Sha256FileHash(absoluteVideoPath)
 	if err != nil {
@@ -18,28 +18,18 @@ func (r *RepoManager) GenerateVideoID(absoluteVideoPath string) (string, error)
 
 // GetVideoDuration returns the duration (in seconds) of the given video file.
 func (r *RepoManager) GetVideoDuration(videoPath string) (float64, error) {
-	duration, err := thirdparty.GetVideoDuration(videoPath)
+	duration, err := r.mediaToolchain.Duration(videoPath)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get duration for %s: %w", videoPath, err)
 	}
 	return duration, nil
 }
 
-// GetVideoDuration returns the duration (in seconds) of the given video file.
+// GetVideoCodect returns the container format, codecs and stream list of the given video file.
 func (r *RepoManager) GetVideoCodect(videoPath string) (datatypes.VideoCodecs, error) {
-
-	codec, err := thirdparty.GetVideoDetails(videoPath)
+	codecs, err := r.mediaToolchain.Probe(videoPath)
 	if err != nil {
 		return datatypes.VideoCodecs{}, fmt.Errorf("failed to get codecs for file: %w", err)
 	}
-
-	return datatypes.VideoCodecs{
-		DurationSec: int(codec.DurationSec),
-		FrameRate:   codec.FrameRate,
-		Resolution:  datatypes.VideoResolution(codec.Resolution),
-		VideoCodec:  codec.VideoCodec,
-		AudioCodec:  codec.AudioCodec,
-		Format:      codec.Format,
-		IsFragment:  codec.IsFragment,
-	}, nil
+	return codecs, nil
 }
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+
+	"ova-cli/source/internal/api"
+	"ova-cli/source/internal/logs"
+)
+
+var adminSocketLogger = logs.Loggers("AdminSocket")
+
+// adminListener drops connections from processes that may not use the admin API.
+type adminListener struct {
+	net.Listener
+}
+
+func (l adminListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		if adminPeerAllowed(conn) {
+			return conn, nil
+		}
+		adminSocketLogger.Warn("Refused an admin socket connection from another user")
+		conn.Close()
+	}
+}
+
+// startAdminSocket serves the local admin API on a Unix socket only the server's user can
+// open. CLI commands find it through the repository lock and hand their changes to the
+// server instead of writing the repository behind its back.
+func (s *OvaServer) startAdminSocket(socketPath string) error {
+	// A server that crashed leaves its socket behind; holding the lock makes it ours to remove
+	os.Remove(socketPath)
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", socketPath, err)
+	}
+	if err := os.Chmod(socketPath, 0600); err != nil {
+		listener.Close()
+		return fmt.Errorf("failed to restrict %s: %w", socketPath, err)
+	}
+
+	router := gin.New()
+	router.Use(gin.Recovery())
+	api.RegisterLocalAdminRoutes(router.Group("/"), s.RepoManager)
+
+	go func() {
+		if err := http.Serve(adminListener{listener}, router); err != nil && !errors.Is(err, net.ErrClosed) {
+			adminSocketLogger.Error("Admin socket stopped: %v", err)
+		}
+	}()
+	adminSocketLogger.Info("Local admin API listening on %s", socketPath)
+	return nil
+}
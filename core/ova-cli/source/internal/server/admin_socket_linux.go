@@ -0,0 +1,32 @@
+//go:build linux
+
+package server
+
+import (
+	"net"
+	"os"
+	"syscall"
+)
+
+// adminPeerAllowed reports whether the process on the other end of an admin socket connection
+// runs as the server's user or as root. The socket's mode already keeps other users out; this
+// also covers the moment between creating the socket and restricting it.
+func adminPeerAllowed(conn net.Conn) bool {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return false
+	}
+	raw, err := unixConn.SyscallConn()
+	if err != nil {
+		return false
+	}
+
+	var cred *syscall.Ucred
+	var credErr error
+	if err := raw.Control(func(fd uintptr) {
+		cred, credErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	}); err != nil || credErr != nil {
+		return false
+	}
+	return cred.Uid == 0 || int(cred.Uid) == os.Getuid()
+}
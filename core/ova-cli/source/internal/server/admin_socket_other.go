@@ -0,0 +1,11 @@
+//go:build !linux
+
+package server
+
+import "net"
+
+// adminPeerAllowed accepts every connection where peer credentials are not available; the
+// socket's file mode is what keeps other users out.
+func adminPeerAllowed(conn net.Conn) bool {
+	return true
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"time"
+
+	"ova-cli/source/internal/logs"
+)
+
+var gcLogger = logs.Loggers("GC")
+
+// startGarbageCollector deletes orphaned and unfinished artefacts once at startup and then
+// at the interval set in the repository config, for as long as the server runs.
+func (s *OvaServer) startGarbageCollector() {
+	interval := s.RepoManager.GetGCInterval()
+	if interval <= 0 {
+		gcLogger.Info("Periodic garbage collection is disabled")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			s.collectGarbage()
+			<-ticker.C
+		}
+	}()
+}
+
+func (s *OvaServer) collectGarbage() {
+	report, err := s.RepoManager.CollectGarbage(false)
+	if err != nil {
+		gcLogger.Error("Garbage collection failed: %v", err)
+		return
+	}
+	for _, msg := range report.Errors {
+		gcLogger.Warn("%s", msg)
+	}
+	if len(report.Items) > 0 {
+		gcLogger.Info("Deleted %d unused artefacts, freed %d bytes", len(report.Items)-len(report.Errors), report.DeletedBytes)
+	}
+}
@@ -79,14 +79,26 @@ func (s *OvaServer) initRoutes() {
 	api.RegisterPreviewRoutes(v1, s.RepoManager)
 	api.RegisterSpaceRoutes(v1, s.RepoManager)
 	api.RegisterUserWatchedRoutes(v1, s.RepoManager)
+	api.RegisterUserProgressRoutes(v1, s.RepoManager)
 	api.RegisterUserPlaylistContentRoutes(v1, s.RepoManager)
+	api.RegisterPlaylistSharingRoutes(v1, s.RepoManager)
+	api.RegisterPlaylistTransferRoutes(v1, s.RepoManager)
 	api.RegisterStoryboardRoutes(v1, s.RepoManager)
 	api.RegisterMarkerRoutes(v1, s.RepoManager)
+	api.RegisterSubtitleRoutes(v1, s.RepoManager)
 	api.RegisterLatestVideoRoute(v1, s.RepoManager)
 	api.RegisterSearchSuggestionsRoutes(v1, s.RepoManager)
 	api.RegisterSpaceContentRoutes(v1, s.RepoManager)
+	api.RegisterSubRepositoryRoutes(v1, s.RepoManager)
 	api.RegisterStatusRoute(v1)
 
+	admin := v1.Group("/admin")
+	admin.Use(api.AdminMiddleware(s.RepoManager))
+	api.RegisterSpaceAdminRoutes(admin, s.RepoManager)
+	api.RegisterDuplicateRoutes(admin, s.RepoManager)
+	api.RegisterVideoAdminRoutes(admin, s.RepoManager)
+	api.RegisterTrashRoutes(admin, s.RepoManager)
+
 	if s.ServeFrontend {
 		s.serveFrontendStatic()
 	}
@@ -105,7 +117,19 @@ func (s *OvaServer) serveFrontendStatic() {
 }
 
 func (s *OvaServer) Run() error {
+	// The server writes the repository for as long as it runs; CLI commands hand it their changes
+	socketPath := s.RepoManager.GetAdminSocketPath()
+	if err := s.RepoManager.Lock("serve", socketPath); err != nil {
+		return err
+	}
+	if err := s.startAdminSocket(socketPath); err != nil {
+		s.RepoManager.Unlock()
+		return err
+	}
+
 	s.initRoutes()
+	s.startGarbageCollector()
+	s.startTrashPurger()
 
 	// mDNS service advertisement removed
 
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"time"
+
+	"ova-cli/source/internal/logs"
+)
+
+// trashPurgeInterval is how often the server looks for trashed videos past their retention period.
+const trashPurgeInterval = time.Hour
+
+var trashLogger = logs.Loggers("Trash")
+
+// startTrashPurger permanently deletes expired videos from the trash once at startup and
+// then every trashPurgeInterval, for as long as the server runs.
+func (s *OvaServer) startTrashPurger() {
+	if s.RepoManager.GetTrashRetention() <= 0 {
+		trashLogger.Info("Trash retention is unlimited; expired videos are not purged")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(trashPurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			s.purgeExpiredTrash()
+			<-ticker.C
+		}
+	}()
+}
+
+func (s *OvaServer) purgeExpiredTrash() {
+	report, err := s.RepoManager.PurgeTrash(nil, false)
+	if err != nil {
+		trashLogger.Error("Purging the trash failed: %v", err)
+		return
+	}
+	for _, msg := range report.Errors {
+		trashLogger.Warn("%s", msg)
+	}
+	if len(report.PurgedVideoIDs) > 0 {
+		trashLogger.Info("Purged %d expired videos, freed %d bytes", len(report.PurgedVideoIDs), report.FreedBytes)
+	}
+}
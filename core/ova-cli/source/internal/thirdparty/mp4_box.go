@@ -0,0 +1,581 @@
+package thirdparty
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"os"
+	"strings"
+)
+
+// ErrNotMP4 is returned by ParseMP4 when a file is not an ISO base media (MP4/MOV) file.
+var ErrNotMP4 = errors.New("not an ISO base media file")
+
+// maxMoovSize bounds the movie box read into memory; real moov boxes are a few megabytes at most.
+const maxMoovSize = 256 << 20
+
+// MP4Info is the metadata read from the boxes of an MP4 file.
+type MP4Info struct {
+	MajorBrand string     // ftyp major brand, e.g. "isom" or "qt  "
+	Timescale  uint32     // Movie timescale (units per second)
+	Duration   uint64     // Movie duration in timescale units
+	Fragmented bool       // The file has an mvex or moof box
+	Tracks     []MP4Track // Tracks in moov order
+}
+
+// MP4Track is one trak box of an MP4 file.
+type MP4Track struct {
+	ID          uint32
+	Handler     string  // hdlr type: "vide", "soun", "sbtl", "text", ...
+	Timescale   uint32  // Media timescale (units per second)
+	Duration    uint64  // Media duration in timescale units
+	Language    string  // ISO 639-2 code from mdhd, "und" when unset
+	Format      string  // Sample entry type, e.g. "avc1", "hvc1", "mp4a"
+	Codec       string  // RFC 6381 codec string, e.g. "avc1.640028" or "mp4a.40.2"
+	Width       int     // Display width from tkhd, or the coded width
+	Height      int     // Display height from tkhd, or the coded height
+	FrameRate   float64 // Average frames per second, 0 when unknown
+	SampleCount uint32
+	Channels    int
+	SampleRate  int
+}
+
+// DurationSec returns the movie duration in seconds.
+func (m *MP4Info) DurationSec() float64 {
+	if m.Timescale == 0 {
+		return 0
+	}
+	return float64(m.Duration) / float64(m.Timescale)
+}
+
+// FirstTrack returns the first track with the given handler type, or nil.
+func (m *MP4Info) FirstTrack(handler string) *MP4Track {
+	for i := range m.Tracks {
+		if m.Tracks[i].Handler == handler {
+			return &m.Tracks[i]
+		}
+	}
+	return nil
+}
+
+// ParseMP4 reads the ftyp, moov and moof boxes of an MP4/MOV file without external
+// tools. Media data is skipped, so only the metadata is read from disk.
+func ParseMP4(path string) (*MP4Info, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	fileSize := fi.Size()
+	if fileSize < 8 {
+		return nil, ErrNotMP4
+	}
+
+	info := &MP4Info{}
+	var moov []byte
+	var offset int64
+	for offset < fileSize {
+		typ, headerSize, boxSize, err := readBoxHeader(f, offset, fileSize)
+		if err != nil {
+			if offset == 0 {
+				return nil, ErrNotMP4
+			}
+			return nil, err
+		}
+		if offset == 0 && !isTopLevelBox(typ) {
+			return nil, ErrNotMP4
+		}
+
+		switch typ {
+		case "ftyp":
+			payload, err := readBoxPayload(f, offset+headerSize, boxSize-headerSize, 1024)
+			if err != nil {
+				return nil, err
+			}
+			if len(payload) >= 4 {
+				info.MajorBrand = string(payload[:4])
+			}
+		case "moov":
+			if moov, err = readBoxPayload(f, offset+headerSize, boxSize-headerSize, maxMoovSize); err != nil {
+				return nil, err
+			}
+		case "moof":
+			info.Fragmented = true
+		}
+
+		// Fragmented files hold thousands of moof/mdat pairs; the first one is enough
+		if moov != nil && info.Fragmented {
+			break
+		}
+		offset += boxSize
+	}
+
+	if moov == nil {
+		return nil, fmt.Errorf("no moov box found in %s", path)
+	}
+	if err := info.parseMoov(moov); err != nil {
+		return nil, fmt.Errorf("invalid moov box in %s: %w", path, err)
+	}
+	return info, nil
+}
+
+// isTopLevelBox reports whether typ can start an ISO base media file.
+func isTopLevelBox(typ string) bool {
+	switch typ {
+	case "ftyp", "moov", "mdat", "free", "skip", "wide", "pnot", "styp", "uuid":
+		return true
+	}
+	return false
+}
+
+// readBoxHeader reads the header of the box at offset and returns its type, header size and total size.
+func readBoxHeader(r io.ReaderAt, offset, fileSize int64) (string, int64, int64, error) {
+	var hdr [16]byte
+	if _, err := r.ReadAt(hdr[:8], offset); err != nil {
+		return "", 0, 0, fmt.Errorf("failed to read box header at %d: %w", offset, err)
+	}
+
+	size := int64(binary.BigEndian.Uint32(hdr[:4]))
+	typ := string(hdr[4:8])
+	headerSize := int64(8)
+
+	switch size {
+	case 0: // box extends to the end of the file
+		size = fileSize - offset
+	case 1: // 64-bit size follows the type
+		if _, err := r.ReadAt(hdr[8:16], offset+8); err != nil {
+			return "", 0, 0, fmt.Errorf("failed to read box size at %d: %w", offset, err)
+		}
+		size = int64(binary.BigEndian.Uint64(hdr[8:16]))
+		headerSize = 16
+	}
+
+	if size < headerSize || offset+size > fileSize {
+		return "", 0, 0, fmt.Errorf("box %q at %d has invalid size %d", typ, offset, size)
+	}
+	return typ, headerSize, size, nil
+}
+
+func readBoxPayload(r io.ReaderAt, offset, size, limit int64) ([]byte, error) {
+	if size > limit {
+		return nil, fmt.Errorf("box at %d is too large (%d bytes)", offset, size)
+	}
+	payload := make([]byte, size)
+	if _, err := r.ReadAt(payload, offset); err != nil {
+		return nil, fmt.Errorf("failed to read box at %d: %w", offset, err)
+	}
+	return payload, nil
+}
+
+// mp4Box is a parsed child box: its type and payload without the header.
+type mp4Box struct {
+	typ  string
+	data []byte
+}
+
+// parseBoxes splits a container payload into its child boxes.
+func parseBoxes(b []byte) ([]mp4Box, error) {
+	var boxes []mp4Box
+	for len(b) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(b[:4]))
+		typ := string(b[4:8])
+		headerSize := uint64(8)
+
+		switch size {
+		case 0:
+			size = uint64(len(b))
+		case 1:
+			if len(b) < 16 {
+				return nil, fmt.Errorf("truncated %q box", typ)
+			}
+			size = binary.BigEndian.Uint64(b[8:16])
+			headerSize = 16
+		}
+		if size < headerSize || size > uint64(len(b)) {
+			return nil, fmt.Errorf("%q box has invalid size %d", typ, size)
+		}
+
+		boxes = append(boxes, mp4Box{typ: typ, data: b[headerSize:size]})
+		b = b[size:]
+	}
+	return boxes, nil
+}
+
+// childBox follows a path of box types below a container payload and returns the payload of the last one.
+func childBox(b []byte, path ...string) ([]byte, bool) {
+	for _, typ := range path {
+		boxes, err := parseBoxes(b)
+		if err != nil {
+			return nil, false
+		}
+		found := false
+		for _, box := range boxes {
+			if box.typ == typ {
+				b, found = box.data, true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return b, true
+}
+
+func (m *MP4Info) parseMoov(moov []byte) error {
+	boxes, err := parseBoxes(moov)
+	if err != nil {
+		return err
+	}
+
+	defaultDurations := map[uint32]uint32{} // trex default sample duration per track ID
+	for _, box := range boxes {
+		switch box.typ {
+		case "mvhd":
+			m.Timescale, m.Duration = parseTimescaleAndDuration(box.data)
+		case "mvex":
+			m.Fragmented = true
+			children, err := parseBoxes(box.data)
+			if err != nil {
+				return err
+			}
+			for _, child := range children {
+				switch {
+				case child.typ == "mehd" && len(child.data) >= 8:
+					if m.Duration == 0 {
+						if child.data[0] == 1 && len(child.data) >= 12 {
+							m.Duration = binary.BigEndian.Uint64(child.data[4:12])
+						} else {
+							m.Duration = uint64(binary.BigEndian.Uint32(child.data[4:8]))
+						}
+					}
+				case child.typ == "trex" && len(child.data) >= 16:
+					defaultDurations[binary.BigEndian.Uint32(child.data[4:8])] = binary.BigEndian.Uint32(child.data[12:16])
+				}
+			}
+		case "trak":
+			track, err := parseTrak(box.data)
+			if err != nil {
+				return err
+			}
+			m.Tracks = append(m.Tracks, track)
+		}
+	}
+
+	for i := range m.Tracks {
+		t := &m.Tracks[i]
+		if t.FrameRate == 0 && t.Handler == "vide" && t.Timescale > 0 && defaultDurations[t.ID] > 0 {
+			t.FrameRate = roundFrameRate(float64(t.Timescale) / float64(defaultDurations[t.ID]))
+		}
+	}
+	return nil
+}
+
+// parseTimescaleAndDuration reads the timescale and duration of an mvhd or mdhd box.
+func parseTimescaleAndDuration(b []byte) (uint32, uint64) {
+	if len(b) >= 32 && b[0] == 1 {
+		return binary.BigEndian.Uint32(b[20:24]), binary.BigEndian.Uint64(b[24:32])
+	}
+	if len(b) >= 20 {
+		return binary.BigEndian.Uint32(b[12:16]), uint64(binary.BigEndian.Uint32(b[16:20]))
+	}
+	return 0, 0
+}
+
+func parseTrak(trak []byte) (MP4Track, error) {
+	track := MP4Track{Language: "und"}
+
+	if tkhd, ok := childBox(trak, "tkhd"); ok {
+		idOffset := 12
+		if len(tkhd) > 0 && tkhd[0] == 1 {
+			idOffset = 20
+		}
+		if len(tkhd) >= idOffset+4 {
+			track.ID = binary.BigEndian.Uint32(tkhd[idOffset : idOffset+4])
+		}
+		// Width and height are the last two 16.16 fixed point fields
+		if len(tkhd) >= 8 {
+			track.Width = int(binary.BigEndian.Uint32(tkhd[len(tkhd)-8:]) >> 16)
+			track.Height = int(binary.BigEndian.Uint32(tkhd[len(tkhd)-4:]) >> 16)
+		}
+	}
+
+	mdia, ok := childBox(trak, "mdia")
+	if !ok {
+		return track, fmt.Errorf("track %d has no mdia box", track.ID)
+	}
+
+	if mdhd, ok := childBox(mdia, "mdhd"); ok {
+		track.Timescale, track.Duration = parseTimescaleAndDuration(mdhd)
+		langOffset := 20
+		if len(mdhd) > 0 && mdhd[0] == 1 {
+			langOffset = 32
+		}
+		if len(mdhd) >= langOffset+2 {
+			if lang := decodeMP4Language(binary.BigEndian.Uint16(mdhd[langOffset:])); lang != "" {
+				track.Language = lang
+			}
+		}
+	}
+
+	if hdlr, ok := childBox(mdia, "hdlr"); ok && len(hdlr) >= 12 {
+		track.Handler = string(hdlr[8:12])
+	}
+
+	stbl, ok := childBox(mdia, "minf", "stbl")
+	if !ok {
+		return track, nil
+	}
+
+	if stsd, ok := childBox(stbl, "stsd"); ok && len(stsd) >= 8 {
+		// Full box header (4) and entry count (4), then the first sample entry
+		if entries, err := parseBoxes(stsd[8:]); err == nil && len(entries) > 0 {
+			parseSampleEntry(&track, entries[0])
+		}
+	}
+
+	if stts, ok := childBox(stbl, "stts"); ok && len(stts) >= 8 {
+		entryCount := int(binary.BigEndian.Uint32(stts[4:8]))
+		var samples, totalDelta uint64
+		for i := 0; i < entryCount && 8+i*8+8 <= len(stts); i++ {
+			count := uint64(binary.BigEndian.Uint32(stts[8+i*8:]))
+			delta := uint64(binary.BigEndian.Uint32(stts[12+i*8:]))
+			samples += count
+			totalDelta += count * delta
+		}
+		track.SampleCount = uint32(samples)
+		if track.Handler == "vide" && totalDelta > 0 && track.Timescale > 0 {
+			track.FrameRate = roundFrameRate(float64(samples) * float64(track.Timescale) / float64(totalDelta))
+		}
+	}
+
+	return track, nil
+}
+
+// decodeMP4Language unpacks an mdhd language: three 5-bit letters offset by 0x60.
+func decodeMP4Language(packed uint16) string {
+	if packed == 0 || packed == 0x7fff {
+		return ""
+	}
+	return string([]byte{
+		byte(packed>>10&0x1f) + 0x60,
+		byte(packed>>5&0x1f) + 0x60,
+		byte(packed&0x1f) + 0x60,
+	})
+}
+
+// parseSampleEntry fills the track's format, codec string and stream properties from an stsd entry.
+func parseSampleEntry(track *MP4Track, entry mp4Box) {
+	track.Format = entry.typ
+	format := entry.typ
+	b := entry.data
+
+	var children []byte
+	switch track.Handler {
+	case "vide":
+		// SampleEntry (8) + VisualSampleEntry fields (70) before the child boxes
+		if len(b) < 78 {
+			return
+		}
+		if track.Width == 0 || track.Height == 0 {
+			track.Width = int(binary.BigEndian.Uint16(b[24:26]))
+			track.Height = int(binary.BigEndian.Uint16(b[26:28]))
+		}
+		children = b[78:]
+	case "soun":
+		// SampleEntry (8) + AudioSampleEntry fields (20); QuickTime versions 1 and 2 add more
+		if len(b) < 28 {
+			return
+		}
+		track.Channels = int(binary.BigEndian.Uint16(b[16:18]))
+		track.SampleRate = int(binary.BigEndian.Uint32(b[24:28]) >> 16)
+		headerSize := 28
+		switch binary.BigEndian.Uint16(b[8:10]) {
+		case 1:
+			headerSize += 16
+		case 2:
+			headerSize += 36
+			if len(b) >= 48 {
+				track.SampleRate = int(math.Float64frombits(binary.BigEndian.Uint64(b[32:40])))
+				track.Channels = int(binary.BigEndian.Uint32(b[40:44]))
+			}
+		}
+		if len(b) < headerSize {
+			return
+		}
+		children = b[headerSize:]
+	default:
+		track.Codec = format
+		return
+	}
+
+	// Encrypted entries (encv/enca) name the original format in sinf/frma
+	if format == "encv" || format == "enca" {
+		if frma, ok := childBox(children, "sinf", "frma"); ok && len(frma) >= 4 {
+			format = string(frma[:4])
+			track.Format = format
+		}
+	}
+
+	track.Codec = codecString(format, children)
+}
+
+// codecString builds the RFC 6381 codec string used in MIME types, e.g. `video/mp4; codecs="avc1.640028"`.
+func codecString(format string, children []byte) string {
+	switch format {
+	case "avc1", "avc2", "avc3", "avc4":
+		if avcC, ok := childBox(children, "avcC"); ok && len(avcC) >= 4 {
+			return fmt.Sprintf("%s.%02x%02x%02x", format, avcC[1], avcC[2], avcC[3])
+		}
+	case "hvc1", "hev1":
+		if hvcC, ok := childBox(children, "hvcC"); ok && len(hvcC) >= 13 {
+			return hevcCodecString(format, hvcC)
+		}
+	case "av01":
+		if av1C, ok := childBox(children, "av1C"); ok && len(av1C) >= 3 {
+			profile := av1C[1] >> 5
+			level := av1C[1] & 0x1f
+			tier := "M"
+			if av1C[2]&0x80 != 0 {
+				tier = "H"
+			}
+			bitDepth := 8
+			if av1C[2]&0x40 != 0 {
+				bitDepth = 10
+				if av1C[2]&0x20 != 0 {
+					bitDepth = 12
+				}
+			}
+			return fmt.Sprintf("av01.%d.%02d%s.%02d", profile, level, tier, bitDepth)
+		}
+	case "vp08", "vp09":
+		if vpcC, ok := childBox(children, "vpcC"); ok && len(vpcC) >= 7 {
+			return fmt.Sprintf("%s.%02d.%02d.%02d", format, vpcC[4], vpcC[5], vpcC[6]>>4)
+		}
+	case "mp4a":
+		if esds, ok := childBox(children, "esds"); ok && len(esds) > 4 {
+			if s := aacCodecString(esds[4:]); s != "" {
+				return s
+			}
+		}
+		return "mp4a.40.2"
+	case "fLaC":
+		return "flac"
+	case "Opus":
+		return "opus"
+	}
+	return format
+}
+
+// hevcCodecString formats an HEVC codec string from an hvcC box, e.g. "hvc1.1.6.L93.B0".
+func hevcCodecString(format string, hvcC []byte) string {
+	profileSpace := hvcC[1] >> 6
+	tierFlag := hvcC[1] >> 5 & 1
+	profileIDC := hvcC[1] & 0x1f
+	compatFlags := bits.Reverse32(binary.BigEndian.Uint32(hvcC[2:6]))
+	constraints := hvcC[6:12]
+	levelIDC := hvcC[12]
+
+	var sb strings.Builder
+	sb.WriteString(format + ".")
+	if profileSpace > 0 {
+		sb.WriteByte("ABC"[profileSpace-1])
+	}
+	fmt.Fprintf(&sb, "%d.%x.", profileIDC, compatFlags)
+	if tierFlag == 1 {
+		sb.WriteString("H")
+	} else {
+		sb.WriteString("L")
+	}
+	fmt.Fprintf(&sb, "%d", levelIDC)
+
+	// Trailing zero constraint bytes are omitted
+	last := len(constraints)
+	for last > 0 && constraints[last-1] == 0 {
+		last--
+	}
+	for _, c := range constraints[:last] {
+		fmt.Fprintf(&sb, ".%X", c)
+	}
+	return sb.String()
+}
+
+// aacCodecString reads the object type from an ES descriptor: "mp4a.40.<audio object type>"
+// for MPEG-4 audio, "mp4a.<object type indication>" otherwise (e.g. "mp4a.6b" for MP3).
+func aacCodecString(esd []byte) string {
+	// ES_Descriptor (tag 3): ES_ID (2), flags (1) with optional fields, then DecoderConfigDescriptor (tag 4)
+	b, ok := descriptorPayload(esd, 0x03)
+	if !ok || len(b) < 3 {
+		return ""
+	}
+	flags := b[2]
+	b = b[3:]
+	if flags&0x80 != 0 { // streamDependenceFlag
+		b = skipBytes(b, 2)
+	}
+	if flags&0x40 != 0 && len(b) > 0 { // URL_Flag
+		b = skipBytes(b, 1+int(b[0]))
+	}
+	if flags&0x20 != 0 { // OCRstreamFlag
+		b = skipBytes(b, 2)
+	}
+
+	dcd, ok := descriptorPayload(b, 0x04)
+	if !ok || len(dcd) < 13 {
+		return ""
+	}
+	objectType := dcd[0]
+	if objectType != 0x40 {
+		return fmt.Sprintf("mp4a.%02x", objectType)
+	}
+
+	// DecoderSpecificInfo (tag 5) starts with the 5-bit audio object type
+	dsi, ok := descriptorPayload(dcd[13:], 0x05)
+	if !ok || len(dsi) < 1 {
+		return "mp4a.40.2"
+	}
+	aot := int(dsi[0] >> 3)
+	if aot == 31 && len(dsi) >= 2 {
+		aot = 32 + (int(dsi[0]&0x07)<<3 | int(dsi[1]>>5))
+	}
+	return fmt.Sprintf("mp4a.40.%d", aot)
+}
+
+// descriptorPayload returns the payload of an MPEG-4 descriptor with the given tag at the start of b.
+func descriptorPayload(b []byte, tag byte) ([]byte, bool) {
+	if len(b) < 2 || b[0] != tag {
+		return nil, false
+	}
+	// The size is stored in up to four bytes of 7 bits each
+	size, i := 0, 1
+	for ; i < len(b) && i <= 4; i++ {
+		size = size<<7 | int(b[i]&0x7f)
+		if b[i]&0x80 == 0 {
+			i++
+			break
+		}
+	}
+	if i+size > len(b) {
+		return b[i:], true
+	}
+	return b[i : i+size], true
+}
+
+func skipBytes(b []byte, n int) []byte {
+	if n > len(b) {
+		return nil
+	}
+	return b[n:]
+}
+
+func roundFrameRate(fps float64) float64 {
+	return math.Round(fps*100) / 100
+}
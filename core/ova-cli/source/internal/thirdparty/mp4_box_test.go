@@ -0,0 +1,398 @@
+package thirdparty
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// Fixture builders. Each returns a complete box: size, type and payload.
+
+func box(typ string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	b := binary.BigEndian.AppendUint32(nil, uint32(8+len(body)))
+	return append(append(b, typ...), body...)
+}
+
+// box64 writes a box with the 64-bit largesize header.
+func box64(typ string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	b := binary.BigEndian.AppendUint32(nil, 1)
+	b = append(b, typ...)
+	b = binary.BigEndian.AppendUint64(b, uint64(16+len(body)))
+	return append(b, body...)
+}
+
+func u16(v uint16) []byte { return binary.BigEndian.AppendUint16(nil, v) }
+func u32(v uint32) []byte { return binary.BigEndian.AppendUint32(nil, v) }
+func u64(v uint64) []byte { return binary.BigEndian.AppendUint64(nil, v) }
+func zeros(n int) []byte  { return make([]byte, n) }
+
+func ftyp(brand string) []byte {
+	return box("ftyp", []byte(brand), u32(0x200), []byte("isomavc1"))
+}
+
+func mvhd(timescale uint32, duration uint32) []byte {
+	return box("mvhd", zeros(12), u32(timescale), u32(duration), zeros(80))
+}
+
+func mvhd64(timescale uint32, duration uint64) []byte {
+	return box("mvhd", []byte{1, 0, 0, 0}, zeros(16), u32(timescale), u64(duration), zeros(80))
+}
+
+func tkhd(trackID uint32, width, height uint16) []byte {
+	return box("tkhd", zeros(12), u32(trackID), zeros(60), u32(uint32(width)<<16), u32(uint32(height)<<16))
+}
+
+func mdhd(timescale, duration uint32, language string) []byte {
+	var packed uint16
+	for _, c := range []byte(language) {
+		packed = packed<<5 | uint16(c-0x60)
+	}
+	return box("mdhd", zeros(12), u32(timescale), u32(duration), u16(packed), zeros(2))
+}
+
+func hdlr(handler string) []byte {
+	return box("hdlr", zeros(8), []byte(handler), zeros(13))
+}
+
+func stsd(entry []byte) []byte {
+	return box("stsd", zeros(4), u32(1), entry)
+}
+
+func stts(count, delta uint32) []byte {
+	if count == 0 {
+		return box("stts", zeros(4), u32(0))
+	}
+	return box("stts", zeros(4), u32(1), u32(count), u32(delta))
+}
+
+func avc1Entry(width, height uint16) []byte {
+	fields := zeros(78)
+	binary.BigEndian.PutUint16(fields[24:], width)
+	binary.BigEndian.PutUint16(fields[26:], height)
+	return box("avc1", fields, box("avcC", []byte{1, 0x64, 0x00, 0x28}))
+}
+
+func mp4aEntry(channels uint16, sampleRate uint32) []byte {
+	fields := zeros(28)
+	binary.BigEndian.PutUint16(fields[16:], channels)
+	binary.BigEndian.PutUint32(fields[24:], sampleRate<<16)
+
+	dsi := []byte{0x05, 2, 0x11, 0x90} // AAC LC
+	dcd := append([]byte{0x04, byte(13 + len(dsi)), 0x40, 0x15}, zeros(11)...)
+	dcd = append(dcd, dsi...)
+	esd := append([]byte{0x03, byte(3 + len(dcd)), 0, 1, 0}, dcd...)
+	return box("mp4a", fields, box("esds", zeros(4), esd))
+}
+
+func trak(header, media, handler, entry, timing []byte) []byte {
+	return box("trak", header, box("mdia", media, handler, box("minf", box("stbl", stsd(entry), timing))))
+}
+
+func videoTrak(frames uint32) []byte {
+	return trak(tkhd(1, 1280, 720), mdhd(15360, 30720, "eng"), hdlr("vide"), avc1Entry(1280, 720), stts(frames, 512))
+}
+
+func audioTrak() []byte {
+	return trak(tkhd(2, 0, 0), mdhd(48000, 96000, "jpn"), hdlr("soun"), mp4aEntry(2, 48000), stts(94, 1024))
+}
+
+// fragmentedMoov describes a fragmented file: the samples live in moof boxes, so the
+// duration comes from mehd and the frame rate from the trex default sample duration.
+func fragmentedMoov(mehdDuration uint32) []byte {
+	var mvex []byte
+	if mehdDuration > 0 {
+		mvex = append(mvex, box("mehd", zeros(4), u32(mehdDuration))...)
+	}
+	mvex = append(mvex, box("trex", zeros(4), u32(1), u32(1), u32(512), zeros(8))...)
+	track := trak(tkhd(1, 1280, 720), mdhd(15360, 0, "eng"), hdlr("vide"), avc1Entry(1280, 720), stts(0, 0))
+	return box("moov", mvhd(1000, 0), box("mvex", mvex), track)
+}
+
+func writeFixture(t *testing.T, name string, parts ...[]byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, bytes.Join(parts, nil), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseMP4(t *testing.T) {
+	tests := []struct {
+		name           string
+		parts          [][]byte
+		wantBrand      string
+		wantTimescale  uint32
+		wantDuration   uint64
+		wantFragmented bool
+		wantTracks     []MP4Track
+	}{
+		{
+			name:          "progressive",
+			parts:         [][]byte{ftyp("isom"), box("moov", mvhd(1000, 2000), videoTrak(60), audioTrak()), box("mdat", zeros(64))},
+			wantBrand:     "isom",
+			wantTimescale: 1000,
+			wantDuration:  2000,
+			wantTracks: []MP4Track{
+				{ID: 1, Handler: "vide", Timescale: 15360, Duration: 30720, Language: "eng", Format: "avc1", Codec: "avc1.640028",
+					Width: 1280, Height: 720, FrameRate: 30, SampleCount: 60},
+				{ID: 2, Handler: "soun", Timescale: 48000, Duration: 96000, Language: "jpn", Format: "mp4a", Codec: "mp4a.40.2",
+					SampleCount: 94, Channels: 2, SampleRate: 48000},
+			},
+		},
+		{
+			name:          "moov after mdat",
+			parts:         [][]byte{ftyp("mp42"), box("mdat", zeros(64)), box("moov", mvhd(600, 1200), videoTrak(60))},
+			wantBrand:     "mp42",
+			wantTimescale: 600,
+			wantDuration:  1200,
+			wantTracks: []MP4Track{
+				{ID: 1, Handler: "vide", Timescale: 15360, Duration: 30720, Language: "eng", Format: "avc1", Codec: "avc1.640028",
+					Width: 1280, Height: 720, FrameRate: 30, SampleCount: 60},
+			},
+		},
+		{
+			name:          "64-bit sizes",
+			parts:         [][]byte{ftyp("isom"), box64("moov", mvhd64(1000, 1<<33), videoTrak(60)), box64("mdat", zeros(64))},
+			wantBrand:     "isom",
+			wantTimescale: 1000,
+			wantDuration:  1 << 33,
+			wantTracks: []MP4Track{
+				{ID: 1, Handler: "vide", Timescale: 15360, Duration: 30720, Language: "eng", Format: "avc1", Codec: "avc1.640028",
+					Width: 1280, Height: 720, FrameRate: 30, SampleCount: 60},
+			},
+		},
+		{
+			name:          "mdat extending to end of file",
+			parts:         [][]byte{ftyp("isom"), box("moov", mvhd(1000, 2000), audioTrak()), u32(0), []byte("mdat"), zeros(64)},
+			wantBrand:     "isom",
+			wantTimescale: 1000,
+			wantDuration:  2000,
+			wantTracks: []MP4Track{
+				{ID: 2, Handler: "soun", Timescale: 48000, Duration: 96000, Language: "jpn", Format: "mp4a", Codec: "mp4a.40.2",
+					SampleCount: 94, Channels: 2, SampleRate: 48000},
+			},
+		},
+		{
+			name:           "fragmented",
+			parts:          [][]byte{ftyp("iso5"), fragmentedMoov(5000), box("moof", box("mfhd", zeros(8))), box("mdat", zeros(64))},
+			wantBrand:      "iso5",
+			wantTimescale:  1000,
+			wantDuration:   5000,
+			wantFragmented: true,
+			wantTracks: []MP4Track{
+				{ID: 1, Handler: "vide", Timescale: 15360, Language: "eng", Format: "avc1", Codec: "avc1.640028",
+					Width: 1280, Height: 720, FrameRate: 30},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := ParseMP4(writeFixture(t, "video.mp4", tt.parts...))
+			if err != nil {
+				t.Fatalf("ParseMP4() error = %v", err)
+			}
+			if info.MajorBrand != tt.wantBrand {
+				t.Errorf("MajorBrand = %q, want %q", info.MajorBrand, tt.wantBrand)
+			}
+			if info.Timescale != tt.wantTimescale || info.Duration != tt.wantDuration {
+				t.Errorf("Timescale, Duration = %d, %d, want %d, %d", info.Timescale, info.Duration, tt.wantTimescale, tt.wantDuration)
+			}
+			if info.Fragmented != tt.wantFragmented {
+				t.Errorf("Fragmented = %v, want %v", info.Fragmented, tt.wantFragmented)
+			}
+			if len(info.Tracks) != len(tt.wantTracks) {
+				t.Fatalf("got %d tracks, want %d", len(info.Tracks), len(tt.wantTracks))
+			}
+			for i, want := range tt.wantTracks {
+				if info.Tracks[i] != want {
+					t.Errorf("track %d = %+v, want %+v", i, info.Tracks[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseMP4Errors(t *testing.T) {
+	moov := box("moov", mvhd(1000, 2000), videoTrak(60))
+
+	// A trak box claiming more bytes than its moov holds
+	badTrak := box("trak", tkhd(1, 1280, 720))
+	binary.BigEndian.PutUint32(badTrak, uint32(len(badTrak)+100))
+
+	tests := []struct {
+		name      string
+		parts     [][]byte
+		wantNoMP4 bool
+		wantErr   string
+	}{
+		{name: "empty file", parts: nil, wantNoMP4: true},
+		{name: "text file", parts: [][]byte{[]byte("WEBVTT\n\n00:00.000 --> 00:01.000\nhello\n")}, wantNoMP4: true},
+		{name: "matroska", parts: [][]byte{{0x1a, 0x45, 0xdf, 0xa3, 0x9f, 0x42, 0x86, 0x81}, zeros(32)}, wantNoMP4: true},
+		{name: "truncated ftyp", parts: [][]byte{ftyp("isom")[:10]}, wantNoMP4: true},
+		{name: "truncated moov", parts: [][]byte{ftyp("isom"), moov[:len(moov)-10]}, wantErr: "invalid size"},
+		{name: "truncated 64-bit header", parts: [][]byte{ftyp("isom"), moov, u32(1), []byte("mdat"), zeros(4)}, wantErr: "failed to read box size"},
+		{name: "64-bit size past end of file", parts: [][]byte{ftyp("isom"), moov, u32(1), []byte("mdat"), u64(1 << 40)}, wantErr: "invalid size"},
+		{name: "truncated child box", parts: [][]byte{ftyp("isom"), box("moov", mvhd(1000, 2000), badTrak)}, wantErr: "invalid moov box"},
+		{name: "no moov", parts: [][]byte{ftyp("isom"), box("mdat", zeros(64))}, wantErr: "no moov box"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseMP4(writeFixture(t, "video.mp4", tt.parts...))
+			if err == nil {
+				t.Fatal("ParseMP4() succeeded, want an error")
+			}
+			if errors.Is(err, ErrNotMP4) != tt.wantNoMP4 {
+				t.Errorf("ParseMP4() error = %v, want ErrNotMP4: %v", err, tt.wantNoMP4)
+			}
+			if tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseMP4() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseBoxes(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      []byte
+		wantTypes []string
+		wantErr   bool
+	}{
+		{name: "empty", data: nil},
+		{name: "siblings", data: bytes.Join([][]byte{box("free"), box("skip", zeros(4))}, nil), wantTypes: []string{"free", "skip"}},
+		{name: "64-bit size", data: box64("free", zeros(4)), wantTypes: []string{"free"}},
+		{name: "size zero runs to the end", data: append(append(u32(0), "free"...), zeros(12)...), wantTypes: []string{"free"}},
+		{name: "trailing bytes shorter than a header", data: append(box("free"), 0, 0, 0), wantTypes: []string{"free"}},
+		{name: "size past the end", data: append(u32(64), "free"...), wantErr: true},
+		{name: "size smaller than the header", data: append(u32(4), "free"...), wantErr: true},
+		{name: "truncated 64-bit header", data: append(append(u32(1), "free"...), 0, 0), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			boxes, err := parseBoxes(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseBoxes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var types []string
+			for _, b := range boxes {
+				types = append(types, b.typ)
+			}
+			if strings.Join(types, ",") != strings.Join(tt.wantTypes, ",") {
+				t.Errorf("parseBoxes() types = %v, want %v", types, tt.wantTypes)
+			}
+		})
+	}
+}
+
+// installFakeFFprobe puts a script where GetFFprobePath looks for ffprobe, next to the test
+// binary, that prints output for any file.
+func installFakeFFprobe(t *testing.T, output string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake ffprobe is a shell script")
+	}
+	exePath, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(filepath.Dir(exePath), "ffmpeg")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	script := "#!/bin/sh\ncat <<'EOF'\n" + output + "\nEOF\n"
+	if err := os.WriteFile(filepath.Join(dir, "ffprobe"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetVideoDetailsFFprobeFallback(t *testing.T) {
+	installFakeFFprobe(t, `{
+  "streams": [
+    {"index": 0, "codec_type": "video", "codec_name": "h264", "profile": "High", "level": 40,
+     "width": 1920, "height": 1080, "avg_frame_rate": "25/1", "r_frame_rate": "25/1"},
+    {"index": 1, "codec_type": "audio", "codec_name": "aac", "profile": "LC", "channels": 2, "sample_rate": "48000"}
+  ],
+  "format": {"duration": "12.5"}
+}`)
+
+	fromFFprobe := VideoDetails{
+		DurationSec: 12,
+		FrameRate:   25,
+		Resolution:  VideoResolution{Width: 1920, Height: 1080},
+		VideoCodec:  "avc1.640028",
+		AudioCodec:  "mp4a.40.2",
+	}
+
+	tests := []struct {
+		name  string
+		file  string
+		parts [][]byte
+		want  VideoDetails
+	}{
+		{
+			name:  "mp4 read from boxes",
+			file:  "video.mp4",
+			parts: [][]byte{ftyp("isom"), box("moov", mvhd(1000, 2000), videoTrak(60), audioTrak()), box("mdat", zeros(64))},
+			want: VideoDetails{
+				DurationSec: 2,
+				FrameRate:   30,
+				Resolution:  VideoResolution{Width: 1280, Height: 720},
+				VideoCodec:  "avc1.640028",
+				AudioCodec:  "mp4a.40.2",
+			},
+		},
+		{
+			name:  "non-mp4 container",
+			file:  "video.mkv",
+			parts: [][]byte{{0x1a, 0x45, 0xdf, 0xa3}, zeros(32)},
+			want:  fromFFprobe,
+		},
+		{
+			name:  "fragmented mp4 without duration",
+			file:  "video.mp4",
+			parts: [][]byte{ftyp("iso5"), fragmentedMoov(0), box("moof", box("mfhd", zeros(8))), box("mdat", zeros(64))},
+			want:  fromFFprobe,
+		},
+		{
+			name:  "truncated mp4",
+			file:  "video.mp4",
+			parts: [][]byte{ftyp("isom"), box("mdat", zeros(64))[:20]},
+			want:  fromFFprobe,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFixture(t, tt.file, tt.parts...)
+			tt.want.Format = filepath.Ext(path)
+
+			got, err := GetVideoDetails(path)
+			if err != nil {
+				t.Fatalf("GetVideoDetails() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetVideoDetails() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("missing file skips ffprobe", func(t *testing.T) {
+		_, err := GetVideoDetails(filepath.Join(t.TempDir(), "missing.mp4"))
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("GetVideoDetails() error = %v, want os.ErrNotExist", err)
+		}
+	})
+}
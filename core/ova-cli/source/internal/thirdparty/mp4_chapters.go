@@ -0,0 +1,103 @@
+package thirdparty
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+)
+
+// ContainerChapter is a chapter stored in a video container.
+type ContainerChapter struct {
+	StartMs int64
+	EndMs   int64
+	Title   string
+}
+
+// GetContainerChapters reads the chapters stored in a video file using ffprobe.
+func GetContainerChapters(videoPath string) ([]ContainerChapter, error) {
+	ffprobePath, err := GetFFprobePath()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(
+		ffprobePath,
+		"-loglevel", "error",
+		"-show_chapters",
+		"-of", "json",
+		videoPath,
+	)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe failed: %w", err)
+	}
+
+	var result struct {
+		Chapters []struct {
+			StartTime string            `json:"start_time"`
+			EndTime   string            `json:"end_time"`
+			Tags      map[string]string `json:"tags"`
+		} `json:"chapters"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+
+	chapters := make([]ContainerChapter, 0, len(result.Chapters))
+	for i, ch := range result.Chapters {
+		start, err := strconv.ParseFloat(ch.StartTime, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start time %q of chapter %d: %w", ch.StartTime, i+1, err)
+		}
+		end, _ := strconv.ParseFloat(ch.EndTime, 64)
+
+		title := ch.Tags["title"]
+		if title == "" {
+			title = fmt.Sprintf("Chapter %d", i+1)
+		}
+
+		chapters = append(chapters, ContainerChapter{
+			StartMs: int64(math.Round(start * 1000)),
+			EndMs:   int64(math.Round(end * 1000)),
+			Title:   title,
+		})
+	}
+	return chapters, nil
+}
+
+// EmbedChapters copies a video into outputPath with the chapters of an FFMETADATA file,
+// replacing any chapters it had. Streams are copied without re-encoding.
+func EmbedChapters(inputPath, metadataPath, outputPath string) error {
+	ffmpegPath, err := GetFFmpegPath()
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(outputPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	cmd := exec.Command(
+		ffmpegPath,
+		"-y",
+		"-i", inputPath,
+		"-i", metadataPath,
+		"-map", "0",
+		"-map_metadata", "0",
+		"-map_chapters", "1",
+		"-c", "copy",
+		"-movflags", "+faststart",
+		outputPath,
+	)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("ffmpeg chapter embedding error: %v, output: %s", err, string(output))
+	}
+	return nil
+}
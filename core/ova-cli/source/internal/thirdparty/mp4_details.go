@@ -3,10 +3,12 @@ package thirdparty
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"math"
+	"os"
 	"os/exec"
 	"path"
+	"strconv"
 )
 
 // VideoResolution defines the width and height of a video.
@@ -27,121 +29,115 @@ type VideoDetails struct {
 }
 
 // GetVideoDetails returns a struct containing the duration, FPS, resolution, and codec details of a video.
+// MP4 and MOV files are read with the built-in box parser; other containers, and MP4 files
+// the parser cannot fully describe, fall back to ffprobe.
 func GetVideoDetails(videoPath string) (VideoDetails, error) {
-
-	// Extract file extension (e.g., .mp4)
-	ext := path.Ext(videoPath)
-
-	// Get the full path to the Bento4 mp4info executable
-	mp4infoPath, err := GetBentoMP4InfoPath()
-	if err != nil {
-		return VideoDetails{}, fmt.Errorf("could not resolve mp4info path: %w", err)
+	details, err := getVideoDetailsFromBoxes(videoPath)
+	if err == nil {
+		return details, nil
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return VideoDetails{}, err
 	}
 
-	// Run mp4info with the --fast option to get details in JSON format
-	cmd := exec.Command(
-		mp4infoPath,
-		"--fast",           // Using the fast option for quicker analysis
-		"--format", "json", // Requesting JSON format
-		videoPath,
-	)
-
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		return VideoDetails{}, fmt.Errorf("mp4info execution failed: %s, error: %w", stderr.String(), err)
+	details, probeErr := getVideoDetailsFromFFprobe(videoPath)
+	if probeErr != nil {
+		if errors.Is(err, ErrNotMP4) {
+			return VideoDetails{}, probeErr
+		}
+		return VideoDetails{}, fmt.Errorf("%v; ffprobe fallback: %w", err, probeErr)
 	}
+	return details, nil
+}
 
-	// Parse mp4info JSON output
-	var result map[string]interface{}
-	err = json.Unmarshal(out.Bytes(), &result)
+// getVideoDetailsFromBoxes reads the video details from the MP4 box structure.
+func getVideoDetailsFromBoxes(videoPath string) (VideoDetails, error) {
+	info, err := ParseMP4(videoPath)
 	if err != nil {
-		return VideoDetails{}, fmt.Errorf("failed to parse mp4info output: %w", err)
+		return VideoDetails{}, err
 	}
 
-	// Extract the relevant details from the JSON output
-	tracks, ok := result["tracks"].([]interface{})
-	if !ok || len(tracks) == 0 {
-		return VideoDetails{}, fmt.Errorf("no tracks found in mp4info output")
+	videoTrack := info.FirstTrack("vide")
+	if videoTrack == nil {
+		return VideoDetails{}, fmt.Errorf("no video track found in %s", videoPath)
 	}
 
-	// Get the first video track (assuming track 1 is always video)
-	videoTrack, ok := tracks[0].(map[string]interface{})
-	if !ok {
-		return VideoDetails{}, fmt.Errorf("invalid video track format")
+	duration := info.DurationSec()
+	if duration == 0 && videoTrack.Timescale > 0 {
+		duration = float64(videoTrack.Duration) / float64(videoTrack.Timescale)
 	}
-
-	// Parse the details from the video track
-	width, widthOk := videoTrack["display_width"].(float64)
-	height, heightOk := videoTrack["display_height"].(float64)
-
-	// Ensure width and height exist and are valid numbers
-	if !widthOk || !heightOk {
-		return VideoDetails{}, fmt.Errorf("missing or invalid width/height in video track")
+	// Fragmented files without an mehd box only know their duration from the fragments
+	if duration == 0 || videoTrack.FrameRate == 0 {
+		return VideoDetails{}, fmt.Errorf("duration or frame rate not stored in the moov box of %s", videoPath)
 	}
 
-	// Check for 'duration_ms' safely and handle the case where it might be missing or nil
-	var duration float64
-	if movie, ok := result["movie"].(map[string]interface{}); ok {
-		if durationStr, ok := movie["duration_ms"].(float64); ok {
-			duration = durationStr / 1000 // Convert from milliseconds to seconds
-		} else {
-			return VideoDetails{}, fmt.Errorf("'duration_ms' is missing or not a float64")
-		}
-	} else {
-		return VideoDetails{}, fmt.Errorf("'movie' field is missing or not a map")
+	audioCodec := ""
+	if audioTrack := info.FirstTrack("soun"); audioTrack != nil {
+		audioCodec = audioTrack.Codec
 	}
 
-	// Parse video codec
-	videoCodec := ""
-	if len(videoTrack["sample_descriptions"].([]interface{})) > 0 {
-		videoCodec = videoTrack["sample_descriptions"].([]interface{})[0].(map[string]interface{})["codecs_string"].(string)
-	}
+	return VideoDetails{
+		Format:      path.Ext(videoPath), // Include the file extension (e.g., ".mp4")
+		DurationSec: int(duration),
+		FrameRate:   videoTrack.FrameRate,
+		IsFragment:  info.Fragmented,
+		Resolution:  VideoResolution{Width: videoTrack.Width, Height: videoTrack.Height},
+		VideoCodec:  videoTrack.Codec,
+		AudioCodec:  audioCodec,
+	}, nil
+}
 
-	// Parse audio codec (from the second track, assuming track 2 is always audio)
-	audioCodec := ""
-	if len(tracks) > 1 {
-		audioTrack, ok := tracks[1].(map[string]interface{})
-		if ok {
-			if len(audioTrack["sample_descriptions"].([]interface{})) > 0 {
-				audioCodec = audioTrack["sample_descriptions"].([]interface{})[0].(map[string]interface{})["codecs_string"].(string)
-			}
-		}
+// getVideoDetailsFromFFprobe reads the video details with ffprobe, for containers such as MKV or WebM.
+func getVideoDetailsFromFFprobe(videoPath string) (VideoDetails, error) {
+	ffprobePath, err := GetFFprobePath()
+	if err != nil {
+		return VideoDetails{}, err
 	}
 
-	// Get FPS using GetVideoFPS function
-	fps, err := GetVideoFPS(videoPath)
-	if err != nil {
-		return VideoDetails{}, fmt.Errorf("failed to get video FPS: %w", err)
+	cmd := exec.Command(
+		ffprobePath,
+		"-loglevel", "error",
+		"-show_streams",
+		"-show_entries", "format=duration",
+		"-of", "json",
+		videoPath,
+	)
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return VideoDetails{}, fmt.Errorf("ffprobe execution failed: %s, error: %w", stderr.String(), err)
 	}
 
-	// Limit FPS to 2 decimal places
-	fps = math.Round(fps*100) / 100 // This rounds the FPS to 2 decimal places
+	var result struct {
+		Streams []ffprobeStream `json:"streams"`
+		Format  struct {
+			Duration string `json:"duration"`
+		} `json:"format"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		return VideoDetails{}, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
 
-	// Check if the video is fragmented
-	isFragment := false
-	if movie, ok := result["movie"].(map[string]interface{}); ok {
-		if fragments, ok := movie["fragments"].(bool); ok {
-			isFragment = fragments
-		} else {
-			// If fragments is not found or isn't a bool, assume it's not fragmented
-			isFragment = false
+	details := VideoDetails{Format: path.Ext(videoPath)}
+	duration, _ := strconv.ParseFloat(result.Format.Duration, 64)
+	details.DurationSec = int(duration)
+
+	foundVideo := false
+	for _, s := range result.Streams {
+		switch {
+		case s.CodecType == "video" && !foundVideo && s.Disposition["attached_pic"] == 0:
+			foundVideo = true
+			ms := s.toMediaStream()
+			details.FrameRate = ms.FrameRate
+			details.Resolution = VideoResolution{Width: s.Width, Height: s.Height}
+			details.VideoCodec = s.codecString()
+		case s.CodecType == "audio" && details.AudioCodec == "":
+			details.AudioCodec = s.codecString()
 		}
-	} else {
-		return VideoDetails{}, fmt.Errorf("'movie' field is missing or not a map")
 	}
-
-	// Return the results in a struct with the file extension and other details
-	return VideoDetails{
-		Format:      ext,           // Include the file extension (e.g., ".mp4")
-		DurationSec: int(duration), // Cast duration to int
-		FrameRate:   fps,           // Use the frame rate directly
-		IsFragment:  isFragment,    // Check if the video is fragmented
-		Resolution:  VideoResolution{Width: int(width), Height: int(height)},
-		VideoCodec:  videoCodec,
-		AudioCodec:  audioCodec,
-	}, nil
+	if !foundVideo {
+		return VideoDetails{}, fmt.Errorf("no video stream found in %s", videoPath)
+	}
+	return details, nil
 }
@@ -2,21 +2,51 @@ package thirdparty
 
 import (
 	"fmt"
-	"os/exec"
+	"strings"
 )
 
-// PrintMP4Info runs mp4info on the provided video path and prints the output.
+// GetMP4Info returns a readable summary of the boxes of an MP4 file: brand, duration,
+// fragmentation and one line per track.
 func GetMP4Info(videoPath string) (string, error) {
-	mp4infoPath, err := GetBentoMP4InfoPath()
+	info, err := ParseMP4(videoPath)
 	if err != nil {
-		return "", fmt.Errorf("could not resolve mp4info path: %w", err)
+		return "", fmt.Errorf("failed to parse MP4 boxes: %w", err)
 	}
 
-	cmd := exec.Command(mp4infoPath, videoPath)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return "", fmt.Errorf("mp4info execution failed: %w\nOutput: %s", err, string(output))
+	var sb strings.Builder
+	line := func(indent int, label string, value any) {
+		fmt.Fprintf(&sb, "%*s%-18s %v\n", indent, "", label+":", value)
+	}
+
+	fragmented := "no"
+	if info.Fragmented {
+		fragmented = "yes"
 	}
+	sb.WriteString("Movie:\n")
+	line(2, "major brand", info.MajorBrand)
+	line(2, "time scale", info.Timescale)
+	line(2, "duration", fmt.Sprintf("%d (%.3f s)", info.Duration, info.DurationSec()))
+	line(2, "fragments", fragmented)
+	fmt.Fprintf(&sb, "Found %d tracks\n", len(info.Tracks))
 
-	return string(output), nil
+	for _, t := range info.Tracks {
+		fmt.Fprintf(&sb, "Track %d:\n", t.ID)
+		line(2, "type", t.Handler)
+		line(2, "language", t.Language)
+		line(2, "media time scale", t.Timescale)
+		line(2, "media duration", t.Duration)
+		line(2, "sample count", t.SampleCount)
+		line(2, "coding", t.Format)
+		line(2, "codecs string", t.Codec)
+		switch t.Handler {
+		case "vide":
+			line(2, "width", t.Width)
+			line(2, "height", t.Height)
+			line(2, "frame rate", fmt.Sprintf("%.2f", t.FrameRate))
+		case "soun":
+			line(2, "sample rate", t.SampleRate)
+			line(2, "channels", t.Channels)
+		}
+	}
+	return sb.String(), nil
 }
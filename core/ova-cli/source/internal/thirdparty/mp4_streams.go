@@ -0,0 +1,243 @@
+package thirdparty
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"ova-cli/source/internal/datatypes"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ffprobeStream is the subset of an ffprobe -show_streams entry that ova records.
+type ffprobeStream struct {
+	Index          int               `json:"index"`
+	CodecType      string            `json:"codec_type"`
+	CodecName      string            `json:"codec_name"`
+	CodecTagString string            `json:"codec_tag_string"`
+	Profile        string            `json:"profile"`
+	Level          int               `json:"level"`
+	BitRate        string            `json:"bit_rate"`
+	Width          int               `json:"width"`
+	Height         int               `json:"height"`
+	AvgFrameRate   string            `json:"avg_frame_rate"`
+	RFrameRate     string            `json:"r_frame_rate"`
+	PixFmt         string            `json:"pix_fmt"`
+	ColorSpace     string            `json:"color_space"`
+	ColorTransfer  string            `json:"color_transfer"`
+	ColorPrimaries string            `json:"color_primaries"`
+	Channels       int               `json:"channels"`
+	ChannelLayout  string            `json:"channel_layout"`
+	SampleRate     string            `json:"sample_rate"`
+	Tags           map[string]string `json:"tags"`
+	Disposition    map[string]int    `json:"disposition"`
+	SideDataList   []struct {
+		SideDataType string `json:"side_data_type"`
+		Rotation     int    `json:"rotation"`
+	} `json:"side_data_list"`
+}
+
+// GetMediaStreams lists every stream of a video file and the container's overall bitrate using ffprobe.
+func GetMediaStreams(videoPath string) (int64, []datatypes.MediaStream, error) {
+	ffprobePath, err := GetFFprobePath()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	cmd := exec.Command(
+		ffprobePath,
+		"-loglevel", "error",
+		"-show_streams",
+		"-show_entries", "format=bit_rate",
+		"-of", "json",
+		videoPath,
+	)
+	out, err := cmd.Output()
+	if err != nil {
+		return 0, nil, fmt.Errorf("ffprobe failed: %w", err)
+	}
+
+	var result struct {
+		Streams []ffprobeStream `json:"streams"`
+		Format  struct {
+			BitRate string `json:"bit_rate"`
+		} `json:"format"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return 0, nil, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+
+	streams := make([]datatypes.MediaStream, 0, len(result.Streams))
+	for _, s := range result.Streams {
+		streams = append(streams, s.toMediaStream())
+	}
+
+	bitrate, _ := strconv.ParseInt(result.Format.BitRate, 10, 64)
+	return bitrate, streams, nil
+}
+
+func (s ffprobeStream) toMediaStream() datatypes.MediaStream {
+	ms := datatypes.MediaStream{
+		Index:    s.Index,
+		Type:     s.CodecType,
+		Codec:    s.CodecName,
+		Profile:  s.Profile,
+		Language: s.Tags["language"],
+		Title:    s.Tags["title"],
+		Default:  s.Disposition["default"] == 1,
+		Forced:   s.Disposition["forced"] == 1,
+	}
+	ms.Bitrate, _ = strconv.ParseInt(s.BitRate, 10, 64)
+
+	switch s.CodecType {
+	case datatypes.StreamTypeVideo:
+		ms.Width = s.Width
+		ms.Height = s.Height
+		ms.FrameRate = parseFrameRate(s.AvgFrameRate)
+		if ms.FrameRate == 0 {
+			ms.FrameRate = parseFrameRate(s.RFrameRate)
+		}
+		ms.PixelFormat = s.PixFmt
+		ms.ColorSpace = s.ColorSpace
+		ms.ColorTransfer = s.ColorTransfer
+		ms.ColorPrimaries = s.ColorPrimaries
+
+		switch s.ColorTransfer {
+		case "smpte2084":
+			ms.HDR = "HDR10"
+		case "arib-std-b67":
+			ms.HDR = "HLG"
+		}
+
+		// Older ffprobe versions report rotation as a tag, newer ones as display matrix side data
+		rotation, _ := strconv.Atoi(s.Tags["rotate"])
+		for _, sd := range s.SideDataList {
+			switch sd.SideDataType {
+			case "Display Matrix":
+				rotation = sd.Rotation
+			case "DOVI configuration record":
+				ms.HDR = "Dolby Vision"
+			}
+		}
+		ms.Rotation = ((rotation % 360) + 360) % 360
+
+	case datatypes.StreamTypeAudio:
+		ms.Channels = s.Channels
+		ms.ChannelLayout = s.ChannelLayout
+		ms.SampleRate, _ = strconv.Atoi(s.SampleRate)
+	}
+
+	return ms
+}
+
+// h264ProfileIDC maps ffprobe's H.264 profile names to profile_idc and constraint flags.
+var h264ProfileIDC = map[string]string{
+	"Constrained Baseline":  "42e0",
+	"Baseline":              "4200",
+	"Main":                  "4d00",
+	"Extended":              "5800",
+	"High":                  "6400",
+	"High 10":               "6e00",
+	"High 4:2:2":            "7a00",
+	"High 4:4:4 Predictive": "f400",
+}
+
+// aacObjectTypes maps ffprobe's AAC profile names to MPEG-4 audio object types.
+var aacObjectTypes = map[string]int{
+	"Main":      1,
+	"LC":        2,
+	"SSR":       3,
+	"LTP":       4,
+	"HE-AAC":    5,
+	"HE-AACv2":  29,
+	"HE-AAC v2": 29,
+	"LD":        23,
+	"ELD":       39,
+	"xHE-AAC":   42,
+	"USAC":      42,
+}
+
+// codecString approximates the RFC 6381 codec string of a stream from ffprobe's output,
+// for containers the MP4 box parser cannot read. Codecs without a known mapping use
+// ffprobe's codec name, which is what WebM MIME types expect (e.g. "vp9", "opus").
+func (s ffprobeStream) codecString() string {
+	switch s.CodecName {
+	case "h264":
+		if profile, ok := h264ProfileIDC[s.Profile]; ok && s.Level > 0 {
+			return fmt.Sprintf("avc1.%s%02x", profile, s.Level)
+		}
+		return "avc1"
+	case "hevc":
+		tag := "hvc1"
+		if s.CodecTagString == "hev1" {
+			tag = "hev1"
+		}
+		switch s.Profile {
+		case "Main":
+			return fmt.Sprintf("%s.1.6.L%d.B0", tag, s.Level)
+		case "Main 10":
+			return fmt.Sprintf("%s.2.4.L%d.B0", tag, s.Level)
+		}
+		return tag
+	case "aac":
+		if aot, ok := aacObjectTypes[s.Profile]; ok {
+			return fmt.Sprintf("mp4a.40.%d", aot)
+		}
+		return "mp4a.40.2"
+	case "mp3":
+		return "mp4a.6b"
+	}
+	return s.CodecName
+}
+
+// parseFrameRate parses an ffprobe rate such as "30000/1001"; it returns 0 for "0/0".
+func parseFrameRate(rate string) float64 {
+	num, den, found := strings.Cut(rate, "/")
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0
+	}
+	if !found {
+		return n
+	}
+	d, err := strconv.ParseFloat(den, 64)
+	if err != nil || d == 0 {
+		return 0
+	}
+	return roundFrameRate(n / d)
+}
+
+// RemuxWithAudioStream writes a copy of a video that keeps its video streams and only the
+// given audio stream, so players without audio track selection play that language.
+// Streams are copied without re-encoding.
+func RemuxWithAudioStream(videoPath string, audioStreamIndex int, outputPath string) error {
+	ffmpegPath, err := GetFFmpegPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	cmd := exec.Command(
+		ffmpegPath,
+		"-y",
+		"-loglevel", "error",
+		"-i", videoPath,
+		"-map", "0:v",
+		"-map", fmt.Sprintf("0:%d", audioStreamIndex),
+		"-c", "copy",
+		"-disposition:a:0", "default",
+		"-movflags", "+faststart",
+		outputPath,
+	)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		os.Remove(outputPath)
+		return fmt.Errorf("ffmpeg remux error: %v, output: %s", err, string(output))
+	}
+	return nil
+}
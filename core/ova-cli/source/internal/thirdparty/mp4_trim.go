@@ -0,0 +1,42 @@
+package thirdparty
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+)
+
+// StreamTrimmedMP4 re-encodes durationSec seconds of a video, starting at startSec, as a
+// fragmented H.264/AAC MP4 and writes it to w while ffmpeg produces it, so the output can be
+// sent to a client without a temporary file. Cancelling ctx stops ffmpeg.
+func StreamTrimmedMP4(ctx context.Context, inputPath string, startSec, durationSec float64, w io.Writer) error {
+	ffmpegPath, err := GetFFmpegPath()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, ffmpegPath,
+		"-ss", fmt.Sprintf("%.2f", startSec),
+		"-i", inputPath,
+		"-t", fmt.Sprintf("%.2f", durationSec),
+		"-c:v", "libx264",
+		"-c:a", "aac",
+		"-preset", "fast",
+		"-movflags", "frag_keyframe+empty_moov", // fragmented, so it can be written to a pipe
+		"-f", "mp4",
+		"pipe:1",
+	)
+	var stderr bytes.Buffer
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("ffmpeg trim error: %v, output: %s", err, stderr.String())
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package thirdparty
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// bitmapSubtitleCodecs are image based and cannot be converted to WebVTT.
+var bitmapSubtitleCodecs = map[string]bool{
+	"hdmv_pgs_subtitle": true,
+	"dvd_subtitle":      true,
+	"dvb_subtitle":      true,
+	"xsub":              true,
+}
+
+// IsTextSubtitleCodec reports whether a subtitle codec is text based and can be converted to WebVTT.
+func IsTextSubtitleCodec(codec string) bool {
+	return !bitmapSubtitleCodecs[codec]
+}
+
+// ExtractSubtitleToVTT converts one subtitle stream of a video to a WebVTT file.
+func ExtractSubtitleToVTT(videoPath string, streamIndex int, outputPath string) error {
+	return runSubtitleConversion(outputPath,
+		"-i", videoPath,
+		"-map", fmt.Sprintf("0:%d", streamIndex),
+	)
+}
+
+// ConvertSubtitleToVTT converts a subtitle file (e.g. .ass or .ssa) to a WebVTT file.
+func ConvertSubtitleToVTT(inputPath, outputPath string) error {
+	return runSubtitleConversion(outputPath, "-i", inputPath)
+}
+
+func runSubtitleConversion(outputPath string, inputArgs ...string) error {
+	ffmpegPath, err := GetFFmpegPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	args := append([]string{"-y", "-loglevel", "error"}, inputArgs...)
+	args = append(args, "-f", "webvtt", outputPath)
+
+	output, err := exec.Command(ffmpegPath, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("ffmpeg subtitle conversion error: %v, output: %s", err, string(output))
+	}
+	return nil
+}
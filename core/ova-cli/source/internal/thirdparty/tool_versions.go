@@ -0,0 +1,118 @@
+package thirdparty
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// GetFFmpegVersion returns the first line of `ffmpeg -version`, e.g. "ffmpeg version 6.1.1 Copyright ...".
+func GetFFmpegVersion() (string, error) {
+	ffmpegPath, err := GetFFmpegPath()
+	if err != nil {
+		return "", err
+	}
+	return firstOutputLine(ffmpegPath, "-hide_banner", "-version")
+}
+
+// GetFFprobeVersion returns the first line of `ffprobe -version`.
+func GetFFprobeVersion() (string, error) {
+	ffprobePath, err := GetFFprobePath()
+	if err != nil {
+		return "", err
+	}
+	return firstOutputLine(ffprobePath, "-hide_banner", "-version")
+}
+
+// GetOpenSSLVersion returns the output of `openssl version`, e.g. "OpenSSL 3.0.13 30 Jan 2024".
+func GetOpenSSLVersion() (string, error) {
+	opensslPath, err := GetOpenSSLPath()
+	if err != nil {
+		return "", err
+	}
+	return firstOutputLine(opensslPath, "version")
+}
+
+// GetBentoMP4InfoVersion returns the banner mp4info prints when run without arguments,
+// e.g. "MP4 File Info - Version 1.6.0". mp4info exits with an error after printing its
+// usage, so only a missing banner counts as a failure.
+func GetBentoMP4InfoVersion() (string, error) {
+	toolPath, err := GetBentoMP4InfoPath()
+	if err != nil {
+		return "", err
+	}
+
+	out, _ := exec.Command(toolPath).CombinedOutput()
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.Contains(line, "Version") {
+			return strings.TrimSpace(line), nil
+		}
+	}
+	return "", fmt.Errorf("failed to read mp4info version from its output")
+}
+
+// GetFFmpegEncoders returns the names of the encoders compiled into ffmpeg, e.g. "libx264".
+func GetFFmpegEncoders() (map[string]bool, error) {
+	return listFFmpegComponents("-encoders")
+}
+
+// GetFFmpegFilters returns the names of the filters compiled into ffmpeg, e.g. "scale".
+func GetFFmpegFilters() (map[string]bool, error) {
+	return listFFmpegComponents("-filters")
+}
+
+// listFFmpegComponents parses the table printed by `ffmpeg -encoders` or `ffmpeg -filters`.
+// Each row starts with a column of capability flags followed by the component name;
+// legend lines above the table read like " V..... = Video" and are skipped.
+func listFFmpegComponents(flag string) (map[string]bool, error) {
+	ffmpegPath, err := GetFFmpegPath()
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := exec.Command(ffmpegPath, "-hide_banner", flag).Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg %s failed: %w", flag, err)
+	}
+
+	components := map[string]bool{}
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[1] == "=" {
+			continue
+		}
+		components[fields[1]] = true
+	}
+	if len(components) == 0 {
+		return nil, fmt.Errorf("ffmpeg %s printed no components", flag)
+	}
+	return components, nil
+}
+
+// toolVersionPattern finds the first dotted version number in a version line,
+// allowing the "n" prefix of release builds (e.g. "ffmpeg version n7.0").
+var toolVersionPattern = regexp.MustCompile(`\bn?(\d+)\.(\d+)`)
+
+// ParseToolVersion extracts the major and minor version from a version line such as
+// "ffmpeg version 6.1.1-3ubuntu5" or "OpenSSL 3.0.13 30 Jan 2024". ok is false for
+// lines without a version number, such as ffmpeg git snapshots ("ffmpeg version N-113000-g...").
+func ParseToolVersion(line string) (major, minor int, ok bool) {
+	m := toolVersionPattern.FindStringSubmatch(line)
+	if m == nil {
+		return 0, 0, false
+	}
+	major, _ = strconv.Atoi(m[1])
+	minor, _ = strconv.Atoi(m[2])
+	return major, minor, true
+}
+
+func firstOutputLine(name string, args ...string) (string, error) {
+	out, err := exec.Command(name, args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %w", name, err)
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return strings.TrimSpace(line), nil
+}
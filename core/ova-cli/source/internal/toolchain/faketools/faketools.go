@@ -0,0 +1,381 @@
+package faketools
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"ova-cli/source/internal/datatypes"
+	"ova-cli/source/internal/interfaces"
+)
+
+// FakeTools implements the MediaToolchain interface in process, without any external
+// binaries. Every video is reported with the configured duration and resolution, and
+// artefacts are small but valid files whose content depends only on the input file's
+// content and the call arguments, so repeated runs produce identical output.
+type FakeTools struct {
+	DurationSec      float64 // Reported duration of every video
+	Width, Height    int     // Reported resolution of every video
+	FrameRate        float64 // Reported frame rate of every video
+	KeyframeInterval float64 // Seconds between generated keyframes
+
+	ContainerChapters []datatypes.VideoMarker // Chapters reported for every video
+
+	mu     sync.Mutex
+	calls  []string
+	errors map[string]error
+}
+
+// NewFakeTools returns a fake toolchain describing every video as a one minute
+// 1280x720 H.264/AAC MP4 with a keyframe every two seconds.
+func NewFakeTools() *FakeTools {
+	return &FakeTools{
+		DurationSec:      60,
+		Width:            1280,
+		Height:           720,
+		FrameRate:        30,
+		KeyframeInterval: 2,
+		errors:           map[string]error{},
+	}
+}
+
+// Ensure FakeTools implements the MediaToolchain interface.
+var _ interfaces.MediaToolchain = (*FakeTools)(nil)
+
+// FailOn makes every later call of the named method ("Probe", "Thumbnail", ...) return err.
+// Pass a nil error to make the method succeed again.
+func (t *FakeTools) FailOn(method string, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err == nil {
+		delete(t.errors, method)
+		return
+	}
+	t.errors[method] = err
+}
+
+// Calls returns the methods called so far, e.g. "Thumbnail movie.mp4", in call order.
+func (t *FakeTools) Calls() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.calls...)
+}
+
+// record logs a call and returns the error configured for the method, if any.
+func (t *FakeTools) record(method, path string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.calls = append(t.calls, method+" "+filepath.Base(path))
+	return t.errors[method]
+}
+
+func (t *FakeTools) Name() string {
+	return "fake"
+}
+
+func (t *FakeTools) Probe(videoPath string) (datatypes.VideoCodecs, error) {
+	if err := t.record("Probe", videoPath); err != nil {
+		return datatypes.VideoCodecs{}, err
+	}
+	if _, err := os.Stat(videoPath); err != nil {
+		return datatypes.VideoCodecs{}, err
+	}
+
+	return datatypes.VideoCodecs{
+		Format:      filepath.Ext(videoPath),
+		DurationSec: int(t.DurationSec),
+		FrameRate:   t.FrameRate,
+		Resolution:  datatypes.VideoResolution{Width: t.Width, Height: t.Height},
+		VideoCodec:  "avc1.640028",
+		AudioCodec:  "mp4a.40.2",
+		Bitrate:     2_000_000,
+		Streams: []datatypes.MediaStream{
+			{
+				Index: 0, Type: datatypes.StreamTypeVideo, Codec: "h264", Profile: "High", Default: true,
+				Width: t.Width, Height: t.Height, FrameRate: t.FrameRate, PixelFormat: "yuv420p",
+				Bitrate: 1_872_000,
+			},
+			{
+				Index: 1, Type: datatypes.StreamTypeAudio, Codec: "aac", Profile: "LC", Language: "eng", Default: true,
+				Channels: 2, ChannelLayout: "stereo", SampleRate: 48000, Bitrate: 128_000,
+			},
+		},
+	}, nil
+}
+
+func (t *FakeTools) Duration(videoPath string) (float64, error) {
+	if err := t.record("Duration", videoPath); err != nil {
+		return 0, err
+	}
+	if _, err := os.Stat(videoPath); err != nil {
+		return 0, err
+	}
+	return t.DurationSec, nil
+}
+
+func (t *FakeTools) Thumbnail(videoPath, outputPath string, timeSec float64) error {
+	if err := t.record("Thumbnail", videoPath); err != nil {
+		return err
+	}
+	if timeSec > t.DurationSec {
+		return fmt.Errorf("thumbnail time exceeds video duration")
+	}
+
+	seed, err := contentSeed(videoPath)
+	if err != nil {
+		return err
+	}
+	return writeJPEG(outputPath, 320, 320*t.Height/t.Width, frameColor(seed, timeSec))
+}
+
+func (t *FakeTools) Preview(videoPath, outputPath string, startSec, durationSec float64) error {
+	if err := t.record("Preview", videoPath); err != nil {
+		return err
+	}
+
+	seed, err := contentSeed(videoPath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	// EBML magic followed by a readable description of the clip
+	content := fmt.Sprintf("\x1a\x45\xdf\xa3fake preview %016x start=%.2f duration=%.2f\n", seed, startSec, durationSec)
+	return os.WriteFile(outputPath, []byte(content), 0644)
+}
+
+func (t *FakeTools) Keyframes(videoPath, outputDir string, width, height int) ([]float64, error) {
+	if err := t.record("Keyframes", videoPath); err != nil {
+		return nil, err
+	}
+
+	seed, err := contentSeed(videoPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output dir: %w", err)
+	}
+
+	var timestamps []float64
+	for i := 0; float64(i)*t.KeyframeInterval < t.DurationSec; i++ {
+		ts := float64(i) * t.KeyframeInterval
+		path := filepath.Join(outputDir, fmt.Sprintf("keyframe_%04d.jpg", i+1))
+		if err := writeJPEG(path, width, height, frameColor(seed, ts)); err != nil {
+			return nil, err
+		}
+		timestamps = append(timestamps, ts)
+	}
+	return timestamps, nil
+}
+
+// SpriteSheets writes one solid sheet per tile of frames, coloured from the frame names.
+func (t *FakeTools) SpriteSheets(framesDir, outputPattern, tile string, width, height int) error {
+	if err := t.record("SpriteSheets", framesDir); err != nil {
+		return err
+	}
+
+	colsStr, rowsStr, _ := strings.Cut(tile, "x")
+	cols, errCols := strconv.Atoi(colsStr)
+	rows, errRows := strconv.Atoi(rowsStr)
+	if errCols != nil || errRows != nil || cols <= 0 || rows <= 0 {
+		return fmt.Errorf("invalid tile format: %s", tile)
+	}
+
+	frames, err := filepath.Glob(filepath.Join(framesDir, "*.jpg"))
+	if err != nil {
+		return err
+	}
+	if len(frames) == 0 {
+		return fmt.Errorf("no keyframes found in %s", framesDir)
+	}
+	sort.Strings(frames)
+
+	perSheet := cols * rows
+	for i := 0; i < len(frames); i += perSheet {
+		h := fnv.New64a()
+		for _, frame := range frames[i:min(i+perSheet, len(frames))] {
+			h.Write([]byte(filepath.Base(frame)))
+		}
+		sheetPath := fmt.Sprintf(outputPattern, i/perSheet+1)
+		if err := writeJPEG(sheetPath, cols*width, rows*height, frameColor(h.Sum64(), 0)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExtractSubtitle writes a single cue naming the stream.
+func (t *FakeTools) ExtractSubtitle(videoPath string, streamIndex int, outputPath string) error {
+	if err := t.record("ExtractSubtitle", videoPath); err != nil {
+		return err
+	}
+	if _, err := os.Stat(videoPath); err != nil {
+		return err
+	}
+	return writeVTT(outputPath, fmt.Sprintf("stream %d of %s", streamIndex, filepath.Base(videoPath)))
+}
+
+// ConvertSubtitle writes a single cue naming the source file.
+func (t *FakeTools) ConvertSubtitle(inputPath, outputPath string) error {
+	if err := t.record("ConvertSubtitle", inputPath); err != nil {
+		return err
+	}
+	if _, err := os.Stat(inputPath); err != nil {
+		return err
+	}
+	return writeVTT(outputPath, "converted from "+filepath.Base(inputPath))
+}
+
+// RemuxAudio copies the video unchanged.
+func (t *FakeTools) RemuxAudio(videoPath string, audioStreamIndex int, outputPath string) error {
+	if err := t.record("RemuxAudio", videoPath); err != nil {
+		return err
+	}
+	return copyFile(videoPath, outputPath)
+}
+
+func (t *FakeTools) Chapters(videoPath string) ([]datatypes.VideoMarker, error) {
+	if err := t.record("Chapters", videoPath); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(videoPath); err != nil {
+		return nil, err
+	}
+	return append([]datatypes.VideoMarker(nil), t.ContainerChapters...), nil
+}
+
+// EmbedChapters copies the video unchanged; the metadata file must exist.
+func (t *FakeTools) EmbedChapters(videoPath, metadataPath, outputPath string) error {
+	if err := t.record("EmbedChapters", videoPath); err != nil {
+		return err
+	}
+	if _, err := os.Stat(metadataPath); err != nil {
+		return err
+	}
+	return copyFile(videoPath, outputPath)
+}
+
+// Transcode copies the video unchanged.
+func (t *FakeTools) Transcode(inputPath, outputPath string) error {
+	if err := t.record("Transcode", inputPath); err != nil {
+		return err
+	}
+	return copyFile(inputPath, outputPath)
+}
+
+// Trim writes the part of the video file proportional to the requested range of the
+// configured duration, so different ranges give different output.
+func (t *FakeTools) Trim(ctx context.Context, videoPath string, startSec, durationSec float64, w io.Writer) error {
+	if err := t.record("Trim", videoPath); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(videoPath)
+	if err != nil {
+		return err
+	}
+
+	from := min(len(data), int(float64(len(data))*startSec/t.DurationSec))
+	to := min(len(data), from+int(float64(len(data))*durationSec/t.DurationSec))
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	_, err = w.Write(data[from:to])
+	return err
+}
+
+// Fragment leaves the file untouched, so video IDs derived from its content stay stable.
+func (t *FakeTools) Fragment(videoPath string) error {
+	if err := t.record("Fragment", videoPath); err != nil {
+		return err
+	}
+	_, err := os.Stat(videoPath)
+	return err
+}
+
+// copyFile copies src to dst, creating the folder of dst.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// writeVTT writes a WebVTT file with one cue holding text.
+func writeVTT(path, text string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	return os.WriteFile(path, []byte("WEBVTT\n\n00:00:00.000 --> 00:00:02.000\n"+text+"\n"), 0644)
+}
+
+// contentSeed hashes the first 64 KiB of a file, so artefacts depend on the video rather than its path.
+func contentSeed(path string) (uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	h := fnv.New64a()
+	if _, err := io.CopyN(h, f, 64<<10); err != nil && err != io.EOF {
+		return 0, err
+	}
+	return h.Sum64(), nil
+}
+
+// frameColor derives a colour from the content seed and timestamp.
+func frameColor(seed uint64, timeSec float64) color.RGBA {
+	v := seed ^ uint64(timeSec*1000)*0x9e3779b97f4a7c15
+	return color.RGBA{R: uint8(v), G: uint8(v >> 8), B: uint8(v >> 16), A: 255}
+}
+
+func writeJPEG(path string, width, height int, c color.RGBA) error {
+	if width <= 0 || height <= 0 {
+		return fmt.Errorf("invalid image size %dx%d", width, height)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := jpeg.Encode(f, img, &jpeg.Options{Quality: 75}); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,124 @@
+package ffmpegtools
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"ova-cli/source/internal/datatypes"
+	"ova-cli/source/internal/interfaces"
+	"ova-cli/source/internal/thirdparty"
+)
+
+// FFmpegTools implements the MediaToolchain interface with the ffmpeg and ffprobe
+// binaries shipped next to the ova executable.
+type FFmpegTools struct{}
+
+// NewFFmpegTools returns the ffmpeg-backed media toolchain.
+func NewFFmpegTools() *FFmpegTools {
+	return &FFmpegTools{}
+}
+
+// Ensure FFmpegTools implements the MediaToolchain interface.
+var _ interfaces.MediaToolchain = (*FFmpegTools)(nil)
+
+func (t *FFmpegTools) Name() string {
+	return "ffmpeg"
+}
+
+func (t *FFmpegTools) Probe(videoPath string) (datatypes.VideoCodecs, error) {
+	details, err := thirdparty.GetVideoDetails(videoPath)
+	if err != nil {
+		return datatypes.VideoCodecs{}, err
+	}
+
+	bitrate, streams, err := thirdparty.GetMediaStreams(videoPath)
+	if err != nil {
+		return datatypes.VideoCodecs{}, fmt.Errorf("failed to get streams: %w", err)
+	}
+
+	return datatypes.VideoCodecs{
+		DurationSec: details.DurationSec,
+		FrameRate:   details.FrameRate,
+		Resolution:  datatypes.VideoResolution(details.Resolution),
+		VideoCodec:  details.VideoCodec,
+		AudioCodec:  details.AudioCodec,
+		Format:      details.Format,
+		IsFragment:  details.IsFragment,
+		Bitrate:     bitrate,
+		Streams:     streams,
+	}, nil
+}
+
+func (t *FFmpegTools) Duration(videoPath string) (float64, error) {
+	return thirdparty.GetVideoDuration(videoPath)
+}
+
+func (t *FFmpegTools) Thumbnail(videoPath, outputPath string, timeSec float64) error {
+	return thirdparty.GenerateImageFromVideo(videoPath, outputPath, timeSec)
+}
+
+func (t *FFmpegTools) Preview(videoPath, outputPath string, startSec, durationSec float64) error {
+	return thirdparty.GenerateWebMFromVideo(videoPath, outputPath, startSec, durationSec)
+}
+
+func (t *FFmpegTools) Keyframes(videoPath, outputDir string, width, height int) ([]float64, error) {
+	if err := thirdparty.ExtractKeyframes(videoPath, outputDir, width, height); err != nil {
+		return nil, err
+	}
+	return thirdparty.GetKeyframePacketTimestamps(videoPath)
+}
+
+func (t *FFmpegTools) SpriteSheets(framesDir, outputPattern, tile string, width, height int) error {
+	return thirdparty.GenerateSpriteSheetsFromFolder(framesDir, outputPattern, tile, width, height)
+}
+
+func (t *FFmpegTools) ExtractSubtitle(videoPath string, streamIndex int, outputPath string) error {
+	return thirdparty.ExtractSubtitleToVTT(videoPath, streamIndex, outputPath)
+}
+
+func (t *FFmpegTools) ConvertSubtitle(inputPath, outputPath string) error {
+	return thirdparty.ConvertSubtitleToVTT(inputPath, outputPath)
+}
+
+func (t *FFmpegTools) RemuxAudio(videoPath string, audioStreamIndex int, outputPath string) error {
+	return thirdparty.RemuxWithAudioStream(videoPath, audioStreamIndex, outputPath)
+}
+
+func (t *FFmpegTools) Chapters(videoPath string) ([]datatypes.VideoMarker, error) {
+	chapters, err := thirdparty.GetContainerChapters(videoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	markers := make([]datatypes.VideoMarker, 0, len(chapters))
+	for _, ch := range chapters {
+		markers = append(markers, datatypes.VideoMarker{
+			StartMs: ch.StartMs,
+			EndMs:   ch.EndMs,
+			Title:   ch.Title,
+		})
+	}
+	return markers, nil
+}
+
+func (t *FFmpegTools) EmbedChapters(videoPath, metadataPath, outputPath string) error {
+	return thirdparty.EmbedChapters(videoPath, metadataPath, outputPath)
+}
+
+func (t *FFmpegTools) Transcode(inputPath, outputPath string) error {
+	return thirdparty.ConvertToMP4(inputPath, outputPath)
+}
+
+func (t *FFmpegTools) Trim(ctx context.Context, videoPath string, startSec, durationSec float64, w io.Writer) error {
+	return thirdparty.StreamTrimmedMP4(ctx, videoPath, startSec, durationSec, w)
+}
+
+func (t *FFmpegTools) Fragment(videoPath string) error {
+	if _, err := os.Stat(videoPath); err != nil {
+		return fmt.Errorf("cannot fragment %s: %w", filepath.Base(videoPath), err)
+	}
+	return thirdparty.ConvertMP4ToFragmentedMP4InPlace(videoPath)
+}
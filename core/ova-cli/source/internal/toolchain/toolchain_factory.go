@@ -0,0 +1,22 @@
+package toolchain
+
+import (
+	"fmt"
+	"ova-cli/source/internal/interfaces"
+	"ova-cli/source/internal/toolchain/faketools"
+	"ova-cli/source/internal/toolchain/ffmpegtools"
+)
+
+// NewMediaToolchain creates a media toolchain of the given type.
+// toolchainType can be "ffmpeg" (the bundled ffmpeg/ffprobe binaries) or "fake"
+// (in-process, deterministic artefacts for tests).
+func NewMediaToolchain(toolchainType string) (interfaces.MediaToolchain, error) {
+	switch toolchainType {
+	case "ffmpeg":
+		return ffmpegtools.NewFFmpegTools(), nil
+	case "fake":
+		return faketools.NewFakeTools(), nil
+	default:
+		return nil, fmt.Errorf("unknown media toolchain: %s", toolchainType)
+	}
+}
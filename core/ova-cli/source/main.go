@@ -27,6 +27,7 @@ Ideal for efficiently hosting online video libraries.`,
 	cmd.InitCommandIndex(rootCmd)
 
 	cmd.InitCommandSpace(rootCmd)
+	cmd.InitCommandPlaylist(rootCmd)
 
 	cmd.InitCommandDocs(rootCmd)
 
@@ -37,13 +38,19 @@ Ideal for efficiently hosting online video libraries.`,
 
 	// storage commands
 	cmd.InitCommandVideo(rootCmd)
+	cmd.InitCommandTrash(rootCmd)
+	cmd.InitCommandExport(rootCmd)
+	cmd.InitCommandImport(rootCmd)
 	cmd.InitCommandUsers(rootCmd)
+	cmd.InitCommandSession(rootCmd)
+	cmd.InitCommandCache(rootCmd)
 
 	cmd.InitCommandConfig(rootCmd)
 
 	// version command
 	cmd.InitCommandVersion(rootCmd)
 	cmd.InitCommandDebug(rootCmd)
+	cmd.InitCommandDoctor(rootCmd)
 
 	rootCmd.Execute()
 	// Initialize the root command and add subcommands